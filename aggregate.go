@@ -0,0 +1,135 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+var ErrNoData = errors.New("No data in the time window")
+
+// Returns the mean of a field over the last window, for the series matching tags (all series if nil)
+func (timeserData *TimeSeriesClientData) GetMean(measurement, field string, window time.Duration, tags map[string]string) (float64, error) {
+	return timeserData.aggregate(measurement, fmt.Sprintf("MEAN(%v)", _quoteIdent(field)), window, tags)
+}
+
+// Returns the maximum of a field over the last window, for the series matching tags (all series if nil)
+func (timeserData *TimeSeriesClientData) GetMax(measurement, field string, window time.Duration, tags map[string]string) (float64, error) {
+	return timeserData.aggregate(measurement, fmt.Sprintf("MAX(%v)", _quoteIdent(field)), window, tags)
+}
+
+// Returns the minimum of a field over the last window, for the series matching tags (all series if nil)
+func (timeserData *TimeSeriesClientData) GetMin(measurement, field string, window time.Duration, tags map[string]string) (float64, error) {
+	return timeserData.aggregate(measurement, fmt.Sprintf("MIN(%v)", _quoteIdent(field)), window, tags)
+}
+
+// Returns the percentile (0 to 100) of a field over the last window, for the series matching tags (all series if nil)
+func (timeserData *TimeSeriesClientData) GetPercentile(measurement, field string, percentile float64, window time.Duration, tags map[string]string) (float64, error) {
+	if percentile < 0 || percentile > 100 {
+		return 0, fmt.Errorf("Percentile %v out of range 0-100", percentile)
+	}
+	return timeserData.aggregate(measurement, fmt.Sprintf("PERCENTILE(%v, %v)", _quoteIdent(field), percentile), window, tags)
+}
+
+// Returns the mean per second rate of increase of a counter field over the last window, for the series matching
+// tags (all series if nil). The rate is taken between the values of each series, decreases, as on a counter reset,
+// are ignored
+func (timeserData *TimeSeriesClientData) GetRate(measurement, field string, window time.Duration, tags map[string]string) (float64, error) {
+	subquery := fmt.Sprintf("SELECT NON_NEGATIVE_DERIVATIVE(%v, 1s) AS rate FROM %v WHERE %v GROUP BY *", _quoteIdent(field), timeserData.measurementIdent(measurement), _windowCondition(window, tags))
+	return timeserData.aggregateQuery(measurement, fmt.Sprintf("SELECT MEAN(rate) FROM (%v)", subquery))
+}
+
+// Returns the rate of increase per unit (1s if 0) of a counter field between its consecutive values in
+// [start, stop), eg. the transmitted bytes per second, in chronological order. Decreases, as on a counter reset,
+// are skipped. tags should match a single series, the values of several series are mixed
+func (timeserData *TimeSeriesClientData) GetDerivative(measurement, field string, unit time.Duration, start, stop time.Time, tags map[string]string) ([]TimedValue, error) {
+	if unit <= 0 {
+		unit = time.Second
+	}
+	queryStr := fmt.Sprintf("SELECT NON_NEGATIVE_DERIVATIVE(%v, %v) FROM %v WHERE %v", _quoteIdent(field), _durationLiteral(unit),
+		timeserData.measurementIdent(measurement), _whereClause(_tagCondition(tags), start, stop))
+	values, err := timeserData.selectValues(measurement, field, queryStr)
+	if err != nil {
+		return nil, err
+	}
+	for i := range values {
+		if values[i].Value, err = _toFloat64(values[i].Value); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// Returns the number of values of a field in [start, stop), for the series matching tags (all series if nil),
+// eg. to check whether an E2 node has reported recently
+func (timeserData *TimeSeriesClientData) Count(measurement, field string, start, stop time.Time, tags map[string]string) (int64, error) {
+	count, err := timeserData.aggregateQuery(measurement, fmt.Sprintf("SELECT COUNT(%v) FROM %v WHERE %v", _quoteIdent(field),
+		timeserData.measurementIdent(measurement), _whereClause(_tagCondition(tags), start, stop)))
+	if err == ErrNoData {
+		return 0, nil
+	}
+	return int64(count), err
+}
+
+// Runs the aggregate selector over the last window
+func (timeserData *TimeSeriesClientData) aggregate(measurement, selector string, window time.Duration, tags map[string]string) (float64, error) {
+	return timeserData.aggregateQuery(measurement, fmt.Sprintf("SELECT %v FROM %v WHERE %v", selector, timeserData.measurementIdent(measurement), _windowCondition(window, tags)))
+}
+
+// Runs an aggregate query and returns its single value
+func (timeserData *TimeSeriesClientData) aggregateQuery(measurement, queryStr string) (float64, error) {
+	q := timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, "")
+	response, err := timeserData.query(q)
+	if err != nil {
+		timeserData.logger().Errorf("Failed to aggregate %v with error %v\n", measurement, err)
+		return 0, err
+	}
+
+	for _, result := range response.Results {
+		for _, row := range result.Series {
+			for _, value := range row.Values {
+				// Columns are time and the aggregate
+				if len(value) < 2 || value[1] == nil {
+					continue
+				}
+				f, err := _toFloat64(value[1])
+				timeserData.logger().Debugf("TimeSeriesDB Aggregate: DB=%v, QueryString=%v, Result=%v, err=%v\n", timeserData.timeSeriesDbName, queryStr, f, err)
+				return f, err
+			}
+		}
+	}
+	return 0, ErrNoData
+}
+
+// Restricts to the last window and the tags
+func _windowCondition(window time.Duration, tags map[string]string) string {
+	if window < 0 {
+		window = -window
+	}
+	condition := "time >= now() - " + _durationLiteral(window)
+	if tagCondition := _tagCondition(tags); tagCondition != "" {
+		condition += " AND " + tagCondition
+	}
+	return condition
+}
@@ -0,0 +1,131 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"stslgo"
+	"testing"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for the aggregation helpers
+func TestTimeSeriesDbAggregates(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return seriesResp("CellKpi", []string{"time", "mean"}, []interface{}{"1970-01-01T00:00:00Z", json.Number("2.5")}), nil
+	}
+	cell := map[string]string{"cellId": "c1"}
+	for _, tc := range []struct {
+		get   func() (float64, error)
+		query string
+	}{
+		{func() (float64, error) { return timeserData.GetMean("CellKpi", "prb", 5*time.Minute, cell) },
+			`SELECT MEAN("prb") FROM "CellKpi" WHERE time >= now() - 5m AND "cellId" = 'c1'`},
+		{func() (float64, error) { return timeserData.GetMax("CellKpi", "prb", time.Hour, nil) },
+			`SELECT MAX("prb") FROM "CellKpi" WHERE time >= now() - 1h`},
+		{func() (float64, error) { return timeserData.GetMin("CellKpi", "prb", time.Hour, nil) },
+			`SELECT MIN("prb") FROM "CellKpi" WHERE time >= now() - 1h`},
+		{func() (float64, error) { return timeserData.GetPercentile("CellKpi", "prb", 95, time.Hour, nil) },
+			`SELECT PERCENTILE("prb", 95) FROM "CellKpi" WHERE time >= now() - 1h`},
+		{func() (float64, error) { return timeserData.GetRate("CellKpi", "bytes", time.Minute, cell) },
+			`SELECT MEAN(rate) FROM (SELECT NON_NEGATIVE_DERIVATIVE("bytes", 1s) AS rate FROM "CellKpi" WHERE time >= now() - 1m AND "cellId" = 'c1' GROUP BY *)`},
+	} {
+		issuedQueries = nil
+		value, err := tc.get()
+		if err != nil || value != 2.5 {
+			t.Errorf("Expected 2.5, got %v with error %v", value, err)
+		}
+		if len(issuedQueries) != 1 || issuedQueries[0] != tc.query {
+			t.Errorf("Unexpected queries %v", issuedQueries)
+		}
+	}
+
+	if _, err = timeserData.GetPercentile("CellKpi", "prb", 101, time.Hour, nil); err == nil {
+		t.Errorf("Expected error for percentile out of range")
+	}
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{}}}, nil
+	}
+	if _, err = timeserData.GetMean("CellKpi", "prb", time.Hour, nil); err != stslgo.ErrNoData {
+		t.Errorf("Expected ErrNoData, got %v", err)
+	}
+}
+
+// Test function for counting the values of a field in a time range
+func TestTimeSeriesDbCount(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return seriesResp("CellKpi", []string{"time", "count"}, []interface{}{"2024-01-01T00:00:00Z", json.Number("12")}), nil
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	count, err := timeserData.Count("CellKpi", "prb", start, start.Add(time.Minute), map[string]string{"cellId": "c1"})
+	if err != nil || count != 12 {
+		t.Errorf("Expected 12, got %v with error %v", count, err)
+	}
+	expected := `SELECT COUNT("prb") FROM "CellKpi" WHERE ("cellId" = 'c1') AND time >= '2024-01-01T00:00:00Z' AND time < '2024-01-01T00:01:00Z'`
+	if len(issuedQueries) != 1 || issuedQueries[0] != expected {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{}}}, nil
+	}
+	if count, err = timeserData.Count("CellKpi", "prb", start, start.Add(time.Minute), nil); err != nil || count != 0 {
+		t.Errorf("Expected 0 for no data, got %v with error %v", count, err)
+	}
+}
+
+// Test function for the rate of increase of a counter between its values
+func TestTimeSeriesDbDerivative(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return seriesResp("CellKpi", []string{"time", "non_negative_derivative"},
+			[]interface{}{"2024-01-01T00:00:10Z", json.Number("1000")}, []interface{}{"2024-01-01T00:00:20Z", json.Number("1500")}), nil
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	values, err := timeserData.GetDerivative("CellKpi", "txBytes", time.Minute, start, start.Add(time.Minute), map[string]string{"cellId": "c1"})
+	if err != nil || len(values) != 2 || values[0].Value != 1000.0 || values[1].Value != 1500.0 || values[1].Time.Second() != 20 {
+		t.Errorf("Unexpected values %v, error %v", values, err)
+	}
+	_, _ = timeserData.GetDerivative("CellKpi", "txBytes", 0, start, start.Add(time.Minute), nil)
+	expected := []string{
+		`SELECT NON_NEGATIVE_DERIVATIVE("txBytes", 1m) FROM "CellKpi" WHERE ("cellId" = 'c1') AND time >= '2024-01-01T00:00:00Z' AND time < '2024-01-01T00:01:00Z'`,
+		`SELECT NON_NEGATIVE_DERIVATIVE("txBytes", 1s) FROM "CellKpi" WHERE time >= '2024-01-01T00:00:00Z' AND time < '2024-01-01T00:01:00Z'`,
+	}
+	if fmt.Sprint(issuedQueries) != fmt.Sprint(expected) {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+}
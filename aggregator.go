@@ -0,0 +1,289 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Aggregate computed by an Aggregator over the samples of a field in a window
+type AggregateFunction string
+
+const (
+	AggregateMean  AggregateFunction = "mean"
+	AggregateMin   AggregateFunction = "min"
+	AggregateMax   AggregateFunction = "max"
+	AggregateCount AggregateFunction = "count"
+	AggregateSum   AggregateFunction = "sum"
+	AggregateLast  AggregateFunction = "last"
+)
+
+// Configuration of an Aggregator
+type AggregatorConfig struct {
+	Window      time.Duration       // Length of the windows, aligned on the epoch
+	Functions   []AggregateFunction // Aggregates written per field, as <field>_<function>. Mean, min, max and count when empty
+	Lateness    time.Duration       // Time a window stays open after its end for the samples arriving late
+	Measurement func(string) string // Name of the measurement of the aggregates, the one of the samples when nil
+}
+
+var ErrAggregatorClosed = errors.New("Aggregator closed")
+
+// Aggregates the samples of a series and window into a single point, written once the window is closed, so that
+// high rate metrics (eg. per UE every second) are stored as eg. 10s mean/min/max/count. Samples are not written
+type Aggregator struct {
+	timeserData *TimeSeriesClientData
+	config      AggregatorConfig
+
+	lock      sync.Mutex
+	windows   map[string]*aggregateWindow
+	watermark time.Time // Windows starting before are written, their late samples are dropped
+	closed    bool
+
+	flushLock sync.Mutex
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+type aggregateWindow struct {
+	measurement string
+	tags        map[string]string
+	start       time.Time
+	fields      map[string]*aggregateState
+}
+
+type aggregateState struct {
+	count         int
+	sum, min, max float64
+	last          float64
+	lastTime      time.Time
+}
+
+// Creates an Aggregator writing to the DB of the client, which closes the windows every config.Window. It must
+// be closed with Close() to write the open windows
+func (timeserData *TimeSeriesClientData) NewAggregator(config AggregatorConfig) (*Aggregator, error) {
+	if config.Window <= 0 {
+		return nil, fmt.Errorf("Invalid aggregation window %v", config.Window)
+	}
+	if len(config.Functions) == 0 {
+		config.Functions = []AggregateFunction{AggregateMean, AggregateMin, AggregateMax, AggregateCount}
+	}
+	for _, function := range config.Functions {
+		switch function {
+		case AggregateMean, AggregateMin, AggregateMax, AggregateCount, AggregateSum, AggregateLast:
+		default:
+			return nil, fmt.Errorf("Unknown aggregate function %v", function)
+		}
+	}
+	agg := &Aggregator{
+		timeserData: timeserData,
+		config:      config,
+		windows:     make(map[string]*aggregateWindow),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go agg.run()
+	timeserData.track(agg, true, agg.Close)
+	return agg, nil
+}
+
+// Adds a sample stamped with the current time
+func (agg *Aggregator) WritePoint(measurement string, tags map[string]string, fields map[string]interface{}) error {
+	return agg.Add(measurement, tags, fields, time.Now())
+}
+
+// Adds a sample to the window of t. The fields must be numeric. Samples of windows already written are dropped
+func (agg *Aggregator) Add(measurement string, tags map[string]string, fields map[string]interface{}, t time.Time) error {
+	values := make(map[string]float64, len(fields))
+	for field, value := range fields {
+		var err error
+		switch value.(type) {
+		case string, bool:
+			err = fmt.Errorf("Not a number: %v", value)
+		case float32:
+			values[field] = float64(value.(float32))
+		default:
+			values[field], err = _toFloat64(value)
+		}
+		if err != nil {
+			return fmt.Errorf("Field %v of %v cannot be aggregated: %v", field, measurement, err)
+		}
+		if math.IsNaN(values[field]) || math.IsInf(values[field], 0) {
+			delete(values, field)
+		}
+	}
+
+	start := _windowStart(t, agg.config.Window)
+	agg.lock.Lock()
+	defer agg.lock.Unlock()
+	if agg.closed {
+		return ErrAggregatorClosed
+	}
+	if start.Before(agg.watermark) {
+		agg.timeserData.reportDropped(1, "aggregator_late")
+		return nil
+	}
+	key := _seriesKey(measurement, tags) + "\x00" + strconv.FormatInt(start.UnixNano(), 10)
+	window, ok := agg.windows[key]
+	if !ok {
+		window = &aggregateWindow{measurement: measurement, tags: make(map[string]string, len(tags)), start: start, fields: make(map[string]*aggregateState)}
+		for tag, value := range tags {
+			window.tags[tag] = value
+		}
+		agg.windows[key] = window
+	}
+	for field, value := range values {
+		state, ok := window.fields[field]
+		if !ok {
+			state = &aggregateState{min: value, max: value}
+			window.fields[field] = state
+		}
+		state.count++
+		state.sum += value
+		state.min = math.Min(state.min, value)
+		state.max = math.Max(state.max, value)
+		if !t.Before(state.lastTime) {
+			state.last, state.lastTime = value, t
+		}
+	}
+	return nil
+}
+
+// Writes the windows ended for longer than the lateness
+func (agg *Aggregator) Flush() error {
+	return agg.flush(_windowStart(time.Now().Add(-agg.config.Lateness), agg.config.Window))
+}
+
+// Returns the start of the window of t, aligned on the Unix epoch like GROUP BY time() (time.Truncate aligns on
+// year 1, which differs for windows not dividing a day)
+func _windowStart(t time.Time, window time.Duration) time.Time {
+	offset := time.Duration(t.UnixNano() % int64(window))
+	if offset < 0 {
+		offset += window
+	}
+	return t.Add(-offset).Round(0)
+}
+
+// Writes the open windows and stops the periodic flush. Returns the error of the final write
+func (agg *Aggregator) Close() error {
+	agg.lock.Lock()
+	if agg.closed {
+		agg.lock.Unlock()
+		return ErrAggregatorClosed
+	}
+	agg.closed = true
+	agg.lock.Unlock()
+
+	agg.timeserData.untrack(agg)
+	close(agg.stop)
+	<-agg.done
+	return agg.flush(time.Unix(math.MaxInt32, 0))
+}
+
+// Writes the windows starting before watermark
+func (agg *Aggregator) flush(watermark time.Time) error {
+	agg.flushLock.Lock()
+	defer agg.flushLock.Unlock()
+
+	agg.lock.Lock()
+	var windows []*aggregateWindow
+	for key, window := range agg.windows {
+		if window.start.Before(watermark) {
+			windows = append(windows, window)
+			delete(agg.windows, key)
+		}
+	}
+	if watermark.After(agg.watermark) {
+		agg.watermark = watermark
+	}
+	agg.lock.Unlock()
+	if len(windows) == 0 {
+		return nil
+	}
+
+	bp, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+		Database:  agg.timeserData.timeSeriesDbName,
+		Precision: agg.timeserData.writePrecision(),
+	})
+	for _, window := range windows {
+		pt, err := agg.point(window)
+		if err != nil {
+			agg.timeserData.logger().Errorf("Error: %s", err.Error())
+			continue
+		}
+		bp.AddPoint(pt)
+	}
+	if err := agg.timeserData.write(bp); err != nil {
+		agg.timeserData.logger().Warnf("TimeSeriesDB Aggregator failed to write %v points: %v\n", len(bp.Points()), err)
+		agg.timeserData.reportDropped(len(bp.Points()), "aggregator_write_failure")
+		return err
+	}
+	agg.timeserData.logger().Debugf("TimeSeriesDB Aggregator: DB=%v wrote %v points\n", agg.timeserData.timeSeriesDbName, len(bp.Points()))
+	return nil
+}
+
+// Returns the point of the aggregates of a window
+func (agg *Aggregator) point(window *aggregateWindow) (*timesrclient.Point, error) {
+	fields := make(map[string]interface{}, len(window.fields)*len(agg.config.Functions))
+	for field, state := range window.fields {
+		for _, function := range agg.config.Functions {
+			name := field + "_" + string(function)
+			switch function {
+			case AggregateMean:
+				fields[name] = state.sum / float64(state.count)
+			case AggregateMin:
+				fields[name] = state.min
+			case AggregateMax:
+				fields[name] = state.max
+			case AggregateCount:
+				fields[name] = state.count
+			case AggregateSum:
+				fields[name] = state.sum
+			case AggregateLast:
+				fields[name] = state.last
+			}
+		}
+	}
+	measurement := window.measurement
+	if agg.config.Measurement != nil {
+		measurement = agg.config.Measurement(measurement)
+	}
+	return timesrclient.NewPoint(measurement, window.tags, fields, window.start)
+}
+
+func (agg *Aggregator) run() {
+	defer close(agg.done)
+	ticker := time.NewTicker(agg.config.Window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-agg.stop:
+			return
+		case <-ticker.C:
+			_ = agg.Flush()
+		}
+	}
+}
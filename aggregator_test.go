@@ -0,0 +1,97 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"stslgo"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for writing the aggregates of windows instead of the samples
+func TestTimeSeriesDbAggregator(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	if _, err = timeserData.NewAggregator(stslgo.AggregatorConfig{}); err == nil {
+		t.Errorf("Expected an error without window")
+	}
+	agg, err := timeserData.NewAggregator(stslgo.AggregatorConfig{Window: 10 * time.Second})
+	if err != nil {
+		t.Fatalf("Unable to create the aggregator with error %v", err)
+	}
+
+	start := time.Now().Add(-time.Minute).Truncate(10 * time.Second)
+	tags := map[string]string{"ue": "1"}
+	for i := 0; i < 15; i++ {
+		if err = agg.Add("UeTable", tags, map[string]interface{}{"thp": i}, start.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("Unable to add sample with error %v", err)
+		}
+	}
+	if err = agg.Add("UeTable", tags, map[string]interface{}{"cell": "a"}, start); err == nil {
+		t.Errorf("Expected an error for a string field")
+	}
+	if len(writtenPoints) != 0 {
+		t.Errorf("Expected no sample written, got %v", writtenPoints)
+	}
+
+	if err = agg.Flush(); err != nil || len(writtenPoints) != 2 {
+		t.Fatalf("Expected 2 windows written, got %v with error %v", writtenPoints, err)
+	}
+	var first *timesrclient.Point
+	for _, pt := range writtenPoints {
+		if pt.Time().Equal(start) {
+			first = pt
+		}
+	}
+	if first == nil {
+		t.Fatalf("No point for the first window in %v", writtenPoints)
+	}
+	fields, _ := first.Fields()
+	if first.Name() != "UeTable" || first.Tags()["ue"] != "1" || fields["thp_mean"] != 4.5 || fields["thp_min"] != 0.0 ||
+		fields["thp_max"] != 9.0 || fields["thp_count"] != int64(10) {
+		t.Errorf("Unexpected aggregates %v", first)
+	}
+
+	// Samples of windows already written are dropped, open windows are written on close
+	writtenPoints = nil
+	_ = agg.Add("UeTable", tags, map[string]interface{}{"thp": 1}, start)
+	_ = agg.WritePoint("UeTable", tags, map[string]interface{}{"thp": 1})
+	if err = agg.Close(); err != nil || len(writtenPoints) != 1 {
+		t.Errorf("Expected the open window written on close, got %v with error %v", writtenPoints, err)
+	}
+	if agg.WritePoint("UeTable", tags, map[string]interface{}{"thp": 1}) != stslgo.ErrAggregatorClosed {
+		t.Errorf("Expected ErrAggregatorClosed after Close")
+	}
+
+	// Windows are aligned on the Unix epoch, as GROUP BY time()
+	writtenPoints = nil
+	agg, _ = timeserData.NewAggregator(stslgo.AggregatorConfig{Window: 7 * time.Second})
+	_ = agg.Add("UeTable", tags, map[string]interface{}{"thp": 1}, time.Unix(14, 0))
+	_ = agg.Add("UeTable", tags, map[string]interface{}{"thp": 2}, time.Unix(20, 0))
+	if err = agg.Close(); err != nil || len(writtenPoints) != 1 || !writtenPoints[0].Time().Equal(time.Unix(14, 0)) {
+		t.Errorf("Expected a single window at %v, got %v with error %v", time.Unix(14, 0), writtenPoints, err)
+	}
+}
@@ -0,0 +1,208 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Comparison of the windowed mean of a field against the threshold of an AlertRule
+type AlertCondition int
+
+const (
+	AlertAbove AlertCondition = iota // Fires when the mean is above the threshold
+	AlertBelow                       // Fires when the mean is below the threshold
+)
+
+// Severity of an AlertRule
+type AlertSeverity int
+
+const (
+	AlertInfo AlertSeverity = iota
+	AlertWarning
+	AlertCritical
+)
+
+var alertSeverityNames = map[AlertSeverity]string{AlertInfo: "info", AlertWarning: "warning", AlertCritical: "critical"}
+
+func (severity AlertSeverity) String() string {
+	return alertSeverityNames[severity]
+}
+
+// Threshold rule on the mean of a field over the last window, optionally for the series matching Tags
+type AlertRule struct {
+	Name        string
+	Measurement string
+	Field       string
+	Tags        map[string]string
+	Condition   AlertCondition
+	Threshold   float64
+	Window      time.Duration
+	Severity    AlertSeverity
+}
+
+// State change of an AlertRule, Firing is false when the alert is resolved
+type Alert struct {
+	Rule   AlertRule
+	Value  float64
+	Time   time.Time
+	Firing bool
+}
+
+// Evaluates AlertRules client side and calls the handler when a rule starts or stops firing
+type Alerts struct {
+	timeserData *TimeSeriesClientData
+	handler     func(Alert)
+	lock        sync.Mutex
+	rules       map[string]AlertRule
+	firing      map[string]bool
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+// Creates an empty set of alert rules evaluated against the DB of the client
+func (timeserData *TimeSeriesClientData) NewAlerts(handler func(Alert)) *Alerts {
+	return &Alerts{
+		timeserData: timeserData,
+		handler:     handler,
+		rules:       make(map[string]AlertRule),
+		firing:      make(map[string]bool),
+	}
+}
+
+// Adds a rule, replacing the one with the same name
+func (alerts *Alerts) AddRule(rule AlertRule) error {
+	if rule.Name == "" || rule.Measurement == "" || rule.Field == "" {
+		return errors.New("Alert rule needs a name, measurement and field")
+	}
+	if rule.Window <= 0 {
+		return errors.New("Alert rule window must be positive")
+	}
+	alerts.lock.Lock()
+	defer alerts.lock.Unlock()
+	alerts.rules[rule.Name] = rule
+	delete(alerts.firing, rule.Name)
+	return nil
+}
+
+// Removes a rule, without resolving it if it is firing
+func (alerts *Alerts) RemoveRule(name string) {
+	alerts.lock.Lock()
+	defer alerts.lock.Unlock()
+	delete(alerts.rules, name)
+	delete(alerts.firing, name)
+}
+
+// Evaluates all rules once, calling the handler for those which changed state. Rules without data in their
+// window keep their state. Returns the first query error, the other rules are evaluated nevertheless
+func (alerts *Alerts) Evaluate() (err error) {
+	alerts.lock.Lock()
+	rules := make([]AlertRule, 0, len(alerts.rules))
+	for _, rule := range alerts.rules {
+		rules = append(rules, rule)
+	}
+	alerts.lock.Unlock()
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name < rules[j].Name })
+
+	for _, rule := range rules {
+		value, qerr := alerts.timeserData.GetMean(rule.Measurement, rule.Field, rule.Window, rule.Tags)
+		if qerr == ErrNoData {
+			continue
+		}
+		if qerr != nil {
+			alerts.timeserData.logger().Warnf("Failed to evaluate alert rule %v with error %v\n", rule.Name, qerr)
+			if err == nil {
+				err = qerr
+			}
+			continue
+		}
+
+		firing := value > rule.Threshold
+		if rule.Condition == AlertBelow {
+			firing = value < rule.Threshold
+		}
+		alerts.lock.Lock()
+		current, ok := alerts.rules[rule.Name]
+		changed := ok && alerts.firing[rule.Name] != firing
+		if changed {
+			alerts.firing[rule.Name] = firing
+		}
+		alerts.lock.Unlock()
+		if !changed {
+			continue
+		}
+
+		alerts.timeserData.logger().Infof("Alert %v (%v) firing=%v, %v of %v is %v\n", rule.Name, rule.Severity, firing, rule.Field, rule.Measurement, value)
+		if alerts.handler != nil {
+			alerts.handler(Alert{Rule: current, Value: value, Time: time.Now(), Firing: firing})
+		}
+	}
+	return err
+}
+
+// Starts evaluating the rules every interval until Stop() is called
+func (alerts *Alerts) Start(interval time.Duration) error {
+	if interval <= 0 {
+		return errors.New("Alert evaluation interval must be positive")
+	}
+	alerts.lock.Lock()
+	defer alerts.lock.Unlock()
+	if alerts.stop != nil {
+		return errors.New("Alerts already started")
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	alerts.stop, alerts.done = stop, done
+	alerts.timeserData.track(alerts, false, func() error {
+		alerts.Stop()
+		return nil
+	})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				alerts.Evaluate()
+			}
+		}
+	}()
+	return nil
+}
+
+// Stops the periodic evaluation started by Start()
+func (alerts *Alerts) Stop() {
+	alerts.lock.Lock()
+	stop, done := alerts.stop, alerts.done
+	alerts.stop, alerts.done = nil, nil
+	alerts.lock.Unlock()
+
+	alerts.timeserData.untrack(alerts)
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
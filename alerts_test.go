@@ -0,0 +1,120 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"stslgo"
+	"sync"
+	"testing"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for evaluating threshold alert rules
+func TestTimeSeriesDbAlerts(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	var lock sync.Mutex
+	mean := "5"
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		switch mean {
+		case "":
+			return &timesrclient.Response{Results: []timesrclient.Result{{}}}, nil
+		case "error":
+			return nil, errors.New("timeout")
+		}
+		return seriesResp("CellKpi", []string{"time", "mean"}, []interface{}{"1970-01-01T00:00:00Z", json.Number(mean)}), nil
+	}
+	setMean := func(value string) {
+		lock.Lock()
+		defer lock.Unlock()
+		mean = value
+	}
+
+	var fired []stslgo.Alert
+	alerts := timeserData.NewAlerts(func(alert stslgo.Alert) {
+		lock.Lock()
+		defer lock.Unlock()
+		fired = append(fired, alert)
+	})
+	if err = alerts.AddRule(stslgo.AlertRule{Name: "high-prb", Measurement: "CellKpi"}); err == nil {
+		t.Errorf("Expected error for rule without field and window")
+	}
+	rule := stslgo.AlertRule{Name: "high-prb", Measurement: "CellKpi", Field: "prb", Tags: map[string]string{"cellId": "c1"},
+		Condition: stslgo.AlertAbove, Threshold: 4, Window: 5 * time.Minute, Severity: stslgo.AlertCritical}
+	if err = alerts.AddRule(rule); err != nil {
+		t.Fatalf("Unable to add rule with error %v", err)
+	}
+
+	issuedQueries = nil
+	for _, value := range []string{"5", "6", "", "3", "3"} {
+		setMean(value)
+		if err = alerts.Evaluate(); err != nil {
+			t.Errorf("Unexpected error %v", err)
+		}
+	}
+	if len(issuedQueries) == 0 || issuedQueries[0] != `SELECT MEAN("prb") FROM "CellKpi" WHERE time >= now() - 5m AND "cellId" = 'c1'` {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+	if len(fired) != 2 || !fired[0].Firing || fired[0].Value != 5 || fired[0].Rule.Severity != stslgo.AlertCritical || fired[1].Firing || fired[1].Value != 3 {
+		t.Errorf("Expected alert to fire and resolve once, got %v", fired)
+	}
+	if stslgo.AlertCritical.String() != "critical" {
+		t.Errorf("Unexpected severity name %v", stslgo.AlertCritical)
+	}
+
+	setMean("error")
+	if err = alerts.Evaluate(); err == nil {
+		t.Errorf("Expected query error")
+	}
+
+	// Periodic evaluation
+	setMean("5")
+	if err = alerts.Start(10 * time.Millisecond); err != nil {
+		t.Fatalf("Unable to start alerts with error %v", err)
+	}
+	if err = alerts.Start(10 * time.Millisecond); err == nil {
+		t.Errorf("Expected error when starting twice")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		lock.Lock()
+		n := len(fired)
+		lock.Unlock()
+		if n == 3 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	alerts.Stop()
+	alerts.RemoveRule("high-prb")
+	if len(fired) != 3 || !fired[2].Firing {
+		t.Errorf("Expected alert to fire from periodic evaluation, got %v", fired)
+	}
+}
@@ -0,0 +1,109 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Value of a field deviating from the others, see DetectAnomalies()
+type Anomaly struct {
+	Time  time.Time
+	Value float64
+	Score float64 // Modified z-score, or z-score when more than half of the values are equal
+}
+
+// Returns the values of a field over the last window whose modified z-score, 0.6745 * |value - median| / MAD
+// (median absolute deviation), exceeds threshold (eg. 3.5), in chronological order. The median is not moved by
+// the anomalies themselves, unlike the mean. When more than half of the values are equal, MAD is 0 and the
+// z-score |value - mean| / standard deviation is used instead
+func (timeserData *TimeSeriesClientData) DetectAnomalies(measurement, field string, window time.Duration, threshold float64) ([]Anomaly, error) {
+	values, err := timeserData.GetRange(measurement, field, time.Now().Add(-window), time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]Sample, 0, len(values))
+	for _, value := range values {
+		if value.Value == nil {
+			continue
+		}
+		f, err := _toFloat64(value.Value)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, Sample{Time: value.Time, Value: f})
+	}
+	anomalies := _anomalies(samples, threshold)
+	timeserData.logger().Debugf("TimeSeriesDB DetectAnomalies: DB=%v Measurement=%v field=%v, values=%v, anomalies=%v\n", timeserData.timeSeriesDbName, measurement, field, len(samples), len(anomalies))
+	return anomalies, nil
+}
+
+// Returns the samples whose score exceeds threshold
+func _anomalies(samples []Sample, threshold float64) []Anomaly {
+	anomalies := []Anomaly{}
+	if len(samples) < 2 {
+		return anomalies
+	}
+	sorted := make([]float64, len(samples))
+	for i, sample := range samples {
+		sorted[i] = sample.Value
+	}
+	median := _median(sorted)
+	deviations := make([]float64, len(samples))
+	for i, sample := range samples {
+		deviations[i] = math.Abs(sample.Value - median)
+	}
+	mad := _median(deviations)
+
+	score := func(v float64) float64 { return 0.6745 * math.Abs(v-median) / mad }
+	if mad == 0 {
+		var sum, squares float64
+		for _, sample := range samples {
+			sum += sample.Value
+		}
+		mean := sum / float64(len(samples))
+		for _, sample := range samples {
+			squares += (sample.Value - mean) * (sample.Value - mean)
+		}
+		std := math.Sqrt(squares / float64(len(samples)))
+		if std == 0 {
+			return anomalies
+		}
+		score = func(v float64) float64 { return math.Abs(v-mean) / std }
+	}
+	for _, sample := range samples {
+		if s := score(sample.Value); s > threshold {
+			anomalies = append(anomalies, Anomaly{Time: sample.Time, Value: sample.Value, Score: s})
+		}
+	}
+	return anomalies
+}
+
+// Returns the median of the values, sorting them
+func _median(values []float64) float64 {
+	sort.Float64s(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
@@ -0,0 +1,69 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for detecting the values deviating from the others
+func TestTimeSeriesDbDetectAnomalies(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	var values []json.Number
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		row := models.Row{Name: "CellKpi", Columns: []string{"time", "prb"}}
+		for i, v := range values {
+			row.Values = append(row.Values, []interface{}{fmt.Sprintf("2024-01-01T00:00:%02dZ", i), v})
+		}
+		return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{row}}}}, nil
+	}
+
+	// Median 10, MAD 1
+	values = []json.Number{"10", "11", "9", "10", "12", "10", "50"}
+	anomalies, err := timeserData.DetectAnomalies("CellKpi", "prb", time.Minute, 3.5)
+	if err != nil || len(anomalies) != 1 || anomalies[0].Value != 50 || anomalies[0].Time.Second() != 6 || anomalies[0].Score < 26 || anomalies[0].Score > 27 {
+		t.Errorf("Unexpected anomalies %v, error %v", anomalies, err)
+	}
+	if len(issuedQueries) != 1 || !strings.HasPrefix(issuedQueries[0], `SELECT "prb" FROM "CellKpi" WHERE time >= '`) {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+
+	// MAD 0, mean 8 and standard deviation 6
+	values = []json.Number{"5", "5", "5", "5", "20"}
+	anomalies, err = timeserData.DetectAnomalies("CellKpi", "prb", time.Minute, 1.5)
+	if err != nil || len(anomalies) != 1 || anomalies[0].Value != 20 || anomalies[0].Score != 2 {
+		t.Errorf("Unexpected anomalies %v, error %v", anomalies, err)
+	}
+
+	values = []json.Number{"5", "5", "5"}
+	if anomalies, err = timeserData.DetectAnomalies("CellKpi", "prb", time.Minute, 1); err != nil || len(anomalies) != 0 {
+		t.Errorf("Unexpected anomalies %v, error %v", anomalies, err)
+	}
+}
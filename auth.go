@@ -0,0 +1,187 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Access of a token to a DB, as granted to its TimeSeriesDB user
+type Privilege int
+
+const (
+	PrivilegeRead Privilege = iota + 1
+	PrivilegeWrite
+	PrivilegeAll
+)
+
+var privilegeNames = map[Privilege]string{
+	PrivilegeRead:  "READ",
+	PrivilegeWrite: "WRITE",
+	PrivilegeAll:   "ALL",
+}
+
+func (privilege Privilege) String() string {
+	if name, ok := privilegeNames[privilege]; ok {
+		return name
+	}
+	return fmt.Sprintf("Privilege(%d)", int(privilege))
+}
+
+// Token of a user as reported by ListTokens(), with the privileges granted per DB
+type TokenInfo struct {
+	UserName string
+	Admin    bool
+	Grants   map[string]Privilege
+}
+
+// Number of random bytes of the secret of a token
+const tokenSecretBytes = 24
+
+// Mints a token for an xApp, scoped to a DB (the DB of the client when empty) with the privilege, eg. PrivilegeRead
+// for a dashboard. TimeSeriesDB authorizes users, so the token is a new user with a random password, returned as
+// "username:password" for Options.Token or a token file. Needs an admin client
+func (timeserData *TimeSeriesClientData) CreateToken(userName, dbName string, privilege Privilege) (token string, err error) {
+	if userName == "" {
+		return "", errors.New("Token needs a user name")
+	}
+	secret, err := _tokenSecret()
+	if err != nil {
+		return "", err
+	}
+	if err = timeserData.CreateUser(userName, secret, false); err != nil {
+		return "", err
+	}
+	if err = timeserData.GrantToken(userName, dbName, privilege); err != nil {
+		// No user left without its scope
+		timeserData.DeleteUser(userName)
+		return "", err
+	}
+	timeserData.logger().Infof("Sucessfully created token of user %v\n", userName)
+	return userName + ":" + secret, nil
+}
+
+// Grants the privilege on a DB (the DB of the client when empty) to the user of a token, replacing the privilege it
+// had on that DB
+func (timeserData *TimeSeriesClientData) GrantToken(userName, dbName string, privilege Privilege) (err error) {
+	if _, ok := privilegeNames[privilege]; !ok {
+		return fmt.Errorf("Invalid privilege %v", privilege)
+	}
+	if dbName == "" {
+		dbName = timeserData.timeSeriesDbName
+	}
+	// Revoking ALL leaves no privilege on the DB
+	queryStr := fmt.Sprintf("REVOKE ALL ON %v FROM %v; GRANT %v ON %v TO %v", _quoteIdent(dbName), _quoteIdent(userName),
+		privilege, _quoteIdent(dbName), _quoteIdent(userName))
+	if _, err = timeserData.query(timesrclient.NewQuery(queryStr, "", "")); err != nil {
+		timeserData.logger().Errorf("Failed to grant %v on %v to user %v with error %v\n", privilege, dbName, userName, err)
+		return err
+	}
+	timeserData.logger().Infof("Sucessfully granted %v on %v to user %v\n", privilege, dbName, userName)
+	return nil
+}
+
+// Replaces the secret of a token, the old token is rejected from now on
+func (timeserData *TimeSeriesClientData) RotateToken(userName string) (token string, err error) {
+	secret, err := _tokenSecret()
+	if err != nil {
+		return "", err
+	}
+	queryStr := fmt.Sprintf("SET PASSWORD FOR %v = %v", _quoteIdent(userName), _quoteLiteral(secret))
+	if _, err = timeserData.query(timesrclient.NewQuery(queryStr, "", "")); err != nil {
+		timeserData.logger().Errorf("Failed to rotate token of user %v with error %v\n", userName, err)
+		return "", err
+	}
+	timeserData.logger().Infof("Sucessfully rotated token of user %v\n", userName)
+	return userName + ":" + secret, nil
+}
+
+// Revokes a token, dropping its user
+func (timeserData *TimeSeriesClientData) RevokeToken(userName string) error {
+	return timeserData.DeleteUser(userName)
+}
+
+// Lists the users of TimeSeriesDB with their privileges, with one query for the users and one for their grants
+func (timeserData *TimeSeriesClientData) ListTokens() (tokens []TokenInfo, err error) {
+	users, err := timeserData.ListUsers()
+	if err != nil {
+		return nil, err
+	}
+	tokens = make([]TokenInfo, len(users))
+	statements := make([]string, len(users))
+	for i, user := range users {
+		tokens[i] = TokenInfo{UserName: user.Name, Admin: user.Admin, Grants: make(map[string]Privilege)}
+		statements[i] = "SHOW GRANTS FOR " + _quoteIdent(user.Name)
+	}
+	if len(statements) == 0 {
+		return tokens, nil
+	}
+
+	response, err := timeserData.query(timesrclient.NewQuery(strings.Join(statements, "; "), "", ""))
+	if err != nil {
+		timeserData.logger().Errorf("Failed to list grants with error %v\n", err)
+		return nil, err
+	}
+	for i, result := range response.Results {
+		if i >= len(tokens) {
+			break
+		}
+		for _, row := range result.Series {
+			// Columns are database and privilege
+			for _, value := range row.Values {
+				if len(value) < 2 {
+					continue
+				}
+				if privilege, ok := _parsePrivilege(fmt.Sprint(value[1])); ok {
+					tokens[i].Grants[fmt.Sprint(value[0])] = privilege
+				}
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// Returns the privilege of a SHOW GRANTS row, false for NO PRIVILEGES
+func _parsePrivilege(name string) (Privilege, bool) {
+	if name == "ALL PRIVILEGES" {
+		return PrivilegeAll, true
+	}
+	for privilege, privilegeName := range privilegeNames {
+		if name == privilegeName {
+			return privilege, true
+		}
+	}
+	return 0, false
+}
+
+// Returns a random secret, which has no : so that it can follow the user name in a token
+func _tokenSecret() (string, error) {
+	secret := make([]byte, tokenSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
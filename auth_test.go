@@ -0,0 +1,96 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+
+	"stslgo"
+)
+
+// Test function for minting, scoping, rotating and revoking the tokens of xApps
+func TestTimeSeriesDbTokens(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	token, err := timeserData.CreateToken("dashboard", "", stslgo.PrivilegeRead)
+	parts := strings.SplitN(token, ":", 2)
+	if err != nil || len(parts) != 2 || parts[0] != "dashboard" || len(parts[1]) < 32 {
+		t.Fatalf("Unexpected token %v, error %v", token, err)
+	}
+	expected := []string{
+		`CREATE USER "dashboard" WITH PASSWORD '` + parts[1] + `'`,
+		`REVOKE ALL ON "testdb" FROM "dashboard"; GRANT READ ON "testdb" TO "dashboard"`,
+	}
+	if strings.Join(issuedQueries, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+
+	rotated, err := timeserData.RotateToken("dashboard")
+	if err != nil || rotated == token || !strings.HasPrefix(rotated, "dashboard:") {
+		t.Errorf("Unexpected rotated token %v, error %v", rotated, err)
+	}
+	if err = timeserData.RevokeToken("dashboard"); err != nil || issuedQueries[len(issuedQueries)-1] != `DROP USER "dashboard"` {
+		t.Errorf("Unexpected revocation %v, error %v", issuedQueries, err)
+	}
+	if err = timeserData.GrantToken("dashboard", "", stslgo.Privilege(7)); err == nil {
+		t.Errorf("Expected error for an invalid privilege")
+	}
+
+	// Failed grant drops the user
+	issuedQueries = nil
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		if strings.HasPrefix(q.Command, "REVOKE") {
+			return nil, errors.New("database not found")
+		}
+		return &timesrclient.Response{}, nil
+	}
+	if _, err = timeserData.CreateToken("kpimon", "missing", stslgo.PrivilegeWrite); err == nil {
+		t.Errorf("Expected error for a failed grant")
+	}
+	if issuedQueries[len(issuedQueries)-1] != `DROP USER "kpimon"` {
+		t.Errorf("Expected user dropped, got %v", issuedQueries)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		if q.Command == "SHOW USERS" {
+			return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+				{Columns: []string{"user", "admin"}, Values: [][]interface{}{{"admin", true}, {"kpimon", false}}},
+			}}}}, nil
+		}
+		return &timesrclient.Response{Results: []timesrclient.Result{
+			{},
+			{Series: []models.Row{{Columns: []string{"database", "privilege"},
+				Values: [][]interface{}{{"testdb", "WRITE"}, {"kpis", "ALL PRIVILEGES"}, {"other", "NO PRIVILEGES"}}}}},
+		}}, nil
+	}
+	tokens, err := timeserData.ListTokens()
+	if err != nil || fmt.Sprint(tokens) != "[{admin true map[]} {kpimon false map[kpis:ALL testdb:WRITE]}]" {
+		t.Errorf("Unexpected tokens %v, error %v", tokens, err)
+	}
+}
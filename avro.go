@@ -0,0 +1,178 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+)
+
+var avroTypes = map[FieldType]string{
+	FieldFloat:   "double",
+	FieldInteger: "long",
+	FieldString:  "string",
+	FieldBoolean: "boolean",
+}
+
+// Writes rows as an Avro object container file, a deflate compressed block per exportBlockSize rows
+type avroWriter struct {
+	w       io.Writer
+	columns []exportColumn
+	sync    [16]byte
+	block   bytes.Buffer
+	rows    int
+}
+
+// Writes the header of the file, with the schema of a record named after the measurement
+func newAvroWriter(w io.Writer, measurement string, columns []exportColumn) (*avroWriter, error) {
+	aw := &avroWriter{w: w, columns: columns}
+	if _, err := rand.Read(aw.sync[:]); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	fields := make([]map[string]interface{}, len(columns))
+	for i, column := range columns {
+		name := _avroName(column.name)
+		for n := 2; names[name]; n++ {
+			name = _avroName(column.name) + "_" + strconv.Itoa(n)
+		}
+		names[name] = true
+		fields[i] = map[string]interface{}{"name": name, "type": []interface{}{"null", avroTypes[column.fieldType]}, "default": nil}
+		if column.required {
+			fields[i] = map[string]interface{}{"name": name, "type": map[string]string{"type": "long", "logicalType": "timestamp-micros"}}
+		}
+		if name != column.name {
+			// The name in the DB
+			fields[i]["doc"] = column.name
+		}
+	}
+	schema, err := json.Marshal(map[string]interface{}{"type": "record", "name": _avroName(measurement), "fields": fields})
+	if err != nil {
+		return nil, err
+	}
+
+	var header bytes.Buffer
+	header.WriteString("Obj\x01")
+	_avroLong(&header, 2)
+	_avroBytes(&header, []byte("avro.schema"))
+	_avroBytes(&header, schema)
+	_avroBytes(&header, []byte("avro.codec"))
+	_avroBytes(&header, []byte("deflate"))
+	_avroLong(&header, 0)
+	header.Write(aw.sync[:])
+	_, err = w.Write(header.Bytes())
+	return aw, err
+}
+
+// Adds a row to the block, writing the block when full
+func (aw *avroWriter) write(row []interface{}) error {
+	for i, value := range row {
+		if !aw.columns[i].required {
+			if value == nil {
+				_avroLong(&aw.block, 0)
+				continue
+			}
+			_avroLong(&aw.block, 1)
+		}
+		switch v := value.(type) {
+		case int64:
+			_avroLong(&aw.block, v)
+		case float64:
+			var b [8]byte
+			binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+			aw.block.Write(b[:])
+		case string:
+			_avroBytes(&aw.block, []byte(v))
+		case bool:
+			if v {
+				aw.block.WriteByte(1)
+			} else {
+				aw.block.WriteByte(0)
+			}
+		}
+	}
+	aw.rows++
+	if aw.rows >= exportBlockSize {
+		return aw.flush()
+	}
+	return nil
+}
+
+// Writes the block of the rows added so far
+func (aw *avroWriter) flush() error {
+	if aw.rows == 0 {
+		return nil
+	}
+	var compressed bytes.Buffer
+	zw, _ := flate.NewWriter(&compressed, flate.DefaultCompression)
+	zw.Write(aw.block.Bytes())
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	var header bytes.Buffer
+	_avroLong(&header, int64(aw.rows))
+	_avroLong(&header, int64(compressed.Len()))
+	aw.block.Reset()
+	aw.rows = 0
+	for _, b := range [][]byte{header.Bytes(), compressed.Bytes(), aw.sync[:]} {
+		if _, err := aw.w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (aw *avroWriter) close() error {
+	return aw.flush()
+}
+
+// Writes a zigzag encoded long
+func _avroLong(buf *bytes.Buffer, v int64) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutVarint(b[:], v)])
+}
+
+// Writes length prefixed bytes, as of a string
+func _avroBytes(buf *bytes.Buffer, b []byte) {
+	_avroLong(buf, int64(len(b)))
+	buf.Write(b)
+}
+
+// Returns a valid Avro name, [A-Za-z_][A-Za-z0-9_]*, the other characters replaced by _
+func _avroName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 {
+		return "_"
+	}
+	return string(b)
+}
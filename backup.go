@@ -0,0 +1,210 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Number of points per write when restoring a backup
+const restoreBatchSize = 5000
+
+// Exports the points of all measurements of the DB within [start, stop) to a file as line protocol, gzip
+// compressed when path ends with .gz. Zero start or stop leaves the range open on that side. Only the default
+// retention policy is exported
+func (timeserData *TimeSeriesClientData) BackupTimeSeriesDB(path string, start, stop time.Time) (err error) {
+	rows, err := timeserData.QueryRows("SHOW MEASUREMENTS")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	var w io.Writer = file
+	if strings.HasSuffix(path, ".gz") {
+		zw := gzip.NewWriter(file)
+		defer func() {
+			if cerr := zw.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = zw
+	}
+	buffered := bufio.NewWriter(w)
+	defer func() {
+		if ferr := buffered.Flush(); err == nil {
+			err = ferr
+		}
+	}()
+
+	fmt.Fprintf(buffered, "# Backup of TimeSeriesDB %v from %v to %v\n", timeserData.timeSeriesDbName, start, stop)
+	points := 0
+	for _, row := range rows {
+		measurement := fmt.Sprint(row["name"])
+		n, err := timeserData.backupMeasurement(buffered, measurement, start, stop)
+		points += n
+		if err != nil {
+			timeserData.logger().Errorf("Failed to back up measurement %v with error %v\n", measurement, err)
+			return err
+		}
+	}
+	timeserData.logger().Infof("Backed up %v points of DB %v to %v\n", points, timeserData.timeSeriesDbName, path)
+	return nil
+}
+
+// Writes the points of a measurement as line protocol
+func (timeserData *TimeSeriesClientData) backupMeasurement(w io.Writer, measurement string, start, stop time.Time) (int, error) {
+	schema, err := timeserData.DescribeMeasurement(measurement)
+	if err != nil {
+		return 0, err
+	}
+
+	points := 0
+	err = timeserData.QueryEach(_rangeQuery(timeserData.MeasurementName(measurement), start, stop), 0, func(row JsonRow) error {
+		timestamp, err := _toTime(row["time"])
+		if err != nil {
+			return err
+		}
+		tags := map[string]string{}
+		fields := map[string]interface{}{}
+		for key, value := range row {
+			switch {
+			case key == "time" || value == nil:
+			case _contains(schema.TagKeys, key):
+				tags[key] = fmt.Sprint(value)
+			default:
+				fields[key] = _backupField(value, schema.Fields[key])
+			}
+		}
+		if len(fields) == 0 {
+			return nil
+		}
+		pt, err := timesrclient.NewPoint(measurement, tags, fields, timestamp)
+		if err != nil {
+			return err
+		}
+		points++
+		_, err = io.WriteString(w, pt.String()+"\n")
+		return err
+	})
+	return points, err
+}
+
+// Returns the query of the points of a measurement within [start, stop), open on the side of a zero time
+func _rangeQuery(measurement string, start, stop time.Time) string {
+	queryStr := fmt.Sprintf("SELECT * FROM %v", _quoteIdent(measurement))
+	conditions := []string{}
+	if !start.IsZero() {
+		conditions = append(conditions, "time >= "+_quoteLiteral(start.UTC().Format(time.RFC3339Nano)))
+	}
+	if !stop.IsZero() {
+		conditions = append(conditions, "time < "+_quoteLiteral(stop.UTC().Format(time.RFC3339Nano)))
+	}
+	if len(conditions) > 0 {
+		queryStr += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return queryStr
+}
+
+// Restores the points of a backup made with BackupTimeSeriesDB into the DB of the client
+func (timeserData *TimeSeriesClientData) RestoreTimeSeriesDB(path string) (err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lines := make([]string, 0, restoreBatchSize)
+	points := 0
+	flush := func() error {
+		if len(lines) == 0 {
+			return nil
+		}
+		err := timeserData.WriteLineProtocolBatch(lines)
+		points += len(lines)
+		lines = lines[:0]
+		return err
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) == restoreBatchSize {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err = scanner.Err(); err == nil {
+		err = flush()
+	}
+	if err != nil {
+		timeserData.logger().Errorf("Failed to restore %v into DB %v with error %v\n", path, timeserData.timeSeriesDbName, err)
+		return err
+	}
+	timeserData.logger().Infof("Restored %v points from %v into DB %v\n", points, path, timeserData.timeSeriesDbName)
+	return nil
+}
+
+// Converts a queried field value to the type of the field
+func _backupField(value interface{}, fieldType FieldType) interface{} {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+	if fieldType == FieldInteger {
+		if i, err := number.Int64(); err == nil {
+			return i
+		}
+	}
+	if f, err := number.Float64(); err == nil {
+		return f
+	}
+	return number.String()
+}
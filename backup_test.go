@@ -0,0 +1,106 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for backing up a DB to a file and restoring it
+func TestTimeSeriesDbBackupRestore(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	dir, err := ioutil.TempDir("", "stslgo-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var selects []string
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		switch {
+		case q.Command == "SHOW MEASUREMENTS":
+			return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+				{Name: "measurements", Columns: []string{"name"}, Values: [][]interface{}{{"CellKpi"}}},
+			}}}}, nil
+		case strings.HasPrefix(q.Command, "SHOW TAG KEYS"):
+			return &timesrclient.Response{Results: []timesrclient.Result{
+				{Series: []models.Row{{Name: "CellKpi", Columns: []string{"tagKey"}, Values: [][]interface{}{{"cellId"}}}}},
+				{Series: []models.Row{{Name: "CellKpi", Columns: []string{"fieldKey", "fieldType"},
+					Values: [][]interface{}{{"prb", "integer"}, {"load", "float"}, {"state", "string"}}}}},
+			}}, nil
+		case strings.HasSuffix(q.Command, "OFFSET 0"):
+			selects = append(selects, q.Command)
+			return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+				{Name: "CellKpi", Columns: []string{"time", "cellId", "load", "prb", "state"}, Values: [][]interface{}{
+					{"2021-08-20T05:47:46.000000001Z", "c1", json.Number("2"), json.Number("3"), "up"},
+					{"2021-08-20T05:47:47Z", "c2", nil, json.Number("5"), nil},
+				}},
+			}}}}, nil
+		}
+		return &timesrclient.Response{Results: []timesrclient.Result{{}}}, nil
+	}
+
+	for _, name := range []string{"backup.lp", "backup.lp.gz"} {
+		path := filepath.Join(dir, name)
+		start := time.Date(2021, 8, 20, 0, 0, 0, 0, time.UTC)
+		if err = timeserData.BackupTimeSeriesDB(path, start, time.Time{}); err != nil {
+			t.Fatalf("Unable to back up DB with error %v", err)
+		}
+		expected := `SELECT * FROM "CellKpi" WHERE time >= '2021-08-20T00:00:00Z' LIMIT 10000 OFFSET 0`
+		if len(selects) != 1 || selects[0] != expected {
+			t.Errorf("Unexpected backup queries %v", selects)
+		}
+		selects = nil
+
+		writtenPoints = nil
+		if err = timeserData.RestoreTimeSeriesDB(path); err != nil {
+			t.Fatalf("Unable to restore DB with error %v", err)
+		}
+		if len(writtenPoints) != 2 {
+			t.Fatalf("Expected 2 restored points, got %v", len(writtenPoints))
+		}
+		fields, _ := writtenPoints[0].Fields()
+		if writtenPoints[0].Name() != "CellKpi" || writtenPoints[0].Tags()["cellId"] != "c1" || fields["prb"] != int64(3) ||
+			fields["load"] != float64(2) || fields["state"] != "up" || writtenPoints[0].Time().UnixNano() != 1629438466000000001 {
+			t.Errorf("Unexpected restored point %v", writtenPoints[0])
+		}
+		fields, _ = writtenPoints[1].Fields()
+		if len(fields) != 1 || fields["prb"] != int64(5) {
+			t.Errorf("Unexpected restored point %v", writtenPoints[1])
+		}
+	}
+
+	if err = timeserData.RestoreTimeSeriesDB(filepath.Join(dir, "missing.lp")); err == nil {
+		t.Errorf("Expected error for missing backup file")
+	}
+}
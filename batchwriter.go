@@ -0,0 +1,258 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Configuration of a BatchWriter
+type BatchWriterConfig struct {
+	BatchSize     int           // Number of points written together, a full batch is written right away
+	FlushInterval time.Duration // Maximum time a point waits to be written, 0 to flush only on full batch or Flush()
+	MaxRetained   int           // Number of failed batches kept for retry on next flush, oldest dropped beyond
+}
+
+var DefaultBatchWriterConfig = BatchWriterConfig{
+	BatchSize:     5000,
+	FlushInterval: time.Second,
+	MaxRetained:   10,
+}
+
+var ErrBatchWriterClosed = errors.New("BatchWriter closed")
+
+// Accumulates points and writes them as batches, each batch in a single request to the TimeSeriesDB
+type BatchWriter struct {
+	timeserData *TimeSeriesClientData
+	config      BatchWriterConfig
+
+	lock     sync.Mutex
+	pending  []*timesrclient.Point
+	retained [][]*timesrclient.Point // Failed batches, oldest first
+	closed   bool
+
+	flushLock sync.Mutex // Serializes the writes so that batches are written in order
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// Creates a BatchWriter writing to the DB of the client. Zero values in config are taken from DefaultBatchWriterConfig,
+// or for BatchSize from the Options of the client
+func (timeserData *TimeSeriesClientData) NewBatchWriter(config BatchWriterConfig) *BatchWriter {
+	if config.BatchSize <= 0 {
+		config.BatchSize = timeserData.batchSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchWriterConfig.BatchSize
+	}
+	if config.MaxRetained <= 0 {
+		config.MaxRetained = DefaultBatchWriterConfig.MaxRetained
+	}
+	bw := &BatchWriter{
+		timeserData: timeserData,
+		config:      config,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	if config.FlushInterval > 0 {
+		go bw.run()
+	} else {
+		close(bw.done)
+	}
+	timeserData.track(bw, true, bw.Close)
+	return bw
+}
+
+// Adds a point stamped with the current time to the batch
+func (bw *BatchWriter) WritePoint(measurement string, tags map[string]string, fields map[string]interface{}) (err error) {
+	fields, err = bw.timeserData.finiteFields(measurement, fields)
+	if err == nil {
+		fields, err = bw.timeserData.schemaFields(measurement, tags, fields)
+	}
+	if err != nil {
+		return err
+	}
+	pt, err := timesrclient.NewPoint(measurement, tags, fields, time.Now())
+	if err != nil {
+		bw.timeserData.logger().Errorf("Error: %s", err.Error())
+		return err
+	}
+	return bw.AddPoint(pt)
+}
+
+// Adds a point to the batch, writing the batch when it is full
+func (bw *BatchWriter) AddPoint(pt *timesrclient.Point) error {
+	bw.lock.Lock()
+	if bw.closed {
+		bw.lock.Unlock()
+		return ErrBatchWriterClosed
+	}
+	bw.pending = append(bw.pending, pt)
+	full := len(bw.pending) >= bw.config.BatchSize
+	bw.lock.Unlock()
+
+	if full {
+		return bw.Flush()
+	}
+	return nil
+}
+
+// Writes the retained batches and the points added so far. Batches failing while TimeSeriesDB is unavailable are
+// retained for the next flush, those it rejects (eg. field type conflicts) are dropped and reported right away
+func (bw *BatchWriter) Flush() (err error) {
+	bw.flushLock.Lock()
+	defer bw.flushLock.Unlock()
+
+	bw.lock.Lock()
+	for len(bw.pending) > 0 {
+		n := bw.config.BatchSize
+		if n > len(bw.pending) {
+			n = len(bw.pending)
+		}
+		bw.retained = append(bw.retained, bw.pending[:n])
+		bw.pending = bw.pending[n:]
+	}
+	batches := bw.retained
+	bw.retained = nil
+	bw.lock.Unlock()
+
+	if metrics := bw.timeserData.metrics; metrics != nil {
+		start := time.Now()
+		points := 0
+		for _, batch := range batches {
+			points += len(batch)
+		}
+		defer func() { metrics.Flushed(points, time.Since(start), err) }()
+	}
+
+	var failed [][]*timesrclient.Point
+	for i, batch := range batches {
+		if err != nil && _transient(err) {
+			// TimeSeriesDB is unavailable, keep the rest for the next flush
+			failed = append(failed, batches[i:]...)
+			break
+		}
+		bp, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+			Database:  bw.timeserData.timeSeriesDbName,
+			Precision: bw.timeserData.writePrecision(),
+		})
+		bp.AddPoints(batch)
+		// Batches failing transiently are retained, and reported only when dropped
+		ctx, _ := _collectFailed(context.Background())
+		werr := bw.timeserData.writeContext(ctx, bp)
+		if werr == nil {
+			bw.timeserData.logger().Debugf("TimeSeriesDB BatchWriter: DB=%v wrote %v points\n", bw.timeserData.timeSeriesDbName, len(batch))
+			continue
+		}
+		err = werr
+		if _transient(werr) {
+			bw.timeserData.logger().Warnf("TimeSeriesDB BatchWriter failed to write %v points: %v\n", len(batch), werr)
+			failed = append(failed, batch)
+			continue
+		}
+		// Rejected by TimeSeriesDB, would fail again
+		bw.timeserData.logger().Errorf("TimeSeriesDB BatchWriter dropping %v rejected points: %v\n", len(batch), werr)
+		bw.abandon([][]*timesrclient.Point{batch}, werr)
+		bw.timeserData.reportDropped(len(batch), "batch_writer_rejected")
+		bw.timeserData.reportWriteError(werr)
+	}
+	if len(failed) == 0 {
+		return err
+	}
+
+	bw.lock.Lock()
+	// Batches added meanwhile go after the failed ones to keep the order
+	bw.retained = append(failed, bw.retained...)
+	if dropped := len(bw.retained) - bw.config.MaxRetained; dropped > 0 {
+		points := 0
+		abandoned := bw.retained[:dropped]
+		for _, batch := range abandoned {
+			points += len(batch)
+		}
+		bw.retained = bw.retained[dropped:]
+		bw.lock.Unlock()
+		bw.abandon(abandoned, err)
+		bw.timeserData.reportDropped(points, "batch_writer_overflow")
+		bw.timeserData.reportWriteError(fmt.Errorf("BatchWriter dropped %v batches (%v points) after write failure: %v", dropped, points, err))
+		return err
+	}
+	bw.lock.Unlock()
+	return err
+}
+
+// Flushes the remaining points and stops the periodic flush. Returns the error of the final flush
+func (bw *BatchWriter) Close() error {
+	bw.lock.Lock()
+	if bw.closed {
+		bw.lock.Unlock()
+		return ErrBatchWriterClosed
+	}
+	bw.closed = true
+	bw.lock.Unlock()
+
+	bw.timeserData.untrack(bw)
+	close(bw.stop)
+	<-bw.done
+	err := bw.Flush()
+	if err != nil {
+		// No further flush of the batches still retained
+		bw.lock.Lock()
+		abandoned := bw.retained
+		bw.retained = nil
+		bw.lock.Unlock()
+		bw.abandon(abandoned, err)
+	}
+	return err
+}
+
+// Hands the batches given up to OnWriteError() and the dead letter file
+func (bw *BatchWriter) abandon(batches [][]*timesrclient.Point, err error) {
+	for _, batch := range batches {
+		bp, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+			Database:  bw.timeserData.timeSeriesDbName,
+			Precision: bw.timeserData.writePrecision(),
+		})
+		bp.AddPoints(batch)
+		if namespaced, nerr := bw.timeserData.namespaced(bp); nerr == nil {
+			bp = namespaced
+		}
+		bw.timeserData.reportFailedBatch(bp, err)
+	}
+}
+
+func (bw *BatchWriter) run() {
+	defer close(bw.done)
+	ticker := time.NewTicker(bw.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-bw.stop:
+			return
+		case <-ticker.C:
+			_ = bw.Flush()
+		}
+	}
+}
@@ -0,0 +1,114 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"errors"
+	"fmt"
+	"stslgo"
+	"testing"
+	"time"
+)
+
+// Test function for writing points in batches with explicit flush
+func TestTimeSeriesDbBatchWriter(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	bw := timeserData.NewBatchWriter(stslgo.BatchWriterConfig{BatchSize: 3, MaxRetained: 1})
+	for i := 0; i < 7; i++ {
+		if err = bw.WritePoint("BatchTable", nil, map[string]interface{}{"prb": i}); err != nil {
+			t.Fatalf("Unable to add point with error %v", err)
+		}
+	}
+	if writeCalls != 2 || len(writtenPoints) != 6 {
+		t.Errorf("Expected 2 full batches written, got %v writes of %v points", writeCalls, len(writtenPoints))
+	}
+	if err = bw.Flush(); err != nil || writeCalls != 3 || len(writtenPoints) != 7 {
+		t.Errorf("Expected flush to write the last point, got %v writes of %v points, err %v", writeCalls, len(writtenPoints), err)
+	}
+
+	// A failed batch is retained and written on the next flush
+	writeErr = errors.New("timeout")
+	for i := 0; i < 3; i++ {
+		_ = bw.WritePoint("BatchTable", nil, map[string]interface{}{"prb": i})
+	}
+	writeErr = nil
+	if err = bw.Flush(); err != nil || len(writtenPoints) != 10 {
+		t.Errorf("Expected retained batch written, got %v points, err %v", len(writtenPoints), err)
+	}
+
+	// Beyond MaxRetained the oldest batch is dropped
+	writeErr = errors.New("timeout")
+	for i := 0; i < 6; i++ {
+		_ = bw.WritePoint("BatchTable", nil, map[string]interface{}{"prb": i})
+	}
+	writeErr = nil
+	if timeserData.WriteErrorCount() != 1 {
+		t.Errorf("Expected dropped batch reported, got %v errors", timeserData.WriteErrorCount())
+	}
+	if err = bw.Close(); err != nil || len(writtenPoints) != 13 {
+		t.Errorf("Expected only the retained batch written on close, got %v points, err %v", len(writtenPoints), err)
+	}
+
+	// A rejected batch is dropped without holding back the next ones
+	bw = timeserData.NewBatchWriter(stslgo.BatchWriterConfig{BatchSize: 3, MaxRetained: 1})
+	writeErr = errors.New("partial write: field type conflict")
+	for i := 0; i < 3; i++ {
+		_ = bw.WritePoint("BatchTable", nil, map[string]interface{}{"prb": i})
+	}
+	writeErr = nil
+	_ = bw.WritePoint("BatchTable", nil, map[string]interface{}{"prb": 3})
+	if err = bw.Flush(); err != nil || len(writtenPoints) != 14 {
+		t.Errorf("Expected only the next point written, got %v points, err %v", len(writtenPoints), err)
+	}
+	_ = bw.Close()
+	if bw.WritePoint("BatchTable", nil, map[string]interface{}{"prb": 1}) != stslgo.ErrBatchWriterClosed {
+		t.Errorf("Expected ErrBatchWriterClosed after Close")
+	}
+}
+
+// Test function for the periodic flush of a BatchWriter
+func TestTimeSeriesDbBatchWriterInterval(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	bw := timeserData.NewBatchWriter(stslgo.BatchWriterConfig{BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+	defer bw.Close()
+	_ = bw.WritePoint("BatchTable", nil, map[string]interface{}{"prb": 1})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mockLock.Lock()
+		written := len(writtenPoints)
+		mockLock.Unlock()
+		if written == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Point not flushed by the flush interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
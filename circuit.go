@@ -0,0 +1,170 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// State of the circuit breaker
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // Requests go to TimeSeriesDB
+	CircuitOpen                         // Requests fail with ErrCircuitOpen without reaching TimeSeriesDB
+	CircuitHalfOpen                     // A single probe request goes to TimeSeriesDB, the others fail
+)
+
+func (state CircuitState) String() string {
+	switch state {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+var ErrCircuitOpen = errors.New("TimeSeriesDB circuit breaker open")
+
+// Opening of the circuit breaker on consecutive network failures (eg. timeouts, connection refused). Errors
+// returned by a reachable TimeSeriesDB do not count
+type CircuitBreakerPolicy struct {
+	FailureThreshold int                         // Consecutive failures opening the circuit, 0 disables the breaker
+	OpenDuration     time.Duration               // Time the circuit stays open before a probe request
+	OnStateChange    func(from, to CircuitState) // Called on each change of state, nil for none
+}
+
+// Optional interface of a MetricsHook receiving the changes of state of the circuit breaker and the requests
+// it rejects, implemented by Metrics
+type CircuitMetricsHook interface {
+	CircuitStateChanged(state CircuitState)
+	CircuitRejected()
+}
+
+type circuitBreaker struct {
+	lock     sync.Mutex
+	endpoint string // Named in the logs
+	policy   CircuitBreakerPolicy
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// Makes the writes and queries fail fast with ErrCircuitOpen while TimeSeriesDB is down, instead of waiting for
+// the timeout of each request. Writes rejected this way go to the spill file when set. The read endpoint, if any,
+// has a circuit breaker of its own with the same policy, so that the failures of one do not fail the requests
+// going to the other. Its changes of state are only logged. To be set before use
+func (timeserData *TimeSeriesClientData) SetCircuitBreaker(policy CircuitBreakerPolicy) {
+	if policy.FailureThreshold <= 0 {
+		timeserData.breaker, timeserData.readBreaker = nil, nil
+		return
+	}
+	timeserData.breaker = &circuitBreaker{endpoint: "TimeSeriesDB", policy: policy}
+	readPolicy := policy
+	readPolicy.OnStateChange = nil
+	timeserData.readBreaker = &circuitBreaker{endpoint: "TimeSeriesDB read endpoint", policy: readPolicy}
+}
+
+// Returns the state of the circuit breaker of the primary, CircuitClosed when there is none
+func (timeserData *TimeSeriesClientData) CircuitState() CircuitState {
+	breaker := timeserData.breaker
+	if breaker == nil {
+		return CircuitClosed
+	}
+	breaker.lock.Lock()
+	defer breaker.lock.Unlock()
+	return breaker.state
+}
+
+// Returns ErrCircuitOpen if a request cannot go through the breaker now
+func (timeserData *TimeSeriesClientData) circuitAllow(breaker *circuitBreaker) error {
+	if breaker == nil {
+		return nil
+	}
+	breaker.lock.Lock()
+	from := breaker.state
+	allowed := true
+	switch {
+	case breaker.state == CircuitOpen && time.Since(breaker.openedAt) >= breaker.policy.OpenDuration:
+		breaker.state = CircuitHalfOpen
+		breaker.probing = true
+	case breaker.state == CircuitOpen, breaker.state == CircuitHalfOpen && breaker.probing:
+		allowed = false
+	case breaker.state == CircuitHalfOpen:
+		breaker.probing = true
+	}
+	to := breaker.state
+	breaker.lock.Unlock()
+
+	timeserData.circuitChanged(breaker, from, to)
+	if !allowed {
+		if hook, ok := timeserData.metrics.(CircuitMetricsHook); ok {
+			hook.CircuitRejected()
+		}
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// Records the outcome of a request allowed by circuitAllow()
+func (timeserData *TimeSeriesClientData) circuitRecord(breaker *circuitBreaker, err error) {
+	if breaker == nil {
+		return
+	}
+	_, failed := err.(net.Error)
+	breaker.lock.Lock()
+	from := breaker.state
+	breaker.probing = false
+	if failed {
+		breaker.failures++
+		if breaker.state == CircuitHalfOpen || breaker.failures >= breaker.policy.FailureThreshold {
+			breaker.state = CircuitOpen
+			breaker.openedAt = time.Now()
+		}
+	} else {
+		breaker.failures = 0
+		breaker.state = CircuitClosed
+	}
+	to := breaker.state
+	breaker.lock.Unlock()
+	timeserData.circuitChanged(breaker, from, to)
+}
+
+// Reports a change of state to the policy and, for the primary, to the metrics hook
+func (timeserData *TimeSeriesClientData) circuitChanged(breaker *circuitBreaker, from, to CircuitState) {
+	if from == to {
+		return
+	}
+	timeserData.logger().Warnf("%v circuit breaker %v -> %v\n", breaker.endpoint, from, to)
+	if breaker != timeserData.breaker {
+		return
+	}
+	if hook, ok := timeserData.metrics.(CircuitMetricsHook); ok {
+		hook.CircuitStateChanged(to)
+	}
+	if onStateChange := breaker.policy.OnStateChange; onStateChange != nil {
+		onStateChange(from, to)
+	}
+}
@@ -0,0 +1,88 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"stslgo"
+	"testing"
+	"time"
+)
+
+// Test function for failing fast while TimeSeriesDB is down
+func TestTimeSeriesDbCircuitBreaker(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	client := &failingWriteClient{failures: 10}
+	timeserData.Iclient = client
+	metrics := stslgo.NewMetrics()
+	timeserData.SetMetricsHook(metrics)
+	transitions := []string{}
+	timeserData.SetCircuitBreaker(stslgo.CircuitBreakerPolicy{
+		FailureThreshold: 2, OpenDuration: 20 * time.Millisecond,
+		OnStateChange: func(from, to stslgo.CircuitState) {
+			transitions = append(transitions, from.String()+">"+to.String())
+		},
+	})
+
+	point := []stslgo.Point{{Fields: map[string]interface{}{"prb": 1}}}
+	for i := 0; i < 2; i++ {
+		if err = timeserData.WritePoints("CellKpi", point); err == nil || stslgo.IsKind(err, stslgo.ErrCircuitOpen) {
+			t.Errorf("Expected the write to fail on TimeSeriesDB, got %v", err)
+		}
+	}
+	if timeserData.CircuitState() != stslgo.CircuitOpen {
+		t.Fatalf("Expected the circuit open, got %v", timeserData.CircuitState())
+	}
+	if err = timeserData.WritePoints("CellKpi", point); !stslgo.IsKind(err, stslgo.ErrCircuitOpen) || client.failures != 8 {
+		t.Errorf("Expected the write to fail fast, got %v with %v failures left", err, client.failures)
+	}
+	if _, err = timeserData.Query("SELECT * FROM CellKpi"); err != stslgo.ErrCircuitOpen {
+		t.Errorf("Expected the query to fail fast, got %v", err)
+	}
+
+	// A failed probe opens the circuit again
+	time.Sleep(25 * time.Millisecond)
+	timeserData.WritePoints("CellKpi", point)
+	if timeserData.CircuitState() != stslgo.CircuitOpen || client.failures != 7 {
+		t.Errorf("Expected the circuit open after the probe, got %v", timeserData.CircuitState())
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	client.failures = 0
+	if err = timeserData.WritePoints("CellKpi", point); err != nil || timeserData.CircuitState() != stslgo.CircuitClosed {
+		t.Errorf("Expected the probe to close the circuit, got %v in state %v", err, timeserData.CircuitState())
+	}
+	expected := []string{"closed>open", "open>half-open", "half-open>open", "open>half-open", "half-open>closed"}
+	if !reflect.DeepEqual(transitions, expected) {
+		t.Errorf("Unexpected transitions %v", transitions)
+	}
+
+	var exported bytes.Buffer
+	metrics.WritePrometheus(&exported)
+	if !strings.Contains(exported.String(), "stslgo_circuit_breaker_state 0\n") || !strings.Contains(exported.String(), "stslgo_circuit_breaker_rejected_total 2\n") {
+		t.Errorf("Unexpected metrics %v", exported.String())
+	}
+}
@@ -0,0 +1,85 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Runs a query and writes its result to w as CSV, in the layout of the CSV output of the TimeSeriesDB HTTP API:
+// name, tags (k=v pairs) and the columns of each series, with a header row whenever the columns change
+func (timeserData *TimeSeriesClientData) QueryToCSV(queryStr string, w io.Writer) (err error) {
+	q := timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, "")
+	response, err := timeserData.query(q)
+	if err != nil {
+		timeserData.logger().Errorf("Failed to query %v for CSV with error %v\n", queryStr, err)
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	var header []string
+	rows := 0
+	for _, result := range response.Results {
+		for _, series := range result.Series {
+			columns := append([]string{"name", "tags"}, series.Columns...)
+			if strings.Join(columns, ",") != strings.Join(header, ",") {
+				header = columns
+				if err = writer.Write(header); err != nil {
+					return err
+				}
+			}
+			tags := _csvTags(series.Tags)
+			for _, value := range series.Values {
+				record := make([]string, 0, len(columns))
+				record = append(record, series.Name, tags)
+				for i := range series.Columns {
+					cell := ""
+					if i < len(value) && value[i] != nil {
+						cell = fmt.Sprint(value[i])
+					}
+					record = append(record, cell)
+				}
+				if err = writer.Write(record); err != nil {
+					return err
+				}
+				rows++
+			}
+		}
+	}
+	writer.Flush()
+	timeserData.logger().Debugf("TimeSeriesDB QueryToCSV: DB=%v, QueryString=%v, rows=%v\n", timeserData.timeSeriesDbName, queryStr, rows)
+	return writer.Error()
+}
+
+// Formats the tags of a series as sorted k=v pairs
+func _csvTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
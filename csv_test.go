@@ -0,0 +1,72 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for writing query results as CSV
+func TestTimeSeriesDbQueryToCSV(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+			{Name: "CellKpi", Tags: map[string]string{"cellId": "c1", "node": "gnb,1"}, Columns: []string{"time", "prb"},
+				Values: [][]interface{}{{"2021-08-20T05:47:46Z", json.Number("3")}, {"2021-08-20T05:47:47Z", nil}}},
+			{Name: "CellKpi", Tags: map[string]string{"cellId": "c2"}, Columns: []string{"time", "prb"},
+				Values: [][]interface{}{{"2021-08-20T05:47:46Z", json.Number("4.5")}}},
+			{Name: "UeKpi", Columns: []string{"time", "rsrp", "state"},
+				Values: [][]interface{}{{"2021-08-20T05:47:46Z", json.Number("-90"), "up"}}},
+		}}}}, nil
+	}
+
+	var buf bytes.Buffer
+	if err = timeserData.QueryToCSV("SELECT * FROM CellKpi, UeKpi GROUP BY *", &buf); err != nil {
+		t.Fatalf("Unable to export CSV with error %v", err)
+	}
+	expected := `name,tags,time,prb
+CellKpi,"cellId=c1,node=gnb,1",2021-08-20T05:47:46Z,3
+CellKpi,"cellId=c1,node=gnb,1",2021-08-20T05:47:47Z,
+CellKpi,cellId=c2,2021-08-20T05:47:46Z,4.5
+name,tags,time,rsrp,state
+UeKpi,,2021-08-20T05:47:46Z,-90,up
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected CSV:\n%v", buf.String())
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return nil, errors.New("timeout")
+	}
+	if err = timeserData.QueryToCSV("SELECT * FROM CellKpi", &buf); err == nil {
+		t.Errorf("Expected query error")
+	}
+}
@@ -0,0 +1,192 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Retention policy of a DB, as reported by SHOW RETENTION POLICIES
+type RetentionPolicyInfo struct {
+	Name               string
+	Duration           time.Duration // 0 for infinite
+	ShardGroupDuration time.Duration
+	ReplicaN           int64
+	Default            bool
+}
+
+// Metadata of a DB for capacity management. TimeSeriesDB does not keep the creation time of a DB, the estimated
+// number of series stands for its size
+type TimeSeriesDBInfo struct {
+	Name              string
+	RetentionPolicies []RetentionPolicyInfo
+	Series            int64 // Estimated series cardinality
+}
+
+// Changes made by UpdateTimeSeriesDB(), empty or zero values are left unchanged
+type UpdateTimeSeriesDBOptions struct {
+	RetentionPolicy string // Retention policy updated, the default one of the DB when empty
+	Duration        string // Retention duration, see ParseRetentionDuration()
+	ShardDuration   string // Time range of the shard groups, eg. 1d, instead of the retention duration
+	Replication     int    // Number of copies of the points in a cluster
+	Default         bool   // Makes the retention policy the default one of the DB
+}
+
+// Updates a retention policy of the DB of the client with a single ALTER RETENTION POLICY. Unlike
+// UpdateRetentionPolicy(), the shard duration is only set when given. TimeSeriesDB keeps no description of a DB
+// and cannot rename a DB or a retention policy: create the new one and copy the points with SELECT * INTO
+func (timeserData *TimeSeriesClientData) UpdateTimeSeriesDB(opts UpdateTimeSeriesDBOptions) (err error) {
+	var changes string
+	if opts.Duration != "" {
+		duration, err := _retentionLiteral(opts.Duration)
+		if err != nil {
+			return err
+		}
+		changes += " DURATION " + duration
+	}
+	if opts.Replication < 0 {
+		return fmt.Errorf("Invalid replication %v", opts.Replication)
+	}
+	if opts.Replication > 0 {
+		changes += fmt.Sprintf(" REPLICATION %v", opts.Replication)
+	}
+	if opts.ShardDuration != "" {
+		shardDuration, err := _retentionLiteral(opts.ShardDuration)
+		if err == nil && shardDuration == "INF" {
+			err = fmt.Errorf("Invalid shard duration %q: shards cannot be infinite", opts.ShardDuration)
+		}
+		if err != nil {
+			return err
+		}
+		changes += " SHARD DURATION " + shardDuration
+	}
+	if opts.Default {
+		changes += " DEFAULT"
+	}
+	if changes == "" {
+		return errors.New("UpdateTimeSeriesDB needs a duration, replication, shard duration or default to update")
+	}
+
+	retentionPolicyName := opts.RetentionPolicy
+	if retentionPolicyName == "" {
+		info, err := timeserData.GetTimeSeriesDBInfo()
+		if err != nil {
+			return err
+		}
+		for _, policy := range info.RetentionPolicies {
+			if policy.Default {
+				retentionPolicyName = policy.Name
+			}
+		}
+		if retentionPolicyName == "" {
+			return fmt.Errorf("DB %v has no default retention policy", timeserData.timeSeriesDbName)
+		}
+	}
+	queryStr := fmt.Sprintf("ALTER RETENTION POLICY %v ON %v%v", _quoteIdent(retentionPolicyName), _quoteIdent(timeserData.timeSeriesDbName), changes)
+	if _, err = timeserData.query(timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, "")); err != nil {
+		timeserData.logger().Errorf("Failed to update retention policy %v of DB %v with error %v\n", retentionPolicyName, timeserData.timeSeriesDbName, err)
+		return err
+	}
+	timeserData.logger().Infof("Sucessfully updated retention policy %v of DB %v:%v\n", retentionPolicyName, timeserData.timeSeriesDbName, changes)
+	return nil
+}
+
+// Returns the metadata of all the DBs, with one query for the names and one per DB
+func (timeserData *TimeSeriesClientData) ListTimeSeriesDBs() (infos []TimeSeriesDBInfo, err error) {
+	response, err := timeserData.query(timesrclient.NewQuery("SHOW DATABASES", "", ""))
+	if err != nil {
+		timeserData.logger().Errorf("Failed to list DBs with error %v\n", err)
+		return nil, err
+	}
+	infos = []TimeSeriesDBInfo{}
+	for _, result := range response.Results {
+		for _, row := range result.Series {
+			for _, value := range row.Values {
+				name, ok := value[0].(string)
+				if !ok {
+					continue
+				}
+				info, err := timeserData.dbInfo(name)
+				if err != nil {
+					return nil, err
+				}
+				infos = append(infos, info)
+			}
+		}
+	}
+	return infos, nil
+}
+
+// Returns the metadata of the DB of the client, ErrTimeSeriesDBNotFound when it does not exist
+func (timeserData *TimeSeriesClientData) GetTimeSeriesDBInfo() (TimeSeriesDBInfo, error) {
+	return timeserData.dbInfo(timeserData.timeSeriesDbName)
+}
+
+// Gets the retention policies and series cardinality of a DB with a single request
+func (timeserData *TimeSeriesClientData) dbInfo(dbName string) (info TimeSeriesDBInfo, err error) {
+	queryStr := fmt.Sprintf("SHOW RETENTION POLICIES ON %v; SHOW SERIES CARDINALITY ON %v", _quoteIdent(dbName), _quoteIdent(dbName))
+	response, err := timeserData.query(timesrclient.NewQuery(queryStr, "", ""))
+	if err != nil {
+		timeserData.logger().Errorf("Failed to get info of DB %v with error %v\n", dbName, err)
+		return info, err
+	}
+
+	info = TimeSeriesDBInfo{Name: dbName, RetentionPolicies: []RetentionPolicyInfo{}}
+	if len(response.Results) > 0 {
+		// Columns are name, duration, shardGroupDuration, replicaN, default
+		for _, row := range response.Results[0].Series {
+			for _, value := range row.Values {
+				if len(value) < 5 {
+					continue
+				}
+				policy := RetentionPolicyInfo{}
+				policy.Name, _ = value[0].(string)
+				duration, _ := value[1].(string)
+				if policy.Duration, err = time.ParseDuration(duration); err != nil {
+					return info, err
+				}
+				shardGroupDuration, _ := value[2].(string)
+				if policy.ShardGroupDuration, err = time.ParseDuration(shardGroupDuration); err != nil {
+					return info, err
+				}
+				policy.ReplicaN, _ = _toInt64(value[3])
+				policy.Default = value[4] == true
+				info.RetentionPolicies = append(info.RetentionPolicies, policy)
+			}
+		}
+	}
+	if len(response.Results) > 1 {
+		// A single count, or one per measurement with the exact cardinality
+		for _, row := range response.Results[1].Series {
+			for _, value := range row.Values {
+				if len(value) > 0 {
+					count, _ := _toInt64(value[0])
+					info.Series += count
+				}
+			}
+		}
+	}
+	timeserData.logger().Debugf("TimeSeriesDB Info: DB=%v info=%v\n", dbName, info)
+	return info, nil
+}
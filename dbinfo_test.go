@@ -0,0 +1,130 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"stslgo"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for listing the DBs with their metadata
+func TestTimeSeriesDbInfo(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		if q.Command == "SHOW DATABASES" {
+			return seriesResp("databases", []string{"name"}, []interface{}{"_internal"}, []interface{}{"testdb"}), nil
+		}
+		if strings.Contains(q.Command, `"missing"`) {
+			return &timesrclient.Response{Results: []timesrclient.Result{{Err: "database not found: missing"}}}, nil
+		}
+		policies := models.Row{Columns: []string{"name", "duration", "shardGroupDuration", "replicaN", "default"}, Values: [][]interface{}{
+			{"autogen", "0s", "168h0m0s", json.Number("1"), false},
+			{"rp_7d", "168h0m0s", "24h0m0s", json.Number("1"), true},
+		}}
+		cardinality := models.Row{Columns: []string{"cardinality estimation"}, Values: [][]interface{}{{json.Number("42")}}}
+		return &timesrclient.Response{Results: []timesrclient.Result{
+			{Series: []models.Row{policies}}, {Series: []models.Row{cardinality}},
+		}}, nil
+	}
+
+	infos, err := timeserData.ListTimeSeriesDBs()
+	if err != nil {
+		t.Fatalf("Unable to list DBs with error %v", err)
+	}
+	if len(infos) != 2 || infos[0].Name != "_internal" || infos[1].Name != "testdb" {
+		t.Fatalf("Unexpected DBs %v", infos)
+	}
+	if issuedQueries[2] != `SHOW RETENTION POLICIES ON "testdb"; SHOW SERIES CARDINALITY ON "testdb"` {
+		t.Errorf("Unexpected query %v", issuedQueries[2])
+	}
+
+	info, err := timeserData.GetTimeSeriesDBInfo()
+	if err != nil {
+		t.Fatalf("Unable to get DB info with error %v", err)
+	}
+	expected := stslgo.RetentionPolicyInfo{Name: "rp_7d", Duration: 7 * 24 * time.Hour, ShardGroupDuration: 24 * time.Hour, ReplicaN: 1, Default: true}
+	if info.Series != 42 || len(info.RetentionPolicies) != 2 || info.RetentionPolicies[1] != expected || info.RetentionPolicies[0].Duration != 0 {
+		t.Errorf("Unexpected info %v", info)
+	}
+
+	missing := stslgo.NewTimeSeriesClientData("missing", "", "")
+	missing.Iclient = timeserData.Iclient
+	if _, err = missing.GetTimeSeriesDBInfo(); !stslgo.IsKind(err, stslgo.ErrTimeSeriesDBNotFound) {
+		t.Errorf("Expected ErrTimeSeriesDBNotFound, got %v", err)
+	}
+}
+
+// Test function for updating the retention policy of the DB with explicit options
+func TestTimeSeriesDbUpdate(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		policies := models.Row{Columns: []string{"name", "duration", "shardGroupDuration", "replicaN", "default"}, Values: [][]interface{}{
+			{"autogen", "0s", "168h0m0s", json.Number("1"), false},
+			{"rp_7d", "168h0m0s", "24h0m0s", json.Number("1"), true},
+		}}
+		return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{policies}}, {}}}, nil
+	}
+
+	if err = timeserData.UpdateTimeSeriesDB(stslgo.UpdateTimeSeriesDBOptions{Duration: "14d", ShardDuration: "12h"}); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	opts := stslgo.UpdateTimeSeriesDBOptions{RetentionPolicy: "autogen", Duration: "1y", Replication: 2, Default: true}
+	if err = timeserData.UpdateTimeSeriesDB(opts); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	expected := []string{
+		`SHOW RETENTION POLICIES ON "testdb"; SHOW SERIES CARDINALITY ON "testdb"`,
+		`ALTER RETENTION POLICY "rp_7d" ON "testdb" DURATION 14d SHARD DURATION 12h`,
+		`ALTER RETENTION POLICY "autogen" ON "testdb" DURATION 365d REPLICATION 2 DEFAULT`,
+	}
+	if fmt.Sprint(issuedQueries) != fmt.Sprint(expected) {
+		t.Errorf("Unexpected queries %q", issuedQueries)
+	}
+
+	issuedQueries = nil
+	for _, opts := range []stslgo.UpdateTimeSeriesDBOptions{
+		{},
+		{Duration: "5x3"},
+		{ShardDuration: "INF"},
+		{Replication: -1},
+	} {
+		if err = timeserData.UpdateTimeSeriesDB(opts); err == nil {
+			t.Errorf("Expected an error for %+v", opts)
+		}
+	}
+	if len(issuedQueries) != 0 {
+		t.Errorf("Unexpected queries %q", issuedQueries)
+	}
+}
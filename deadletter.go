@@ -0,0 +1,154 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// File keeping the batches which failed for good, in the format of influx -import
+type deadLetterFile struct {
+	lock     sync.Mutex
+	path     string
+	maxBytes int64
+	size     int64
+}
+
+// Sets a hook receiving every batch which failed for good, after the retries and unless kept in the spill file,
+// with the line protocol of its points (nanosecond timestamps), eg. to raise an alarm or re-route the KPIs. The
+// batches retried later, by a BatchWriter or ConsumeKafka(), are passed only once given up. It is called by the
+// failing write and must not block
+func (timeserData *TimeSeriesClientData) OnWriteError(hook func(err error, batchLines []string)) {
+	timeserData.writeErrorHook = hook
+}
+
+// Appends the batches which failed for good to a file, up to maxBytes (DefaultSpillMaxBytes if 0), which can be
+// loaded once the cause is fixed with influx -import -path <path>. Each batch is preceded by its DB, retention
+// policy, time and error as comments
+func (timeserData *TimeSeriesClientData) SetDeadLetterFile(path string, maxBytes int64) error {
+	if maxBytes <= 0 {
+		maxBytes = DefaultSpillMaxBytes
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	timeserData.deadLetter = &deadLetterFile{path: path, maxBytes: maxBytes, size: info.Size()}
+	return nil
+}
+
+// Batches which failed to write, kept for the caller retrying them instead of reported
+type failedBatches struct {
+	lock    sync.Mutex
+	batches []timesrclient.BatchPoints
+	err     error
+}
+
+type failedBatchesKey struct{}
+
+// Returns a context collecting the batches failing to write instead of reporting them, for the callers which
+// retry them and report them with reportFailed() when they give up
+func _collectFailed(ctx context.Context) (context.Context, *failedBatches) {
+	failed := &failedBatches{}
+	return context.WithValue(ctx, failedBatchesKey{}, failed), failed
+}
+
+// Keeps a batch which failed to write in the collector of ctx
+func _keepFailed(ctx context.Context, bp timesrclient.BatchPoints, err error) {
+	if failed, ok := ctx.Value(failedBatchesKey{}).(*failedBatches); ok {
+		failed.lock.Lock()
+		failed.batches = append(failed.batches, bp)
+		failed.err = err
+		failed.lock.Unlock()
+	}
+}
+
+// Hands the batches collected which failed for good to the hook and the dead letter file
+func (timeserData *TimeSeriesClientData) reportFailed(failed *failedBatches) {
+	failed.lock.Lock()
+	batches, err := failed.batches, failed.err
+	failed.batches = nil
+	failed.lock.Unlock()
+	for _, bp := range batches {
+		timeserData.reportFailedBatch(bp, err)
+	}
+}
+
+// Hands a batch which failed for good to the hook and the dead letter file
+func (timeserData *TimeSeriesClientData) reportFailedBatch(bp timesrclient.BatchPoints, err error) {
+	hook, deadLetter := timeserData.writeErrorHook, timeserData.deadLetter
+	if hook == nil && deadLetter == nil {
+		return
+	}
+	lines := make([]string, len(bp.Points()))
+	for i, pt := range bp.Points() {
+		lines[i] = _spillLine(pt, bp.Precision())
+	}
+	if hook != nil {
+		hook(err, lines)
+	}
+	if deadLetter != nil {
+		if derr := deadLetter.append(bp, lines, err); derr != nil {
+			timeserData.logger().Errorf("TimeSeriesDB dead letter file %v: dropping %v points: %v\n", deadLetter.path, len(lines), derr)
+		}
+	}
+}
+
+// Appends the lines of a failed batch
+func (deadLetter *deadLetterFile) append(bp timesrclient.BatchPoints, lines []string, writeErr error) error {
+	var record strings.Builder
+	deadLetter.lock.Lock()
+	defer deadLetter.lock.Unlock()
+	if deadLetter.size == 0 {
+		record.WriteString("# DML\n")
+	}
+	fmt.Fprintf(&record, "# CONTEXT-DATABASE: %v\n", bp.Database())
+	if bp.RetentionPolicy() != "" {
+		fmt.Fprintf(&record, "# CONTEXT-RETENTION-POLICY: %v\n", bp.RetentionPolicy())
+	}
+	fmt.Fprintf(&record, "# %v %v\n", time.Now().UTC().Format(time.RFC3339), strings.Replace(writeErr.Error(), "\n", " ", -1))
+	for _, line := range lines {
+		record.WriteString(line + "\n")
+	}
+	if deadLetter.size+int64(record.Len()) > deadLetter.maxBytes {
+		return fmt.Errorf("file full")
+	}
+
+	file, err := os.OpenFile(deadLetter.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	n, err := file.WriteString(record.String())
+	deadLetter.size += int64(n)
+	return err
+}
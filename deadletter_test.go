@@ -0,0 +1,114 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stslgo"
+)
+
+// Test function for passing the batches failed for good to the hook and the dead letter file
+func TestTimeSeriesDbDeadLetter(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	var hookErr error
+	var hookLines []string
+	timeserData.OnWriteError(func(err error, batchLines []string) {
+		hookErr, hookLines = err, batchLines
+	})
+	dir, err := ioutil.TempDir("", "stslgo")
+	if err != nil {
+		t.Fatalf("TempDir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "deadletter.txt")
+	if err = timeserData.SetDeadLetterFile(path, 0); err != nil {
+		t.Fatalf("SetDeadLetterFile failed: %v", err)
+	}
+
+	writeErr = errors.New("partial write: field type conflict")
+	ts := time.Unix(0, 1000)
+	_ = timeserData.WritePointAt("DeadTable", map[string]string{"cell": "c1"}, map[string]interface{}{"prb": 1}, ts)
+	_ = timeserData.WritePointAt("DeadTable", map[string]string{"cell": "c2"}, map[string]interface{}{"prb": 2}, ts)
+	if !stslgo.IsKind(hookErr, stslgo.ErrWriteFailed) || stslgo.ErrorCause(hookErr) != writeErr {
+		t.Errorf("Expected hook invoked with the write error, got %v", hookErr)
+	}
+	if len(hookLines) != 1 || hookLines[0] != "DeadTable,cell=c2 prb=2i 1000" {
+		t.Errorf("Unexpected hook lines %q", hookLines)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Reading the dead letter file failed: %v", err)
+	}
+	text := string(content)
+	if !strings.HasPrefix(text, "# DML\n# CONTEXT-DATABASE: testdb\n") || strings.Count(text, "# DML") != 1 {
+		t.Errorf("Unexpected dead letter header %q", text)
+	}
+	if !strings.Contains(text, "field type conflict\nDeadTable,cell=c1 prb=1i 1000\n") || !strings.HasSuffix(text, "DeadTable,cell=c2 prb=2i 1000\n") {
+		t.Errorf("Unexpected dead letter content %q", text)
+	}
+
+	// Batches rejected by TimeSeriesDB are reported right away by a BatchWriter
+	hookLines = nil
+	rejecting := timeserData.NewBatchWriter(stslgo.BatchWriterConfig{BatchSize: 1, MaxRetained: 1})
+	_ = rejecting.WritePoint("DeadTable", map[string]string{"cell": "c5"}, map[string]interface{}{"prb": 5})
+	if len(hookLines) != 1 || !strings.HasPrefix(hookLines[0], "DeadTable,cell=c5 prb=5i ") {
+		t.Errorf("Expected the rejected batch reported, got %q", hookLines)
+	}
+	_ = rejecting.Close()
+
+	// Batches retained by a BatchWriter are reported once dropped
+	writeErr = errors.New("timeout")
+	hookLines = nil
+	bw := timeserData.NewBatchWriter(stslgo.BatchWriterConfig{BatchSize: 1, MaxRetained: 1})
+	_ = bw.WritePoint("DeadTable", map[string]string{"cell": "c3"}, map[string]interface{}{"prb": 3})
+	if hookLines != nil {
+		t.Errorf("Hook invoked for a batch retained for retry: %q", hookLines)
+	}
+	_ = bw.WritePoint("DeadTable", map[string]string{"cell": "c4"}, map[string]interface{}{"prb": 4})
+	if len(hookLines) != 1 || !strings.HasPrefix(hookLines[0], "DeadTable,cell=c3 prb=3i ") {
+		t.Errorf("Expected the dropped batch reported, got %q", hookLines)
+	}
+	hookLines = nil
+	_ = bw.Close()
+	if len(hookLines) != 1 || !strings.HasPrefix(hookLines[0], "DeadTable,cell=c4 prb=4i ") {
+		t.Errorf("Expected the batch retained at close reported, got %q", hookLines)
+	}
+
+	// Successful writes are not reported
+	writeErr = nil
+	hookLines = nil
+	_ = timeserData.WritePoint("DeadTable", nil, map[string]interface{}{"prb": 3})
+	if hookLines != nil {
+		t.Errorf("Hook invoked for a successful write")
+	}
+}
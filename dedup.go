@@ -0,0 +1,126 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Points written within the window, by the hash of their database, retention policy and line protocol
+type dedupFilter struct {
+	lock    sync.Mutex
+	window  time.Duration
+	seen    map[uint64]time.Time
+	order   []dedupEntry // Hashes in the order they were written, for expiring them
+	skipped int64
+}
+
+type dedupEntry struct {
+	hash uint64
+	at   time.Time
+}
+
+// Skips the points identical (measurement, tags, fields and timestamp) to a point written within the last window,
+// eg. E2 indications retransmitted or written twice after a subscription restart. Only points with the timestamp
+// of their source deduplicate, points stamped at the time of the write never match. 0 disables the deduplication.
+// To be set before writing
+func (timeserData *TimeSeriesClientData) SetDedupWindow(window time.Duration) {
+	if window <= 0 {
+		timeserData.dedup = nil
+		return
+	}
+	timeserData.dedup = &dedupFilter{window: window, seen: make(map[uint64]time.Time)}
+}
+
+// Returns the number of duplicate points skipped since SetDedupWindow()
+func (timeserData *TimeSeriesClientData) DuplicatesSkipped() int64 {
+	dedup := timeserData.dedup
+	if dedup == nil {
+		return 0
+	}
+	dedup.lock.Lock()
+	defer dedup.lock.Unlock()
+	return dedup.skipped
+}
+
+// Writes the points of the batch not written within the window. Points are remembered once written, so that a
+// failed write can be retried
+func (dedup *dedupFilter) write(bp timesrclient.BatchPoints, write func(timesrclient.BatchPoints) error) error {
+	batch, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+		Database:         bp.Database(),
+		Precision:        bp.Precision(),
+		RetentionPolicy:  bp.RetentionPolicy(),
+		WriteConsistency: bp.WriteConsistency(),
+	})
+	hashes := make([]uint64, 0, len(bp.Points()))
+	batchHashes := make(map[uint64]bool, len(bp.Points()))
+
+	dedup.lock.Lock()
+	now := time.Now()
+	dedup.expire(now)
+	for _, pt := range bp.Points() {
+		hash := _pointHash(bp, pt)
+		if _, ok := dedup.seen[hash]; ok || batchHashes[hash] {
+			dedup.skipped++
+			continue
+		}
+		batchHashes[hash] = true
+		hashes = append(hashes, hash)
+		batch.AddPoint(pt)
+	}
+	dedup.lock.Unlock()
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	if err := write(batch); err != nil {
+		return err
+	}
+	dedup.lock.Lock()
+	defer dedup.lock.Unlock()
+	for _, hash := range hashes {
+		dedup.seen[hash] = now
+		dedup.order = append(dedup.order, dedupEntry{hash: hash, at: now})
+	}
+	return nil
+}
+
+// Forgets the points written before the window, called with the lock held
+func (dedup *dedupFilter) expire(now time.Time) {
+	n := 0
+	for ; n < len(dedup.order) && now.Sub(dedup.order[n].at) > dedup.window; n++ {
+		entry := dedup.order[n]
+		if at, ok := dedup.seen[entry.hash]; ok && !at.After(entry.at) {
+			delete(dedup.seen, entry.hash)
+		}
+	}
+	dedup.order = dedup.order[n:]
+}
+
+// Hashes the destination and line protocol of a point, whose tags and fields are sorted
+func _pointHash(bp timesrclient.BatchPoints, pt *timesrclient.Point) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(bp.Database() + "\t" + bp.RetentionPolicy() + "\t" + pt.String()))
+	return h.Sum64()
+}
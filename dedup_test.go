@@ -0,0 +1,82 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"errors"
+	"fmt"
+	"stslgo"
+	"testing"
+	"time"
+)
+
+// Test function for skipping the points written again within the deduplication window
+func TestTimeSeriesDbDedup(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	timeserData.SetDedupWindow(50 * time.Millisecond)
+
+	at := time.Date(2021, 8, 20, 5, 47, 46, 0, time.UTC)
+	points := []stslgo.Point{
+		{Tags: map[string]string{"cellId": "c1"}, Fields: map[string]interface{}{"prb": 1, "load": 0.5}, Time: at},
+		{Tags: map[string]string{"cellId": "c1"}, Fields: map[string]interface{}{"load": 0.5, "prb": 1}, Time: at},
+		{Tags: map[string]string{"cellId": "c2"}, Fields: map[string]interface{}{"prb": 1, "load": 0.5}, Time: at},
+	}
+	if err = timeserData.WritePoints("CellKpi", points); err != nil {
+		t.Fatalf("Unable to write points with error %v", err)
+	}
+	if len(writtenPoints) != 2 || timeserData.DuplicatesSkipped() != 1 {
+		t.Fatalf("Expected the duplicate within the batch skipped, got %v points", len(writtenPoints))
+	}
+
+	// Retransmission
+	if err = timeserData.WritePointAt("CellKpi", map[string]string{"cellId": "c2"}, map[string]interface{}{"prb": 1, "load": 0.5}, at); err != nil {
+		t.Fatalf("Unable to write point with error %v", err)
+	}
+	if writeCalls != 1 || timeserData.DuplicatesSkipped() != 2 {
+		t.Errorf("Expected the retransmitted point skipped, got %v writes", writeCalls)
+	}
+	if err = timeserData.WritePointAt("CellKpi", map[string]string{"cellId": "c2"}, map[string]interface{}{"prb": 2, "load": 0.5}, at); err != nil || writeCalls != 2 {
+		t.Errorf("Expected a point with another value written, got %v writes with error %v", writeCalls, err)
+	}
+
+	// Failed writes can be retried
+	writeErr = errors.New("timeout")
+	point := map[string]interface{}{"prb": 3}
+	timeserData.WritePointAt("CellKpi", nil, point, at)
+	writeErr = nil
+	if timeserData.WritePointAt("CellKpi", nil, point, at); len(writtenPoints) != 4 {
+		t.Errorf("Expected the point of the failed write written, got %v points", len(writtenPoints))
+	}
+
+	// After the window
+	time.Sleep(60 * time.Millisecond)
+	if err = timeserData.WritePoints("CellKpi", points[:1]); err != nil || len(writtenPoints) != 5 {
+		t.Errorf("Expected the point written after the window, got %v points with error %v", len(writtenPoints), err)
+	}
+
+	timeserData.SetDedupWindow(0)
+	timeserData.WritePoints("CellKpi", points[:1])
+	if len(writtenPoints) != 6 {
+		t.Errorf("Expected no deduplication when disabled, got %v points", len(writtenPoints))
+	}
+}
@@ -0,0 +1,76 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"fmt"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Returns the names of the measurements of the DB
+func (timeserData *TimeSeriesClientData) ListMeasurements() ([]string, error) {
+	return timeserData.showColumn("SHOW MEASUREMENTS", 0)
+}
+
+// Checks whether the DB has a measurement, ie. points were written to it and not all dropped
+func (timeserData *TimeSeriesClientData) MeasurementExists(measurement string) (bool, error) {
+	names, err := timeserData.showColumn(fmt.Sprintf("SHOW MEASUREMENTS WITH MEASUREMENT = %v", timeserData.measurementIdent(measurement)), 0)
+	return len(names) > 0, err
+}
+
+// Returns the tag keys of a measurement
+func (timeserData *TimeSeriesClientData) ListTagKeys(measurement string) ([]string, error) {
+	return timeserData.showColumn(fmt.Sprintf("SHOW TAG KEYS FROM %v", timeserData.measurementIdent(measurement)), 0)
+}
+
+// Returns the values of a tag of a measurement
+func (timeserData *TimeSeriesClientData) ListTagValues(measurement, tagKey string) ([]string, error) {
+	// Columns are key and value
+	return timeserData.showColumn(fmt.Sprintf("SHOW TAG VALUES FROM %v WITH KEY = %v", timeserData.measurementIdent(measurement), _quoteIdent(tagKey)), 1)
+}
+
+// Returns the field keys of a measurement, DescribeMeasurement() gives their types
+func (timeserData *TimeSeriesClientData) ListFieldKeys(measurement string) ([]string, error) {
+	return timeserData.showColumn(fmt.Sprintf("SHOW FIELD KEYS FROM %v", timeserData.measurementIdent(measurement)), 0)
+}
+
+// Runs a SHOW query and returns a column of its rows, in the order of TimeSeriesDB
+func (timeserData *TimeSeriesClientData) showColumn(queryStr string, column int) ([]string, error) {
+	q := timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, "")
+	response, err := timeserData.query(q)
+	if err != nil {
+		timeserData.logger().Errorf("Failed to run %v with error %v\n", queryStr, err)
+		return nil, err
+	}
+
+	names := []string{}
+	for _, result := range response.Results {
+		for _, series := range result.Series {
+			for _, value := range series.Values {
+				if len(value) > column {
+					names = append(names, fmt.Sprint(value[column]))
+				}
+			}
+		}
+	}
+	timeserData.logger().Debugf("TimeSeriesDB %v: DB=%v result=%v\n", queryStr, timeserData.timeSeriesDbName, names)
+	return names, nil
+}
@@ -0,0 +1,108 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"stslgo"
+	"testing"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for discovering the measurements, tags and fields of the DB
+func TestTimeSeriesDbDiscovery(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		switch {
+		case q.Command == "SHOW MEASUREMENTS":
+			return seriesResp("measurements", []string{"name"}, []interface{}{"CellKpi"}, []interface{}{"UeKpi"}), nil
+		case strings.HasPrefix(q.Command, "SHOW TAG KEYS"):
+			return seriesResp("CellKpi", []string{"tagKey"}, []interface{}{"cellId"}, []interface{}{"plmn"}), nil
+		case strings.HasPrefix(q.Command, "SHOW TAG VALUES"):
+			return seriesResp("CellKpi", []string{"key", "value"}, []interface{}{"cellId", "c1"}, []interface{}{"cellId", "c2"}), nil
+		case strings.HasPrefix(q.Command, "SHOW FIELD KEYS"):
+			return seriesResp("CellKpi", []string{"fieldKey", "fieldType"}, []interface{}{"load", "float"}, []interface{}{"prb", "integer"}), nil
+		}
+		return nil, errors.New("unexpected query")
+	}
+
+	for name, call := range map[string]struct {
+		list     func() ([]string, error)
+		query    string
+		expected []string
+	}{
+		"ListMeasurements": {timeserData.ListMeasurements, "SHOW MEASUREMENTS", []string{"CellKpi", "UeKpi"}},
+		"ListTagKeys": {func() ([]string, error) { return timeserData.ListTagKeys("CellKpi") },
+			`SHOW TAG KEYS FROM "CellKpi"`, []string{"cellId", "plmn"}},
+		"ListTagValues": {func() ([]string, error) { return timeserData.ListTagValues("CellKpi", "cellId") },
+			`SHOW TAG VALUES FROM "CellKpi" WITH KEY = "cellId"`, []string{"c1", "c2"}},
+		"ListFieldKeys": {func() ([]string, error) { return timeserData.ListFieldKeys("CellKpi") },
+			`SHOW FIELD KEYS FROM "CellKpi"`, []string{"load", "prb"}},
+	} {
+		issuedQueries = nil
+		names, err := call.list()
+		if err != nil {
+			t.Errorf("%v failed with error %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(names, call.expected) || issuedQueries[0] != call.query {
+			t.Errorf("Unexpected %v result %v for %v", name, names, issuedQueries)
+		}
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Err: "database not found: testdb"}, nil
+	}
+	if _, err = timeserData.ListMeasurements(); !stslgo.IsKind(err, stslgo.ErrTimeSeriesDBNotFound) {
+		t.Errorf("Expected ErrTimeSeriesDBNotFound, got %v", err)
+	}
+}
+
+// Test function for checking whether a measurement exists
+func TestTimeSeriesDbMeasurementExists(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		if strings.HasSuffix(q.Command, `"CellKpi"`) {
+			return seriesResp("measurements", []string{"name"}, []interface{}{"CellKpi"}), nil
+		}
+		return &timesrclient.Response{Results: []timesrclient.Result{{}}}, nil
+	}
+	if exists, err := timeserData.MeasurementExists("CellKpi"); err != nil || !exists {
+		t.Errorf("Expected CellKpi to exist, got %v with error %v", exists, err)
+	}
+	if exists, err := timeserData.MeasurementExists("UeKpi"); err != nil || exists {
+		t.Errorf("Expected UeKpi not to exist, got %v with error %v", exists, err)
+	}
+	if len(issuedQueries) != 2 || issuedQueries[0] != `SHOW MEASUREMENTS WITH MEASUREMENT = "CellKpi"` {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+}
@@ -0,0 +1,118 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Value of a field at a time, see QueryDownsampled()
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// Intervals picked by QueryDownsampled(), beyond them whole days are used
+var downsampleIntervals = []time.Duration{
+	time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond,
+	50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second, 15 * time.Second, 30 * time.Second,
+	time.Minute, 2 * time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour,
+}
+
+// Returns the mean of a field over [start, stop) in evenly spaced windows starting at start, the smallest round
+// interval giving at most maxPoints samples, eg. for a chart. Windows without points are skipped
+func (timeserData *TimeSeriesClientData) QueryDownsampled(measurement, field string, start, stop time.Time, maxPoints int) ([]Sample, error) {
+	return timeserData.QueryDownsampledFill(measurement, field, start, stop, maxPoints, "none")
+}
+
+// As QueryDownsampled(), filling the windows without points for a continuous series: null, none, previous, linear
+// or a number. Windows left empty, eg. before the first point with previous, have a NaN value
+func (timeserData *TimeSeriesClientData) QueryDownsampledFill(measurement, field string, start, stop time.Time, maxPoints int, fill string) (samples []Sample, err error) {
+	if maxPoints <= 0 || !stop.After(start) {
+		return nil, fmt.Errorf("Invalid downsampling of %v to %v points in [%v, %v)", measurement, maxPoints, start, stop)
+	}
+	if !_validFill(fill) {
+		return nil, fmt.Errorf("Unsupported fill %q", fill)
+	}
+	interval := _downsampleInterval(stop.Sub(start), maxPoints)
+	queryStr := fmt.Sprintf("SELECT MEAN(%v) FROM %v WHERE %v GROUP BY time(%v%v) fill(%v)", _quoteIdent(field),
+		timeserData.measurementIdent(measurement), _whereClause("", start, stop), _durationLiteral(interval), _downsampleOffset(start, interval), fill)
+	response, err := timeserData.query(timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, ""))
+	if err != nil {
+		timeserData.logger().Errorf("Failed to downsample %v with error %v\n", measurement, err)
+		return nil, err
+	}
+
+	for _, result := range response.Results {
+		for _, row := range result.Series {
+			for _, value := range row.Values {
+				// Columns are time and the mean
+				if len(value) < 2 || (value[1] == nil && fill == "none") {
+					continue
+				}
+				sample := Sample{Value: math.NaN()}
+				if sample.Time, err = _toTime(value[0]); err != nil {
+					return nil, err
+				}
+				if value[1] != nil {
+					if sample.Value, err = _toFloat64(value[1]); err != nil {
+						return nil, err
+					}
+				}
+				samples = append(samples, sample)
+			}
+		}
+	}
+	timeserData.logger().Debugf("TimeSeriesDB Downsampled: DB=%v, QueryString=%v, Samples=%v\n", timeserData.timeSeriesDbName, queryStr, len(samples))
+	return samples, nil
+}
+
+// Returns the smallest round interval splitting span in at most maxPoints windows
+func _downsampleInterval(span time.Duration, maxPoints int) time.Duration {
+	interval := (span + time.Duration(maxPoints) - 1) / time.Duration(maxPoints)
+	if interval < time.Millisecond {
+		return interval
+	}
+	for _, round := range downsampleIntervals {
+		if round >= interval {
+			return round
+		}
+	}
+	day := 24 * time.Hour
+	return (interval + day - 1) / day * day
+}
+
+// Returns the offset aligning the windows on start, as InfluxQL aligns them on the epoch
+func _downsampleOffset(start time.Time, interval time.Duration) string {
+	offset := time.Duration(start.UnixNano() % int64(interval))
+	if offset < 0 {
+		offset += interval
+	}
+	if offset == 0 {
+		return ""
+	}
+	return ", " + _durationLiteral(offset)
+}
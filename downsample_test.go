@@ -0,0 +1,113 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for downsampling a field to at most a number of points
+func TestTimeSeriesDbQueryDownsampled(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+			{Name: "cellMetrics", Columns: []string{"time", "mean"}, Values: [][]interface{}{
+				{"2024-01-01T00:00:07Z", json.Number("1.5")},
+				{"2024-01-01T00:05:07Z", nil},
+				{"2024-01-01T00:10:07Z", json.Number("3")},
+			}},
+		}}}}, nil
+	}
+
+	// 1h in 20 points needs 3m windows, rounded to 5m and aligned on start
+	start := time.Date(2024, 1, 1, 0, 0, 7, 0, time.UTC)
+	samples, err := timeserData.QueryDownsampled("cellMetrics", "prb", start, start.Add(time.Hour), 20)
+	if err != nil {
+		t.Fatalf("QueryDownsampled failed: %v", err)
+	}
+	expected := `SELECT MEAN("prb") FROM "cellMetrics" WHERE time >= '2024-01-01T00:00:07Z' AND time < '2024-01-01T01:00:07Z' GROUP BY time(5m, 7s) fill(none)`
+	if len(issuedQueries) != 1 || issuedQueries[0] != expected {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+	if len(samples) != 2 || !samples[0].Time.Equal(start) || samples[0].Value != 1.5 || samples[1].Value != 3 {
+		t.Errorf("Unexpected samples %v", samples)
+	}
+
+	// Several days, 2024-01-01 is 1d past a multiple of 3d since the epoch
+	start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, _ = timeserData.QueryDownsampled("cellMetrics", "prb", start, start.Add(30*24*time.Hour), 10)
+	expected = `SELECT MEAN("prb") FROM "cellMetrics" WHERE time >= '2024-01-01T00:00:00Z' AND time < '2024-01-31T00:00:00Z' GROUP BY time(3d, 1d) fill(none)`
+	if len(issuedQueries) != 2 || issuedQueries[1] != expected {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+
+	if _, err = timeserData.QueryDownsampled("cellMetrics", "prb", start, start, 10); err == nil {
+		t.Errorf("Expected an error for an empty range")
+	}
+}
+
+// Test function for filling the empty windows of a downsampled field
+func TestTimeSeriesDbQueryDownsampledFill(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+			{Name: "cellMetrics", Columns: []string{"time", "mean"}, Values: [][]interface{}{
+				{"2024-01-01T00:00:00Z", nil},
+				{"2024-01-01T00:01:00Z", json.Number("2")},
+				{"2024-01-01T00:02:00Z", json.Number("2")},
+			}},
+		}}}}, nil
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples, err := timeserData.QueryDownsampledFill("cellMetrics", "prb", start, start.Add(3*time.Minute), 3, "previous")
+	if err != nil {
+		t.Fatalf("QueryDownsampledFill failed: %v", err)
+	}
+	expected := `SELECT MEAN("prb") FROM "cellMetrics" WHERE time >= '2024-01-01T00:00:00Z' AND time < '2024-01-01T00:03:00Z' GROUP BY time(1m) fill(previous)`
+	if len(issuedQueries) != 1 || issuedQueries[0] != expected {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+	if len(samples) != 3 || !math.IsNaN(samples[0].Value) || samples[1].Value != 2 || samples[2].Value != 2 {
+		t.Errorf("Unexpected samples %v", samples)
+	}
+
+	for _, fill := range []string{"linear", "null", "0"} {
+		if _, err = timeserData.QueryDownsampledFill("cellMetrics", "prb", start, start.Add(3*time.Minute), 3, fill); err != nil {
+			t.Errorf("Fill %v failed: %v", fill, err)
+		}
+	}
+	if _, err = timeserData.QueryDownsampledFill("cellMetrics", "prb", start, start.Add(3*time.Minute), 3, "spline"); err == nil {
+		t.Errorf("Expected an error for an unsupported fill")
+	}
+}
@@ -0,0 +1,118 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration of a retention policy, 0 for the infinite retention INF
+type RetentionDuration time.Duration
+
+// Units of the retention durations. Months and years are 30 and 365 days, InfluxQL has no calendar units
+var retentionUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "u": time.Microsecond, "µ": time.Microsecond, "us": time.Microsecond, "µs": time.Microsecond,
+	"ms": time.Millisecond, "s": time.Second, "m": time.Minute, "h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour,
+	"mo": 30 * 24 * time.Hour, "y": 365 * 24 * time.Hour,
+}
+
+// Duration literal of InfluxQL
+var _influxDuration = regexp.MustCompile(`^(INF|inf|([0-9]+(ns|u|µ|ms|s|m|h|d|w))+)$`)
+
+// Parses a retention duration: numbers each followed by a unit (ns, u or us, ms, s, m, h, d, w, mo or y), eg. 7d,
+// 1h30m, 1.5h or 6mo, or INF. Go durations and InfluxQL duration literals are accepted as is
+func ParseRetentionDuration(s string) (RetentionDuration, error) {
+	str := strings.TrimSpace(s)
+	if strings.EqualFold(str, "INF") || str == "0" {
+		return 0, nil
+	}
+	if str == "" {
+		return 0, fmt.Errorf("Invalid retention duration %q: empty", s)
+	}
+
+	var total time.Duration
+	for rest := str; rest != ""; {
+		number := len(rest) - len(strings.TrimLeft(rest, "0123456789."))
+		if number == 0 {
+			return 0, fmt.Errorf("Invalid retention duration %q: expected a number at %q", s, rest)
+		}
+		unit := len(rest) - len(strings.TrimLeft(rest[number:], "abcdefghijklmnopqrstuvwxyzµ")) - number
+		if unit == 0 {
+			return 0, fmt.Errorf("Invalid retention duration %q: missing unit after %v", s, rest[:number])
+		}
+		scale, ok := retentionUnits[rest[number:number+unit]]
+		if !ok {
+			return 0, fmt.Errorf("Invalid retention duration %q: unknown unit %q, expected ns, u, ms, s, m, h, d, w, mo or y", s, rest[number:number+unit])
+		}
+		value, err := strconv.ParseFloat(rest[:number], 64)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid retention duration %q: bad number %q", s, rest[:number])
+		}
+		// Whole numbers are converted exactly
+		d := time.Duration(value * float64(scale))
+		if n, err := strconv.ParseInt(rest[:number], 10, 64); err == nil {
+			if n > math.MaxInt64/int64(scale) {
+				return 0, fmt.Errorf("Invalid retention duration %q: too long", s)
+			}
+			d = time.Duration(n) * scale
+		} else if value*float64(scale) >= math.MaxInt64 {
+			return 0, fmt.Errorf("Invalid retention duration %q: too long", s)
+		}
+		if total > math.MaxInt64-d {
+			return 0, fmt.Errorf("Invalid retention duration %q: too long", s)
+		}
+		total += d
+		rest = rest[number+unit:]
+	}
+	return RetentionDuration(total), nil
+}
+
+// Returns the InfluxQL literal of the duration in its largest exact unit, eg. 1w for 7d, or INF. Months and years
+// are written as days. The literal is parsed back to the same duration
+func (d RetentionDuration) String() string {
+	if d <= 0 {
+		return "INF"
+	}
+	return _durationLiteral(time.Duration(d))
+}
+
+// Parses a retention duration, see ParseRetentionDuration()
+func _parseRetention(duration string) (time.Duration, error) {
+	d, err := ParseRetentionDuration(duration)
+	return time.Duration(d), err
+}
+
+// Returns the InfluxQL literal of a retention duration, the duration itself when it is one already so that
+// eg. 7d is not sent as 1w
+func _retentionLiteral(duration string) (string, error) {
+	d, err := ParseRetentionDuration(duration)
+	if err != nil {
+		return "", err
+	}
+	if literal := strings.TrimSpace(duration); _influxDuration.MatchString(literal) {
+		return literal, nil
+	}
+	return d.String(), nil
+}
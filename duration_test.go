@@ -0,0 +1,99 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"stslgo"
+)
+
+// Test function for parsing and formatting the retention durations
+func TestTimeSeriesDbRetentionDuration(t *testing.T) {
+	for _, tc := range []struct {
+		duration string
+		expected time.Duration
+		literal  string
+	}{
+		{"7d", 7 * 24 * time.Hour, "1w"},
+		{"36h", 36 * time.Hour, "36h"},
+		{"1h30m", 90 * time.Minute, "90m"},
+		{"1.5h", 90 * time.Minute, "90m"},
+		{"2w3d", 17 * 24 * time.Hour, "17d"},
+		{" 6mo", 180 * 24 * time.Hour, "180d"},
+		{"1y", 365 * 24 * time.Hour, "365d"},
+		{"250ms", 250 * time.Millisecond, "250ms"},
+		{"10µs", 10 * time.Microsecond, "10u"},
+		{"INF", 0, "INF"},
+		{"0", 0, "INF"},
+	} {
+		d, err := stslgo.ParseRetentionDuration(tc.duration)
+		if err != nil || time.Duration(d) != tc.expected || d.String() != tc.literal {
+			t.Errorf("%q: unexpected duration %v (%v), error %v", tc.duration, time.Duration(d), d, err)
+			continue
+		}
+		if back, err := stslgo.ParseRetentionDuration(d.String()); err != nil || back != d {
+			t.Errorf("%q: %v does not round-trip, got %v with error %v", tc.duration, d, back, err)
+		}
+	}
+
+	for duration, message := range map[string]string{
+		"":         "empty",
+		"5x3":      `unknown unit "x"`,
+		"7dd":      `unknown unit "dd"`,
+		"7":        "missing unit after 7",
+		"d":        `expected a number at "d"`,
+		"-1d":      `expected a number at "-1d"`,
+		"1.2.3h":   `bad number "1.2.3"`,
+		"1000000y": "too long",
+	} {
+		if _, err := stslgo.ParseRetentionDuration(duration); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("%q: expected an error with %q, got %v", duration, message, err)
+		}
+	}
+}
+
+// Test function for the retention durations of the retention policies
+func TestTimeSeriesDbRetentionPolicyDuration(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	if err = timeserData.CreateRetentionPolicy("rp_month", "1mo", false); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if err = timeserData.UpdateRetentionPolicy("rp_month", "7d", false); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if err = timeserData.CreateRetentionPolicy("rp_bad", "5x3", false); err == nil {
+		t.Errorf("Expected an error for an invalid duration")
+	}
+	expected := []string{
+		"CREATE RETENTION POLICY rp_month ON testdb DURATION 30d REPLICATION 1 SHARD DURATION 30d ",
+		"ALTER RETENTION POLICY rp_month ON testdb DURATION 7d SHARD DURATION 7d ",
+	}
+	if fmt.Sprint(issuedQueries) != fmt.Sprint(expected) {
+		t.Errorf("Unexpected queries %q", issuedQueries)
+	}
+}
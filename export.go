@@ -0,0 +1,128 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// File format of ExportMeasurement()
+type ExportFormat string
+
+const (
+	ExportAvro    ExportFormat = "avro"    // Avro object container file, deflate compressed
+	ExportParquet ExportFormat = "parquet" // Parquet file, uncompressed and plain encoded
+)
+
+// Number of rows per Avro block or Parquet row group
+const exportBlockSize = 10000
+
+// Column of an exported measurement
+type exportColumn struct {
+	name      string
+	fieldType FieldType // Type of the values, FieldInteger for the time in microseconds
+	required  bool      // Never null, only the time
+	tag       bool
+}
+
+// Writes the rows of an export in a file format
+type exportWriter interface {
+	write(row []interface{}) error
+	close() error
+}
+
+// Exports the points of a measurement within [start, stop) to w as an Avro or Parquet file, eg. for the training
+// pipelines of AI/ML rApps. Zero start or stop leaves the range open on that side. The file has a time column in
+// microseconds, a string column per tag and a column per field of its type, null when the point has no value.
+// The points are read page by page and written block by block, so that the whole measurement is not in memory
+func (timeserData *TimeSeriesClientData) ExportMeasurement(measurement string, start, stop time.Time, format ExportFormat, w io.Writer) (err error) {
+	schema, err := timeserData.DescribeMeasurement(measurement)
+	if err != nil {
+		return err
+	}
+	columns := _exportColumns(schema)
+
+	var writer exportWriter
+	switch format {
+	case ExportAvro:
+		writer, err = newAvroWriter(w, measurement, columns)
+	case ExportParquet:
+		writer = newParquetWriter(w, columns)
+	default:
+		err = fmt.Errorf("Unknown export format %v", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	points := 0
+	err = timeserData.QueryEach(_rangeQuery(timeserData.MeasurementName(measurement), start, stop), 0, func(row JsonRow) error {
+		timestamp, err := _toTime(row["time"])
+		if err != nil {
+			return err
+		}
+		values := make([]interface{}, len(columns))
+		values[0] = timestamp.UnixNano() / int64(time.Microsecond)
+		for i, column := range columns[1:] {
+			if value := row[column.name]; value != nil {
+				if column.tag {
+					values[i+1] = fmt.Sprint(value)
+				} else if value, ok := _coerceField(value, column.fieldType); ok {
+					values[i+1] = value
+				}
+			}
+		}
+		points++
+		return writer.write(values)
+	})
+	if err == nil {
+		err = writer.close()
+	}
+	if err != nil {
+		timeserData.logger().Errorf("Failed to export measurement %v with error %v\n", measurement, err)
+		return err
+	}
+	timeserData.logger().Infof("Exported %v points of measurement %v as %v\n", points, measurement, format)
+	return nil
+}
+
+// Returns the time column followed by the tags and the fields in alphabetical order
+func _exportColumns(schema MeasurementSchema) []exportColumn {
+	columns := []exportColumn{{name: "time", fieldType: FieldInteger, required: true}}
+	tags := append([]string{}, schema.TagKeys...)
+	sort.Strings(tags)
+	for _, tag := range tags {
+		columns = append(columns, exportColumn{name: tag, fieldType: FieldString, tag: true})
+	}
+	fields := make([]string, 0, len(schema.Fields))
+	for field := range schema.Fields {
+		if !_contains(tags, field) {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		columns = append(columns, exportColumn{name: field, fieldType: schema.Fields[field]})
+	}
+	return columns
+}
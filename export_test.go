@@ -0,0 +1,170 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"stslgo"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Mocks a measurement with a tag and a field of each type
+func exportResp(q timesrclient.Query) (*timesrclient.Response, error) {
+	switch {
+	case strings.HasPrefix(q.Command, "SHOW TAG KEYS"):
+		return &timesrclient.Response{Results: []timesrclient.Result{
+			{Series: []models.Row{{Name: "CellKpi", Columns: []string{"tagKey"}, Values: [][]interface{}{{"cellId"}}}}},
+			{Series: []models.Row{{Name: "CellKpi", Columns: []string{"fieldKey", "fieldType"},
+				Values: [][]interface{}{{"prb", "integer"}, {"DRB.load", "float"}, {"state", "string"}, {"up", "boolean"}}}}},
+		}}, nil
+	case strings.HasSuffix(q.Command, "OFFSET 0"):
+		return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+			{Name: "CellKpi", Columns: []string{"time", "DRB.load", "cellId", "prb", "state", "up"}, Values: [][]interface{}{
+				{"2021-08-20T05:47:46.000001Z", json.Number("2.5"), "c1", json.Number("3"), "up", true},
+				{"2021-08-20T05:47:47Z", nil, "c2", json.Number("5"), nil, false},
+			}},
+		}}}}, nil
+	}
+	return &timesrclient.Response{Results: []timesrclient.Result{{}}}, nil
+}
+
+// Test function for exporting a measurement as an Avro file
+func TestTimeSeriesDbExportAvro(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	queryResp = exportResp
+
+	var out bytes.Buffer
+	if err = timeserData.ExportMeasurement("CellKpi", time.Time{}, time.Time{}, stslgo.ExportAvro, &out); err != nil {
+		t.Fatalf("Unable to export with error %v", err)
+	}
+	r := bytes.NewReader(out.Bytes())
+	magic := make([]byte, 4)
+	r.Read(magic)
+	if string(magic) != "Obj\x01" {
+		t.Fatalf("Unexpected magic %q", magic)
+	}
+	readLong := func(r *bytes.Reader) int64 {
+		v, _ := binary.ReadVarint(r)
+		return v
+	}
+	readBytes := func(r *bytes.Reader) []byte {
+		b := make([]byte, readLong(r))
+		r.Read(b)
+		return b
+	}
+	meta := map[string]string{}
+	for n := readLong(r); n > 0; n-- {
+		key := readBytes(r)
+		meta[string(key)] = string(readBytes(r))
+	}
+	readLong(r)
+	if meta["avro.codec"] != "deflate" || !strings.Contains(meta["avro.schema"], `"name":"DRB_load","type":["null","double"]`) ||
+		!strings.Contains(meta["avro.schema"], `"logicalType":"timestamp-micros"`) {
+		t.Errorf("Unexpected metadata %v", meta)
+	}
+	sync := make([]byte, 16)
+	r.Read(sync)
+
+	if rows := readLong(r); rows != 2 {
+		t.Fatalf("Expected a block of 2 rows, got %v", rows)
+	}
+	compressed := readBytes(r)
+	block, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		t.Fatalf("Unable to inflate the block with error %v", err)
+	}
+	tail := make([]byte, 16)
+	if r.Read(tail); !bytes.Equal(tail, sync) || r.Len() != 0 {
+		t.Errorf("Expected the sync marker at the end of the file")
+	}
+
+	// time, cellId, DRB_load, prb, state, up, each but the time preceded by its union branch
+	br := bytes.NewReader(block)
+	if micros := readLong(br); micros != time.Date(2021, 8, 20, 5, 47, 46, 1000, time.UTC).UnixNano()/1000 {
+		t.Errorf("Unexpected time %v", micros)
+	}
+	readLong(br)
+	cell := readBytes(br)
+	var load uint64
+	readLong(br)
+	binary.Read(br, binary.LittleEndian, &load)
+	readLong(br)
+	prb := readLong(br)
+	readLong(br)
+	state := readBytes(br)
+	readLong(br)
+	up, _ := br.ReadByte()
+	if math.Float64frombits(load) != 2.5 || string(cell) != "c1" || prb != 3 || string(state) != "up" || up != 1 {
+		t.Errorf("Unexpected first row %v %s %v %s %v", math.Float64frombits(load), cell, prb, state, up)
+	}
+	readLong(br)
+	readLong(br)
+	readBytes(br)
+	if union := readLong(br); union != 0 {
+		t.Errorf("Expected a null load in the second row, got union branch %v", union)
+	}
+}
+
+// Test function for exporting a measurement as a Parquet file
+func TestTimeSeriesDbExportParquet(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	queryResp = exportResp
+
+	var out bytes.Buffer
+	if err = timeserData.ExportMeasurement("CellKpi", time.Time{}, time.Time{}, stslgo.ExportParquet, &out); err != nil {
+		t.Fatalf("Unable to export with error %v", err)
+	}
+	file := out.Bytes()
+	if !bytes.HasPrefix(file, []byte("PAR1")) || !bytes.HasSuffix(file, []byte("PAR1")) {
+		t.Fatalf("Missing Parquet magic in %q", file)
+	}
+	size := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer := file[len(file)-8-size : len(file)-8]
+	for _, name := range []string{"time", "cellId", "DRB.load", "prb", "state", "up"} {
+		if !bytes.Contains(footer, []byte(name)) {
+			t.Errorf("Column %v missing in the footer", name)
+		}
+	}
+	if !bytes.Contains(file[:len(file)-8-size], []byte("c2")) {
+		t.Errorf("Tag values missing in the data")
+	}
+
+	if err = timeserData.ExportMeasurement("CellKpi", time.Time{}, time.Time{}, "csv", &out); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,166 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// http.Handler exposing the latest values of measurements as Prometheus gauges, so that the Prometheus of the
+// platform can scrape KPIs stored in TimeSeriesDB. See NewPrometheusExporter()
+type PrometheusExporter struct {
+	timeserData  *TimeSeriesClientData
+	prefix       string
+	measurements []string
+	window       time.Duration
+}
+
+// Creates a handler exposing, on each scrape, the latest value of each numeric field of each series of the
+// measurements (all measurements when empty) as the gauge <prefix><measurement>_<field> labelled with the tags
+// of the series. Booleans are exposed as 0 or 1, strings are not exposed. Only the values of the last window are
+// considered when window is not 0, which bounds the cost of a scrape
+func (timeserData *TimeSeriesClientData) NewPrometheusExporter(prefix string, measurements []string, window time.Duration) *PrometheusExporter {
+	return &PrometheusExporter{timeserData: timeserData, prefix: prefix, measurements: measurements, window: window}
+}
+
+func (e *PrometheusExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	measurements := e.measurements
+	if len(measurements) == 0 {
+		var err error
+		if measurements, err = e.timeserData.ListMeasurements(); err != nil {
+			_httpError(w, _httpStatus(err), err)
+			return
+		}
+	}
+	if len(measurements) == 0 {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		return
+	}
+
+	statements := make([]string, len(measurements))
+	for i, measurement := range measurements {
+		statements[i] = fmt.Sprintf("SELECT LAST(*) FROM %v", e.timeserData.measurementIdent(measurement))
+		if e.window > 0 {
+			statements[i] += fmt.Sprintf(" WHERE time > now() - %vu", e.window.Nanoseconds()/int64(time.Microsecond))
+		}
+		statements[i] += " GROUP BY *"
+	}
+	q := timesrclient.NewQuery(strings.Join(statements, "; "), e.timeserData.timeSeriesDbName, "")
+	response, err := e.timeserData.query(q)
+	if err != nil {
+		e.timeserData.logger().Warnf("TimeSeriesDB Prometheus scrape failed: %v\n", err)
+		_httpError(w, _httpStatus(err), err)
+		return
+	}
+
+	// Samples per metric, a metric being declared once
+	samples := make(map[string][]string)
+	for i, result := range response.Results {
+		if i >= len(measurements) {
+			break
+		}
+		for _, series := range result.Series {
+			labels := _promLabels(series.Tags)
+			for _, value := range series.Values {
+				for j, column := range series.Columns {
+					if j >= len(value) || !strings.HasPrefix(column, "last_") {
+						continue
+					}
+					number, ok := _promValue(value[j])
+					if !ok {
+						continue
+					}
+					name := _promName(e.prefix + measurements[i] + "_" + strings.TrimPrefix(column, "last_"))
+					samples[name] = append(samples[name], name+labels+" "+number)
+				}
+			}
+		}
+	}
+	names := make([]string, 0, len(samples))
+	for name := range samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	for _, name := range names {
+		fmt.Fprintf(w, "# TYPE %v gauge\n", name)
+		sort.Strings(samples[name])
+		for _, sample := range samples[name] {
+			fmt.Fprintf(w, "%v\n", sample)
+		}
+	}
+}
+
+// Returns a value in the Prometheus text format, false for the values which are not numbers
+func _promValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return "1", true
+		}
+		return "0", true
+	case string, nil:
+		return "", false
+	}
+	f, err := _toFloat64(value)
+	if err != nil {
+		return "", false
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64), true
+}
+
+// Returns the labels of the tags which have a value, empty when none, with the names sanitized and the values escaped
+func _promLabels(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key, value := range tags {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	labels := make([]string, len(keys))
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	for i, key := range keys {
+		labels[i] = strings.Replace(_promName(key), ":", "_", -1) + `="` + escaper.Replace(tags[key]) + `"`
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(labels, ",") + "}"
+}
+
+// Returns a valid Prometheus metric name, [a-zA-Z_:][a-zA-Z0-9_:]*, the other characters replaced by _
+func _promName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !(c == '_' || c == ':' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,81 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for exposing the latest values to Prometheus
+func TestTimeSeriesDbPrometheusExporter(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{
+			{Series: []models.Row{
+				{Name: "CellKpi", Tags: map[string]string{"cell.id": "c1", "site": `a"b`}, Columns: []string{"time", "last_prb", "last_state", "last_up"},
+					Values: [][]interface{}{{"1970-01-01T00:00:00Z", json.Number("40"), "active", true}}},
+				{Name: "CellKpi", Tags: map[string]string{"cell.id": "c2", "site": ""}, Columns: []string{"time", "last_prb", "last_state", "last_up"},
+					Values: [][]interface{}{{"1970-01-01T00:00:00Z", json.Number("2.5"), nil, nil}}},
+			}},
+			{Series: []models.Row{
+				{Name: "UeKpi", Columns: []string{"time", "last_DRB.UEThpDl"}, Values: [][]interface{}{{"1970-01-01T00:00:00Z", json.Number("12")}}},
+			}},
+		}}, nil
+	}
+
+	exporter := timeserData.NewPrometheusExporter("ric_", []string{"CellKpi", "UeKpi"}, 5*time.Minute)
+	recorder := httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	expected := `# TYPE ric_CellKpi_prb gauge
+ric_CellKpi_prb{cell_id="c1",site="a\"b"} 40
+ric_CellKpi_prb{cell_id="c2"} 2.5
+# TYPE ric_CellKpi_up gauge
+ric_CellKpi_up{cell_id="c1",site="a\"b"} 1
+# TYPE ric_UeKpi_DRB_UEThpDl gauge
+ric_UeKpi_DRB_UEThpDl 12
+`
+	if recorder.Code != http.StatusOK || recorder.Body.String() != expected {
+		t.Errorf("Unexpected scrape %v:\n%v", recorder.Code, recorder.Body.String())
+	}
+	if len(issuedQueries) != 1 || !strings.Contains(issuedQueries[0], `SELECT LAST(*) FROM "UeKpi" WHERE time > now() - 300000000u GROUP BY *`) {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return nil, fmt.Errorf("timeout")
+	}
+	recorder = httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusBadGateway {
+		t.Errorf("Expected 502 when the query fails, got %v", recorder.Code)
+	}
+}
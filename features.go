@@ -0,0 +1,162 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Statistical features of the values of a field of a series in a window, see ExtractFeatures()
+type Features struct {
+	Start time.Time
+	Tags  map[string]string // Tags of the series
+	Field string
+	Count int
+	Mean  float64
+	Std   float64 // Sample standard deviation, as STDDEV()
+	Min   float64
+	Max   float64
+	P95   float64 // Nearest rank, as PERCENTILE()
+	Slope float64 // Increase per second fitted by least squares
+}
+
+// Returns the features of the fields of each series in the windows of [start, stop), aligned on start, eg. as
+// input of an ML model. The values are fetched and summarized by the client, ordered by series, window then as
+// fields. Windows without values of a field are skipped
+func (timeserData *TimeSeriesClientData) ExtractFeatures(measurement string, fields []string, window time.Duration, start, stop time.Time) ([]Features, error) {
+	if len(fields) == 0 || window <= 0 || !stop.After(start) {
+		return nil, errors.New("ExtractFeatures needs at least one field, a window > 0 and start before stop")
+	}
+	quoted := make([]string, len(fields))
+	for i, field := range fields {
+		quoted[i] = _quoteIdent(field)
+	}
+	queryStr := fmt.Sprintf("SELECT %v FROM %v WHERE %v GROUP BY *", strings.Join(quoted, ", "),
+		timeserData.measurementIdent(measurement), _whereClause("", start, stop))
+	response, err := timeserData.query(timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, ""))
+	if err != nil {
+		timeserData.logger().Errorf("Failed to extract the features of %v with error %v\n", measurement, err)
+		return nil, err
+	}
+
+	features := []Features{}
+	values := 0
+	for _, result := range response.Results {
+		for _, row := range result.Series {
+			seriesFeatures, err := _seriesFeatures(row, fields, window, start)
+			if err != nil {
+				return nil, err
+			}
+			features = append(features, seriesFeatures...)
+			values += len(row.Values)
+		}
+	}
+	timeserData.logger().Debugf("TimeSeriesDB ExtractFeatures: DB=%v Measurement=%v fields=%v, values=%v, features=%v\n", timeserData.timeSeriesDbName, measurement, fields, values, len(features))
+	return features, nil
+}
+
+// Computes the features of the fields of a series per window
+func _seriesFeatures(row models.Row, fields []string, window time.Duration, start time.Time) ([]Features, error) {
+	columns := make(map[string]int)
+	for i, column := range row.Columns {
+		columns[column] = i
+	}
+	// Values of each field per window
+	windows := make(map[int64]map[string][]Sample)
+	for _, value := range row.Values {
+		t, err := _toTime(value[0])
+		if err != nil {
+			return nil, err
+		}
+		index := int64(t.Sub(start) / window)
+		for _, field := range fields {
+			i, ok := columns[field]
+			if !ok || value[i] == nil {
+				continue
+			}
+			v, err := _toFloat64(value[i])
+			if err != nil {
+				return nil, err
+			}
+			if windows[index] == nil {
+				windows[index] = make(map[string][]Sample)
+			}
+			windows[index][field] = append(windows[index][field], Sample{Time: t, Value: v})
+		}
+	}
+	indexes := make([]int64, 0, len(windows))
+	for index := range windows {
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+	features := []Features{}
+	for _, index := range indexes {
+		for _, field := range fields {
+			if samples := windows[index][field]; len(samples) > 0 {
+				f := _features(samples)
+				f.Start, f.Tags, f.Field = start.Add(time.Duration(index)*window), row.Tags, field
+				features = append(features, f)
+			}
+		}
+	}
+	return features, nil
+}
+
+// Computes the features of the samples of a window
+func _features(samples []Sample) Features {
+	n := float64(len(samples))
+	f := Features{Count: len(samples), Min: math.Inf(1), Max: math.Inf(-1)}
+	values := make([]float64, len(samples))
+	var sum, sumT, sumTT, sumTV float64
+	for i, sample := range samples {
+		values[i] = sample.Value
+		sum += sample.Value
+		f.Min = math.Min(f.Min, sample.Value)
+		f.Max = math.Max(f.Max, sample.Value)
+		// Seconds since the first sample, for precision
+		t := sample.Time.Sub(samples[0].Time).Seconds()
+		sumT += t
+		sumTT += t * t
+		sumTV += t * sample.Value
+	}
+	f.Mean = sum / n
+	if len(samples) > 1 {
+		var squares float64
+		for _, v := range values {
+			squares += (v - f.Mean) * (v - f.Mean)
+		}
+		f.Std = math.Sqrt(squares / (n - 1))
+	}
+	if denominator := n*sumTT - sumT*sumT; denominator != 0 {
+		f.Slope = (n*sumTV - sumT*sum) / denominator
+	}
+	sort.Float64s(values)
+	f.P95 = values[int(math.Ceil(0.95*n))-1]
+	return f
+}
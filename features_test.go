@@ -0,0 +1,82 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for extracting the features of fields per window
+func TestTimeSeriesDbExtractFeatures(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+			{Name: "CellKpi", Tags: map[string]string{"cellId": "c1"}, Columns: []string{"time", "prb", "ue"}, Values: [][]interface{}{
+				{"2024-01-01T00:00:00Z", json.Number("10"), json.Number("1")},
+				{"2024-01-01T00:00:30Z", json.Number("20"), nil},
+				{"2024-01-01T00:01:00Z", json.Number("5"), nil},
+				{"2024-01-01T00:01:10Z", json.Number("5"), nil},
+			}},
+			{Name: "CellKpi", Tags: map[string]string{"cellId": "c2"}, Columns: []string{"time", "prb", "ue"}, Values: [][]interface{}{
+				{"2024-01-01T00:00:15Z", json.Number("100"), nil},
+			}},
+		}}}}, nil
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	features, err := timeserData.ExtractFeatures("CellKpi", []string{"prb", "ue"}, time.Minute, start, start.Add(2*time.Minute))
+	if err != nil {
+		t.Fatalf("ExtractFeatures failed: %v", err)
+	}
+	expected := `SELECT "prb", "ue" FROM "CellKpi" WHERE time >= '2024-01-01T00:00:00Z' AND time < '2024-01-01T00:02:00Z' GROUP BY *`
+	if len(issuedQueries) != 1 || issuedQueries[0] != expected {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+	if len(features) != 4 {
+		t.Fatalf("Expected 4 features, got %v", features)
+	}
+	if f := features[0]; f.Field != "prb" || f.Tags["cellId"] != "c1" || !f.Start.Equal(start) || f.Count != 2 || f.Mean != 15 || math.Abs(f.Std-math.Sqrt(50)) > 1e-9 ||
+		f.Min != 10 || f.Max != 20 || f.P95 != 20 || math.Abs(f.Slope-1.0/3) > 1e-9 {
+		t.Errorf("Unexpected features %+v", f)
+	}
+	if f := features[1]; f.Field != "ue" || f.Count != 1 || f.Mean != 1 || f.Std != 0 || f.P95 != 1 || f.Slope != 0 {
+		t.Errorf("Unexpected features %+v", f)
+	}
+	if f := features[2]; f.Field != "prb" || !f.Start.Equal(start.Add(time.Minute)) || f.Mean != 5 || f.Slope != 0 {
+		t.Errorf("Unexpected features %+v", f)
+	}
+	if f := features[3]; f.Field != "prb" || f.Tags["cellId"] != "c2" || !f.Start.Equal(start) || f.Count != 1 || f.Mean != 100 {
+		t.Errorf("Unexpected features of the second series %+v", f)
+	}
+
+	if _, err = timeserData.ExtractFeatures("CellKpi", nil, time.Minute, start, start.Add(time.Minute)); err == nil {
+		t.Errorf("Expected an error without fields")
+	}
+}
@@ -0,0 +1,73 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"fmt"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Number of horizons, and at least of seasons, of history the forecast is fitted on
+const forecastHistory = 10
+
+// Predicts the mean of a field in the windows of every over the next horizon with the Holt-Winters method of
+// TimeSeriesDB, fitted on the means of the last 10 horizons, or 10 seasons if longer. seasonality is the number
+// of windows of a season, eg. 24 hourly windows for a daily pattern of the traffic, 0 for none
+func (timeserData *TimeSeriesClientData) Forecast(measurement, field string, horizon, every time.Duration, seasonality int) (forecast []Sample, err error) {
+	if horizon <= 0 || every <= 0 || seasonality < 0 {
+		return nil, fmt.Errorf("Invalid forecast of %v over %v in windows of %v with seasonality %v", measurement, horizon, every, seasonality)
+	}
+	n := int((horizon + every - 1) / every)
+	history := forecastHistory * n
+	if history < forecastHistory*seasonality {
+		history = forecastHistory * seasonality
+	}
+	queryStr := fmt.Sprintf("SELECT HOLT_WINTERS(MEAN(%v), %v, %v) FROM %v WHERE time >= now() - %v GROUP BY time(%v)", _quoteIdent(field), n, seasonality,
+		timeserData.measurementIdent(measurement), _durationLiteral(time.Duration(history)*every), _durationLiteral(every))
+	response, err := timeserData.query(timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, ""))
+	if err != nil {
+		timeserData.logger().Errorf("Failed to forecast %v with error %v\n", measurement, err)
+		return nil, err
+	}
+
+	forecast = []Sample{}
+	for _, result := range response.Results {
+		for _, row := range result.Series {
+			for _, value := range row.Values {
+				// Columns are time and the prediction
+				if len(value) < 2 || value[1] == nil {
+					continue
+				}
+				var sample Sample
+				if sample.Time, err = _toTime(value[0]); err != nil {
+					return nil, err
+				}
+				if sample.Value, err = _toFloat64(value[1]); err != nil {
+					return nil, err
+				}
+				forecast = append(forecast, sample)
+			}
+		}
+	}
+	timeserData.logger().Debugf("TimeSeriesDB Forecast: DB=%v, QueryString=%v, points=%v\n", timeserData.timeSeriesDbName, queryStr, len(forecast))
+	return forecast, nil
+}
@@ -0,0 +1,57 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for forecasting a field with Holt-Winters
+func TestTimeSeriesDbForecast(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return seriesResp("CellKpi", []string{"time", "holt_winters"},
+			[]interface{}{"2024-01-01T01:00:00Z", json.Number("42.5")}, []interface{}{"2024-01-01T02:00:00Z", json.Number("43")}), nil
+	}
+
+	forecast, err := timeserData.Forecast("CellKpi", "prb", 90*time.Minute, time.Hour, 0)
+	if err != nil || len(forecast) != 2 || forecast[0].Value != 42.5 || forecast[1].Time.Hour() != 2 {
+		t.Errorf("Unexpected forecast %v, error %v", forecast, err)
+	}
+	_, _ = timeserData.Forecast("CellKpi", "prb", time.Hour, time.Hour, 24)
+	expected := []string{
+		`SELECT HOLT_WINTERS(MEAN("prb"), 2, 0) FROM "CellKpi" WHERE time >= now() - 20h GROUP BY time(1h)`,
+		`SELECT HOLT_WINTERS(MEAN("prb"), 1, 24) FROM "CellKpi" WHERE time >= now() - 10d GROUP BY time(1h)`,
+	}
+	if fmt.Sprint(issuedQueries) != fmt.Sprint(expected) {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+	if _, err = timeserData.Forecast("CellKpi", "prb", time.Hour, 0, 0); err == nil {
+		t.Errorf("Expected an error without windows")
+	}
+}
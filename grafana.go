@@ -0,0 +1,401 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Number of points of a Grafana panel when the request tells neither the interval nor the maximum
+const grafanaDefaultPoints = 1000
+
+// http.Handler implementing the Grafana JSON (SimpleJSON) datasource protocol, so that Grafana panels show the
+// measurements without access to TimeSeriesDB. See NewGrafanaHandler()
+//
+//	GET  /             Test of the datasource
+//	POST /search       Targets <measurement>/<field> of the numeric fields containing the typed text
+//	POST /query        Time series or tables of the targets over the range of the panel
+//	POST /annotations  Points of the measurement of the annotation query, eg. events stored by RecordEvent()
+type GrafanaHandler struct {
+	timeserData *TimeSeriesClientData
+	token       string
+	mux         *http.ServeMux
+}
+
+// Range of a Grafana request
+type grafanaRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Ad hoc filter of a Grafana query, applied to the tags
+type grafanaFilter struct {
+	Key      string `json:"key"`
+	Operator string `json:"operator"` // =, !=, =~ or !~
+	Value    string `json:"value"`
+}
+
+// Body of a POST /query request
+type grafanaQuery struct {
+	Range         grafanaRange `json:"range"`
+	IntervalMs    int64        `json:"intervalMs"`
+	MaxDataPoints int64        `json:"maxDataPoints"`
+	Targets       []struct {
+		Target string `json:"target"`
+		Type   string `json:"type"` // timeserie (default) or table
+		Hide   bool   `json:"hide"`
+	} `json:"targets"`
+	AdhocFilters []grafanaFilter `json:"adhocFilters"`
+}
+
+// Creates a Grafana JSON datasource over the client. Requests must carry "Authorization: Token <token>" (or
+// Bearer), as set by the custom headers of the datasource, an empty token disables the authentication.
+//
+// A target is either <measurement>/<field>, the mean of the field per series over the interval of the panel in
+// the namespace of the client, or a SELECT query where $timeFilter is replaced by the range of the panel and
+// $interval by its interval. The annotation queries name a measurement of the namespace as well
+func (timeserData *TimeSeriesClientData) NewGrafanaHandler(token string) *GrafanaHandler {
+	h := &GrafanaHandler{timeserData: timeserData, token: token, mux: http.NewServeMux()}
+	h.mux.HandleFunc("/", h.test)
+	h.mux.HandleFunc("/search", h.search)
+	h.mux.HandleFunc("/query", h.query)
+	h.mux.HandleFunc("/annotations", h.annotations)
+	return h
+}
+
+func (h *GrafanaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !_httpAuthorized(w, r, h.token) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, httpMaxBodyBytes)
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *GrafanaHandler) test(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if _httpMethod(w, r, http.MethodGet, http.MethodHead) {
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (h *GrafanaHandler) search(w http.ResponseWriter, r *http.Request) {
+	if !_httpMethod(w, r, http.MethodPost) {
+		return
+	}
+	var request struct {
+		Target string `json:"target"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		_httpError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Series per measurement, with the key and type of the fields as columns
+	q := timesrclient.NewQuery("SHOW FIELD KEYS", h.timeserData.timeSeriesDbName, "")
+	response, err := h.timeserData.query(q)
+	if err != nil {
+		h.reply(w, err, nil)
+		return
+	}
+	targets := []string{}
+	for _, result := range response.Results {
+		for _, series := range result.Series {
+			for _, value := range series.Values {
+				if len(value) < 2 || (value[1] != "float" && value[1] != "integer") {
+					continue
+				}
+				target := series.Name + "/" + fmt.Sprint(value[0])
+				if strings.Contains(target, request.Target) {
+					targets = append(targets, target)
+				}
+			}
+		}
+	}
+	sort.Strings(targets)
+	h.reply(w, nil, targets)
+}
+
+func (h *GrafanaHandler) query(w http.ResponseWriter, r *http.Request) {
+	if !_httpMethod(w, r, http.MethodPost) {
+		return
+	}
+	var request grafanaQuery
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		_httpError(w, http.StatusBadRequest, err)
+		return
+	}
+	interval := _grafanaInterval(request)
+	filter, err := _grafanaFilter(request.AdhocFilters)
+	if err != nil {
+		_httpError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	results := []interface{}{}
+	for _, target := range request.Targets {
+		if target.Hide || target.Target == "" {
+			continue
+		}
+		queryStr, err := _grafanaStatement(target.Target, request.Range, interval, filter, h.timeserData.measurementIdent)
+		if err != nil {
+			_httpError(w, http.StatusBadRequest, err)
+			return
+		}
+		response, err := h.timeserData.query(timesrclient.NewQuery(queryStr, h.timeserData.timeSeriesDbName, ""))
+		if err != nil {
+			h.reply(w, err, nil)
+			return
+		}
+		for _, result := range response.Results {
+			for _, series := range result.Series {
+				if target.Type == "table" {
+					results = append(results, _grafanaTable(series))
+				} else {
+					results = append(results, _grafanaSeries(target.Target, series)...)
+				}
+			}
+		}
+	}
+	h.reply(w, nil, results)
+}
+
+func (h *GrafanaHandler) annotations(w http.ResponseWriter, r *http.Request) {
+	if !_httpMethod(w, r, http.MethodPost) {
+		return
+	}
+	var request struct {
+		Range      grafanaRange    `json:"range"`
+		Annotation json.RawMessage `json:"annotation"`
+	}
+	var annotation struct {
+		Query string `json:"query"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err == nil {
+		err = json.Unmarshal(request.Annotation, &annotation)
+	}
+	if err != nil || annotation.Query == "" {
+		_httpError(w, http.StatusBadRequest, errors.New("Body must be JSON with an annotation query naming a measurement"))
+		return
+	}
+
+	queryStr := fmt.Sprintf("SELECT * FROM %v WHERE %v GROUP BY *", h.timeserData.measurementIdent(annotation.Query), _whereClause("", request.Range.From, request.Range.To))
+	response, err := h.timeserData.query(timesrclient.NewQuery(queryStr, h.timeserData.timeSeriesDbName, ""))
+	if err != nil {
+		h.reply(w, err, nil)
+		return
+	}
+	// Title is the name tag of the events, text the fields of the point and tags the other tags of the series
+	annotations := []interface{}{}
+	for _, result := range response.Results {
+		for _, series := range result.Series {
+			title := series.Tags["name"]
+			if title == "" {
+				title = annotation.Query
+			}
+			tags := []string{}
+			for key, value := range series.Tags {
+				if key != "name" && value != "" {
+					tags = append(tags, key+"="+value)
+				}
+			}
+			sort.Strings(tags)
+			for _, value := range series.Values {
+				if len(value) == 0 {
+					continue
+				}
+				timestamp, err := _toTime(value[0])
+				if err != nil {
+					continue
+				}
+				var text []string
+				for j := 1; j < len(series.Columns) && j < len(value); j++ {
+					if value[j] != nil {
+						text = append(text, fmt.Sprintf("%v=%v", series.Columns[j], value[j]))
+					}
+				}
+				annotations = append(annotations, map[string]interface{}{
+					"annotation": request.Annotation,
+					"time":       timestamp.UnixNano() / int64(time.Millisecond),
+					"title":      title,
+					"text":       strings.Join(text, ", "),
+					"tags":       tags,
+				})
+			}
+		}
+	}
+	h.reply(w, nil, annotations)
+}
+
+// Replies the JSON result of a request, or its error with the matching status
+func (h *GrafanaHandler) reply(w http.ResponseWriter, err error, result interface{}) {
+	if err != nil {
+		h.timeserData.logger().Warnf("TimeSeriesDB Grafana request failed: %v\n", err)
+		_httpError(w, _httpStatus(err), err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// Returns the interval of the points of a panel, as requested or to get its maximum number of points
+func _grafanaInterval(request grafanaQuery) time.Duration {
+	interval := time.Duration(request.IntervalMs) * time.Millisecond
+	if interval <= 0 {
+		points := request.MaxDataPoints
+		if points <= 0 {
+			points = grafanaDefaultPoints
+		}
+		interval = request.Range.To.Sub(request.Range.From) / time.Duration(points)
+	}
+	interval = interval.Truncate(time.Millisecond)
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	return interval
+}
+
+// Returns the InfluxQL condition of the ad hoc filters, empty when none
+func _grafanaFilter(filters []grafanaFilter) (string, error) {
+	conditions := make([]string, len(filters))
+	for i, filter := range filters {
+		switch filter.Operator {
+		case "=", "!=":
+			conditions[i] = fmt.Sprintf("%v %v %v", _quoteIdent(filter.Key), filter.Operator, _quoteLiteral(filter.Value))
+		case "=~", "!~":
+			conditions[i] = fmt.Sprintf("%v %v /%v/", _quoteIdent(filter.Key), filter.Operator, strings.Replace(filter.Value, "/", `\/`, -1))
+		default:
+			return "", fmt.Errorf("Unsupported ad hoc filter operator %v", filter.Operator)
+		}
+	}
+	return strings.Join(conditions, " AND "), nil
+}
+
+// Returns the query of a target, failing for the queries which are not read only
+func _grafanaStatement(target string, timeRange grafanaRange, interval time.Duration, filter string, measurementIdent func(string) string) (string, error) {
+	timeFilter := _whereClause(filter, timeRange.From, timeRange.To)
+	if _statementOperation(target) == "SELECT" {
+		if !_readOnly(target) {
+			return "", fmt.Errorf("Target %v is not a read only query", target)
+		}
+		replacer := strings.NewReplacer("$timeFilter", timeFilter, "$__interval", _durationLiteral(interval), "$interval", _durationLiteral(interval))
+		return replacer.Replace(target), nil
+	}
+	separator := strings.Index(target, "/")
+	if separator <= 0 || separator == len(target)-1 {
+		return "", fmt.Errorf("Target %v is neither <measurement>/<field> nor a SELECT query", target)
+	}
+	return fmt.Sprintf("SELECT MEAN(%v) FROM %v WHERE %v GROUP BY time(%v), * fill(none)", _quoteIdent(target[separator+1:]),
+		measurementIdent(target[:separator]), timeFilter, _durationLiteral(interval)), nil
+}
+
+// Returns the time series of the numeric columns of a series, named after the target (or the column for the
+// queries selecting several) and the tags of the series
+func _grafanaSeries(target string, series models.Row) []interface{} {
+	var tags []string
+	for key, value := range series.Tags {
+		if value != "" {
+			tags = append(tags, key+"="+value)
+		}
+	}
+	sort.Strings(tags)
+	var timeseries []interface{}
+	for j := 1; j < len(series.Columns); j++ {
+		name := target
+		if len(series.Columns) > 2 {
+			name = series.Name + "." + series.Columns[j]
+		}
+		if len(tags) > 0 {
+			name += " {" + strings.Join(tags, ", ") + "}"
+		}
+		datapoints := [][2]float64{}
+		for _, value := range series.Values {
+			if j >= len(value) {
+				continue
+			}
+			timestamp, err := _toTime(value[0])
+			if err != nil {
+				continue
+			}
+			if _, ok := value[j].(string); ok || value[j] == nil {
+				continue
+			}
+			number, err := _toFloat64(value[j])
+			if err != nil {
+				continue
+			}
+			datapoints = append(datapoints, [2]float64{number, float64(timestamp.UnixNano() / int64(time.Millisecond))})
+		}
+		timeseries = append(timeseries, map[string]interface{}{"target": name, "datapoints": datapoints})
+	}
+	return timeseries
+}
+
+// Returns a series as table, the tags of the series being columns as well
+func _grafanaTable(series models.Row) interface{} {
+	tagKeys := make([]string, 0, len(series.Tags))
+	for key := range series.Tags {
+		tagKeys = append(tagKeys, key)
+	}
+	sort.Strings(tagKeys)
+
+	columns := []map[string]string{{"text": "Time", "type": "time"}}
+	for _, key := range tagKeys {
+		columns = append(columns, map[string]string{"text": key, "type": "string"})
+	}
+	for j := 1; j < len(series.Columns); j++ {
+		columns = append(columns, map[string]string{"text": series.Columns[j]})
+	}
+	rows := make([][]interface{}, 0, len(series.Values))
+	for _, value := range series.Values {
+		if len(value) == 0 {
+			continue
+		}
+		timestamp, err := _toTime(value[0])
+		if err != nil {
+			continue
+		}
+		row := []interface{}{timestamp.UnixNano() / int64(time.Millisecond)}
+		for _, key := range tagKeys {
+			row = append(row, series.Tags[key])
+		}
+		for j := 1; j < len(series.Columns); j++ {
+			if j < len(value) {
+				row = append(row, value[j])
+			} else {
+				row = append(row, nil)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return map[string]interface{}{"type": "table", "columns": columns, "rows": rows}
+}
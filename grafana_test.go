@@ -0,0 +1,112 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for the Grafana JSON datasource
+func TestTimeSeriesDbGrafanaHandler(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	handler := timeserData.NewGrafanaHandler("secret")
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %v", w.Code)
+	}
+	if w = request(http.MethodGet, "/", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected 200 for the test of the datasource, got %v", w.Code)
+	}
+
+	// Search lists the numeric fields
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+			{Name: "CellKpi", Columns: []string{"fieldKey", "fieldType"}, Values: [][]interface{}{{"prb", "float"}, {"state", "string"}, {"ues", "integer"}}},
+			{Name: "UeKpi", Columns: []string{"fieldKey", "fieldType"}, Values: [][]interface{}{{"DRB.UEThpDl", "float"}}},
+		}}}}, nil
+	}
+	w = request(http.MethodPost, "/search", `{"target": "Cell"}`)
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `["CellKpi/prb","CellKpi/ues"]` {
+		t.Errorf("Unexpected search reply %v %v", w.Code, w.Body.String())
+	}
+
+	// Query of a field and a raw query as table
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+			{Name: "CellKpi", Tags: map[string]string{"cell": "c1"}, Columns: []string{"time", "mean"},
+				Values: [][]interface{}{{"2024-01-01T00:00:00Z", json.Number("40")}, {"2024-01-01T00:01:00Z", json.Number("42.5")}}},
+		}}}}, nil
+	}
+	w = request(http.MethodPost, "/query", `{"range": {"from": "2024-01-01T00:00:00Z", "to": "2024-01-01T01:00:00Z"}, "intervalMs": 60000,
+		"targets": [{"target": "CellKpi/prb", "refId": "A"}, {"target": "SELECT mean(prb) FROM CellKpi WHERE $timeFilter GROUP BY time($interval), cell", "type": "table"}],
+		"adhocFilters": [{"key": "cell", "operator": "=", "value": "c1"}]}`)
+	expected := `[{"datapoints":[[40,1704067200000],[42.5,1704067260000]],"target":"CellKpi/prb {cell=c1}"},` +
+		`{"columns":[{"text":"Time","type":"time"},{"text":"cell","type":"string"},{"text":"mean"}],"rows":[[1704067200000,"c1",40],[1704067260000,"c1",42.5]],"type":"table"}]`
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != expected {
+		t.Errorf("Unexpected query reply %v %v", w.Code, w.Body.String())
+	}
+	timeFilter := `("cell" = 'c1') AND time >= '2024-01-01T00:00:00Z' AND time < '2024-01-01T01:00:00Z'`
+	if len(issuedQueries) < 3 ||
+		issuedQueries[1] != `SELECT MEAN("prb") FROM "CellKpi" WHERE `+timeFilter+` GROUP BY time(1m), * fill(none)` ||
+		issuedQueries[2] != `SELECT mean(prb) FROM CellKpi WHERE `+timeFilter+` GROUP BY time(1m), cell` {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+	w = request(http.MethodPost, "/query", `{"targets": [{"target": "SELECT mean(prb) INTO CellKpi_1m FROM CellKpi"}]}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a query writing into a measurement, got %v", w.Code)
+	}
+
+	// Annotations of recorded events
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+			{Name: "Alarms", Tags: map[string]string{"name": "link-down", "cell": "c1"}, Columns: []string{"time", "active"},
+				Values: [][]interface{}{{"2024-01-01T00:00:00Z", true}}},
+		}}}}, nil
+	}
+	w = request(http.MethodPost, "/annotations", `{"range": {"from": "2024-01-01T00:00:00Z", "to": "2024-01-01T01:00:00Z"}, "annotation": {"name": "alarms", "query": "Alarms"}}`)
+	expected = `[{"annotation":{"name":"alarms","query":"Alarms"},"tags":["cell=c1"],"text":"active=true","time":1704067200000,"title":"link-down"}]`
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != expected {
+		t.Errorf("Unexpected annotations reply %v %v", w.Code, w.Body.String())
+	}
+	if issuedQueries[len(issuedQueries)-1] != `SELECT * FROM "Alarms" WHERE time >= '2024-01-01T00:00:00Z' AND time < '2024-01-01T01:00:00Z' GROUP BY *` {
+		t.Errorf("Unexpected annotation query %v", issuedQueries[len(issuedQueries)-1])
+	}
+}
@@ -0,0 +1,139 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Field names used for the histogram points
+const (
+	histogramBucketPrefix = "le_"
+	histogramCountField   = "count"
+	histogramSumField     = "sum"
+)
+
+// Cumulative bucket counters of one histogram series
+type histogram struct {
+	bounds []float64
+	counts []int64 // counts[i] is the number of values <= bounds[i], last one is for +Inf
+	count  int64
+	sum    float64
+}
+
+// Records a value in a histogram with the given upper bounds (ascending). The bucket counters are kept in memory
+// per measurement and tags, and written as a point with one cumulative counter field per bound named
+// le_<bound> (le_+Inf for all values) along with count and sum fields
+func (timeserData *TimeSeriesClientData) RecordHistogram(measurement string, value float64, bounds []float64, tags map[string]string) (err error) {
+	for i := 1; i < len(bounds); i++ {
+		if bounds[i] <= bounds[i-1] {
+			return errors.New("Histogram bounds must be in ascending order")
+		}
+	}
+	key := _seriesKey(measurement, tags)
+
+	timeserData.histogramLock.Lock()
+	if timeserData.histograms == nil {
+		timeserData.histograms = make(map[string]*histogram)
+	}
+	h, ok := timeserData.histograms[key]
+	if !ok {
+		h = &histogram{bounds: append([]float64{}, bounds...), counts: make([]int64, len(bounds)+1)}
+		timeserData.histograms[key] = h
+	} else if !_sameBounds(h.bounds, bounds) {
+		timeserData.histogramLock.Unlock()
+		return fmt.Errorf("Histogram %v already recorded with bounds %v", key, h.bounds)
+	}
+
+	for i, bound := range h.bounds {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.counts[len(h.bounds)]++
+	h.count++
+	h.sum += value
+
+	fields := map[string]interface{}{
+		histogramCountField: h.count,
+		histogramSumField:   h.sum,
+	}
+	for i, bound := range h.bounds {
+		fields[histogramBucketPrefix+strconv.FormatFloat(bound, 'g', -1, 64)] = h.counts[i]
+	}
+	fields[histogramBucketPrefix+"+Inf"] = h.counts[len(h.bounds)]
+	// Timestamped under the lock, so that the latest point holds the latest counters whatever the write order
+	t := time.Now()
+	timeserData.histogramLock.Unlock()
+
+	return timeserData.WritePointAt(measurement, tags, fields, t)
+}
+
+// Reads back the latest bucket counters of a histogram, keyed by bucket field name (eg. le_0.5, le_+Inf)
+func (timeserData *TimeSeriesClientData) QueryHistogram(measurement string, tags map[string]string) (buckets map[string]int64, err error) {
+	queryStr := fmt.Sprintf("SELECT * FROM %v", timeserData.measurementIdent(measurement))
+	if condition := _tagCondition(tags); condition != "" {
+		queryStr += " WHERE " + condition
+	}
+	queryStr += " ORDER BY time DESC LIMIT 1"
+
+	q := timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, "")
+	response, err := timeserData.query(q)
+	if err != nil {
+		timeserData.logger().Errorf("Failed to query histogram %v with error %v\n", measurement, err)
+		return nil, err
+	}
+
+	buckets = make(map[string]int64)
+	for _, result := range response.Results {
+		for _, row := range result.Series {
+			for _, value := range row.Values {
+				for i, column := range row.Columns {
+					if !strings.HasPrefix(column, histogramBucketPrefix) || i >= len(value) {
+						continue
+					}
+					if n, ok := _toInt64(value[i]); ok {
+						buckets[column] = n
+					}
+				}
+			}
+		}
+	}
+	timeserData.logger().Debugf("TimeSeriesDB QueryHistogram: DB=%v Measurement=%v tags=%v, buckets=%v\n", timeserData.timeSeriesDbName, measurement, tags, buckets)
+	return buckets, nil
+}
+
+func _sameBounds(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for recording values into histogram buckets and reading them back
+func TestTimeSeriesDbHistogram(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	bounds := []float64{0.1, 1, 10}
+	tags := map[string]string{"cellId": "1"}
+	for _, v := range []float64{0.05, 0.5, 0.7, 5, 50} {
+		if err = timeserData.RecordHistogram("LatencyTable", v, bounds, tags); err != nil {
+			t.Fatalf("Unable to record histogram value with error %v", err)
+		}
+	}
+	if len(writtenPoints) != 5 {
+		t.Fatalf("Expected 5 points, got %v", len(writtenPoints))
+	}
+
+	// Serve the last written point back as the query result
+	last := writtenPoints[len(writtenPoints)-1]
+	fields, _ := last.Fields()
+	columns := []string{"time"}
+	for k := range fields {
+		columns = append(columns, k)
+	}
+	sort.Strings(columns[1:])
+	row := []interface{}{last.Time().Format("2006-01-02T15:04:05.999999999Z07:00")}
+	for _, c := range columns[1:] {
+		row = append(row, fields[c])
+	}
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		resp := timesrclient.Response{}
+		result := timesrclient.Result{}
+		result.Series = append(result.Series, models.Row{Name: "LatencyTable", Columns: columns, Values: [][]interface{}{row}})
+		resp.Results = append(resp.Results, result)
+		return &resp, nil
+	}
+
+	buckets, err := timeserData.QueryHistogram("LatencyTable", tags)
+	if err != nil {
+		t.Fatalf("Unable to query histogram with error %v", err)
+	}
+	expected := map[string]int64{"le_0.1": 1, "le_1": 3, "le_10": 4, "le_+Inf": 5}
+	if len(buckets) != len(expected) {
+		t.Errorf("Expected buckets %v, got %v", expected, buckets)
+	}
+	for k, v := range expected {
+		if buckets[k] != v {
+			t.Errorf("Bucket %v expected %v, got %v", k, v, buckets[k])
+		}
+	}
+
+	if err = timeserData.RecordHistogram("LatencyTable", 1, []float64{1, 2}, tags); err == nil {
+		t.Errorf("Expected error when recording with different bounds")
+	}
+}
+
+// Test function for recording histograms while a write is in progress
+func TestTimeSeriesDbHistogramConcurrentWrite(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	client := &heldWriteClient{started: make(chan struct{}, 2), release: make(chan struct{})}
+	timeserData.Iclient = client
+	done := make(chan error, 2)
+	for _, cell := range []string{"c1", "c2"} {
+		go func(cell string) {
+			done <- timeserData.RecordHistogram("LatencyHist", 3, []float64{1, 5}, map[string]string{"cellId": cell})
+		}(cell)
+	}
+
+	// Both writes are in progress at once, the second one does not wait for the first
+	for i := 0; i < 2; i++ {
+		select {
+		case <-client.started:
+		case <-time.After(time.Second):
+			t.Fatalf("Histogram write waiting for another one")
+		}
+	}
+	close(client.release)
+	for i := 0; i < 2; i++ {
+		if err = <-done; err != nil {
+			t.Errorf("Unable to record with error %v", err)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Maximum size of the body of a request to the HTTPHandler
+const httpMaxBodyBytes = 32 << 20
+
+// HTTP facade of a client, for xApps and dashboards without a Go client:
+//
+//	POST   /write                    JSON {"points": [{"measurement", "tags", "fields", "time"}]}, or line protocol with Content-Type text/plain
+//	POST   /query                    JSON {"query": "..."}, replies {"rows": [...]}
+//	POST   /db                       Creates the DB of the client
+//	DELETE /db                       Deletes the DB of the client
+//	POST   /retention-policies       JSON {"name", "duration", "default"}
+//	DELETE /retention-policies/NAME  Deletes a retention policy
+//	POST   /api/v1/prom/write        Prometheus remote write request, see WritePrometheus()
+//
+// Errors are replied as JSON {"error": "..."}
+type HTTPHandler struct {
+	timeserData *TimeSeriesClientData
+	token       string
+	mux         *http.ServeMux
+}
+
+// Point of a POST /write request
+type httpPoint struct {
+	Measurement string                 `json:"measurement"`
+	Tags        map[string]string      `json:"tags"`
+	Fields      map[string]interface{} `json:"fields"`
+	Time        string                 `json:"time"` // RFC3339, current time when empty
+}
+
+// Creates the HTTP facade of the client. Requests must carry "Authorization: Token <token>" (or Bearer),
+// an empty token disables the authentication
+func (timeserData *TimeSeriesClientData) NewHTTPHandler(token string) *HTTPHandler {
+	h := &HTTPHandler{timeserData: timeserData, token: token, mux: http.NewServeMux()}
+	h.mux.HandleFunc("/write", h.write)
+	h.mux.HandleFunc("/query", h.query)
+	h.mux.HandleFunc("/db", h.db)
+	h.mux.HandleFunc("/retention-policies", h.retentionPolicies)
+	h.mux.HandleFunc("/retention-policies/", h.retentionPolicies)
+	h.mux.HandleFunc("/api/v1/prom/write", h.promWrite)
+	return h
+}
+
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !_httpAuthorized(w, r, h.token) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, httpMaxBodyBytes)
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *HTTPHandler) write(w http.ResponseWriter, r *http.Request) {
+	if !_httpMethod(w, r, http.MethodPost) {
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		_httpError(w, http.StatusBadRequest, err)
+		return
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "text/plain") {
+		h.reply(w, h.timeserData.WriteLineProtocol(string(body)), http.StatusNoContent, nil)
+		return
+	}
+
+	var request struct {
+		Points []httpPoint `json:"points"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err = decoder.Decode(&request); err != nil {
+		_httpError(w, http.StatusBadRequest, err)
+		return
+	}
+	points := make([]Point, len(request.Points))
+	for i, p := range request.Points {
+		points[i] = Point{Measurement: p.Measurement, Tags: p.Tags, Fields: make(map[string]interface{}, len(p.Fields))}
+		if p.Measurement == "" {
+			_httpError(w, http.StatusBadRequest, errors.New("Point without measurement"))
+			return
+		}
+		for key, value := range p.Fields {
+			if field, ok := _jsonField(value); ok {
+				points[i].Fields[key] = field
+			}
+		}
+		if p.Time != "" {
+			if points[i].Time, err = time.Parse(time.RFC3339Nano, p.Time); err != nil {
+				_httpError(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+	}
+	h.reply(w, h.timeserData.WritePointsMixed(points), http.StatusNoContent, nil)
+}
+
+func (h *HTTPHandler) promWrite(w http.ResponseWriter, r *http.Request) {
+	if !_httpMethod(w, r, http.MethodPost) {
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, promMaxBodyBytes))
+	if err != nil {
+		_httpError(w, http.StatusBadRequest, err)
+		return
+	}
+	_, err = h.timeserData.WritePrometheus(body)
+	h.reply(w, err, http.StatusNoContent, nil)
+}
+
+func (h *HTTPHandler) query(w http.ResponseWriter, r *http.Request) {
+	if !_httpMethod(w, r, http.MethodPost) {
+		return
+	}
+	var request struct {
+		Query string `json:"query"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Query == "" {
+		_httpError(w, http.StatusBadRequest, errors.New("Body must be JSON with a query"))
+		return
+	}
+	rows, err := h.timeserData.QueryRows(request.Query)
+	h.reply(w, err, http.StatusOK, map[string]interface{}{"rows": rows})
+}
+
+func (h *HTTPHandler) db(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.reply(w, h.timeserData.CreateTimeSeriesDB(), http.StatusNoContent, nil)
+	case http.MethodDelete:
+		h.reply(w, h.timeserData.DeleteTimeSeriesDB(), http.StatusNoContent, nil)
+	default:
+		_httpMethod(w, r, http.MethodPost, http.MethodDelete)
+	}
+}
+
+func (h *HTTPHandler) retentionPolicies(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/retention-policies"), "/")
+	switch {
+	case r.Method == http.MethodPost && name == "":
+		var request struct {
+			Name     string `json:"name"`
+			Duration string `json:"duration"`
+			Default  bool   `json:"default"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Name == "" || request.Duration == "" {
+			_httpError(w, http.StatusBadRequest, errors.New("Body must be JSON with a name and duration"))
+			return
+		}
+		h.reply(w, h.timeserData.CreateRetentionPolicy(request.Name, request.Duration, request.Default), http.StatusNoContent, nil)
+	case r.Method == http.MethodDelete && name != "":
+		h.reply(w, h.timeserData.DeleteRetentionPolicy(name), http.StatusNoContent, nil)
+	case name == "":
+		_httpMethod(w, r, http.MethodPost)
+	default:
+		_httpMethod(w, r, http.MethodDelete)
+	}
+}
+
+// Replies the result of an operation, or its error with the matching status
+func (h *HTTPHandler) reply(w http.ResponseWriter, err error, status int, result interface{}) {
+	if err != nil {
+		h.timeserData.logger().Warnf("TimeSeriesDB HTTP request failed: %v\n", err)
+		_httpError(w, _httpStatus(err), err)
+		return
+	}
+	if result == nil {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+// Replies 401 when the request does not carry "Authorization: Token <token>" (or Bearer), unless token is empty
+func _httpAuthorized(w http.ResponseWriter, r *http.Request, token string) bool {
+	if token == "" {
+		return true
+	}
+	auth := r.Header.Get("Authorization")
+	received := strings.TrimPrefix(strings.TrimPrefix(auth, "Token "), "Bearer ")
+	if received == auth || subtle.ConstantTimeCompare([]byte(received), []byte(token)) != 1 {
+		_httpError(w, http.StatusUnauthorized, errors.New("Invalid or missing token"))
+		return false
+	}
+	return true
+}
+
+// Replies 405 when the method of the request is not one of methods
+func _httpMethod(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	_httpError(w, http.StatusMethodNotAllowed, errors.New("Method not allowed"))
+	return false
+}
+
+func _httpError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// Status of the reply to a failed operation: 503 when not connected, 404 for a missing DB, 502 when
+// TimeSeriesDB failed and 400 for the invalid requests
+func _httpStatus(err error) int {
+	if err == ErrNotConnected || err == ErrCircuitOpen {
+		return http.StatusServiceUnavailable
+	}
+	if kind, ok := err.(*kindError); ok {
+		if kind.kind == ErrTimeSeriesDBNotFound {
+			return http.StatusNotFound
+		}
+		return http.StatusBadGateway
+	}
+	return http.StatusBadRequest
+}
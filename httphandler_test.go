@@ -0,0 +1,116 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for the HTTP facade of the client
+func TestTimeSeriesDbHTTPHandler(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	handler := timeserData.NewHTTPHandler("secret")
+
+	request := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Authorization", "Token secret")
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// Authentication
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(`{"query": "SHOW DATABASES"}`)))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %v", w.Code)
+	}
+
+	// Writes
+	w = request(http.MethodPost, "/write", "application/json",
+		`{"points": [{"measurement": "CellKpi", "tags": {"cellId": "c1"}, "fields": {"prb": 3, "load": 0.5}, "time": "2021-08-20T05:47:46Z"}]}`)
+	if w.Code != http.StatusNoContent || len(writtenPoints) != 1 {
+		t.Fatalf("Unexpected reply %v %v", w.Code, w.Body.String())
+	}
+	fields, _ := writtenPoints[0].Fields()
+	if writtenPoints[0].Tags()["cellId"] != "c1" || fields["prb"] != int64(3) || fields["load"] != 0.5 || writtenPoints[0].Time().Unix() != 1629438466 {
+		t.Errorf("Unexpected point %v", writtenPoints[0])
+	}
+	if w = request(http.MethodPost, "/write", "text/plain", "CellKpi,cellId=c2 prb=4i"); w.Code != http.StatusNoContent || len(writtenPoints) != 2 {
+		t.Errorf("Unexpected reply %v %v", w.Code, w.Body.String())
+	}
+	if w = request(http.MethodPost, "/write", "application/json", `{"points": [{"fields": {"prb": 3}}]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for point without measurement, got %v", w.Code)
+	}
+	if w = request(http.MethodGet, "/write", "", ""); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %v", w.Code)
+	}
+	writeErr = errors.New("timeout")
+	if w = request(http.MethodPost, "/write", "text/plain", "CellKpi prb=4i"); w.Code != http.StatusBadGateway {
+		t.Errorf("Expected 502 for failed write, got %v", w.Code)
+	}
+
+	// Queries
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return seriesResp("CellKpi", []string{"time", "prb"}, []interface{}{"2021-08-20T05:47:46Z", json.Number("3")}), nil
+	}
+	w = request(http.MethodPost, "/query", "application/json", `{"query": "SELECT prb FROM CellKpi"}`)
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"rows":[{"prb":3,"time":"2021-08-20T05:47:46Z"}]}` {
+		t.Errorf("Unexpected reply %v %v", w.Code, w.Body.String())
+	}
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Err: "database not found: testdb"}, nil
+	}
+	if w = request(http.MethodPost, "/query", "application/json", `{"query": "SELECT prb FROM CellKpi"}`); w.Code != http.StatusNotFound ||
+		!strings.Contains(w.Body.String(), `"error":"TimeSeriesDB not found`) {
+		t.Errorf("Expected 404 for missing DB, got %v %v", w.Code, w.Body.String())
+	}
+
+	// Administration
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{}}}, nil
+	}
+	issuedQueries = nil
+	request(http.MethodPost, "/db", "", "")
+	request(http.MethodPost, "/retention-policies", "application/json", `{"name": "rp_7d", "duration": "7d"}`)
+	request(http.MethodDelete, "/retention-policies/rp_7d", "", "")
+	request(http.MethodDelete, "/db", "", "")
+	if len(issuedQueries) != 4 || !strings.HasPrefix(issuedQueries[0], "CREATE DATABASE") || !strings.HasPrefix(issuedQueries[1], "CREATE RETENTION POLICY") ||
+		!strings.HasPrefix(issuedQueries[2], "DROP RETENTION POLICY") || !strings.HasPrefix(issuedQueries[3], "DROP DATABASE") {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+	if w = request(http.MethodPost, "/retention-policies", "application/json", `{"name": "rp"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for missing duration, got %v", w.Code)
+	}
+}
@@ -0,0 +1,132 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Measurement and fields of one side of QueryJoin(), all the fields when Fields is empty
+type JoinSide struct {
+	Measurement string
+	Fields      []string
+}
+
+// Joins the points of two measurements in [start, stop) having the same time and tag values of tagKeys, eg. the PRB
+// usage and the throughput per cell. The rows, ordered by time, hold the time, the tag keys and the columns of both
+// sides; columns found on both sides are named <measurement>.<column>. Only the points matched on both sides are
+// returned, to join the aggregates of windows use QueryBuilder queries with QueryBatch()
+func (timeserData *TimeSeriesClientData) QueryJoin(left, right JoinSide, tagKeys []string, start, stop time.Time) (rows []JsonRow, err error) {
+	keys := append([]string{"time"}, tagKeys...)
+	queries := []string{timeserData.joinQuery(left, tagKeys, start, stop), timeserData.joinQuery(right, tagKeys, start, stop)}
+	results, errs := timeserData.QueryBatch(context.Background(), queries)
+	for i, err := range errs {
+		if err != nil {
+			timeserData.logger().Errorf("Failed to query %v for join with error %v\n", queries[i], err)
+			return nil, err
+		}
+	}
+
+	// Columns other than the keys, named after the measurement when on both sides
+	leftNames, rightNames := _joinColumns(results[0], keys), _joinColumns(results[1], keys)
+	for column := range leftNames {
+		if _, ok := rightNames[column]; ok {
+			leftNames[column] = left.Measurement + "." + column
+			rightNames[column] = right.Measurement + "." + column
+		}
+	}
+
+	rightRows := make(map[string][]JsonRow)
+	for _, row := range results[1] {
+		key := _joinKey(row, keys)
+		rightRows[key] = append(rightRows[key], row)
+	}
+	rows = []JsonRow{}
+	for _, leftRow := range results[0] {
+		for _, rightRow := range rightRows[_joinKey(leftRow, keys)] {
+			row := make(JsonRow, len(keys)+len(leftNames)+len(rightNames))
+			for _, key := range keys {
+				row[key] = leftRow[key]
+			}
+			for column, name := range leftNames {
+				row[name] = leftRow[column]
+			}
+			for column, name := range rightNames {
+				row[name] = rightRow[column]
+			}
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		ti, _ := _toTime(rows[i]["time"])
+		tj, _ := _toTime(rows[j]["time"])
+		return ti.Before(tj)
+	})
+	timeserData.logger().Debugf("TimeSeriesDB QueryJoin: DB=%v, %v and %v, rows=%v\n", timeserData.timeSeriesDbName, left.Measurement, right.Measurement, len(rows))
+	return rows, nil
+}
+
+// Selects the fields of a join side, grouped by the tag keys so that they are returned with the rows
+func (timeserData *TimeSeriesClientData) joinQuery(side JoinSide, tagKeys []string, start, stop time.Time) string {
+	fields := "*"
+	if len(side.Fields) > 0 {
+		quoted := make([]string, len(side.Fields))
+		for i, field := range side.Fields {
+			quoted[i] = _quoteIdent(field)
+		}
+		fields = strings.Join(quoted, ", ")
+	}
+	queryStr := fmt.Sprintf("SELECT %v FROM %v WHERE %v", fields, timeserData.measurementIdent(side.Measurement), _whereClause("", start, stop))
+	if len(tagKeys) > 0 {
+		quoted := make([]string, len(tagKeys))
+		for i, tagKey := range tagKeys {
+			quoted[i] = _quoteIdent(tagKey)
+		}
+		queryStr += " GROUP BY " + strings.Join(quoted, ", ")
+	}
+	return queryStr
+}
+
+// Returns the columns of the rows other than the keys, mapped to themselves
+func _joinColumns(rows []JsonRow, keys []string) map[string]string {
+	columns := make(map[string]string)
+	for _, row := range rows {
+		for column := range row {
+			columns[column] = column
+		}
+	}
+	for _, key := range keys {
+		delete(columns, key)
+	}
+	return columns
+}
+
+// Returns the values of the keys of a row, identifying the rows to join
+func _joinKey(row JsonRow, keys []string) string {
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = fmt.Sprint(row[key])
+	}
+	return strings.Join(values, "\x00")
+}
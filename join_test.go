@@ -0,0 +1,85 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+
+	"stslgo"
+)
+
+// Test function for joining two measurements on time and tags
+func TestTimeSeriesDbQueryJoin(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		if strings.Contains(q.Command, `"prbUsage"`) {
+			return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+				{Name: "prbUsage", Tags: map[string]string{"cell": "c1"}, Columns: []string{"time", "prb", "ue"}, Values: [][]interface{}{
+					{"2024-01-01T00:00:10Z", json.Number("40"), json.Number("3")},
+					{"2024-01-01T00:00:20Z", json.Number("50"), json.Number("4")},
+				}},
+				{Name: "prbUsage", Tags: map[string]string{"cell": "c2"}, Columns: []string{"time", "prb", "ue"}, Values: [][]interface{}{
+					{"2024-01-01T00:00:00Z", json.Number("10"), json.Number("1")},
+				}},
+			}}}}, nil
+		}
+		return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+			{Name: "throughput", Tags: map[string]string{"cell": "c1"}, Columns: []string{"time", "dl", "ue"}, Values: [][]interface{}{
+				{"2024-01-01T00:00:10Z", json.Number("100"), json.Number("3")},
+			}},
+			{Name: "throughput", Tags: map[string]string{"cell": "c2"}, Columns: []string{"time", "dl", "ue"}, Values: [][]interface{}{
+				{"2024-01-01T00:00:00Z", json.Number("200"), json.Number("1")},
+				{"2024-01-01T00:00:20Z", json.Number("300"), json.Number("2")},
+			}},
+		}}}}, nil
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows, err := timeserData.QueryJoin(stslgo.JoinSide{Measurement: "prbUsage", Fields: []string{"prb", "ue"}}, stslgo.JoinSide{Measurement: "throughput"}, []string{"cell"}, start, start.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("QueryJoin failed: %v", err)
+	}
+	sort.Strings(issuedQueries)
+	expected := []string{
+		`SELECT "prb", "ue" FROM "prbUsage" WHERE time >= '2024-01-01T00:00:00Z' AND time < '2024-01-01T00:01:00Z' GROUP BY "cell"`,
+		`SELECT * FROM "throughput" WHERE time >= '2024-01-01T00:00:00Z' AND time < '2024-01-01T00:01:00Z' GROUP BY "cell"`,
+	}
+	if fmt.Sprint(issuedQueries) != fmt.Sprint(expected) {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows joined, got %v", rows)
+	}
+	if fmt.Sprint(rows[0]) != "map[cell:c2 dl:200 prb:10 prbUsage.ue:1 throughput.ue:1 time:2024-01-01T00:00:00Z]" ||
+		fmt.Sprint(rows[1]) != "map[cell:c1 dl:100 prb:40 prbUsage.ue:3 throughput.ue:3 time:2024-01-01T00:00:10Z]" {
+		t.Errorf("Unexpected rows %v", rows)
+	}
+}
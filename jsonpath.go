@@ -0,0 +1,194 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Value of the inserted JSON stored by InsertJsonWithPaths()
+type PathSpec struct {
+	Path string // JSONPath of the value, eg. $.cellMetrics[0].prbUsage or $['Cell-RF']['cellId']
+	Name string // Name of the field or tag, the last key of the path when empty
+	Tag  bool   // Stored as tag instead of field
+}
+
+// Step of a JSONPath: an object key or, when key is empty, an array index
+type pathStep struct {
+	key   string
+	index int
+}
+
+// Inserts only the values at the JSONPaths of pathSpecs, instead of flattening the whole JSON, for verbose E2
+// or Netconf payloads of which a few values are needed. jsonBuffer holds an object or an array of objects, each
+// inserted as a point. The paths support the children .key and ['key'] and the array indexes [n], negative
+// from the end. Values missing or which cannot be fields are not stored, rows without fields are skipped.
+// The time key of the measurement (see SetTimeKey()) is taken from the names of pathSpecs
+func (timeserData *TimeSeriesClientData) InsertJsonWithPaths(measurement string, pathSpecs []PathSpec, jsonBuffer []byte) (err error) {
+	paths := make([][]pathStep, len(pathSpecs))
+	names := make([]string, len(pathSpecs))
+	for i, spec := range pathSpecs {
+		if paths[i], err = _parsePath(spec.Path); err != nil {
+			timeserData.logger().Errorf("%v\n", err)
+			return err
+		}
+		names[i] = spec.Name
+		if names[i] == "" {
+			if len(paths[i]) == 0 || paths[i][len(paths[i])-1].key == "" {
+				err = fmt.Errorf("JSONPath %v needs a name", spec.Path)
+				timeserData.logger().Errorf("%v\n", err)
+				return err
+			}
+			names[i] = paths[i][len(paths[i])-1].key
+		}
+	}
+
+	var data interface{}
+	if err = timeserData.unmarshalJson(jsonBuffer, &data); err != nil {
+		timeserData.logger().Errorf("\n Not able to Parse data %s", err.Error())
+		return err
+	}
+	rows, ok := data.([]interface{})
+	if !ok {
+		rows = []interface{}{data}
+	}
+
+	bp, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+		Database:  timeserData.timeSeriesDbName,
+		Precision: timeserData.writePrecision(),
+	})
+	for i, row := range rows {
+		values := make(map[string]interface{})
+		tags := make(map[string]string)
+		for j, spec := range pathSpecs {
+			value, ok := _evalPath(paths[j], row)
+			if !ok || value == nil {
+				continue
+			}
+			if spec.Tag {
+				tags[names[j]] = _tagValue(value)
+			} else {
+				values[names[j]] = value
+			}
+		}
+		timestamp, err := timeserData.extractTime(measurement, values)
+		if err != nil {
+			return err
+		}
+		fields := timeserData.jsonFields(measurement, values)
+		if len(fields) == 0 {
+			timeserData.logger().Warnf("Row %v of measurement %v has no value at the paths, skipped\n", i, measurement)
+			continue
+		}
+		pt, err := timeserData.jsonPoint(measurement, tags, fields, timestamp)
+		if err != nil {
+			return err
+		}
+		bp.AddPoint(pt)
+	}
+	if len(bp.Points()) == 0 {
+		return nil
+	}
+	err = timeserData.write(bp)
+	timeserData.logger().Debugf("TimeSeriesDB InsertJsonWithPaths: DB=%v Measurement=%v points=%v err=%v\n", timeserData.timeSeriesDbName, measurement, len(bp.Points()), err)
+	return err
+}
+
+// Parses a JSONPath into its steps
+func _parsePath(path string) ([]pathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSONPath %v does not start with $", path)
+	}
+	steps := []pathStep{}
+	rest := path[1:]
+	for rest != "" {
+		switch {
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("JSONPath %v has an empty key", path)
+			}
+			steps = append(steps, pathStep{key: key})
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "['") || strings.HasPrefix(rest, `["`):
+			end := strings.Index(rest[2:], string(rest[1])+"]")
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %v has an unterminated key", path)
+			}
+			if end == 0 {
+				// An empty key would be taken for index 0
+				return nil, fmt.Errorf("JSONPath %v has an empty key", path)
+			}
+			steps = append(steps, pathStep{key: rest[2 : end+2]})
+			rest = rest[end+4:]
+		case rest[0] == '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %v has an unterminated index", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("JSONPath %v has an invalid index %v", path, rest[1:end])
+			}
+			steps = append(steps, pathStep{index: index})
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("JSONPath %v is invalid at %v", path, rest)
+		}
+	}
+	return steps, nil
+}
+
+// Returns the value at the steps of a JSONPath
+func _evalPath(steps []pathStep, value interface{}) (interface{}, bool) {
+	for _, step := range steps {
+		if step.key != "" {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if value, ok = object[step.key]; !ok {
+				return nil, false
+			}
+			continue
+		}
+		array, ok := value.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		index := step.index
+		if index < 0 {
+			index += len(array)
+		}
+		if index < 0 || index >= len(array) {
+			return nil, false
+		}
+		value = array[index]
+	}
+	return value, true
+}
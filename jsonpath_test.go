@@ -0,0 +1,75 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"stslgo"
+)
+
+// Test function for inserting the values at JSONPaths only
+func TestTimeSeriesDbInsertJsonWithPaths(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	timeserData.SetTimeKey("E2Table", "ts", "s")
+	specs := []stslgo.PathSpec{
+		{Path: "$.header['Cell-RF'].cellId", Name: "CellID", Tag: true},
+		{Path: "$.header.collectionStart", Name: "ts"},
+		{Path: "$.measData[0].prbUsage"},
+		{Path: "$.measData[-1].ueCount", Name: "LastUeCount"},
+		{Path: "$.missing.value"},
+	}
+	payload := `[
+		{"header": {"Cell-RF": {"cellId": "c1"}, "collectionStart": 1629438466, "verbose": {"a": 1}},
+		 "measData": [{"prbUsage": 40, "ueCount": 3}, {"prbUsage": 60, "ueCount": 5}]},
+		{"header": {"Cell-RF": {"cellId": "c2"}}}
+	]`
+	if err = timeserData.InsertJsonWithPaths("E2Table", specs, []byte(payload)); err != nil || len(writtenPoints) != 1 {
+		t.Fatalf("Expected a point written, got %v with error %v", writtenPoints, err)
+	}
+	pt := writtenPoints[0]
+	fields, _ := pt.Fields()
+	if len(fields) != 2 || fields["prbUsage"] != 40.0 || fields["LastUeCount"] != 5.0 {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+	if tags := pt.Tags(); len(tags) != 1 || tags["CellID"] != "c1" {
+		t.Errorf("Unexpected tags %v", tags)
+	}
+	if !pt.Time().Equal(time.Unix(1629438466, 0)) {
+		t.Errorf("Unexpected time %v", pt.Time())
+	}
+
+	// A single object
+	writtenPoints = nil
+	if err = timeserData.InsertJsonWithPaths("E2Table", specs, []byte(`{"measData": [{"prbUsage": 10}]}`)); err != nil || len(writtenPoints) != 1 {
+		t.Errorf("Expected a point written, got %v with error %v", writtenPoints, err)
+	}
+
+	for _, path := range []string{"header.cellId", "$.header[x]", "$['unterminated", "$..a", "$[0]", "$.header['']", `$[""].a`} {
+		if err = timeserData.InsertJsonWithPaths("E2Table", []stslgo.PathSpec{{Path: path}}, []byte(`{}`)); err == nil {
+			t.Errorf("Expected an error for path %v", path)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"time"
+)
+
+// Message of a Kafka topic, as read or written by the Kafka client of the application
+type KafkaMessage struct {
+	Topic string
+	Key   []byte
+	Value []byte
+	Time  time.Time
+}
+
+// Consumer of Kafka messages, eg. an adapter of a kafka-go Reader, so that the library does not depend on a
+// Kafka client
+type KafkaReader interface {
+	FetchMessage(ctx context.Context) (KafkaMessage, error)         // Blocks until a message is available or ctx is done
+	CommitMessages(ctx context.Context, msgs ...KafkaMessage) error // Marks the messages as consumed
+}
+
+// Producer of Kafka messages, eg. an adapter of a kafka-go Writer
+type KafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...KafkaMessage) error
+}
+
+// Configuration of ConsumeKafka()
+type KafkaSourceConfig struct {
+	Measurement    string                            // Measurement the JSON rows are inserted in, as by InsertJsonArray()
+	MeasurementKey string                            // Key of the rows naming their measurement instead, as by InsertJsonArrayRouted()
+	IgnoreList     []string                          // Keys not stored
+	RetryInterval  time.Duration                     // Wait before writing again while TimeSeriesDB is unavailable, 1s when 0
+	OnError        func(msg KafkaMessage, err error) // Called for the messages skipped, eg. invalid JSON or rejected by TimeSeriesDB
+}
+
+// Configuration of PublishKafka()
+type KafkaSinkConfig struct {
+	Topic       string // Topic the points are published to
+	Measurement string // Measurement of the points published, all measurements when empty
+	BatchSize   int    // Maximum number of messages per write, 100 when 0
+}
+
+// JSON of a point published by PublishKafka()
+type kafkaPoint struct {
+	Measurement string                 `json:"measurement"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Fields      map[string]interface{} `json:"fields"`
+	Time        time.Time              `json:"time"`
+}
+
+// Inserts the JSON KPI messages (an object or an array of rows) read from Kafka until ctx is done or the reader
+// fails, eg. for the telemetry of SMO integrations. A message is committed once written, so that it is written
+// at least once. While TimeSeriesDB is unavailable the message is written again every RetryInterval, messages
+// which cannot be written are skipped and reported to OnError
+func (timeserData *TimeSeriesClientData) ConsumeKafka(ctx context.Context, reader KafkaReader, config KafkaSourceConfig) error {
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = time.Second
+	}
+	for {
+		msg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			timeserData.logger().Errorf("Failed to fetch Kafka message: %v\n", err)
+			return err
+		}
+		for {
+			// The message is written again or skipped, and its batches reported only when skipped
+			wctx, failed := _collectFailed(context.Background())
+			if config.MeasurementKey != "" {
+				_, err = timeserData.insertJsonArrayRouted(wctx, config.MeasurementKey, config.IgnoreList, msg.Value)
+			} else {
+				err = timeserData.insertJsonArray(wctx, config.Measurement, config.IgnoreList, msg.Value)
+			}
+			if err == nil || !_transient(err) {
+				timeserData.reportFailed(failed)
+				break
+			}
+			timeserData.logger().Warnf("Failed to write Kafka message of topic %v, retrying: %v\n", msg.Topic, err)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(config.RetryInterval):
+			}
+		}
+		if err != nil {
+			timeserData.logger().Errorf("Skipping Kafka message of topic %v: %v\n", msg.Topic, err)
+			if config.OnError != nil {
+				config.OnError(msg, err)
+			}
+		}
+		if err = reader.CommitMessages(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			timeserData.logger().Errorf("Failed to commit Kafka message: %v\n", err)
+			return err
+		}
+	}
+}
+
+// Publishes the points written by this client to a Kafka topic until ctx is done, as JSON objects with the
+// measurement, tags, fields and time of the point, keyed by series. Points are taken from a Subscription, see
+// Subscribe(). Points which fail to be published are dropped
+func (timeserData *TimeSeriesClientData) PublishKafka(ctx context.Context, writer KafkaWriter, config KafkaSinkConfig) error {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	sub := timeserData.Subscribe(config.Measurement)
+	defer sub.Close()
+	for {
+		var points []Point
+		select {
+		case <-ctx.Done():
+			return nil
+		case pt := <-sub.C:
+			points = append(points, pt)
+		}
+		// Points already there go in the same write
+		for len(points) < config.BatchSize && len(sub.C) > 0 {
+			points = append(points, <-sub.C)
+		}
+		msgs := make([]KafkaMessage, 0, len(points))
+		for _, pt := range points {
+			value, err := json.Marshal(kafkaPoint{Measurement: pt.Measurement, Tags: pt.Tags, Fields: pt.Fields, Time: pt.Time.UTC()})
+			if err != nil {
+				timeserData.logger().Errorf("Failed to encode point of measurement %v for Kafka: %v\n", pt.Measurement, err)
+				timeserData.reportDropped(1, "kafka_publish_failure")
+				continue
+			}
+			msgs = append(msgs, KafkaMessage{Topic: config.Topic, Key: []byte(_seriesKey(pt.Measurement, pt.Tags)), Value: value, Time: pt.Time})
+		}
+		if len(msgs) == 0 {
+			continue
+		}
+		if err := writer.WriteMessages(ctx, msgs...); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			timeserData.logger().Errorf("Failed to publish %v points to Kafka topic %v: %v\n", len(msgs), config.Topic, err)
+			timeserData.reportDropped(len(msgs), "kafka_publish_failure")
+		}
+	}
+}
+
+// Errors of TimeSeriesDB answering 5xx to a write when overloaded. The client reports only the body of the
+// response, not its status
+var overloadErrors = []string{"timeout", "cache-max-memory-size exceeded", "max-concurrent-write-limit exceeded", "hinted handoff queue"}
+
+// Whether a write failed because TimeSeriesDB is unavailable, and may succeed later
+func _transient(err error) bool {
+	if e, ok := err.(*kindError); ok {
+		err = e.cause
+	}
+	_, unreachable := err.(net.Error)
+	if unreachable || err == ErrCircuitOpen || err == ErrNotConnected || err == ErrWriteLimited {
+		return true
+	}
+	for _, overload := range overloadErrors {
+		if strings.Contains(err.Error(), overload) {
+			return true
+		}
+	}
+	return false
+}
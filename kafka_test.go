@@ -0,0 +1,140 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"stslgo"
+)
+
+// Kafka reader of the messages of a channel, recording the commits
+type fakeKafkaReader struct {
+	msgs      chan stslgo.KafkaMessage
+	committed chan stslgo.KafkaMessage
+}
+
+func (r *fakeKafkaReader) FetchMessage(ctx context.Context) (stslgo.KafkaMessage, error) {
+	select {
+	case msg := <-r.msgs:
+		return msg, nil
+	case <-ctx.Done():
+		return stslgo.KafkaMessage{}, ctx.Err()
+	}
+}
+
+func (r *fakeKafkaReader) CommitMessages(ctx context.Context, msgs ...stslgo.KafkaMessage) error {
+	for _, msg := range msgs {
+		r.committed <- msg
+	}
+	return nil
+}
+
+type fakeKafkaWriter chan stslgo.KafkaMessage
+
+func (w fakeKafkaWriter) WriteMessages(ctx context.Context, msgs ...stslgo.KafkaMessage) error {
+	for _, msg := range msgs {
+		w <- msg
+	}
+	return nil
+}
+
+// Test function for inserting the JSON messages of a Kafka topic
+func TestTimeSeriesDbConsumeKafka(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	timeserData.Iclient = &failingWriteClient{failures: 1}
+	reader := &fakeKafkaReader{msgs: make(chan stslgo.KafkaMessage, 2), committed: make(chan stslgo.KafkaMessage, 2)}
+	var skipped []string
+	reported := 0
+	timeserData.OnWriteError(func(err error, batchLines []string) { reported++ })
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- timeserData.ConsumeKafka(ctx, reader, stslgo.KafkaSourceConfig{
+			Measurement:   "CellKpi",
+			RetryInterval: time.Millisecond,
+			OnError:       func(msg stslgo.KafkaMessage, err error) { skipped = append(skipped, string(msg.Value)) },
+		})
+	}()
+
+	// Committed once written, after a retry
+	reader.msgs <- stslgo.KafkaMessage{Topic: "kpi", Value: []byte(`[{"prb": 1}, {"prb": 2}]`)}
+	if msg := <-reader.committed; string(msg.Value) != `[{"prb": 1}, {"prb": 2}]` {
+		t.Errorf("Unexpected commit %v", msg)
+	}
+	// Invalid JSON skipped
+	reader.msgs <- stslgo.KafkaMessage{Topic: "kpi", Value: []byte(`{`)}
+	<-reader.committed
+	cancel()
+	if err = <-done; err != nil {
+		t.Errorf("Expected no error on cancel, got %v", err)
+	}
+	if len(writtenPoints) != 2 || len(skipped) != 1 {
+		t.Errorf("Expected 2 points written and a message skipped, got %v and %v", writtenPoints, skipped)
+	}
+	if reported != 0 {
+		t.Errorf("Expected the batch written again not reported as failed, got %v reports", reported)
+	}
+}
+
+// Test function for publishing the written points to a Kafka topic
+func TestTimeSeriesDbPublishKafka(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	writer := make(fakeKafkaWriter, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- timeserData.PublishKafka(ctx, writer, stslgo.KafkaSinkConfig{Topic: "points", Measurement: "CellKpi"})
+	}()
+
+	// Subscribed once the first point is published
+	deadline := time.Now().Add(5 * time.Second)
+	var msg stslgo.KafkaMessage
+	for msg.Topic == "" && time.Now().Before(deadline) {
+		timeserData.WritePointAt("CellKpi", map[string]string{"cell": "c1"}, map[string]interface{}{"prb": 3}, time.Unix(1629438466, 0))
+		select {
+		case msg = <-writer:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	cancel()
+	if err = <-done; err != nil {
+		t.Errorf("Expected no error on cancel, got %v", err)
+	}
+
+	var point map[string]interface{}
+	if err = json.Unmarshal(msg.Value, &point); err != nil {
+		t.Fatalf("Unable to decode message %q with error %v", msg.Value, err)
+	}
+	if msg.Topic != "points" || string(msg.Key) != "CellKpi,cell=c1" || point["measurement"] != "CellKpi" ||
+		point["time"] != "2021-08-20T05:47:46Z" || point["fields"].(map[string]interface{})["prb"] != 3.0 {
+		t.Errorf("Unexpected message %v %s", msg, msg.Value)
+	}
+}
@@ -0,0 +1,98 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+// Package kpm writes decoded E2SM-KPM indication messages as tagged points of the TimeSeriesDB
+package kpm
+
+import (
+	"fmt"
+	"strconv"
+	"stslgo"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Tags of the points written for an indication
+const (
+	TagCellID      = "cellID"
+	TagUeID        = "ueID"
+	TagRanFunction = "ranFunction"
+)
+
+// Decoded E2SM-KPM indication (header and message format 1) for a cell or a single UE
+type Indication struct {
+	RanFunctionID     int64
+	CellID            string          // Cell global ID
+	UeID              string          // Empty for cell level measurements
+	CollectStartTime  time.Time       // colletStartTime of the indication header
+	GranularityPeriod time.Duration   // granulPeriod, time between consecutive items of MeasData
+	MeasNames         []string        // measName of each item of measInfoList
+	MeasData          [][]interface{} // measRecord of each measDataItem, aligned with MeasNames. int64, float64 or nil for noValue
+}
+
+// Converts an indication to one point per measDataItem, with the meas names as fields and the cell, UE and
+// RAN function as tags. The point of item i is timestamped CollectStartTime + i * GranularityPeriod
+func Points(measurement string, ind Indication) ([]*timesrclient.Point, error) {
+	if len(ind.MeasData) > 1 && ind.GranularityPeriod <= 0 {
+		return nil, fmt.Errorf("Granularity period needed for %v measDataItems", len(ind.MeasData))
+	}
+	tags := map[string]string{TagRanFunction: strconv.FormatInt(ind.RanFunctionID, 10)}
+	if ind.CellID != "" {
+		tags[TagCellID] = ind.CellID
+	}
+	if ind.UeID != "" {
+		tags[TagUeID] = ind.UeID
+	}
+
+	points := make([]*timesrclient.Point, 0, len(ind.MeasData))
+	for i, records := range ind.MeasData {
+		if len(records) != len(ind.MeasNames) {
+			return nil, fmt.Errorf("measDataItem %v has %v records for %v meas names", i, len(records), len(ind.MeasNames))
+		}
+		fields := map[string]interface{}{}
+		for j, record := range records {
+			if record != nil {
+				fields[ind.MeasNames[j]] = record
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		pt, err := timesrclient.NewPoint(measurement, tags, fields, ind.CollectStartTime.Add(time.Duration(i)*ind.GranularityPeriod))
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, pt)
+	}
+	return points, nil
+}
+
+// Writes the points of an indication to the DB of the client in a single batch
+func Write(timeserData *stslgo.TimeSeriesClientData, measurement string, ind Indication) error {
+	points, err := Points(measurement, ind)
+	if err != nil || len(points) == 0 {
+		return err
+	}
+	lines := make([]string, len(points))
+	for i, pt := range points {
+		lines[i] = pt.String()
+	}
+	return timeserData.WriteLineProtocolBatch(lines)
+}
@@ -0,0 +1,101 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package kpm_test
+
+import (
+	"stslgo"
+	"stslgo/kpm"
+	"testing"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Mock client recording the written points
+type MockClient struct {
+	points []*timesrclient.Point
+}
+
+func (c *MockClient) Close() error {
+	return nil
+}
+
+func (c *MockClient) Ping(timeout time.Duration) (time.Duration, string, error) {
+	return 0, "1.8.0", nil
+}
+
+func (c *MockClient) Query(q timesrclient.Query) (*timesrclient.Response, error) {
+	return &timesrclient.Response{}, nil
+}
+
+func (c *MockClient) Write(bp timesrclient.BatchPoints) error {
+	c.points = append(c.points, bp.Points()...)
+	return nil
+}
+
+// Test function for writing an E2SM-KPM indication
+func TestKpmWrite(t *testing.T) {
+	timeserData := stslgo.NewTimeSeriesClientData("testdb", "testuser", "testpasswd")
+	mock := &MockClient{}
+	timeserData.Iclient = mock
+
+	start := time.Date(2021, 8, 20, 5, 47, 0, 0, time.UTC)
+	ind := kpm.Indication{
+		RanFunctionID:     2,
+		CellID:            "00101-0000000001",
+		CollectStartTime:  start,
+		GranularityPeriod: time.Second,
+		MeasNames:         []string{"DRB.UEThpDl", "RRU.PrbUsedDl"},
+		MeasData:          [][]interface{}{{12.5, int64(30)}, {nil, nil}, {13.0, nil}},
+	}
+	if err := kpm.Write(timeserData, "CellKpm", ind); err != nil {
+		t.Fatalf("Unable to write indication with error %v", err)
+	}
+	if len(mock.points) != 2 {
+		t.Fatalf("Expected 2 points, got %v", mock.points)
+	}
+	pt := mock.points[0]
+	fields, _ := pt.Fields()
+	if pt.Name() != "CellKpm" || pt.Tags()[kpm.TagCellID] != "00101-0000000001" || pt.Tags()[kpm.TagRanFunction] != "2" ||
+		pt.Tags()[kpm.TagUeID] != "" || fields["DRB.UEThpDl"] != 12.5 || fields["RRU.PrbUsedDl"] != int64(30) || !pt.Time().Equal(start) {
+		t.Errorf("Unexpected point %v", pt)
+	}
+	fields, _ = mock.points[1].Fields()
+	if len(fields) != 1 || !mock.points[1].Time().Equal(start.Add(2*time.Second)) {
+		t.Errorf("Unexpected point %v", mock.points[1])
+	}
+
+	// UE level measurements
+	points, err := kpm.Points("UeKpm", kpm.Indication{RanFunctionID: 2, CellID: "c1", UeID: "ue7", CollectStartTime: start,
+		MeasNames: []string{"DRB.UEThpDl"}, MeasData: [][]interface{}{{int64(5)}}})
+	if err != nil || len(points) != 1 || points[0].Tags()[kpm.TagUeID] != "ue7" {
+		t.Errorf("Unexpected UE points %v with error %v", points, err)
+	}
+
+	ind.MeasData = [][]interface{}{{12.5}}
+	if err = kpm.Write(timeserData, "CellKpm", ind); err == nil {
+		t.Errorf("Expected error for records not aligned with meas names")
+	}
+	ind.MeasData = [][]interface{}{{1.0, 2.0}, {1.0, 2.0}}
+	ind.GranularityPeriod = 0
+	if _, err = kpm.Points("CellKpm", ind); err == nil {
+		t.Errorf("Expected error for missing granularity period")
+	}
+}
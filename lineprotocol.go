@@ -0,0 +1,69 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"strings"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Writes points given in line protocol, one per line, with timestamps in nanoseconds (the current time when
+// missing). The points are validated against the schema registry, if any, and written as a single batch
+func (timeserData *TimeSeriesClientData) WriteLineProtocol(lines string) (err error) {
+	points, err := models.ParsePointsString(lines)
+	if err != nil {
+		timeserData.logger().Errorf("Failed to parse line protocol: %v\n", err)
+		return err
+	}
+	if len(points) == 0 {
+		return nil
+	}
+
+	bp, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+		Database:  (*timeserData).timeSeriesDbName,
+		Precision: timeserData.writePrecision(),
+	})
+	for _, point := range points {
+		pt := timesrclient.NewPointFrom(point)
+		if timeserData.schemaRegistry != nil {
+			fields, err := pt.Fields()
+			if err == nil {
+				fields, err = timeserData.schemaFields(pt.Name(), pt.Tags(), fields)
+			}
+			if err == nil {
+				pt, err = timesrclient.NewPoint(pt.Name(), pt.Tags(), fields, pt.Time())
+			}
+			if err != nil {
+				return err
+			}
+		}
+		bp.AddPoint(pt)
+	}
+	err = timeserData.write(bp)
+	timeserData.logger().Debugf("TimeSeriesDB WriteLineProtocol: DB=%v points=%v, err=%v\n", timeserData.timeSeriesDbName, len(points), err)
+	return err
+}
+
+// Same as WriteLineProtocol for lines given separately
+func (timeserData *TimeSeriesClientData) WriteLineProtocolBatch(lines []string) error {
+	return timeserData.WriteLineProtocol(strings.Join(lines, "\n"))
+}
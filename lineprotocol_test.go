@@ -0,0 +1,70 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"fmt"
+	"stslgo"
+	"testing"
+	"time"
+)
+
+// Test function for writing points given in line protocol
+func TestTimeSeriesDbWriteLineProtocol(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	err = timeserData.WriteLineProtocol("CellKpi,cellId=c1 prb=3i,state=\"up\" 1629174962020974506\nCellKpi,cellId=c2 prb=5i\n")
+	if err != nil {
+		t.Fatalf("Unable to write line protocol with error %v", err)
+	}
+	if writeCalls != 1 || len(writtenPoints) != 2 {
+		t.Fatalf("Expected 2 points in a single write, got %v in %v", len(writtenPoints), writeCalls)
+	}
+	fields, _ := writtenPoints[0].Fields()
+	if writtenPoints[0].Name() != "CellKpi" || writtenPoints[0].Tags()["cellId"] != "c1" || fields["prb"] != int64(3) || fields["state"] != "up" ||
+		writtenPoints[0].Time().UnixNano() != 1629174962020974506 {
+		t.Errorf("Unexpected point %v", writtenPoints[0])
+	}
+	if time.Since(writtenPoints[1].Time()) > time.Minute {
+		t.Errorf("Expected current time for point without timestamp, got %v", writtenPoints[1].Time())
+	}
+
+	if err = timeserData.WriteLineProtocolBatch([]string{"CellKpi,cellId=c3 prb=1i", "CellKpi prb="}); err == nil {
+		t.Errorf("Expected parse error")
+	}
+
+	// Validated against the schema registry
+	registry := stslgo.NewSchemaRegistry()
+	registry.Register("CellKpi", stslgo.MeasurementSchema{Fields: map[string]stslgo.FieldType{"prb": stslgo.FieldFloat, "state": stslgo.FieldString}})
+	timeserData.SetSchemaRegistry(registry)
+	if err = timeserData.WriteLineProtocolBatch([]string{"CellKpi,cellId=c3 prb=1i 1629174962020974506"}); err != nil {
+		t.Fatalf("Unable to write line protocol with error %v", err)
+	}
+	fields, _ = writtenPoints[2].Fields()
+	if fields["prb"] != 1.0 || writtenPoints[2].Time().UnixNano() != 1629174962020974506 {
+		t.Errorf("Expected coerced point, got %v", writtenPoints[2])
+	}
+	if err = timeserData.WriteLineProtocol("CellKpi state=1i"); err == nil {
+		t.Errorf("Expected schema conflict")
+	}
+}
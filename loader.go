@@ -0,0 +1,298 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Format of the records read by LoadHistory()
+type LoadFormat int
+
+const (
+	LoadCSV   LoadFormat = iota // CSV with a header row naming the columns
+	LoadJSONL                   // One JSON object per line, flattened as InsertJson()
+)
+
+// Configuration of LoadHistory()
+type LoadOptions struct {
+	TimeColumn      string             // Column holding the timestamp, time when empty
+	TimeLayout      string             // time.Parse layout, or epoch unit s, ms, us or ns. RFC3339 when empty
+	TagColumns      []string           // Columns written as tags, the others as fields
+	BatchSize       int                // Points per write, 5000 when 0
+	PointsPerSecond float64            // Rate of the written points, 0 for no limit
+	Skip            int64              // Records skipped, eg. the LoadProgress.Records of an interrupted load to resume it
+	Progress        func(LoadProgress) // Called after each written batch
+}
+
+// Progress of LoadHistory()
+type LoadProgress struct {
+	Records int64 // Records read and written, including the skipped ones
+	Points  int64 // Points written
+	Bytes   int64 // Bytes read
+}
+
+// Streams the records of a large CSV or JSONL file into a measurement in batches, eg. to replay captured RAN
+// traces into a lab RIC. Numbers are written as floats, or as per SetJsonNumberMode() with JsonNumberPreserve as
+// integers when the first value of their column is an integer. Booleans are written as such, empty values are
+// skipped. On error the returned progress holds the records written so far: loading again with Skip set to its
+// Records resumes the load
+func (timeserData *TimeSeriesClientData) LoadHistory(r io.Reader, format LoadFormat, measurement string, opts LoadOptions) (progress LoadProgress, err error) {
+	if opts.TimeColumn == "" {
+		opts.TimeColumn = "time"
+	}
+	if opts.TimeLayout == "" {
+		opts.TimeLayout = time.RFC3339
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = restoreBatchSize
+	}
+	counter := &countingReader{r: r}
+	next, err := timeserData.loadRecords(counter, format)
+	if err != nil {
+		return progress, err
+	}
+
+	started := time.Now()
+	integers := make(map[string]bool)
+	var bp timesrclient.BatchPoints
+	var records int64
+	flush := func() error {
+		if bp == nil || len(bp.Points()) == 0 {
+			progress.Records = records
+			return nil
+		}
+		if err := timeserData.write(bp); err != nil {
+			return err
+		}
+		progress.Points += int64(len(bp.Points()))
+		progress.Records, progress.Bytes = records, counter.n
+		bp = nil
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+		if opts.PointsPerSecond > 0 {
+			due := started.Add(time.Duration(float64(progress.Points) / opts.PointsPerSecond * float64(time.Second)))
+			time.Sleep(time.Until(due))
+		}
+		return nil
+	}
+	for {
+		record, rerr := next()
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			err = fmt.Errorf("Record %v: %v", records+1, rerr)
+			break
+		}
+		records++
+		if records <= opts.Skip {
+			progress.Records = records
+			continue
+		}
+		pt, perr := timeserData.loadPoint(measurement, record, opts, integers)
+		if perr != nil {
+			err = fmt.Errorf("Record %v: %v", records, perr)
+			break
+		}
+		if pt == nil {
+			continue
+		}
+		if bp == nil {
+			bp, _ = timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+				Database:  timeserData.timeSeriesDbName,
+				Precision: timeserData.writePrecision(),
+			})
+		}
+		bp.AddPoint(pt)
+		if len(bp.Points()) >= opts.BatchSize {
+			if err = flush(); err != nil {
+				break
+			}
+		}
+	}
+	// On error the records of the batch not written are left out of the progress, to be loaded on resume
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		timeserData.logger().Errorf("Failed to load history into %v after %v records with error %v\n", measurement, progress.Records, err)
+		return progress, err
+	}
+	progress.Bytes = counter.n
+	timeserData.logger().Infof("Loaded %v points of %v records into %v\n", progress.Points, progress.Records, measurement)
+	return progress, nil
+}
+
+// Converts a record to a point, nil when it has no field. integers holds whether the numbers of each column seen
+// so far are integers
+func (timeserData *TimeSeriesClientData) loadPoint(measurement string, record map[string]interface{}, opts LoadOptions, integers map[string]bool) (*timesrclient.Point, error) {
+	value, ok := record[opts.TimeColumn]
+	if !ok || value == nil {
+		return nil, fmt.Errorf("No time column %v", opts.TimeColumn)
+	}
+	timestamp, err := _parseTime(value, opts.TimeLayout)
+	if err != nil {
+		return nil, err
+	}
+	delete(record, opts.TimeColumn)
+	tags := make(map[string]string)
+	for _, key := range opts.TagColumns {
+		if value, ok := record[key]; ok {
+			if value != nil {
+				tags[key] = _tagValue(value)
+			}
+			delete(record, key)
+		}
+	}
+	fields := make(map[string]interface{}, len(record))
+	for key, value := range record {
+		if value == nil {
+			continue
+		}
+		number, ok := value.(json.Number)
+		if !ok {
+			fields[key] = _loadValue(value)
+			continue
+		}
+		integer, seen := integers[key]
+		if !seen {
+			_, err := number.Int64()
+			integer = timeserData.jsonNumberMode == JsonNumberPreserve && err == nil
+			integers[key] = integer
+		}
+		if fields[key], err = _loadNumber(number, integer); err != nil {
+			return nil, fmt.Errorf("Column %v: %v", key, err)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	fields, err = timeserData.finiteFields(measurement, fields)
+	if err != nil {
+		return nil, err
+	}
+	return timesrclient.NewPoint(measurement, tags, fields, timestamp)
+}
+
+// Returns a reader of the records of the format, io.EOF after the last one
+func (timeserData *TimeSeriesClientData) loadRecords(r io.Reader, format LoadFormat) (func() (map[string]interface{}, error), error) {
+	switch format {
+	case LoadCSV:
+		reader := csv.NewReader(r)
+		reader.ReuseRecord = true
+		header, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		header = append([]string{}, header...)
+		return func() (map[string]interface{}, error) {
+			values, err := reader.Read()
+			if err != nil {
+				return nil, err
+			}
+			record := make(map[string]interface{}, len(header))
+			for i, column := range header {
+				if i >= len(values) || values[i] == "" {
+					continue
+				}
+				// As JSON numbers, converted by _loadNumber and keeping nanosecond epochs exact
+				if _, err := strconv.ParseFloat(values[i], 64); err == nil {
+					record[column] = json.Number(values[i])
+				} else {
+					record[column] = values[i]
+				}
+			}
+			return record, nil
+		}, nil
+	case LoadJSONL:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		return func() (map[string]interface{}, error) {
+			for scanner.Scan() {
+				line := bytes.TrimSpace(scanner.Bytes())
+				if len(line) == 0 {
+					continue
+				}
+				var nested map[string]interface{}
+				decoder := json.NewDecoder(bytes.NewReader(line))
+				decoder.UseNumber()
+				if err := decoder.Decode(&nested); err != nil {
+					return nil, err
+				}
+				record := make(map[string]interface{})
+				if err := _flatten(true, record, nested, "", nil, FlattenOptions{}, 0, timeserData.logger()); err != nil {
+					return nil, err
+				}
+				return record, nil
+			}
+			if err := scanner.Err(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}, nil
+	}
+	return nil, fmt.Errorf("Unsupported load format %v", format)
+}
+
+// Converts a loaded number to an integer or float field
+func _loadNumber(number json.Number, integer bool) (interface{}, error) {
+	if !integer {
+		return number.Float64()
+	}
+	n, err := number.Int64()
+	if err != nil {
+		return nil, fmt.Errorf("%v is not an integer as the first value of the column", number)
+	}
+	return n, nil
+}
+
+// Converts a loaded value other than a number to a boolean or string field
+func _loadValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// Counts the bytes read
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
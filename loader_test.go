@@ -0,0 +1,130 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"stslgo"
+)
+
+const loaderCSV = `time,cell,prb,state
+2024-01-01T00:00:00Z,c1,10,true
+2024-01-01T00:00:01Z,c1,11.5,false
+2024-01-01T00:00:02Z,c2,,
+2024-01-01T00:00:03Z,c2,13,idle
+2024-01-01T00:00:04Z,c1,14,true
+`
+
+// Test function for loading CSV history in batches and resuming an interrupted load
+func TestTimeSeriesDbLoadHistoryCSV(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	var reported []stslgo.LoadProgress
+	opts := stslgo.LoadOptions{TagColumns: []string{"cell"}, BatchSize: 2, Progress: func(p stslgo.LoadProgress) { reported = append(reported, p) }}
+	progress, err := timeserData.LoadHistory(strings.NewReader(loaderCSV), stslgo.LoadCSV, "CellKpi", opts)
+	if err != nil || progress.Records != 5 || progress.Points != 4 || progress.Bytes != int64(len(loaderCSV)) {
+		t.Errorf("Unexpected progress %+v, error %v", progress, err)
+	}
+	if writeCalls != 2 || len(reported) != 2 || reported[0].Records != 2 || reported[1].Records != 5 || reported[1].Points != 4 {
+		t.Errorf("Unexpected batches %v, progress %+v", writeCalls, reported)
+	}
+	if len(writtenPoints) != 4 {
+		t.Fatalf("Expected 4 points written, got %v", writtenPoints)
+	}
+	for i, expected := range []string{
+		"CellKpi,cell=c1 prb=10,state=true 1704067200000000000",
+		"CellKpi,cell=c1 prb=11.5,state=false 1704067201000000000",
+		`CellKpi,cell=c2 prb=13,state="idle" 1704067203000000000`,
+		"CellKpi,cell=c1 prb=14,state=true 1704067204000000000",
+	} {
+		if writtenPoints[i].String() != expected {
+			t.Errorf("Unexpected point %v, expected %v", writtenPoints[i], expected)
+		}
+	}
+
+	// Failing write, the progress holds the records written before
+	writtenPoints = nil
+	opts.Progress = nil
+	writeErr = errors.New("timeout")
+	progress, err = timeserData.LoadHistory(strings.NewReader(loaderCSV), stslgo.LoadCSV, "CellKpi", opts)
+	if err == nil || progress.Records != 0 || progress.Points != 0 {
+		t.Errorf("Unexpected progress %+v, error %v", progress, err)
+	}
+
+	// Resuming after the first 3 records
+	writeErr = nil
+	opts.Skip = 3
+	progress, err = timeserData.LoadHistory(strings.NewReader(loaderCSV), stslgo.LoadCSV, "CellKpi", opts)
+	if err != nil || progress.Records != 5 || progress.Points != 2 || len(writtenPoints) != 2 {
+		t.Errorf("Unexpected progress %+v, error %v, points %v", progress, err, writtenPoints)
+	}
+}
+
+// Test function for loading JSONL history with epoch timestamps
+func TestTimeSeriesDbLoadHistoryJSONL(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	valid := `{"ts": 1704067200000, "ue": {"id": 7}, "thp": 1.5}
+
+{"ts": 1704067201000, "ue": {"id": 8}, "thp": 2}
+`
+	jsonl := valid + `{"ts": 1704067202000, "ue": {"id": 9}, "thp": }
+`
+	opts := stslgo.LoadOptions{TimeColumn: "ts", TimeLayout: "ms", TagColumns: []string{"ue.id"}}
+	progress, err := timeserData.LoadHistory(strings.NewReader(jsonl), stslgo.LoadJSONL, "UeKpi", opts)
+	if err == nil || !strings.Contains(err.Error(), "Record 3") || progress.Records != 0 || len(writtenPoints) != 0 {
+		t.Errorf("Unexpected progress %+v, error %v", progress, err)
+	}
+
+	progress, err = timeserData.LoadHistory(strings.NewReader(valid), stslgo.LoadJSONL, "UeKpi", opts)
+	if err != nil || progress.Records != 2 || len(writtenPoints) != 2 {
+		t.Fatalf("Unexpected progress %+v, error %v", progress, err)
+	}
+	if writtenPoints[0].String() != "UeKpi,ue.id=7 thp=1.5 1704067200000000000" || writtenPoints[1].String() != "UeKpi,ue.id=8 thp=2 1704067201000000000" {
+		t.Errorf("Unexpected points %v", writtenPoints)
+	}
+
+	// Integer columns, typed by their first value
+	writtenPoints = nil
+	timeserData.SetJsonNumberMode(stslgo.JsonNumberPreserve)
+	counters := `{"ts": 1704067200000, "bytes": 10, "thp": 1.5}
+{"ts": 1704067201000, "bytes": 20, "thp": 2}
+{"ts": 1704067202000, "bytes": 30.5, "thp": 3}
+`
+	progress, err = timeserData.LoadHistory(strings.NewReader(counters), stslgo.LoadJSONL, "UeKpi", opts)
+	if err == nil || !strings.Contains(err.Error(), "Record 3") || progress.Records != 0 {
+		t.Errorf("Expected a fraction in an integer column rejected, got progress %+v, error %v", progress, err)
+	}
+	progress, err = timeserData.LoadHistory(strings.NewReader(counters), stslgo.LoadJSONL, "UeKpi", stslgo.LoadOptions{TimeColumn: "ts", TimeLayout: "ms", BatchSize: 2})
+	if len(writtenPoints) != 2 || writtenPoints[0].String() != "UeKpi bytes=10i,thp=1.5 1704067200000000000" ||
+		writtenPoints[1].String() != "UeKpi bytes=20i,thp=2 1704067201000000000" {
+		t.Errorf("Unexpected points %v", writtenPoints)
+	}
+}
@@ -0,0 +1,55 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"github.com/rs/zerolog/log"
+)
+
+// Receives the log messages of a client, eg. an adapter to the mdclog of the xapp-frame.
+// The messages are formatted as by fmt.Sprintf
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// Default Logger, writing to the global zerolog logger whose level is set with SetLoggingLevel()
+type zerologLogger struct{}
+
+func (zerologLogger) Debugf(format string, args ...interface{}) { log.Debug().Msgf(format, args...) }
+func (zerologLogger) Infof(format string, args ...interface{})  { log.Info().Msgf(format, args...) }
+func (zerologLogger) Warnf(format string, args ...interface{})  { log.Warn().Msgf(format, args...) }
+func (zerologLogger) Errorf(format string, args ...interface{}) { log.Error().Msgf(format, args...) }
+
+// Sets the logger of the client, nil for the default zerolog logger.
+// Connections created afterwards by CreateTimeSeriesConnection() log to it as well
+func (timeserData *TimeSeriesClientData) SetLogger(logger Logger) {
+	timeserData.log = logger
+}
+
+// Returns the logger of the client
+func (timeserData *TimeSeriesClientData) logger() Logger {
+	if timeserData.log == nil {
+		return zerologLogger{}
+	}
+	return timeserData.log
+}
@@ -0,0 +1,75 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Logger recording the messages per level
+type testLogger struct {
+	lock     sync.Mutex
+	messages []string
+}
+
+func (l *testLogger) add(level, format string, args ...interface{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.messages = append(l.messages, level+" "+fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Debugf(format string, args ...interface{}) { l.add("debug", format, args...) }
+func (l *testLogger) Infof(format string, args ...interface{})  { l.add("info", format, args...) }
+func (l *testLogger) Warnf(format string, args ...interface{})  { l.add("warn", format, args...) }
+func (l *testLogger) Errorf(format string, args ...interface{}) { l.add("error", format, args...) }
+
+// Test function for routing the log messages to the logger set on the client
+func TestTimeSeriesDbLogger(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	logger := &testLogger{}
+	timeserData.SetLogger(logger)
+
+	timeserData.CreateTimeSeriesDB()
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return nil, errors.New("timeout")
+	}
+	timeserData.DropMeasurement("CellKpi")
+	timeserData.Query("SELECT * FROM CellKpi")
+
+	logged := strings.Join(logger.messages, "")
+	for _, expected := range []string{
+		"info Sucessfully created DB testdb",
+		"error Failed to delete measurement CellKpi with error TimeSeriesDB query failed: timeout",
+		"debug TimeSeriesDB Query: DB=testdb, QueryString=SELECT * FROM CellKpi",
+	} {
+		if !strings.Contains(logged, expected) {
+			t.Errorf("Missing %q in %q", expected, logger.messages)
+		}
+	}
+}
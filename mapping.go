@@ -0,0 +1,90 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"fmt"
+)
+
+// Normalization of the keys of the JSON inserted in a measurement, eg. from vendor names to O-RAN KPI names.
+// All the keys are flattened JSON keys as received. It is applied before SetTagKeys() and SetTimeKey(), which
+// name the keys as renamed
+type FieldMapping struct {
+	Rename     map[string]string         // New name of the keys
+	Convert    map[string]UnitConversion // Conversion of numeric values, eg. kbps to Mbps
+	Drop       []string                  // Keys not stored
+	StaticTags map[string]string         // Tags added to all the points
+}
+
+// Converts a value v to v*Scale + Offset
+type UnitConversion struct {
+	Scale  float64
+	Offset float64
+}
+
+// Sets the normalization of the keys of the JSON inserted in the measurement by InsertJson(), InsertJsonArray()
+// and the other JSON insert APIs. Passing an empty mapping stores the keys as received again
+func (timeserData *TimeSeriesClientData) SetFieldMapping(measurement string, mapping FieldMapping) {
+	timeserData.jsonConfigLock.Lock()
+	defer timeserData.jsonConfigLock.Unlock()
+	if timeserData.fieldMappings == nil {
+		timeserData.fieldMappings = make(map[string]FieldMapping)
+	}
+	if len(mapping.Rename) == 0 && len(mapping.Convert) == 0 && len(mapping.Drop) == 0 && len(mapping.StaticTags) == 0 {
+		delete(timeserData.fieldMappings, measurement)
+		return
+	}
+	timeserData.fieldMappings[measurement] = mapping
+}
+
+// Applies the FieldMapping of the measurement to flatjson, which is modified, and returns the mapping
+func (timeserData *TimeSeriesClientData) mapFields(measurement string, flatjson map[string]interface{}) (FieldMapping, error) {
+	timeserData.jsonConfigLock.RLock()
+	mapping := timeserData.fieldMappings[measurement]
+	timeserData.jsonConfigLock.RUnlock()
+
+	for _, key := range mapping.Drop {
+		delete(flatjson, key)
+	}
+	for key, conversion := range mapping.Convert {
+		value, ok := flatjson[key]
+		if !ok || value == nil {
+			continue
+		}
+		number, err := _toFloat64(value)
+		if _, isString := value.(string); isString || err != nil {
+			err = fmt.Errorf("Not able to convert %v=%v of measurement %v: not a number", key, value, measurement)
+			timeserData.logger().Errorf("%v\n", err)
+			return mapping, err
+		}
+		flatjson[key] = number*conversion.Scale + conversion.Offset
+	}
+	renamed := make(map[string]interface{}, len(mapping.Rename))
+	for key, name := range mapping.Rename {
+		if value, ok := flatjson[key]; ok {
+			renamed[name] = value
+			delete(flatjson, key)
+		}
+	}
+	for name, value := range renamed {
+		flatjson[name] = value
+	}
+	return mapping, nil
+}
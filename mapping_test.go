@@ -0,0 +1,71 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"fmt"
+	"testing"
+
+	"stslgo"
+)
+
+// Test function for normalizing the keys of inserted JSON
+func TestTimeSeriesDbFieldMapping(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	timeserData.SetFieldMapping("CellTable", stslgo.FieldMapping{
+		Rename:     map[string]string{"vendor.dlThpKbps": "DRB.UEThpDl", "vendor.cell": "CellID"},
+		Convert:    map[string]stslgo.UnitConversion{"vendor.dlThpKbps": {Scale: 0.001}, "temp": {Scale: 1, Offset: 273.15}},
+		Drop:       []string{"vendor.debug"},
+		StaticTags: map[string]string{"vendor": "acme"},
+	})
+	timeserData.SetTagKeys("CellTable", []string{"CellID"})
+
+	err = timeserData.InsertJsonArray("CellTable", nil, []byte(`[{"vendor": {"dlThpKbps": 2500, "cell": "c1", "debug": "x"}, "temp": 20}]`))
+	if err != nil || len(writtenPoints) != 1 {
+		t.Fatalf("Expected a point written, got %v with error %v", writtenPoints, err)
+	}
+	fields, _ := writtenPoints[0].Fields()
+	tags := writtenPoints[0].Tags()
+	if len(fields) != 2 || fields["DRB.UEThpDl"] != 2.5 || fields["temp"] != 293.15 {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+	if len(tags) != 2 || tags["CellID"] != "c1" || tags["vendor"] != "acme" {
+		t.Errorf("Unexpected tags %v", tags)
+	}
+
+	// Conversion of a value which is not a number
+	writtenPoints = nil
+	if err = timeserData.InsertJson("CellTable", nil, []byte(`{"temp": "hot"}`)); err == nil || len(writtenPoints) != 0 {
+		t.Errorf("Expected an error for a value not converted, got %v", err)
+	}
+
+	// An empty mapping stores the keys as received
+	timeserData.SetFieldMapping("CellTable", stslgo.FieldMapping{})
+	timeserData.SetTagKeys("CellTable", nil)
+	if err = timeserData.InsertJson("CellTable", nil, []byte(`{"temp": 20}`)); err != nil || len(writtenPoints) != 1 {
+		t.Fatalf("Expected a point written, got %v with error %v", writtenPoints, err)
+	}
+	if fields, _ := writtenPoints[0].Fields(); fields["temp"] != 20.0 {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+}
@@ -0,0 +1,255 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Receives the outcome of the operations of a client, eg. to export them to Prometheus. Called synchronously,
+// so implementations have to be fast and safe for concurrent use
+type MetricsHook interface {
+	Written(points int, err error)                         // A batch of points was written
+	Dropped(points int, reason string)                     // Points were dropped by the library, eg. BatchWriter overflow
+	Queried(duration time.Duration, err error)             // A query completed
+	Flushed(points int, duration time.Duration, err error) // A BatchWriter flush completed
+}
+
+// Upper bounds in seconds of the latency histogram buckets, as the Prometheus client defaults
+var metricsLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// MetricsHook counting the operations of the clients it is set on, exported in the Prometheus text format
+// by ServeHTTP() or WritePrometheus(), without depending on the Prometheus client library
+type Metrics struct {
+	writes        int64
+	writeErrors   int64
+	pointsWritten int64
+	queries       int64
+	queryErrors   int64
+	circuitState  int64
+	circuitReject int64
+	dropLock      sync.Mutex
+	dropped       map[string]int64
+	queryLatency  latencyHistogram
+	flushLatency  latencyHistogram
+}
+
+type latencyHistogram struct {
+	lock   sync.Mutex
+	counts []int64
+	count  int64
+	sum    float64
+}
+
+// Creates the counters of a MetricsHook, to be set with SetMetricsHook()
+func NewMetrics() *Metrics {
+	return &Metrics{dropped: make(map[string]int64)}
+}
+
+// Sets the hook receiving the outcome of writes, queries and BatchWriter flushes, nil for none
+func (timeserData *TimeSeriesClientData) SetMetricsHook(hook MetricsHook) {
+	timeserData.metrics = hook
+}
+
+func (m *Metrics) Written(points int, err error) {
+	atomic.AddInt64(&m.writes, 1)
+	if err != nil {
+		atomic.AddInt64(&m.writeErrors, 1)
+		return
+	}
+	atomic.AddInt64(&m.pointsWritten, int64(points))
+}
+
+func (m *Metrics) Dropped(points int, reason string) {
+	m.dropLock.Lock()
+	defer m.dropLock.Unlock()
+	m.dropped[reason] += int64(points)
+}
+
+func (m *Metrics) Queried(duration time.Duration, err error) {
+	atomic.AddInt64(&m.queries, 1)
+	if err != nil {
+		atomic.AddInt64(&m.queryErrors, 1)
+	}
+	m.queryLatency.observe(duration)
+}
+
+func (m *Metrics) Flushed(points int, duration time.Duration, err error) {
+	m.flushLatency.observe(duration)
+}
+
+func (m *Metrics) CircuitStateChanged(state CircuitState) {
+	atomic.StoreInt64(&m.circuitState, int64(state))
+}
+
+func (m *Metrics) CircuitRejected() {
+	atomic.AddInt64(&m.circuitReject, 1)
+}
+
+// Serves the metrics in the Prometheus text format, eg. on /metrics
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.WritePrometheus(w)
+}
+
+// Writes the metrics in the Prometheus text format
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	var err error
+	printf := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+	counter := func(name, help string, value int64) {
+		printf("# HELP %v %v\n# TYPE %v counter\n%v %v\n", name, help, name, name, value)
+	}
+	counter("stslgo_writes_total", "Batches written to TimeSeriesDB.", atomic.LoadInt64(&m.writes))
+	counter("stslgo_write_errors_total", "Batches failing to write to TimeSeriesDB.", atomic.LoadInt64(&m.writeErrors))
+	counter("stslgo_points_written_total", "Points written to TimeSeriesDB.", atomic.LoadInt64(&m.pointsWritten))
+	counter("stslgo_queries_total", "Queries sent to TimeSeriesDB.", atomic.LoadInt64(&m.queries))
+	counter("stslgo_query_errors_total", "Queries failing.", atomic.LoadInt64(&m.queryErrors))
+	printf("# HELP stslgo_circuit_breaker_state State of the circuit breaker, 0 closed, 1 open, 2 half-open.\n# TYPE stslgo_circuit_breaker_state gauge\nstslgo_circuit_breaker_state %v\n", atomic.LoadInt64(&m.circuitState))
+	counter("stslgo_circuit_breaker_rejected_total", "Requests failed by the open circuit breaker.", atomic.LoadInt64(&m.circuitReject))
+
+	m.dropLock.Lock()
+	reasons := make([]string, 0, len(m.dropped))
+	for reason := range m.dropped {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	printf("# HELP stslgo_points_dropped_total Points dropped by the library.\n# TYPE stslgo_points_dropped_total counter\n")
+	for _, reason := range reasons {
+		printf("stslgo_points_dropped_total{reason=%q} %v\n", reason, m.dropped[reason])
+	}
+	m.dropLock.Unlock()
+
+	m.queryLatency.write(printf, "stslgo_query_duration_seconds", "Latency of the queries.")
+	m.flushLatency.write(printf, "stslgo_batch_flush_duration_seconds", "Duration of the BatchWriter flushes.")
+	return err
+}
+
+func (h *latencyHistogram) observe(duration time.Duration) {
+	seconds := duration.Seconds()
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.counts == nil {
+		h.counts = make([]int64, len(metricsLatencyBuckets))
+	}
+	for i, bound := range metricsLatencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+func (h *latencyHistogram) write(printf func(string, ...interface{}), name, help string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	printf("# HELP %v %v\n# TYPE %v histogram\n", name, help, name)
+	for i, bound := range metricsLatencyBuckets {
+		var count int64
+		if h.counts != nil {
+			count = h.counts[i]
+		}
+		printf("%v_bucket{le=\"%v\"} %v\n", name, strconv.FormatFloat(bound, 'g', -1, 64), count)
+	}
+	printf("%v_bucket{le=\"+Inf\"} %v\n%v_sum %v\n%v_count %v\n", name, h.count, name, strconv.FormatFloat(h.sum, 'g', -1, 64), name, h.count)
+}
+
+// Runs a query on the connection, reporting it to the metrics hook and the tracer
+func (timeserData *TimeSeriesClientData) query(q timesrclient.Query) (*timesrclient.Response, error) {
+	return timeserData.queryContext(context.Background(), q)
+}
+
+// Runs a query on the connection within the span of ctx. Errors of the response are returned as err as well,
+// ErrTimeSeriesDBNotFound for a missing database and ErrQueryFailed otherwise
+func (timeserData *TimeSeriesClientData) queryContext(ctx context.Context, q timesrclient.Query) (*timesrclient.Response, error) {
+	if timeserData.Iclient == nil {
+		return nil, ErrNotConnected
+	}
+	_, span := timeserData.startSpan(ctx, "stslgo.Query")
+	span.SetAttribute("db.name", q.Database)
+	span.SetAttribute("db.operation", _statementOperation(q.Command))
+	span.SetAttribute("db.statement.length", len(q.Command))
+
+	var cacheKey string
+	if cache := timeserData.queryCache; cache != nil {
+		if cacheKey = _queryCacheKey(ctx, q); cacheKey != "" {
+			if response, ok := cache.get(cacheKey); ok {
+				span.SetAttribute("stslgo.cached", true)
+				span.End(nil)
+				return response.(*timesrclient.Response), nil
+			}
+		}
+	}
+	client, breaker := timeserData.queryClient(ctx, q), timeserData.breaker
+	if client != timeserData.Iclient {
+		breaker = timeserData.readBreaker
+	}
+	if err := timeserData.circuitAllow(breaker); err != nil {
+		span.End(err)
+		return nil, err
+	}
+	start := time.Now()
+	response, err := client.Query(q)
+	timeserData.circuitRecord(breaker, err)
+	if !_readOnly(q.Command) {
+		// The statement may have changed what the cached queries return
+		timeserData.clearQueryCache()
+	}
+	if err == nil && response != nil {
+		err = response.Error()
+	}
+	if err != nil {
+		kind := ErrQueryFailed
+		if strings.Contains(err.Error(), "database not found") {
+			kind = ErrTimeSeriesDBNotFound
+		}
+		err = &kindError{kind: kind, cause: err}
+	}
+	if timeserData.metrics != nil {
+		timeserData.metrics.Queried(time.Since(start), err)
+	}
+	if err == nil && cacheKey != "" {
+		timeserData.queryCache.put(cacheKey, response)
+	}
+	span.End(err)
+	return response, err
+}
+
+// Reports points dropped by the library to the metrics hook
+func (timeserData *TimeSeriesClientData) reportDropped(points int, reason string) {
+	if timeserData.metrics != nil {
+		timeserData.metrics.Dropped(points, reason)
+	}
+}
@@ -0,0 +1,78 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"stslgo"
+	"testing"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for the counters of the metrics hook in the Prometheus text format
+func TestTimeSeriesDbMetrics(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	metrics := stslgo.NewMetrics()
+	timeserData.SetMetricsHook(metrics)
+
+	fields := map[string]interface{}{"prb": 3}
+	timeserData.WritePoint("CellKpi", nil, fields)
+	timeserData.WritePoint("CellKpi", nil, fields)
+	timeserData.Query("SELECT * FROM CellKpi")
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Err: "database not found: testdb"}, nil
+	}
+	timeserData.Query("SELECT * FROM CellKpi")
+
+	// Second failed flush drops the first batch
+	writeErr = errors.New("timeout")
+	bw := timeserData.NewBatchWriter(stslgo.BatchWriterConfig{BatchSize: 2, MaxRetained: 1})
+	bw.WritePoint("CellKpi", nil, fields)
+	bw.WritePoint("CellKpi", nil, fields)
+	bw.WritePoint("CellKpi", nil, fields)
+	bw.Flush()
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	output := recorder.Body.String()
+	for _, line := range []string{
+		"stslgo_writes_total 4",
+		"stslgo_write_errors_total 2",
+		"stslgo_points_written_total 2",
+		"stslgo_queries_total 2",
+		"stslgo_query_errors_total 1",
+		`stslgo_points_dropped_total{reason="batch_writer_overflow"} 2`,
+		`stslgo_query_duration_seconds_bucket{le="+Inf"} 2`,
+		"stslgo_query_duration_seconds_count 2",
+		"stslgo_batch_flush_duration_seconds_count 2",
+		"# TYPE stslgo_query_duration_seconds histogram",
+	} {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Missing %q in metrics:\n%v", line, output)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"strings"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Tag keys of the name and version of the xApp added to the written points, see SetNamespace()
+const (
+	XappNameTag    = "xapp"
+	XappVersionTag = "xapp_version"
+)
+
+// Isolates the data of an xApp sharing the DB with other xApps: measurements are written as
+// <namespace>.<measurement> and read back from there by the methods taking a measurement, so that two xApps
+// writing ueMetrics do not collide. The written points are tagged with xappName and xappVersion when not empty,
+// unless they have these tags already. Queries written by hand have to use MeasurementName()
+func (timeserData *TimeSeriesClientData) SetNamespace(namespace, xappName, xappVersion string) {
+	timeserData.namespaceLock.Lock()
+	defer timeserData.namespaceLock.Unlock()
+	timeserData.namespace = namespace
+	timeserData.xappName = xappName
+	timeserData.xappVersion = xappVersion
+}
+
+// Sets tags added to every written point, eg. the RIC instance, PLMN or site of the deployment, so that callers
+// do not have to repeat them. Tags given by the caller take precedence. nil or empty removes them. The writes of
+// points which the tags make invalid, eg. with a series key too long, fail
+func (timeserData *TimeSeriesClientData) SetGlobalTags(tags map[string]string) {
+	globalTags := make(map[string]string, len(tags))
+	for key, value := range tags {
+		if key != "" && value != "" {
+			globalTags[key] = value
+		}
+	}
+	timeserData.namespaceLock.Lock()
+	defer timeserData.namespaceLock.Unlock()
+	timeserData.globalTags = globalTags
+}
+
+// Returns the name a measurement is stored under in the namespace of the client. Names already in the namespace
+// are returned unchanged
+func (timeserData *TimeSeriesClientData) MeasurementName(measurement string) string {
+	timeserData.namespaceLock.RLock()
+	namespace := timeserData.namespace
+	timeserData.namespaceLock.RUnlock()
+	return _namespacedName(namespace, measurement)
+}
+
+// Returns the name of a measurement in the namespace
+func _namespacedName(namespace, measurement string) string {
+	prefix := namespace + "."
+	if namespace == "" || measurement == "" || strings.HasPrefix(measurement, prefix) {
+		return measurement
+	}
+	return prefix + measurement
+}
+
+// Returns the stored name of a measurement quoted for use in InfluxQL
+func (timeserData *TimeSeriesClientData) measurementIdent(measurement string) string {
+	return _quoteIdent(timeserData.MeasurementName(measurement))
+}
+
+// Returns the batch with its points moved to the namespace and tagged with the xApp and the global tags, bp
+// itself when there is nothing to change. Fails when a point cannot be rebuilt, eg. when the tags added make its
+// series key too long
+func (timeserData *TimeSeriesClientData) namespaced(bp timesrclient.BatchPoints) (timesrclient.BatchPoints, error) {
+	timeserData.namespaceLock.RLock()
+	namespace, globalTags := timeserData.namespace, timeserData.globalTags
+	xappTags := map[string]string{XappNameTag: timeserData.xappName, XappVersionTag: timeserData.xappVersion}
+	timeserData.namespaceLock.RUnlock()
+	if namespace == "" && xappTags[XappNameTag] == "" && xappTags[XappVersionTag] == "" && len(globalTags) == 0 {
+		return bp, nil
+	}
+	batch, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+		Database:         bp.Database(),
+		Precision:        bp.Precision(),
+		RetentionPolicy:  bp.RetentionPolicy(),
+		WriteConsistency: bp.WriteConsistency(),
+	})
+	for _, pt := range bp.Points() {
+		name, tags := _namespacedName(namespace, pt.Name()), pt.Tags()
+		changed := name != pt.Name()
+		for key, value := range xappTags {
+			if _, ok := tags[key]; !ok && value != "" {
+				tags[key], changed = value, true
+			}
+		}
+		for key, value := range globalTags {
+			if _, ok := tags[key]; !ok {
+				tags[key], changed = value, true
+			}
+		}
+		if changed {
+			fields, err := pt.Fields()
+			if err == nil {
+				pt, err = timesrclient.NewPoint(name, tags, fields, pt.Time())
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		batch.AddPoint(pt)
+	}
+	return batch, nil
+}
@@ -0,0 +1,125 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"stslgo"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for isolating the measurements of an xApp in its namespace
+func TestTimeSeriesDbNamespace(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	timeserData.SetNamespace("kpimon", "kpimon", "1.2.0")
+	if name := timeserData.MeasurementName("ueMetrics"); name != "kpimon.ueMetrics" || timeserData.MeasurementName(name) != name {
+		t.Errorf("Unexpected measurement name %v", name)
+	}
+	sub := timeserData.Subscribe("ueMetrics")
+	defer sub.Close()
+
+	timeserData.WritePoint("ueMetrics", map[string]string{"ue": "1"}, map[string]interface{}{"thp": 10})
+	timeserData.WritePoint("kpimon.ueMetrics", map[string]string{"xapp": "other"}, map[string]interface{}{"thp": 20})
+	if len(writtenPoints) != 2 {
+		t.Fatalf("Expected 2 points written, got %v", len(writtenPoints))
+	}
+	for i, expected := range []map[string]string{
+		{"ue": "1", "xapp": "kpimon", "xapp_version": "1.2.0"},
+		{"xapp": "other", "xapp_version": "1.2.0"},
+	} {
+		if pt := writtenPoints[i]; pt.Name() != "kpimon.ueMetrics" || fmt.Sprint(pt.Tags()) != fmt.Sprint(expected) {
+			t.Errorf("Unexpected point %v", pt)
+		}
+	}
+	if point := <-sub.C; point.Measurement != "kpimon.ueMetrics" || point.Fields["thp"] != int64(10) {
+		t.Errorf("Unexpected point delivered %v", point)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+			{Name: "kpimon.ueMetrics", Columns: []string{"time", "thp"}, Values: [][]interface{}{{"2024-01-01T00:00:00Z", json.Number("20")}}},
+		}}}}, nil
+	}
+	if value, err := timeserData.GetFloat("ueMetrics", "thp"); err != nil || value != 20 {
+		t.Errorf("Unexpected value %v, error %v", value, err)
+	}
+	if _, err = timeserData.ListFieldKeys("ueMetrics"); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if len(issuedQueries) != 2 || !strings.Contains(issuedQueries[0], `FROM "kpimon.ueMetrics"`) || !strings.Contains(issuedQueries[1], `FROM "kpimon.ueMetrics"`) {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+
+	// Grafana targets and annotations name measurements of the namespace
+	handler := timeserData.NewGrafanaHandler("")
+	for path, body := range map[string]string{
+		"/query":       `{"range": {"from": "2024-01-01T00:00:00Z", "to": "2024-01-01T01:00:00Z"}, "targets": [{"target": "ueMetrics/thp"}]}`,
+		"/annotations": `{"range": {"from": "2024-01-01T00:00:00Z", "to": "2024-01-01T01:00:00Z"}, "annotation": {"query": "alarms"}}`,
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	}
+	if len(issuedQueries) != 4 || !strings.Contains(issuedQueries[2]+issuedQueries[3], `FROM "kpimon.ueMetrics"`) ||
+		!strings.Contains(issuedQueries[2]+issuedQueries[3], `FROM "kpimon.alarms"`) {
+		t.Errorf("Unexpected Grafana queries %v", issuedQueries[2:])
+	}
+}
+
+// Test function for adding the global tags to the written points
+func TestTimeSeriesDbGlobalTags(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	tags := map[string]string{"ric": "ric-1", "plmn": "00101", "site": ""}
+	timeserData.SetGlobalTags(tags)
+	tags["ric"] = "changed"
+
+	timeserData.WritePoint("cellMetrics", map[string]string{"cell": "c1"}, map[string]interface{}{"prb": 1})
+	timeserData.WritePoint("cellMetrics", map[string]string{"plmn": "00102"}, map[string]interface{}{"prb": 2})
+	timeserData.SetGlobalTags(map[string]string{"ric": strings.Repeat("r", 70000)})
+	if err = timeserData.WritePoints("cellMetrics", []stslgo.Point{{Fields: map[string]interface{}{"prb": 0}}}); err == nil {
+		t.Errorf("Expected the write of a point with a series key too long to fail")
+	}
+	timeserData.SetGlobalTags(nil)
+	timeserData.WritePoint("cellMetrics", nil, map[string]interface{}{"prb": 3})
+	if len(writtenPoints) != 3 {
+		t.Fatalf("Expected 3 points written, got %v", len(writtenPoints))
+	}
+	for i, expected := range []map[string]string{
+		{"cell": "c1", "plmn": "00101", "ric": "ric-1"},
+		{"plmn": "00102", "ric": "ric-1"},
+		{},
+	} {
+		if pt := writtenPoints[i]; pt.Name() != "cellMetrics" || fmt.Sprint(pt.Tags()) != fmt.Sprint(expected) {
+			t.Errorf("Unexpected point %v", pt)
+		}
+	}
+}
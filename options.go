@@ -0,0 +1,117 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"time"
+)
+
+// Configuration of a client, for running clients against different TimeSeriesDB instances in one process.
+// Zero values fall back to the environment variables and defaults used by NewTimeSeriesClientData()
+type Options struct {
+	Host                 string               // TimeSeriesDB host, default TIMESERIESDB_SERVICE_HOST or localhost
+	Port                 string               // TimeSeriesDB HTTP port, default TIMESERIESDB_SERVICE_PORT_HTTP or 8086
+	ReadHost             string               // TimeSeriesDB host serving the queries, see SetReadEndpoint()
+	ReadPort             string               // HTTP port of ReadHost, default Port
+	DbName               string               // TimeSeries DB to be used
+	UserName             string               // Username for accessing the TimeSeries DB
+	Password             string               // Password for accessing the TimeSeries DB
+	Token                string               // "username:password" or password, instead of UserName and Password
+	TokenFile            string               // File the token is read from and reloaded, see SetTokenFile()
+	TokenRefreshInterval time.Duration        // Interval of the checks of TokenFile
+	Timeout              time.Duration        // Timeout of the requests to TimeSeriesDB, 0 for none
+	QueryPoolSize        int                  // Number of clients serving the queries concurrently, see SetQueryPoolSize()
+	TLS                  *TLSOptions          // TLS settings, default from the environment
+	Reconnect            *ReconnectPolicy     // Health checking and reconnection, default DefaultReconnectPolicy
+	BatchSize            int                  // Default BatchSize of the BatchWriters of the client
+	Precision            string               // Precision of the written timestamps, see SetWritePrecision()
+	UseGzip              bool                 // Compression of the writes, see SetWriteGzip()
+	MaxRetries           int                  // Retries of the writes failing on network errors, see SetWriteRetry()
+	RetryInterval        time.Duration        // Wait before each retry of a write, DefaultWriteRetryInterval if not positive
+	JsonNumberMode       JsonNumberMode       // Decoding of the numbers of the inserted JSON
+	DedupWindow          time.Duration        // Window of the deduplication of the written points, see SetDedupWindow()
+	ValueCacheSize       int                  // Number of latest values cached, see SetValueCache()
+	ValueCacheTTL        time.Duration        // Time a latest value is cached
+	QueryCacheSize       int                  // Number of query responses cached, see SetQueryCache()
+	QueryCacheTTL        time.Duration        // Time a query response is cached
+	WriteLimits          WriteLimits          // Rate and concurrency limits of the writes, see SetWriteLimits()
+	CircuitBreaker       CircuitBreakerPolicy // Failing fast while TimeSeriesDB is down, see SetCircuitBreaker()
+	Namespace            string               // Prefix of the measurements of the client, see SetNamespace()
+	XappName             string               // Value of the xapp tag of the written points
+	XappVersion          string               // Value of the xapp_version tag of the written points
+	GlobalTags           map[string]string    // Tags added to the written points, see SetGlobalTags()
+	LogLevel             string               // Logging level set with SetLoggingLevel(), which is global to the process
+	Metrics              MetricsHook          // Receives the outcome of the operations, eg. NewMetrics()
+	Logger               Logger               // Receives the log messages, zerolog by default
+	ErrorHandler         func(error)          // Receives the errors of writes not returned to the caller, see SetWriteErrorMode()
+}
+
+// Creates a client configured with opts. As NewTimeSeriesClientData(), it does not connect
+func NewTimeSeriesClientWithOptions(opts Options) (*TimeSeriesClientData, error) {
+	if opts.LogLevel != "" {
+		SetLoggingLevel(opts.LogLevel)
+	}
+	timeserData := &TimeSeriesClientData{
+		timeSeriesDbName:   opts.DbName,
+		timeSeriesUserName: opts.UserName,
+		timeSeriesPassword: opts.Password,
+		host:               opts.Host,
+		port:               opts.Port,
+		readHost:           opts.ReadHost,
+		readPort:           opts.ReadPort,
+		timeout:            opts.Timeout,
+		queryPoolSize:      opts.QueryPoolSize,
+		tlsOptions:         opts.TLS,
+		reconnectPolicy:    DefaultReconnectPolicy,
+		batchSize:          opts.BatchSize,
+		writeGzip:          opts.UseGzip,
+		jsonNumberMode:     opts.JsonNumberMode,
+		metrics:            opts.Metrics,
+		log:                opts.Logger,
+	}
+	if opts.ErrorHandler != nil {
+		timeserData.SetWriteErrorMode(WriteErrorHandler, opts.ErrorHandler)
+	}
+	timeserData.SetWriteRetry(opts.MaxRetries, opts.RetryInterval)
+	timeserData.SetNamespace(opts.Namespace, opts.XappName, opts.XappVersion)
+	timeserData.SetGlobalTags(opts.GlobalTags)
+	timeserData.SetDedupWindow(opts.DedupWindow)
+	timeserData.SetValueCache(opts.ValueCacheSize, opts.ValueCacheTTL)
+	timeserData.SetQueryCache(opts.QueryCacheSize, opts.QueryCacheTTL)
+	timeserData.SetWriteLimits(opts.WriteLimits)
+	timeserData.SetCircuitBreaker(opts.CircuitBreaker)
+	if opts.Reconnect != nil {
+		timeserData.reconnectPolicy = *opts.Reconnect
+	}
+	if opts.Precision != "" {
+		if err := timeserData.SetWritePrecision(opts.Precision); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Token != "" {
+		timeserData.setToken(opts.Token)
+	}
+	if opts.TokenFile != "" {
+		if err := timeserData.SetTokenFile(opts.TokenFile, opts.TokenRefreshInterval); err != nil {
+			return nil, err
+		}
+	}
+	return timeserData, nil
+}
@@ -0,0 +1,89 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"stslgo"
+	"testing"
+	"time"
+)
+
+// Starts a TimeSeriesDB stub recording the database and credentials of each query
+func optionsServer(t *testing.T, seen chan<- string) (server *httptest.Server, host, port string) {
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		seen <- r.Host + " " + r.FormValue("db") + " " + user + ":" + password
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"results":[{"statement_id":0}]}`))
+	}))
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, port, _ = net.SplitHostPort(serverURL.Host)
+	return server, host, port
+}
+
+// Test function for two clients configured with Options against different TimeSeriesDB instances
+func TestTimeSeriesDbOptions(t *testing.T) {
+	seen := make(chan string, 2)
+	server1, host1, port1 := optionsServer(t, seen)
+	defer server1.Close()
+	server2, host2, port2 := optionsServer(t, seen)
+	defer server2.Close()
+
+	noReconnect := stslgo.ReconnectPolicy{}
+	client1, err := stslgo.NewTimeSeriesClientWithOptions(stslgo.Options{
+		Host: host1, Port: port1, DbName: "raw", UserName: "xapp1", Password: "secret1",
+		Timeout: time.Second, Reconnect: &noReconnect,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client2, err := stslgo.NewTimeSeriesClientWithOptions(stslgo.Options{
+		Host: host2, Port: port2, DbName: "agg", Token: "xapp2:secret2", Reconnect: &noReconnect,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, client := range []*stslgo.TimeSeriesClientData{client1, client2} {
+		if err = client.CreateTimeSeriesConnection(); err != nil {
+			t.Fatalf("Unable to connect with error %v", err)
+		}
+		defer client.Close()
+	}
+
+	for i, expected := range []string{
+		host1 + ":" + port1 + " raw xapp1:secret1",
+		host2 + ":" + port2 + " agg xapp2:secret2",
+	} {
+		client := []*stslgo.TimeSeriesClientData{client1, client2}[i]
+		if _, err = client.Query("SHOW MEASUREMENTS"); err != nil {
+			t.Fatalf("Query failed with error %v", err)
+		}
+		if got := <-seen; got != expected {
+			t.Errorf("Expected query %q, got %q", expected, got)
+		}
+	}
+}
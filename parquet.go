@@ -0,0 +1,363 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// Parquet physical types, repetitions, converted types and encodings
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetRequired = 0
+	parquetOptional = 1
+
+	parquetUTF8            = 0
+	parquetTimestampMicros = 10
+
+	parquetPlain = 0
+	parquetRLE   = 3
+)
+
+var parquetTypes = map[FieldType]int32{
+	FieldFloat:   parquetDouble,
+	FieldInteger: parquetInt64,
+	FieldString:  parquetByteArray,
+	FieldBoolean: parquetBoolean,
+}
+
+// Writes rows as a Parquet file, a row group per exportBlockSize rows with a single uncompressed and plain
+// encoded data page per column. Only the time column is required, the others are optional
+type parquetWriter struct {
+	w         io.Writer
+	columns   []exportColumn
+	offset    int64
+	values    [][]interface{} // Of the current row group, per column
+	rows      int
+	rowGroups []parquetRowGroup
+}
+
+type parquetRowGroup struct {
+	rows   int
+	chunks []parquetChunk
+}
+
+type parquetChunk struct {
+	offset int64 // Of the page header
+	size   int64
+}
+
+func newParquetWriter(w io.Writer, columns []exportColumn) *parquetWriter {
+	return &parquetWriter{w: w, columns: columns, values: make([][]interface{}, len(columns))}
+}
+
+// Adds a row to the row group, writing the row group when full
+func (pw *parquetWriter) write(row []interface{}) error {
+	for i, value := range row {
+		pw.values[i] = append(pw.values[i], value)
+	}
+	pw.rows++
+	if pw.rows >= exportBlockSize {
+		return pw.flush()
+	}
+	return nil
+}
+
+// Writes the row group of the rows added so far
+func (pw *parquetWriter) flush() error {
+	if pw.rows == 0 {
+		return nil
+	}
+	// Magic number first, so that the offsets are in the file
+	if err := pw.put(nil); err != nil {
+		return err
+	}
+	rowGroup := parquetRowGroup{rows: pw.rows}
+	for i, column := range pw.columns {
+		var page bytes.Buffer
+		if !column.required {
+			levels := _parquetLevels(pw.values[i])
+			var size [4]byte
+			binary.LittleEndian.PutUint32(size[:], uint32(len(levels)))
+			page.Write(size[:])
+			page.Write(levels)
+		}
+		_parquetPlain(&page, pw.values[i])
+
+		header := &thriftWriter{}
+		header.begin()
+		header.i32(1, 0) // DATA_PAGE
+		header.i32(2, int32(page.Len()))
+		header.i32(3, int32(page.Len()))
+		header.structField(5)
+		header.i32(1, int32(pw.rows))
+		header.i32(2, parquetPlain)
+		header.i32(3, parquetRLE)
+		header.i32(4, parquetRLE)
+		header.end()
+		header.end()
+
+		chunk := parquetChunk{offset: pw.offset, size: int64(header.Len() + page.Len())}
+		if err := pw.put(header.Bytes()); err != nil {
+			return err
+		}
+		if err := pw.put(page.Bytes()); err != nil {
+			return err
+		}
+		rowGroup.chunks = append(rowGroup.chunks, chunk)
+		pw.values[i] = pw.values[i][:0]
+	}
+	pw.rowGroups = append(pw.rowGroups, rowGroup)
+	pw.rows = 0
+	return nil
+}
+
+// Writes the last row group and the footer
+func (pw *parquetWriter) close() error {
+	if err := pw.flush(); err != nil {
+		return err
+	}
+	if err := pw.put(nil); err != nil {
+		return err
+	}
+
+	footer := &thriftWriter{}
+	footer.begin()
+	footer.i32(1, 1)
+	footer.list(2, thriftStruct, len(pw.columns)+1)
+	footer.begin()
+	footer.binary(4, "schema")
+	footer.i32(5, int32(len(pw.columns)))
+	footer.end()
+	for _, column := range pw.columns {
+		footer.begin()
+		footer.i32(1, parquetTypes[column.fieldType])
+		if column.required {
+			footer.i32(3, parquetRequired)
+		} else {
+			footer.i32(3, parquetOptional)
+		}
+		footer.binary(4, column.name)
+		if column.required {
+			footer.i32(6, parquetTimestampMicros)
+		} else if column.fieldType == FieldString {
+			footer.i32(6, parquetUTF8)
+		}
+		footer.end()
+	}
+	rows := 0
+	for _, rowGroup := range pw.rowGroups {
+		rows += rowGroup.rows
+	}
+	footer.i64(3, int64(rows))
+	footer.list(4, thriftStruct, len(pw.rowGroups))
+	for _, rowGroup := range pw.rowGroups {
+		footer.begin()
+		footer.list(1, thriftStruct, len(rowGroup.chunks))
+		var size int64
+		for i, chunk := range rowGroup.chunks {
+			column := pw.columns[i]
+			footer.begin()
+			footer.i64(2, chunk.offset)
+			footer.structField(3)
+			footer.i32(1, parquetTypes[column.fieldType])
+			if column.required {
+				footer.list(2, thriftI32, 1)
+				footer.varint(parquetPlain)
+			} else {
+				footer.list(2, thriftI32, 2)
+				footer.varint(parquetPlain)
+				footer.varint(parquetRLE)
+			}
+			footer.list(3, thriftBinary, 1)
+			footer.string(column.name)
+			footer.i32(4, 0) // UNCOMPRESSED
+			footer.i64(5, int64(rowGroup.rows))
+			footer.i64(6, chunk.size)
+			footer.i64(7, chunk.size)
+			footer.i64(9, chunk.offset)
+			footer.end()
+			footer.end()
+			size += chunk.size
+		}
+		footer.i64(2, size)
+		footer.i64(3, int64(rowGroup.rows))
+		footer.end()
+	}
+	footer.binary(6, "stslgo")
+	footer.end()
+
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(footer.Len()))
+	for _, b := range [][]byte{footer.Bytes(), size[:], []byte("PAR1")} {
+		if err := pw.put(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes b, after the magic number at the start of the file
+func (pw *parquetWriter) put(b []byte) error {
+	if pw.offset == 0 {
+		if _, err := pw.w.Write([]byte("PAR1")); err != nil {
+			return err
+		}
+		pw.offset = 4
+	}
+	n, err := pw.w.Write(b)
+	pw.offset += int64(n)
+	return err
+}
+
+// Returns the definition levels of the values of an optional column, 0 for null, as a bit-packed run
+func _parquetLevels(values []interface{}) []byte {
+	groups := (len(values) + 7) / 8
+	var b [binary.MaxVarintLen64]byte
+	levels := append([]byte{}, b[:binary.PutUvarint(b[:], uint64(groups)<<1|1)]...)
+	packed := make([]byte, groups)
+	for i, value := range values {
+		if value != nil {
+			packed[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return append(levels, packed...)
+}
+
+// Writes the values which are not null with the plain encoding
+func _parquetPlain(buf *bytes.Buffer, values []interface{}) {
+	var bits []byte
+	n := 0
+	for _, value := range values {
+		var b [8]byte
+		switch v := value.(type) {
+		case int64:
+			binary.LittleEndian.PutUint64(b[:], uint64(v))
+			buf.Write(b[:])
+		case float64:
+			binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+			buf.Write(b[:])
+		case string:
+			binary.LittleEndian.PutUint32(b[:4], uint32(len(v)))
+			buf.Write(b[:4])
+			buf.WriteString(v)
+		case bool:
+			if n%8 == 0 {
+				bits = append(bits, 0)
+			}
+			if v {
+				bits[n/8] |= 1 << uint(n%8)
+			}
+			n++
+		}
+	}
+	buf.Write(bits)
+}
+
+// Thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// Encodes structs with the Thrift compact protocol, as the Parquet metadata
+type thriftWriter struct {
+	bytes.Buffer
+	last []int16 // Id of the last field of the nested structs
+}
+
+// Starts a struct, a list element or the top level one
+func (t *thriftWriter) begin() {
+	t.last = append(t.last, 0)
+}
+
+// Ends a struct
+func (t *thriftWriter) end() {
+	t.WriteByte(0)
+	t.last = t.last[:len(t.last)-1]
+}
+
+// Writes the header of a field
+func (t *thriftWriter) field(id int16, fieldType byte) {
+	last := &t.last[len(t.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		t.WriteByte(fieldType)
+		t.varint(int64(id))
+	}
+	*last = id
+}
+
+// Writes a zigzag encoded integer, as the i32 and i64 values
+func (t *thriftWriter) varint(v int64) {
+	var b [binary.MaxVarintLen64]byte
+	t.Write(b[:binary.PutVarint(b[:], v)])
+}
+
+// Writes a length prefixed string, as the binary values
+func (t *thriftWriter) string(s string) {
+	var b [binary.MaxVarintLen64]byte
+	t.Write(b[:binary.PutUvarint(b[:], uint64(len(s)))])
+	t.WriteString(s)
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.string(s)
+}
+
+// Starts a struct field, ended by end()
+func (t *thriftWriter) structField(id int16) {
+	t.field(id, thriftStruct)
+	t.begin()
+}
+
+// Writes the header of a list field, followed by its elements
+func (t *thriftWriter) list(id int16, elementType byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.WriteByte(byte(size)<<4 | elementType)
+		return
+	}
+	t.WriteByte(0xF0 | elementType)
+	var b [binary.MaxVarintLen64]byte
+	t.Write(b[:binary.PutUvarint(b[:], uint64(size))])
+}
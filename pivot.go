@@ -0,0 +1,92 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Runs the query and returns one row per time and series tags holding the columns of all its series, eg. the
+// fields of SELECT * FROM "prbUsage", "throughput" at the same time in a single row. Null values do not overwrite
+// the values of another series, columns of the same name in several measurements do. Rows are ordered by time
+func (timeserData *TimeSeriesClientData) QueryPivoted(queryStr string) (rows []JsonRow, err error) {
+	response, err := timeserData.Query(queryStr)
+	if err != nil {
+		timeserData.logger().Errorf("Failed to query %v with error %v\n", queryStr, err)
+		return nil, err
+	}
+
+	rows = []JsonRow{}
+	index := make(map[string]JsonRow)
+	for _, result := range response.Results {
+		for _, series := range result.Series {
+			timeColumn := -1
+			for i, column := range series.Columns {
+				if column == "time" {
+					timeColumn = i
+				}
+			}
+			for _, value := range series.Values {
+				var key string
+				if timeColumn >= 0 && timeColumn < len(value) {
+					key = _pivotKey(value[timeColumn], series.Tags)
+				}
+				row, ok := index[key]
+				if !ok || key == "" {
+					row = make(JsonRow, len(series.Columns)+len(series.Tags))
+					for k, v := range series.Tags {
+						row[k] = v
+					}
+					index[key] = row
+					rows = append(rows, row)
+				}
+				for i, column := range series.Columns {
+					if i < len(value) && (value[i] != nil || row[column] == nil) {
+						row[column] = value[i]
+					}
+				}
+			}
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		ti, _ := _toTime(rows[i]["time"])
+		tj, _ := _toTime(rows[j]["time"])
+		return ti.Before(tj)
+	})
+	timeserData.logger().Debugf("TimeSeriesDB QueryPivoted: DB=%v, QueryString=%v, rows=%v\n", timeserData.timeSeriesDbName, queryStr, len(rows))
+	return rows, nil
+}
+
+// Returns the time and tags identifying the row of a series value
+func _pivotKey(t interface{}, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var key strings.Builder
+	fmt.Fprint(&key, t)
+	for _, k := range keys {
+		fmt.Fprintf(&key, "\x00%v=%v", k, tags[k])
+	}
+	return key.String()
+}
@@ -0,0 +1,71 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for merging the series of a query into a row per time
+func TestTimeSeriesDbQueryPivoted(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+			{Name: "prbUsage", Tags: map[string]string{"cell": "c1"}, Columns: []string{"time", "prb", "dl"}, Values: [][]interface{}{
+				{"2024-01-01T00:00:10Z", json.Number("40"), nil},
+				{"2024-01-01T00:00:20Z", json.Number("50"), nil},
+			}},
+			{Name: "throughput", Tags: map[string]string{"cell": "c1"}, Columns: []string{"time", "prb", "dl"}, Values: [][]interface{}{
+				{"2024-01-01T00:00:00Z", nil, json.Number("200")},
+				{"2024-01-01T00:00:10Z", nil, json.Number("100")},
+			}},
+			{Name: "throughput", Tags: map[string]string{"cell": "c2"}, Columns: []string{"time", "prb", "dl"}, Values: [][]interface{}{
+				{"2024-01-01T00:00:10Z", nil, json.Number("300")},
+			}},
+		}}}}, nil
+	}
+
+	rows, err := timeserData.QueryPivoted(`SELECT "prb", "dl" FROM "prbUsage", "throughput" GROUP BY "cell"`)
+	if err != nil {
+		t.Fatalf("QueryPivoted failed: %v", err)
+	}
+	expected := []string{
+		"map[cell:c1 dl:200 prb:<nil> time:2024-01-01T00:00:00Z]",
+		"map[cell:c1 dl:100 prb:40 time:2024-01-01T00:00:10Z]",
+		"map[cell:c2 dl:300 prb:<nil> time:2024-01-01T00:00:10Z]",
+		"map[cell:c1 dl:<nil> prb:50 time:2024-01-01T00:00:20Z]",
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected %v rows, got %v", len(expected), rows)
+	}
+	for i, row := range rows {
+		if fmt.Sprint(row) != expected[i] {
+			t.Errorf("Unexpected row %v, expected %v", row, expected[i])
+		}
+	}
+}
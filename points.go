@@ -0,0 +1,81 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Point written by WritePoints() and WritePointsMixed()
+type Point struct {
+	Measurement string // Measurement of the point, only used by WritePointsMixed()
+	Tags        map[string]string
+	Fields      map[string]interface{}
+	Time        time.Time // Current time when zero
+}
+
+// Writes many points of a measurement as a single batch. Unlike WritePoint(), the error of the write is returned
+func (timeserData *TimeSeriesClientData) WritePoints(measurement string, points []Point) error {
+	named := make([]Point, len(points))
+	for i, point := range points {
+		named[i] = point
+		named[i].Measurement = measurement
+	}
+	return timeserData.WritePointsMixed(named)
+}
+
+// Writes many points, each into its own measurement, as a single batch
+func (timeserData *TimeSeriesClientData) WritePointsMixed(points []Point) (err error) {
+	if len(points) == 0 {
+		return nil
+	}
+	bp, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+		Database:  timeserData.timeSeriesDbName,
+		Precision: timeserData.writePrecision(),
+	})
+
+	now := time.Now()
+	for _, point := range points {
+		fields, err := timeserData.finiteFields(point.Measurement, point.Fields)
+		if err == nil {
+			fields, err = timeserData.schemaFields(point.Measurement, point.Tags, fields)
+		}
+		if err != nil {
+			return err
+		}
+		timestamp := point.Time
+		if timestamp.IsZero() {
+			timestamp = now
+		}
+		pt, err := timesrclient.NewPoint(point.Measurement, point.Tags, fields, timestamp)
+		if err != nil {
+			timeserData.logger().Errorf("Error: %v\n", err.Error())
+			return err
+		}
+		bp.AddPoint(pt)
+	}
+	if err = timeserData.write(bp); err != nil {
+		return err
+	}
+	timeserData.logger().Debugf("TimeSeriesDB WritePoints: DB=%v points=%v\n", timeserData.timeSeriesDbName, len(points))
+	return nil
+}
@@ -0,0 +1,72 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"errors"
+	"fmt"
+	"stslgo"
+	"testing"
+	"time"
+)
+
+// Test function for writing many points in a single batch
+func TestTimeSeriesDbWritePoints(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	at := time.Date(2021, 8, 20, 5, 47, 46, 0, time.UTC)
+	err = timeserData.WritePoints("CellKpi", []stslgo.Point{
+		{Tags: map[string]string{"cellId": "c1"}, Fields: map[string]interface{}{"prb": 1}, Time: at},
+		{Measurement: "Ignored", Tags: map[string]string{"cellId": "c2"}, Fields: map[string]interface{}{"load": 0.5}},
+	})
+	if err != nil {
+		t.Fatalf("Unable to write points with error %v", err)
+	}
+	if writeCalls != 1 || len(writtenPoints) != 2 {
+		t.Fatalf("Expected 2 points in a single write, got %v in %v", len(writtenPoints), writeCalls)
+	}
+	first, _ := writtenPoints[0].Fields()
+	second, _ := writtenPoints[1].Fields()
+	if writtenPoints[0].Name() != "CellKpi" || writtenPoints[1].Name() != "CellKpi" || !writtenPoints[0].Time().Equal(at) ||
+		len(first) != 1 || first["prb"] != int64(1) || len(second) != 1 || second["load"] != 0.5 || writtenPoints[1].Tags()["cellId"] != "c2" {
+		t.Errorf("Unexpected points %v", writtenPoints)
+	}
+
+	// Heterogeneous measurements
+	writtenPoints = nil
+	err = timeserData.WritePointsMixed([]stslgo.Point{
+		{Measurement: "CellKpi", Fields: map[string]interface{}{"prb": 2}},
+		{Measurement: "UeKpi", Fields: map[string]interface{}{"rsrp": -90}},
+	})
+	if err != nil || len(writtenPoints) != 2 || writtenPoints[0].Name() != "CellKpi" || writtenPoints[1].Name() != "UeKpi" {
+		t.Errorf("Unexpected points %v with error %v", writtenPoints, err)
+	}
+
+	if err = timeserData.WritePoints("CellKpi", []stslgo.Point{{Fields: map[string]interface{}{}}}); err == nil {
+		t.Errorf("Expected error for point without fields")
+	}
+	writeErr = errors.New("timeout")
+	if err = timeserData.WritePoints("CellKpi", []stslgo.Point{{Fields: map[string]interface{}{"prb": 3}}}); !stslgo.IsKind(err, stslgo.ErrWriteFailed) {
+		t.Errorf("Expected ErrWriteFailed, got %v", err)
+	}
+}
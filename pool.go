@@ -0,0 +1,88 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Client spreading the queries over several underlying clients, each with its own HTTP connections, so that many
+// concurrent queries (eg. the fan out of an analytics control loop) do not queue on the connections of a single
+// client. Writes and pings use the first client
+type PooledClient struct {
+	clients []TimeSeriesDataGoClient
+	next    uint32
+}
+
+// Creates a pool of size clients from connect
+func NewPooledClient(size int, connect func() (TimeSeriesDataGoClient, error)) (*PooledClient, error) {
+	if size < 1 {
+		return nil, errors.New("Pool size must be at least 1")
+	}
+	pool := &PooledClient{}
+	for i := 0; i < size; i++ {
+		client, err := connect()
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		pool.clients = append(pool.clients, client)
+	}
+	return pool, nil
+}
+
+// Sets the number of clients serving the queries, taken into account by the next CreateTimeSeriesConnection().
+// 1 (default) for a single client
+func (timeserData *TimeSeriesClientData) SetQueryPoolSize(size int) {
+	timeserData.queryPoolSize = size
+}
+
+// Returns the number of clients of the pool
+func (pool *PooledClient) Size() int {
+	return len(pool.clients)
+}
+
+func (pool *PooledClient) Ping(timeout time.Duration) (time.Duration, string, error) {
+	return _ping(pool.clients[0], timeout)
+}
+
+// Runs the query on the next client in turn
+func (pool *PooledClient) Query(q timesrclient.Query) (*timesrclient.Response, error) {
+	n := atomic.AddUint32(&pool.next, 1)
+	return pool.clients[int(n%uint32(len(pool.clients)))].Query(q)
+}
+
+func (pool *PooledClient) Write(bp timesrclient.BatchPoints) error {
+	return pool.clients[0].Write(bp)
+}
+
+// Closes all the clients, returns the first error
+func (pool *PooledClient) Close() (err error) {
+	for _, client := range pool.clients {
+		if cerr := client.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"errors"
+	"stslgo"
+	"sync"
+	"testing"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Mock client counting its requests
+type countingClient struct {
+	lock    sync.Mutex
+	queries int
+	writes  int
+	closed  bool
+}
+
+func (c *countingClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (c *countingClient) Ping(timeout time.Duration) (time.Duration, string, error) {
+	return 0, "1.8.0", nil
+}
+
+func (c *countingClient) Query(q timesrclient.Query) (*timesrclient.Response, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.queries++
+	return &timesrclient.Response{}, nil
+}
+
+func (c *countingClient) Write(bp timesrclient.BatchPoints) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.writes++
+	return nil
+}
+
+// Test function for spreading the queries over a pool of clients
+func TestTimeSeriesDbPooledClient(t *testing.T) {
+	clients := []*countingClient{}
+	pool, err := stslgo.NewPooledClient(3, func() (stslgo.TimeSeriesDataGoClient, error) {
+		client := &countingClient{}
+		clients = append(clients, client)
+		return client, nil
+	})
+	if err != nil {
+		t.Fatalf("Unable to create pool with error %v", err)
+	}
+	if pool.Size() != 3 {
+		t.Errorf("Expected 3 clients, got %v", pool.Size())
+	}
+
+	timeserData := stslgo.NewTimeSeriesClientData("testdb", "", "")
+	timeserData.Iclient = pool
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			timeserData.Query("SELECT * FROM CellKpi")
+		}()
+	}
+	wg.Wait()
+	for i, client := range clients {
+		if client.queries != 10 {
+			t.Errorf("Expected 10 queries on client %v, got %v", i, client.queries)
+		}
+	}
+
+	timeserData.WritePoints("CellKpi", []stslgo.Point{{Fields: map[string]interface{}{"prb": 1}}})
+	if clients[0].writes != 1 {
+		t.Errorf("Expected the write on the first client")
+	}
+	pool.Close()
+	for i, client := range clients {
+		if !client.closed {
+			t.Errorf("Expected client %v closed", i)
+		}
+	}
+
+	// Clients already created are closed on failure
+	clients = nil
+	calls := 0
+	_, err = stslgo.NewPooledClient(2, func() (stslgo.TimeSeriesDataGoClient, error) {
+		if calls++; calls == 2 {
+			return nil, errors.New("refused")
+		}
+		client := &countingClient{}
+		clients = append(clients, client)
+		return client, nil
+	})
+	if err == nil || !clients[0].closed {
+		t.Errorf("Expected error and the first client closed, got %v", err)
+	}
+	if _, err = stslgo.NewPooledClient(0, nil); err == nil {
+		t.Errorf("Expected error for an empty pool")
+	}
+}
@@ -0,0 +1,111 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Timeout of the health check when the context passed to Preflight has no deadline
+const preflightPingTimeout = 5 * time.Second
+
+// Lists all the checks which failed in Preflight
+type PreflightError struct {
+	Failures []string
+}
+
+func (e *PreflightError) Error() string {
+	return "TimeSeriesDB preflight failed: " + strings.Join(e.Failures, "; ")
+}
+
+// Checks in one call that the TimeSeriesDB is healthy, the credentials are accepted, the database exists
+// and can be read. All the checks are run, returns nil or a *PreflightError describing every check which failed
+func (timeserData *TimeSeriesClientData) Preflight(ctx context.Context) error {
+	failures := []string{}
+	fail := func(format string, args ...interface{}) {
+		failures = append(failures, fmt.Sprintf(format, args...))
+	}
+	done := func() error {
+		if len(failures) == 0 {
+			timeserData.logger().Infof("TimeSeriesDB preflight successful for DB %v\n", timeserData.timeSeriesDbName)
+			return nil
+		}
+		err := &PreflightError{Failures: failures}
+		timeserData.logger().Errorf("%v\n", err)
+		return err
+	}
+
+	if timeserData.Iclient == nil {
+		fail("not connected, call CreateTimeSeriesConnection first")
+		return done()
+	}
+
+	// Health, checked with the queries below by the clients which cannot ping
+	if _, ok := timeserData.Iclient.(pinger); ok {
+		timeout := preflightPingTimeout
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+		if _, _, err := _ping(timeserData.Iclient, timeout); err != nil {
+			fail("health check failed: %v", err)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		fail("cancelled: %v", err)
+		return done()
+	}
+
+	// Credentials, InfluxDB 1.x has no organizations so the user is what gets resolved
+	userName, _ := timeserData.credentials()
+	response, err := timeserData.query(timesrclient.NewQuery("SHOW DATABASES", "", ""))
+	if err != nil {
+		fail("credentials of user %q rejected: %v", userName, err)
+	} else {
+		// Database
+		found := false
+		for _, result := range response.Results {
+			for _, row := range result.Series {
+				for _, value := range row.Values {
+					if len(value) > 0 && value[0] == timeserData.timeSeriesDbName {
+						found = true
+					}
+				}
+			}
+		}
+		if !found {
+			fail("database %q not found", timeserData.timeSeriesDbName)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		fail("cancelled: %v", err)
+		return done()
+	}
+
+	// Authorized read
+	if _, err = timeserData.query(timesrclient.NewQuery("SHOW MEASUREMENTS LIMIT 1", timeserData.timeSeriesDbName, "")); err != nil {
+		fail("read of database %q not authorized for user %q: %v", timeserData.timeSeriesDbName, userName, err)
+	}
+	return done()
+}
@@ -0,0 +1,95 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"stslgo"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Mocks SHOW DATABASES listing the databases and SHOW MEASUREMENTS failing with readErr
+func preflightResp(databases []string, showErr, readErr error) func(q timesrclient.Query) (*timesrclient.Response, error) {
+	return func(q timesrclient.Query) (*timesrclient.Response, error) {
+		if strings.HasPrefix(q.Command, "SHOW DATABASES") {
+			if showErr != nil {
+				return nil, showErr
+			}
+			var values [][]interface{}
+			for _, db := range databases {
+				values = append(values, []interface{}{db})
+			}
+			return seriesResp("databases", []string{"name"}, values...), nil
+		}
+		if readErr != nil {
+			return &timesrclient.Response{Err: readErr.Error()}, nil
+		}
+		return &timesrclient.Response{Results: []timesrclient.Result{{}}}, nil
+	}
+}
+
+// Test function for the preflight check on valid and broken setups
+func TestTimeSeriesDbPreflight(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	queryResp = preflightResp([]string{"_internal", "testdb"}, nil, nil)
+	if err = timeserData.Preflight(context.Background()); err != nil {
+		t.Errorf("Expected preflight to pass, got %v", err)
+	}
+
+	broken := []struct {
+		name     string
+		pingErr  error
+		dbs      []string
+		showErr  error
+		readErr  error
+		expected string
+	}{
+		{"unhealthy", errors.New("connection refused"), nil, nil, nil, "health check failed: connection refused"},
+		{"bad credentials", nil, nil, errors.New("authorization failed"), nil, `credentials of user "testuser" rejected`},
+		{"missing database", nil, []string{"_internal"}, nil, nil, `database "testdb" not found`},
+		{"read not allowed", nil, []string{"testdb"}, nil, errors.New("not authorized"), `read of database "testdb" not authorized`},
+	}
+	for _, tc := range broken {
+		pingErr = tc.pingErr
+		queryResp = preflightResp(tc.dbs, tc.showErr, tc.readErr)
+		err = timeserData.Preflight(context.Background())
+		if err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("%v: expected error containing %q, got %v", tc.name, tc.expected, err)
+		}
+	}
+
+	// Every failing check is reported
+	pingErr = errors.New("connection refused")
+	queryResp = preflightResp([]string{"_internal"}, nil, errors.New("not authorized"))
+	err = timeserData.Preflight(context.Background())
+	if preflightErr, ok := err.(*stslgo.PreflightError); !ok || len(preflightErr.Failures) != 3 {
+		t.Errorf("Expected the 3 failing checks reported, got %v", err)
+	}
+}
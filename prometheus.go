@@ -0,0 +1,250 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Maximum size of a decompressed Prometheus remote write request
+const promMaxDecodedBytes = 128 << 20
+
+// Maximum size of a Prometheus remote write request body, well beyond the batches Prometheus sends
+const promMaxBodyBytes = 8 << 20
+
+var errPromTruncated = errors.New("Truncated Prometheus remote write request")
+
+// Writes the samples of a Prometheus remote write request (snappy compressed protobuf WriteRequest), eg. of the
+// node exporters of the RIC cluster, as InfluxDB does: the measurement is the metric name, the other labels are
+// tags and the sample is the value field. NaN samples, such as the staleness markers, are not written.
+// Returns the number of points written. See also POST /api/v1/prom/write of the HTTPHandler
+func (timeserData *TimeSeriesClientData) WritePrometheus(payload []byte) (points int, err error) {
+	request, err := _snappyDecode(payload)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid Prometheus remote write request: %v", err)
+	}
+	bp, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+		Database:  timeserData.timeSeriesDbName,
+		Precision: timeserData.writePrecision(),
+	})
+
+	err = _protoFields(request, func(field int, value uint64, data []byte) error {
+		if field != 1 {
+			// Metadata
+			return nil
+		}
+		var name string
+		tags := make(map[string]string)
+		var samples [][]byte
+		err := _protoFields(data, func(field int, value uint64, data []byte) error {
+			switch field {
+			case 1:
+				var labelName, labelValue string
+				err := _protoFields(data, func(field int, value uint64, data []byte) error {
+					if field == 1 {
+						labelName = string(data)
+					} else if field == 2 {
+						labelValue = string(data)
+					}
+					return nil
+				})
+				if labelName == "__name__" {
+					name = labelValue
+				} else if labelName != "" {
+					tags[labelName] = labelValue
+				}
+				return err
+			case 2:
+				samples = append(samples, data)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			return errors.New("Prometheus time series without __name__ label")
+		}
+		for _, sample := range samples {
+			var v float64
+			var timestamp int64
+			err := _protoFields(sample, func(field int, value uint64, data []byte) error {
+				if field == 1 {
+					v = math.Float64frombits(value)
+				} else if field == 2 {
+					timestamp = int64(value)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if math.IsNaN(v) {
+				continue
+			}
+			fields, err := timeserData.finiteFields(name, map[string]interface{}{"value": v})
+			if err != nil {
+				return err
+			}
+			if len(fields) == 0 {
+				continue
+			}
+			pt, err := timesrclient.NewPoint(name, tags, fields, time.Unix(0, timestamp*int64(time.Millisecond)))
+			if err != nil {
+				return err
+			}
+			bp.AddPoint(pt)
+		}
+		return nil
+	})
+	if err != nil {
+		timeserData.logger().Errorf("Failed to decode Prometheus remote write request: %v\n", err)
+		return 0, err
+	}
+	if len(bp.Points()) == 0 {
+		return 0, nil
+	}
+	if err = timeserData.write(bp); err != nil {
+		return 0, err
+	}
+	timeserData.logger().Debugf("TimeSeriesDB WritePrometheus: DB=%v points=%v\n", timeserData.timeSeriesDbName, len(bp.Points()))
+	return len(bp.Points()), nil
+}
+
+// Calls fn with the number of each field of a protobuf message and its value, the varint or fixed value in value,
+// the bytes of a length delimited value in data
+func _protoFields(message []byte, fn func(field int, value uint64, data []byte) error) error {
+	for len(message) > 0 {
+		key, n := binary.Uvarint(message)
+		if n <= 0 {
+			return errPromTruncated
+		}
+		message = message[n:]
+		var value uint64
+		var data []byte
+		switch key & 7 {
+		case 0:
+			if value, n = binary.Uvarint(message); n <= 0 {
+				return errPromTruncated
+			}
+			message = message[n:]
+		case 1:
+			if len(message) < 8 {
+				return errPromTruncated
+			}
+			value, message = binary.LittleEndian.Uint64(message), message[8:]
+		case 2:
+			length, n := binary.Uvarint(message)
+			if n <= 0 || length > uint64(len(message)-n) {
+				return errPromTruncated
+			}
+			data, message = message[n:n+int(length)], message[n+int(length):]
+		case 5:
+			if len(message) < 4 {
+				return errPromTruncated
+			}
+			value, message = uint64(binary.LittleEndian.Uint32(message)), message[4:]
+		default:
+			return fmt.Errorf("Unsupported protobuf wire type %v", key&7)
+		}
+		if err := fn(int(key>>3), value, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decodes a snappy block, as the body of the Prometheus remote write requests
+func _snappyDecode(src []byte) ([]byte, error) {
+	length, n := binary.Uvarint(src)
+	if n <= 0 || length > promMaxDecodedBytes {
+		return nil, errors.New("invalid snappy length")
+	}
+	src = src[n:]
+	// The length is not trusted for the allocation, the buffer grows as the data is decoded
+	capacity := uint64(4 * len(src))
+	if capacity > length {
+		capacity = length
+	}
+	dst := make([]byte, 0, capacity)
+	for len(src) > 0 {
+		tag := src[0]
+		var literal, copyLength, offset int
+		switch tag & 3 {
+		case 0:
+			literal = int(tag>>2) + 1
+			src = src[1:]
+			if extra := literal - 60; extra > 0 {
+				// Length - 1 in the next 1 to 4 bytes
+				if len(src) < extra {
+					return nil, errPromTruncated
+				}
+				var b [4]byte
+				copy(b[:], src[:extra])
+				literal = int(binary.LittleEndian.Uint32(b[:])) + 1
+				src = src[extra:]
+			}
+			if literal <= 0 || literal > len(src) || uint64(len(dst)+literal) > length {
+				return nil, errPromTruncated
+			}
+			dst = append(dst, src[:literal]...)
+			src = src[literal:]
+			continue
+		case 1:
+			if len(src) < 2 {
+				return nil, errPromTruncated
+			}
+			copyLength = 4 + int(tag>>2&7)
+			offset = int(tag&0xe0)<<3 | int(src[1])
+			src = src[2:]
+		case 2:
+			if len(src) < 3 {
+				return nil, errPromTruncated
+			}
+			copyLength = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint16(src[1:]))
+			src = src[3:]
+		case 3:
+			if len(src) < 5 {
+				return nil, errPromTruncated
+			}
+			copyLength = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint32(src[1:]))
+			src = src[5:]
+		}
+		if offset <= 0 || offset > len(dst) || uint64(len(dst)+copyLength) > length {
+			return nil, errors.New("invalid snappy copy")
+		}
+		// Byte by byte, the copy may overlap what it appends
+		for i := 0; i < copyLength; i++ {
+			dst = append(dst, dst[len(dst)-offset])
+		}
+	}
+	if uint64(len(dst)) != length {
+		return nil, errors.New("snappy length mismatch")
+	}
+	return dst, nil
+}
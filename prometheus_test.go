@@ -0,0 +1,117 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+// Appends a protobuf length delimited field
+func protoBytes(message []byte, field int, data []byte) []byte {
+	message = appendUvarint(message, uint64(field<<3|2))
+	message = appendUvarint(message, uint64(len(data)))
+	return append(message, data...)
+}
+
+// Returns a Prometheus time series with a sample
+func promSeries(labels map[string]string, value float64, timestamp int64) []byte {
+	var series []byte
+	for name, value := range labels {
+		series = protoBytes(series, 1, protoBytes(protoBytes(nil, 1, []byte(name)), 2, []byte(value)))
+	}
+	sample := make([]byte, 9)
+	sample[0] = 1<<3 | 1
+	binary.LittleEndian.PutUint64(sample[1:], math.Float64bits(value))
+	sample = appendUvarint(append(sample, 2<<3), uint64(timestamp))
+	return protoBytes(series, 2, sample)
+}
+
+// Compresses as a single snappy literal
+func snappyLiteral(data []byte) []byte {
+	block := appendUvarint(nil, uint64(len(data)))
+	block = append(block, 61<<2, byte(len(data)-1), byte((len(data)-1)>>8))
+	return append(block, data...)
+}
+
+// Test function for writing Prometheus remote write requests
+func TestTimeSeriesDbWritePrometheus(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	var request []byte
+	request = protoBytes(request, 1, promSeries(map[string]string{"__name__": "node_load1", "instance": "node-1"}, 0.5, 1629438466000))
+	request = protoBytes(request, 1, promSeries(map[string]string{"__name__": "up", "job": "node"}, math.NaN(), 1629438466000))
+
+	handler := timeserData.NewHTTPHandler("")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/prom/write", bytes.NewReader(snappyLiteral(request))))
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("Unexpected status %v: %v", recorder.Code, recorder.Body.String())
+	}
+	if len(writtenPoints) != 1 {
+		t.Fatalf("Expected the NaN sample skipped, got %v", writtenPoints)
+	}
+	pt := writtenPoints[0]
+	fields, _ := pt.Fields()
+	if pt.Name() != "node_load1" || pt.Tags()["instance"] != "node-1" || len(pt.Tags()) != 1 || fields["value"] != 0.5 ||
+		!pt.Time().Equal(time.Unix(1629438466, 0)) {
+		t.Errorf("Unexpected point %v", pt)
+	}
+
+	for _, payload := range [][]byte{[]byte("not snappy"), snappyLiteral(request[:len(request)-3]),
+		snappyLiteral(protoBytes(nil, 1, promSeries(map[string]string{"job": "node"}, 1, 0)))} {
+		if _, err = timeserData.WritePrometheus(payload); err == nil {
+			t.Errorf("Expected an error for payload %q", payload)
+		}
+	}
+
+	// The length announced by the payload is not allocated up front
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	allocated := stats.TotalAlloc
+	if _, err = timeserData.WritePrometheus(append(appendUvarint(nil, 100<<20), 0, 'x')); err == nil {
+		t.Errorf("Expected an error for a payload shorter than announced")
+	}
+	runtime.ReadMemStats(&stats)
+	if stats.TotalAlloc-allocated > 1<<20 {
+		t.Errorf("Expected no allocation of the announced length, %v bytes allocated", stats.TotalAlloc-allocated)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/prom/write", bytes.NewReader(make([]byte, 9<<20))))
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "too large") {
+		t.Errorf("Expected an oversized body rejected, got %v: %v", recorder.Code, recorder.Body.String())
+	}
+}
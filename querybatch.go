@@ -0,0 +1,69 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"sync"
+)
+
+// Maximum number of queries of a QueryBatch running at the same time
+const queryBatchWorkers = 8
+
+// Executes the queries concurrently and returns their rows and errors index-aligned with the queries.
+// The queries run within ctx, which carries their spans and read routing (see ReadFromPrimary()).
+// Queries not yet started when ctx is done fail with the context error
+func (timeserData *TimeSeriesClientData) QueryBatch(ctx context.Context, queries []string) ([][]JsonRow, []error) {
+	results := make([][]JsonRow, len(queries))
+	errs := make([]error, len(queries))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	workers := queryBatchWorkers
+	if len(queries) < workers {
+		workers = len(queries)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				response, err := timeserData.QueryContext(ctx, queries[i])
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				results[i] = _jsonRows(response)
+			}
+		}()
+	}
+	for i := range queries {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	timeserData.logger().Debugf("TimeSeriesDB QueryBatch: DB=%v queries=%v, errors=%v\n", timeserData.timeSeriesDbName, len(queries), errs)
+	return results, errs
+}
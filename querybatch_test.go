@@ -0,0 +1,81 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for concurrent queries returning index-aligned results and errors
+func TestTimeSeriesDbQueryBatch(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	// Each query returns its own measurement name as value, except the failing one
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		name := q.Command[strings.LastIndex(q.Command, " ")+1:]
+		if name == "Broken" {
+			return nil, errors.New("query failed")
+		}
+		return seriesResp(name, []string{"time", "name"}, []interface{}{"2021-08-20T05:47:46Z", name}), nil
+	}
+
+	var queries []string
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("Table%v", i)
+		if i == 7 {
+			name = "Broken"
+		}
+		queries = append(queries, "SELECT * FROM "+name)
+	}
+
+	results, errs := timeserData.QueryBatch(context.Background(), queries)
+	if len(results) != len(queries) || len(errs) != len(queries) {
+		t.Fatalf("Results not aligned with queries: %v %v", len(results), len(errs))
+	}
+	for i := range queries {
+		if i == 7 {
+			if errs[i] == nil || results[i] != nil {
+				t.Errorf("Expected error only for query %v, got %v %v", i, results[i], errs[i])
+			}
+			continue
+		}
+		if errs[i] != nil || len(results[i]) != 1 || results[i][0]["name"] != fmt.Sprintf("Table%v", i) {
+			t.Errorf("Unexpected result for query %v: %v %v", i, results[i], errs[i])
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, errs = timeserData.QueryBatch(ctx, queries[:3])
+	for i, err := range errs {
+		if err != context.Canceled {
+			t.Errorf("Expected cancelled query %v, got %v", i, err)
+		}
+	}
+}
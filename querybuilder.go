@@ -0,0 +1,268 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Aggregate functions accepted by QueryBuilder.Aggregate
+var queryAggregates = map[string]bool{
+	"count": true, "distinct": true, "integral": true, "mean": true, "median": true, "mode": true,
+	"spread": true, "stddev": true, "sum": true, "first": true, "last": true, "max": true, "min": true,
+}
+
+// Comparison operators accepted by QueryBuilder.Filter, regex operators take a *regexp source string
+var queryOperators = map[string]bool{
+	"=": true, "!=": true, "<>": true, "<": true, "<=": true, ">": true, ">=": true, "=~": true, "!~": true,
+}
+
+// Fluent builder of InfluxQL SELECT statements. Identifiers and values are quoted and escaped, so they can
+// come from untrusted input (eg. E2 node IDs). Errors are reported by Build()
+type QueryBuilder struct {
+	database    string
+	measurement string
+	fields      []string
+	conditions  []string
+	start, stop string
+	aggregate   string
+	every       time.Duration
+	groupBy     []string
+	fill        string
+	desc        bool
+	limit       int
+	errs        []string
+}
+
+// Starts building a query
+func NewQuery() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+// Selects the database, by default the database of the client running the query is used
+func (b *QueryBuilder) From(database string) *QueryBuilder {
+	b.database = database
+	return b
+}
+
+// Selects the measurement to query
+func (b *QueryBuilder) Measurement(measurement string) *QueryBuilder {
+	b.measurement = measurement
+	return b
+}
+
+// Adds fields to the selection, all fields are selected if none is added
+func (b *QueryBuilder) Field(fields ...string) *QueryBuilder {
+	b.fields = append(b.fields, fields...)
+	return b
+}
+
+// Restricts to the points of the last d, d can be negative as in -1*time.Hour
+func (b *QueryBuilder) Range(d time.Duration) *QueryBuilder {
+	if d < 0 {
+		d = -d
+	}
+	b.start = "now() - " + _durationLiteral(d)
+	b.stop = ""
+	return b
+}
+
+// Restricts to the points in [start, stop), a zero stop means no upper bound
+func (b *QueryBuilder) Between(start, stop time.Time) *QueryBuilder {
+	b.start = _quoteLiteral(start.UTC().Format(time.RFC3339Nano))
+	b.stop = ""
+	if !stop.IsZero() {
+		b.stop = _quoteLiteral(stop.UTC().Format(time.RFC3339Nano))
+	}
+	return b
+}
+
+// Adds a condition on a tag or field, conditions are combined with AND. value is a string, number or bool,
+// and the source of the regular expression for =~ and !~
+func (b *QueryBuilder) Filter(key, op string, value interface{}) *QueryBuilder {
+	if !queryOperators[op] {
+		b.errs = append(b.errs, fmt.Sprintf("unsupported operator %q", op))
+		return b
+	}
+	var literal string
+	switch v := value.(type) {
+	case string:
+		if op == "=~" || op == "!~" {
+			literal = "/" + strings.Replace(v, "/", `\/`, -1) + "/"
+		} else {
+			literal = _quoteLiteral(v)
+		}
+	case bool:
+		literal = strconv.FormatBool(v)
+	case float64:
+		literal = strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		literal = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		literal = fmt.Sprint(v)
+	default:
+		b.errs = append(b.errs, fmt.Sprintf("unsupported value %v for %v", value, key))
+		return b
+	}
+	b.conditions = append(b.conditions, fmt.Sprintf("%v %v %v", _quoteIdent(key), op, literal))
+	return b
+}
+
+// Shorthand for Filter(tag, "=", value)
+func (b *QueryBuilder) Where(tag, value string) *QueryBuilder {
+	return b.Filter(tag, "=", value)
+}
+
+// Applies the aggregate function (eg. mean, max) to the fields, over windows of every if not 0
+func (b *QueryBuilder) Aggregate(function string, every time.Duration) *QueryBuilder {
+	if !queryAggregates[strings.ToLower(function)] {
+		b.errs = append(b.errs, fmt.Sprintf("unsupported aggregate %q", function))
+		return b
+	}
+	b.aggregate = strings.ToLower(function)
+	b.every = every
+	return b
+}
+
+// Fills the empty aggregation windows: null, none, previous, linear or a number
+func (b *QueryBuilder) Fill(fill string) *QueryBuilder {
+	if !_validFill(fill) {
+		b.errs = append(b.errs, fmt.Sprintf("unsupported fill %q", fill))
+		return b
+	}
+	b.fill = fill
+	return b
+}
+
+// Groups the results by the tags
+func (b *QueryBuilder) GroupBy(tags ...string) *QueryBuilder {
+	b.groupBy = append(b.groupBy, tags...)
+	return b
+}
+
+// Returns the newest points first
+func (b *QueryBuilder) Desc() *QueryBuilder {
+	b.desc = true
+	return b
+}
+
+// Limits the number of points returned per series
+func (b *QueryBuilder) Limit(n int) *QueryBuilder {
+	b.limit = n
+	return b
+}
+
+// Generates the InfluxQL statement
+func (b *QueryBuilder) Build() (string, error) {
+	if b.measurement == "" {
+		b.errs = append(b.errs, "no measurement")
+	}
+	if b.fill != "" && b.every == 0 {
+		b.errs = append(b.errs, "fill needs an aggregate window")
+	}
+	if len(b.errs) > 0 {
+		return "", errors.New("Invalid query: " + strings.Join(b.errs, ", "))
+	}
+
+	selection := []string{}
+	for _, field := range b.fields {
+		if b.aggregate != "" {
+			selection = append(selection, fmt.Sprintf("%v(%v) AS %v", b.aggregate, _quoteIdent(field), _quoteIdent(field)))
+		} else {
+			selection = append(selection, _quoteIdent(field))
+		}
+	}
+	if len(selection) == 0 {
+		if b.aggregate != "" {
+			selection = append(selection, b.aggregate+"(*)")
+		} else {
+			selection = append(selection, "*")
+		}
+	}
+
+	source := _quoteIdent(b.measurement)
+	if b.database != "" {
+		source = _quoteIdent(b.database) + ".." + source
+	}
+	query := fmt.Sprintf("SELECT %v FROM %v", strings.Join(selection, ", "), source)
+
+	conditions := append([]string{}, b.conditions...)
+	if b.start != "" {
+		conditions = append(conditions, "time >= "+b.start)
+	}
+	if b.stop != "" {
+		conditions = append(conditions, "time < "+b.stop)
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	groups := []string{}
+	if b.aggregate != "" && b.every > 0 {
+		groups = append(groups, fmt.Sprintf("time(%v)", _durationLiteral(b.every)))
+	}
+	for _, tag := range b.groupBy {
+		groups = append(groups, _quoteIdent(tag))
+	}
+	if len(groups) > 0 {
+		query += " GROUP BY " + strings.Join(groups, ", ")
+	}
+	if b.fill != "" {
+		query += " fill(" + b.fill + ")"
+	}
+	if b.desc {
+		query += " ORDER BY time DESC"
+	}
+	if b.limit > 0 {
+		query += fmt.Sprintf(" LIMIT %v", b.limit)
+	}
+	return query, nil
+}
+
+// Formats a duration as InfluxQL duration literal, using the largest exact unit
+func _durationLiteral(d time.Duration) string {
+	units := []struct {
+		suffix string
+		unit   time.Duration
+	}{
+		{"w", 7 * 24 * time.Hour}, {"d", 24 * time.Hour}, {"h", time.Hour}, {"m", time.Minute},
+		{"s", time.Second}, {"ms", time.Millisecond}, {"u", time.Microsecond},
+	}
+	for _, u := range units {
+		if d%u.unit == 0 {
+			return fmt.Sprintf("%v%v", int64(d/u.unit), u.suffix)
+		}
+	}
+	return fmt.Sprintf("%vns", int64(d))
+}
+
+// Checks a fill strategy of the empty aggregation windows: null, none, previous, linear or a number
+func _validFill(fill string) bool {
+	switch fill {
+	case "null", "none", "previous", "linear":
+		return true
+	}
+	_, err := strconv.ParseFloat(fill, 64)
+	return err == nil
+}
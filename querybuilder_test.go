@@ -0,0 +1,72 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"stslgo"
+	"testing"
+	"time"
+)
+
+// Test function for generating InfluxQL with the query builder
+func TestTimeSeriesDbQueryBuilder(t *testing.T) {
+	cases := []struct {
+		builder  *stslgo.QueryBuilder
+		expected string
+	}{
+		{
+			stslgo.NewQuery().From("testdb").Range(-1*time.Hour).Measurement("kpm").Field("prbUsage").
+				Filter("cellId", "=", "555001").Aggregate("mean", 5*time.Minute),
+			`SELECT mean("prbUsage") AS "prbUsage" FROM "testdb".."kpm" WHERE "cellId" = '555001' AND time >= now() - 1h GROUP BY time(5m)`,
+		},
+		{
+			stslgo.NewQuery().Measurement("kpm").Filter("thp", ">", 10.5).GroupBy("cellId").Desc().Limit(3),
+			`SELECT * FROM "kpm" WHERE "thp" > 10.5 GROUP BY "cellId" ORDER BY time DESC LIMIT 3`,
+		},
+		{
+			stslgo.NewQuery().Measurement("kpm").Field("a").Aggregate("max", 90*time.Second).Fill("previous").
+				Between(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)),
+			`SELECT max("a") AS "a" FROM "kpm" WHERE time >= '2022-01-01T00:00:00Z' AND time < '2022-01-02T00:00:00Z' GROUP BY time(90s) fill(previous)`,
+		},
+		// Untrusted input stays inside its quotes
+		{
+			stslgo.NewQuery().Measurement(`kpm"; DROP DATABASE x`).Where("node", `a' OR 'b'='b`).Filter("name", "=~", "^gnb/1"),
+			`SELECT * FROM "kpm\"; DROP DATABASE x" WHERE "node" = 'a\' OR \'b\'=\'b' AND "name" =~ /^gnb\/1/`,
+		},
+	}
+	for i, tc := range cases {
+		query, err := tc.builder.Build()
+		if err != nil || query != tc.expected {
+			t.Errorf("Case %v: expected %q, got %q with error %v", i, tc.expected, query, err)
+		}
+	}
+
+	invalid := []*stslgo.QueryBuilder{
+		stslgo.NewQuery().Field("a"),
+		stslgo.NewQuery().Measurement("kpm").Aggregate("mean); DROP", time.Minute),
+		stslgo.NewQuery().Measurement("kpm").Filter("a", "OR", 1),
+		stslgo.NewQuery().Measurement("kpm").Fill("linear"),
+	}
+	for i, b := range invalid {
+		if query, err := b.Build(); err == nil {
+			t.Errorf("Invalid case %v: expected error, got %q", i, query)
+		}
+	}
+}
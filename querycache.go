@@ -0,0 +1,53 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Serves the repeated SELECT and SHOW queries from a cache of up to size responses, each kept for ttl, for
+// dashboards running the same aggregates every few seconds. Queries are keyed on their database, retention
+// policy, precision, parameters and statement with the whitespace collapsed. The writes of the client and its
+// other statements (eg. DELETE) clear the cache. The cached responses are shared and must not be modified.
+// Chunked queries and those of a ReadFromPrimary() context, as the reads of the SDL storage, are not cached.
+// 0 size disables the cache. To be set before use
+func (timeserData *TimeSeriesClientData) SetQueryCache(size int, ttl time.Duration) {
+	if size <= 0 || ttl <= 0 {
+		timeserData.queryCache = nil
+		return
+	}
+	timeserData.queryCache = newLRUCache(size, ttl)
+}
+
+// Returns the key of a query in the cache, empty for the queries which are not cached
+func _queryCacheKey(ctx context.Context, q timesrclient.Query) string {
+	if q.Chunked || ctx.Value(readFromPrimaryKey{}) != nil || !_readOnly(q.Command) {
+		return ""
+	}
+	command := strings.TrimRight(strings.Join(strings.Fields(q.Command), " "), "; ")
+	// Maps are printed sorted by key
+	return fmt.Sprintf("%v\x00%v\x00%v\x00%v\x00%v", q.Database, q.RetentionPolicy, q.Precision, q.Parameters, command)
+}
@@ -0,0 +1,94 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"stslgo"
+)
+
+// Test function for serving repeated read queries from the cache
+func TestTimeSeriesDbQueryCache(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	timeserData.SetQueryCache(2, 50*time.Millisecond)
+
+	issuedQueries = nil
+	timeserData.Query(`SELECT MEAN("rsrp") FROM "cells" WHERE time > now() - 1m GROUP BY time(10s)`)
+	response, err := timeserData.Query("SELECT MEAN(\"rsrp\")  FROM \"cells\"\n\tWHERE time > now() - 1m GROUP BY time(10s);")
+	if err != nil || response == nil {
+		t.Fatalf("Unexpected response %v with error %v", response, err)
+	}
+	if len(issuedQueries) != 1 {
+		t.Errorf("Expected the normalized query to be cached, got %v", issuedQueries)
+	}
+
+	// Other databases, writes and ReadFromPrimary() are not served from the cache
+	issuedQueries = nil
+	timeserData.QueryFrom("other", `SELECT MEAN("rsrp") FROM "cells" WHERE time > now() - 1m GROUP BY time(10s)`)
+	timeserData.Query(`DELETE FROM "cells"`)
+	timeserData.Query(`DELETE FROM "cells"`)
+	timeserData.QueryContext(stslgo.ReadFromPrimary(context.Background()), `SELECT MEAN("rsrp") FROM "cells" WHERE time > now() - 1m GROUP BY time(10s)`)
+	if len(issuedQueries) != 4 {
+		t.Errorf("Expected 4 queries, got %v", issuedQueries)
+	}
+
+	// Least recently used evicted
+	issuedQueries = nil
+	timeserData.Query("SHOW MEASUREMENTS")
+	timeserData.Query("SHOW TAG KEYS")
+	timeserData.Query(`SELECT MEAN("rsrp") FROM "cells" WHERE time > now() - 1m GROUP BY time(10s)`)
+	if len(issuedQueries) != 3 {
+		t.Errorf("Expected the evicted query to be issued again, got %v", issuedQueries)
+	}
+
+	// Expiry
+	issuedQueries = nil
+	time.Sleep(60 * time.Millisecond)
+	timeserData.Query("SHOW TAG KEYS")
+	if len(issuedQueries) != 1 {
+		t.Errorf("Expected the expired query to be issued again, got %v", issuedQueries)
+	}
+
+	// Deletes invalidate
+	issuedQueries = nil
+	timeserData.DropMeasurement("cells")
+	timeserData.Query("SHOW TAG KEYS")
+	if len(issuedQueries) != 2 {
+		t.Errorf("Expected the query to be issued again after the delete, got %v", issuedQueries)
+	}
+
+	// So do the writes and the statements other than reads
+	issuedQueries = nil
+	timeserData.WritePoint("cells", nil, map[string]interface{}{"rsrp": -90})
+	timeserData.Query("SHOW TAG KEYS")
+	timeserData.Query(`CREATE RETENTION POLICY "rp_1d" ON "testdb" DURATION 1d REPLICATION 1`)
+	timeserData.Query("SHOW TAG KEYS")
+	if len(issuedQueries) != 3 {
+		t.Errorf("Expected the query to be issued again after the write and the statement, got %v", issuedQueries)
+	}
+	timeserData.SetQueryCache(0, 0)
+}
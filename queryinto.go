@@ -0,0 +1,194 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrNotStructSlicePointer = errors.New("Destination is not a pointer to a slice of structs")
+
+// Runs the query and appends one struct per result row to dest, a pointer to a slice of structs (or of pointers
+// to structs). Columns and tags are matched by the ts struct tags as used by WriteStruct, `ts:"time"` gets the time
+func (timeserData *TimeSeriesClientData) QueryInto(queryStr string, dest interface{}) (err error) {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return ErrNotStructSlicePointer
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return ErrNotStructSlicePointer
+	}
+
+	rows, err := timeserData.QueryRows(queryStr)
+	if err != nil {
+		timeserData.logger().Errorf("Failed to query %v with error %v\n", queryStr, err)
+		return err
+	}
+	for _, row := range rows {
+		item := reflect.New(elemType)
+		if err = _scanRow(row, item.Elem()); err != nil {
+			return err
+		}
+		if isPtr {
+			slice.Set(reflect.Append(slice, item))
+		} else {
+			slice.Set(reflect.Append(slice, item.Elem()))
+		}
+	}
+	timeserData.logger().Debugf("TimeSeriesDB QueryInto: DB=%v, QueryString=%v, rows=%v\n", timeserData.timeSeriesDbName, queryStr, len(rows))
+	return nil
+}
+
+// Sets the ts tagged fields of the struct from the row
+func _scanRow(row JsonRow, value reflect.Value) error {
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		sf := structType.Field(i)
+		spec, ok := sf.Tag.Lookup(structTagKey)
+		if !ok || spec == "-" || sf.PkgPath != "" {
+			continue
+		}
+		parts := strings.SplitN(spec, ",", 2)
+		name := sf.Name
+		if len(parts) == 2 && parts[0] != "" {
+			name = parts[0]
+		} else if len(parts) == 1 && parts[0] == "time" {
+			name = "time"
+		}
+
+		column, ok := row[name]
+		if !ok || column == nil {
+			continue
+		}
+		if err := _assignValue(value.Field(i), column); err != nil {
+			return fmt.Errorf("Struct field %v from %v=%v: %v", sf.Name, name, column, err)
+		}
+	}
+	return nil
+}
+
+// Assigns a value decoded by the TimeSeriesDB client to a struct field. Numbers not representable by the
+// field, eg. fractional for an integer or out of its range, are errors rather than truncated
+func _assignValue(fv reflect.Value, v interface{}) error {
+	if fv.Kind() == reflect.Ptr {
+		elem := reflect.New(fv.Type().Elem())
+		if err := _assignValue(elem.Elem(), v); err != nil {
+			return err
+		}
+		fv.Set(elem)
+		return nil
+	}
+	if fv.Type() == reflect.TypeOf(time.Time{}) {
+		t, err := _toTime(v)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	str := fmt.Sprint(v)
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(str)
+	case reflect.Bool:
+		b, ok := v.(bool)
+		if !ok {
+			var err error
+			if b, err = strconv.ParseBool(str); err != nil {
+				return err
+			}
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			f, ferr := _toFloat64(v)
+			if ferr != nil {
+				return err
+			}
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return fmt.Errorf("Value %v is not an integer of %v", v, fv.Type())
+			}
+			n = int64(f)
+		}
+		if fv.OverflowInt(n) {
+			return fmt.Errorf("Value %v overflows %v", v, fv.Type())
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			f, ferr := _toFloat64(v)
+			if ferr != nil {
+				return err
+			}
+			if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+				return fmt.Errorf("Value %v is not an integer of %v", v, fv.Type())
+			}
+			n = uint64(f)
+		}
+		if fv.OverflowUint(n) {
+			return fmt.Errorf("Value %v overflows %v", v, fv.Type())
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := _toFloat64(v)
+		if err != nil {
+			return err
+		}
+		if fv.OverflowFloat(f) {
+			return fmt.Errorf("Value %v overflows %v", v, fv.Type())
+		}
+		fv.SetFloat(f)
+	default:
+		return fmt.Errorf("Unsupported field type %v", fv.Type())
+	}
+	return nil
+}
+
+// Converts a numeric value returned by the TimeSeriesDB client to float64
+func _toFloat64(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case json.Number:
+		return n.Float64()
+	case float64:
+		return n, nil
+	case string:
+		return strconv.ParseFloat(n, 64)
+	}
+	if n, ok := _toInt64(v); ok {
+		return float64(n), nil
+	}
+	return 0, fmt.Errorf("Not a number: %v", v)
+}
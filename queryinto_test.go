@@ -0,0 +1,105 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"stslgo"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for decoding query results into structs
+func TestTimeSeriesDbQueryInto(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		result := timesrclient.Result{}
+		result.Series = append(result.Series, models.Row{
+			Name:    "KpiTable",
+			Tags:    map[string]string{"cellId": "555001"},
+			Columns: []string{"time", "prbUsage", "ActiveUes", "degraded"},
+			Values: [][]interface{}{
+				{"2022-03-01T10:00:00Z", json.Number("42.5"), json.Number("12"), true},
+				{"2022-03-01T10:00:01Z", json.Number("43"), json.Number("13"), nil},
+			},
+		})
+		return &timesrclient.Response{Results: []timesrclient.Result{result}}, nil
+	}
+
+	var kpis []cellKpi
+	if err = timeserData.QueryInto(`SELECT * FROM "KpiTable" GROUP BY "cellId"`, &kpis); err != nil {
+		t.Fatalf("Unable to query into structs with error %v", err)
+	}
+	if len(kpis) != 2 {
+		t.Fatalf("Expected 2 structs, got %v", kpis)
+	}
+	first := kpis[0]
+	if first.CellId != "555001" || first.PrbUsage != 42.5 || first.ActiveUes != 12 || first.Degraded == nil || !*first.Degraded {
+		t.Errorf("Unexpected struct %+v", first)
+	}
+	if !first.Collected.Equal(time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected time %v", first.Collected)
+	}
+	if kpis[1].Degraded != nil || kpis[1].PrbUsage != 43 {
+		t.Errorf("Unexpected struct %+v", kpis[1])
+	}
+
+	var ptrs []*cellKpi
+	if err = timeserData.QueryInto(`SELECT * FROM "KpiTable"`, &ptrs); err != nil || len(ptrs) != 2 || ptrs[1].ActiveUes != 13 {
+		t.Errorf("Unexpected pointer results %v with error %v", ptrs, err)
+	}
+
+	if err = timeserData.QueryInto(`SELECT * FROM "KpiTable"`, kpis); err != stslgo.ErrNotStructSlicePointer {
+		t.Errorf("Expected ErrNotStructSlicePointer, got %v", err)
+	}
+
+	// Integral floats, eg. of aggregates, fit integer fields, fractional or out of range values do not
+	for _, test := range []struct {
+		ues      json.Number
+		expected uint32
+		fails    bool
+	}{
+		{json.Number("14.0"), 14, false},
+		{json.Number("12.7"), 0, true},
+		{json.Number("-1"), 0, true},
+		{json.Number("4294967296"), 0, true},
+	} {
+		queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+			return seriesResp("KpiTable", []string{"time", "ActiveUes"}, []interface{}{"2022-03-01T10:00:00Z", test.ues}), nil
+		}
+		kpis = nil
+		err = timeserData.QueryInto(`SELECT * FROM "KpiTable"`, &kpis)
+		if test.fails {
+			if err == nil {
+				t.Errorf("Expected %v to fail, got %+v", test.ues, kpis)
+			}
+		} else if err != nil || len(kpis) != 1 || kpis[0].ActiveUes != test.expected {
+			t.Errorf("Unexpected structs %+v for %v with error %v", kpis, test.ues, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"fmt"
+	"strings"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Default number of rows fetched per query by QueryStream
+const DefaultPageSize = 10000
+
+// Iterator over the rows of a query fetched page by page, see QueryStream()
+type RowIterator struct {
+	timeserData *TimeSeriesClientData
+	queryStr    string
+	pageSize    int
+	offset      int
+	page        []JsonRow
+	row         JsonRow
+	last        bool
+	err         error
+}
+
+// Runs a SELECT query page by page with LIMIT and OFFSET, so that large results are not held in memory at once.
+// The query must not have its own LIMIT or OFFSET. With GROUP BY the pages apply per series, and the rows of
+// the series come interleaved page by page. pageSize 0 stands for DefaultPageSize
+func (timeserData *TimeSeriesClientData) QueryStream(queryStr string, pageSize int) *RowIterator {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return &RowIterator{
+		timeserData: timeserData,
+		queryStr:    strings.TrimRight(strings.TrimSpace(queryStr), ";"),
+		pageSize:    pageSize,
+	}
+}
+
+// Calls fn with each row of a query fetched page by page as by QueryStream(), until fn returns an error
+func (timeserData *TimeSeriesClientData) QueryEach(queryStr string, pageSize int, fn func(row JsonRow) error) error {
+	it := timeserData.QueryStream(queryStr, pageSize)
+	for it.Next() {
+		if err := fn(it.Row()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// Advances to the next row, fetching the next page when needed. Returns false at the end or on error
+func (it *RowIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.last || it.err != nil {
+			it.row = nil
+			return false
+		}
+		it.fetch()
+	}
+	it.row, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Returns the current row
+func (it *RowIterator) Row() JsonRow {
+	return it.row
+}
+
+// Returns the error which ended the iteration, if any
+func (it *RowIterator) Err() error {
+	return it.err
+}
+
+// Fetches the next page of rows
+func (it *RowIterator) fetch() {
+	queryStr := fmt.Sprintf("%v LIMIT %v OFFSET %v", it.queryStr, it.pageSize, it.offset)
+	q := timesrclient.NewQuery(queryStr, it.timeserData.timeSeriesDbName, "")
+	response, err := it.timeserData.query(q)
+	if err != nil {
+		it.timeserData.logger().Errorf("Failed to query page at offset %v with error %v\n", it.offset, err)
+		it.err = err
+		return
+	}
+
+	// The last page is short for every series
+	it.last = true
+	for _, result := range response.Results {
+		for _, series := range result.Series {
+			if len(series.Values) >= it.pageSize {
+				it.last = false
+			}
+		}
+	}
+	it.page = _jsonRows(response)
+	it.offset += it.pageSize
+	it.timeserData.logger().Debugf("TimeSeriesDB QueryStream: DB=%v, QueryString=%v, rows=%v\n", it.timeserData.timeSeriesDbName, queryStr, len(it.page))
+}
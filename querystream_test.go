@@ -0,0 +1,95 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"stslgo"
+	"testing"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for iterating over the rows of a query page by page
+func TestTimeSeriesDbQueryStream(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	// 7 rows served by LIMIT and OFFSET
+	page := regexp.MustCompile(`LIMIT (\d+) OFFSET (\d+)$`)
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		match := page.FindStringSubmatch(q.Command)
+		limit, _ := strconv.Atoi(match[1])
+		offset, _ := strconv.Atoi(match[2])
+		var values [][]interface{}
+		for i := offset; i < 7 && i < offset+limit; i++ {
+			values = append(values, []interface{}{i})
+		}
+		return seriesResp("CellKpi", []string{"prb"}, values...), nil
+	}
+
+	it := timeserData.QueryStream("SELECT prb FROM CellKpi;", 3)
+	count := 0
+	for it.Next() {
+		if it.Row()["prb"] != count {
+			t.Errorf("Unexpected row %v at %v", it.Row(), count)
+		}
+		count++
+	}
+	if it.Err() != nil || count != 7 {
+		t.Errorf("Expected 7 rows, got %v with error %v", count, it.Err())
+	}
+	expected := []string{
+		"SELECT prb FROM CellKpi LIMIT 3 OFFSET 0",
+		"SELECT prb FROM CellKpi LIMIT 3 OFFSET 3",
+		"SELECT prb FROM CellKpi LIMIT 3 OFFSET 6",
+	}
+	if fmt.Sprint(issuedQueries) != fmt.Sprint(expected) {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+
+	// Callback variant stopped by the callback
+	stop := errors.New("stop")
+	count = 0
+	err = timeserData.QueryEach("SELECT prb FROM CellKpi", 2, func(row stslgo.JsonRow) error {
+		count++
+		if count == 4 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || count != 4 {
+		t.Errorf("Expected stop after 4 rows, got %v with error %v", count, err)
+	}
+
+	// Query failure
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return nil, errors.New("timeout")
+	}
+	it = timeserData.QueryStream("SELECT prb FROM CellKpi", 0)
+	if it.Next() || it.Err() == nil {
+		t.Errorf("Expected query error")
+	}
+}
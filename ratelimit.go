@@ -0,0 +1,157 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Handling of the writes exceeding the WriteLimits
+type WriteLimitPolicy int
+
+const (
+	WriteLimitBlock WriteLimitPolicy = iota // Wait until the write is within the limits (default)
+	WriteLimitDrop                          // Drop the points of the write with ErrWriteLimited
+)
+
+var ErrWriteLimited = errors.New("Write dropped by the write limits")
+
+// Limits of the writes of a client, so that a flood of points (eg. from a misbehaving E2 node) cannot overwhelm
+// TimeSeriesDB or exhaust the memory of the xApp. Zero values mean no limit
+type WriteLimits struct {
+	PointsPerSecond float64                         // Sustained rate of written points
+	Burst           int                             // Points which can be written at once above the rate, PointsPerSecond when 0
+	MaxInFlight     int                             // Writes to TimeSeriesDB in progress at the same time
+	Policy          WriteLimitPolicy                // Waiting or dropping when a limit is reached
+	OnDrop          func(points int, reason string) // Called when points are dropped with WriteLimitDrop
+}
+
+// Token bucket of the points and semaphore of the writes in progress
+type writeLimiter struct {
+	limits   WriteLimits
+	lock     sync.Mutex
+	tokens   float64
+	last     time.Time
+	inFlight chan struct{}
+}
+
+// Limits the rate and concurrency of the writes. Points dropped are also reported to the MetricsHook, with the
+// reason rate_limit or in_flight_limit. BatchWriters keep the batches dropped as failed writes for the next flush.
+// To be set before writing
+func (timeserData *TimeSeriesClientData) SetWriteLimits(limits WriteLimits) {
+	if limits.PointsPerSecond <= 0 && limits.MaxInFlight <= 0 {
+		timeserData.limiter = nil
+		return
+	}
+	if limits.Burst <= 0 {
+		limits.Burst = int(limits.PointsPerSecond)
+		if limits.Burst < 1 {
+			limits.Burst = 1
+		}
+	}
+	limiter := &writeLimiter{limits: limits, tokens: float64(limits.Burst), last: time.Now()}
+	if limits.MaxInFlight > 0 {
+		limiter.inFlight = make(chan struct{}, limits.MaxInFlight)
+	}
+	timeserData.limiter = limiter
+}
+
+// Waits for or fails a write of points as per the limits, release() has to be called once the write is done
+func (timeserData *TimeSeriesClientData) acquireWrite(ctx context.Context, points int) (release func(), err error) {
+	limiter := timeserData.limiter
+	if limiter == nil {
+		return func() {}, nil
+	}
+	if !limiter.take(ctx, points) {
+		return nil, timeserData.dropWrite(ctx, limiter, points, "rate_limit")
+	}
+	if limiter.inFlight == nil {
+		return func() {}, nil
+	}
+	if limiter.limits.Policy == WriteLimitDrop {
+		select {
+		case limiter.inFlight <- struct{}{}:
+		default:
+			return nil, timeserData.dropWrite(ctx, limiter, points, "in_flight_limit")
+		}
+	} else {
+		select {
+		case limiter.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return func() { <-limiter.inFlight }, nil
+}
+
+// Reports the points of a write over the limits as dropped, unless ctx ended while waiting
+func (timeserData *TimeSeriesClientData) dropWrite(ctx context.Context, limiter *writeLimiter, points int, reason string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	timeserData.logger().Warnf("TimeSeriesDB write of %v points dropped: %v\n", points, reason)
+	timeserData.reportDropped(points, reason)
+	if onDrop := limiter.limits.OnDrop; onDrop != nil {
+		onDrop(points, reason)
+	}
+	return ErrWriteLimited
+}
+
+// Takes the tokens of the points, waiting for them with WriteLimitBlock. Writes larger than the burst wait for
+// a full bucket and leave it in debt
+func (limiter *writeLimiter) take(ctx context.Context, points int) bool {
+	if limiter.limits.PointsPerSecond <= 0 {
+		return true
+	}
+	need := float64(points)
+	if need > float64(limiter.limits.Burst) {
+		need = float64(limiter.limits.Burst)
+	}
+	for {
+		limiter.lock.Lock()
+		now := time.Now()
+		limiter.tokens += now.Sub(limiter.last).Seconds() * limiter.limits.PointsPerSecond
+		if limiter.tokens > float64(limiter.limits.Burst) {
+			limiter.tokens = float64(limiter.limits.Burst)
+		}
+		limiter.last = now
+		if limiter.tokens >= need {
+			limiter.tokens -= float64(points)
+			limiter.lock.Unlock()
+			return true
+		}
+		wait := time.Duration((need - limiter.tokens) / limiter.limits.PointsPerSecond * float64(time.Second))
+		limiter.lock.Unlock()
+		if limiter.limits.Policy == WriteLimitDrop {
+			return false
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		}
+	}
+}
@@ -0,0 +1,134 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"context"
+	"fmt"
+	"stslgo"
+	"testing"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Mock client whose writes wait until released
+type blockingClient struct {
+	MockClient
+	started chan bool
+	release chan bool
+}
+
+func (c *blockingClient) Write(bp timesrclient.BatchPoints) error {
+	c.started <- true
+	<-c.release
+	return c.MockClient.Write(bp)
+}
+
+// Test function for dropping the writes above the rate limit
+func TestTimeSeriesDbWriteLimitsRateDrop(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	droppedPoints := 0
+	timeserData.SetWriteLimits(stslgo.WriteLimits{
+		PointsPerSecond: 1, Burst: 2, Policy: stslgo.WriteLimitDrop,
+		OnDrop: func(points int, reason string) {
+			if reason == "rate_limit" {
+				droppedPoints += points
+			}
+		},
+	})
+
+	points := []stslgo.Point{{Fields: map[string]interface{}{"prb": 1}}, {Fields: map[string]interface{}{"prb": 2}}}
+	if err = timeserData.WritePoints("CellKpi", points); err != nil {
+		t.Fatalf("Unable to write the burst with error %v", err)
+	}
+	if err = timeserData.WritePoints("CellKpi", points[:1]); err != stslgo.ErrWriteLimited {
+		t.Errorf("Expected ErrWriteLimited, got %v", err)
+	}
+	if len(writtenPoints) != 2 || droppedPoints != 1 {
+		t.Errorf("Expected 2 points written and 1 dropped, got %v and %v", len(writtenPoints), droppedPoints)
+	}
+}
+
+// Test function for waiting for the rate limit
+func TestTimeSeriesDbWriteLimitsRateBlock(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	timeserData.SetWriteLimits(stslgo.WriteLimits{PointsPerSecond: 100, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err = timeserData.WritePoints("CellKpi", []stslgo.Point{{Fields: map[string]interface{}{"prb": i}}}); err != nil {
+			t.Fatalf("Unable to write point with error %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 25*time.Millisecond || len(writtenPoints) != 4 {
+		t.Errorf("Expected 4 points written at 100 per second, got %v in %v", len(writtenPoints), elapsed)
+	}
+
+	// Waiting ends with the context
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	timeserData.SetWriteLimits(stslgo.WriteLimits{PointsPerSecond: 0.1, Burst: 1})
+	writtenPoints = nil
+	start = time.Now()
+	timeserData.WritePointContext(ctx, "CellKpi", nil, map[string]interface{}{"prb": 1})
+	timeserData.WritePointContext(ctx, "CellKpi", nil, map[string]interface{}{"prb": 2})
+	if elapsed := time.Since(start); elapsed > time.Second || len(writtenPoints) != 1 {
+		t.Errorf("Expected the second write to end with the context, got %v points in %v", len(writtenPoints), elapsed)
+	}
+}
+
+// Test function for limiting the writes in progress
+func TestTimeSeriesDbWriteLimitsInFlight(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	client := &blockingClient{started: make(chan bool), release: make(chan bool)}
+	timeserData.Iclient = client
+	timeserData.SetWriteLimits(stslgo.WriteLimits{MaxInFlight: 1, Policy: stslgo.WriteLimitDrop})
+
+	done := make(chan error)
+	go func() {
+		done <- timeserData.WritePoints("CellKpi", []stslgo.Point{{Fields: map[string]interface{}{"prb": 1}}})
+	}()
+	<-client.started
+	if err = timeserData.WritePoints("CellKpi", []stslgo.Point{{Fields: map[string]interface{}{"prb": 2}}}); err != stslgo.ErrWriteLimited {
+		t.Errorf("Expected ErrWriteLimited while a write is in progress, got %v", err)
+	}
+	client.release <- true
+	if err = <-done; err != nil {
+		t.Errorf("Unable to write point with error %v", err)
+	}
+
+	go func() {
+		<-client.started
+		client.release <- true
+	}()
+	if err = timeserData.WritePoints("CellKpi", []stslgo.Point{{Fields: map[string]interface{}{"prb": 3}}}); err != nil {
+		t.Errorf("Expected the write to succeed once the slot is released, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Timeout of the periodic health check pings
+const healthCheckTimeout = 5 * time.Second
+
+// Health check of the clients which support it, as the clients of TimeSeriesDB and of this package do
+type pinger interface {
+	Ping(timeout time.Duration) (time.Duration, string, error)
+}
+
+// Pings the client, or checks it with a query when it cannot ping. Returns the round trip time and the version
+// of TimeSeriesDB, unknown without ping
+func _ping(client TimeSeriesDataGoClient, timeout time.Duration) (time.Duration, string, error) {
+	if p, ok := client.(pinger); ok {
+		return p.Ping(timeout)
+	}
+	start := time.Now()
+	response, err := client.Query(timesrclient.NewQuery("SHOW DATABASES", "", ""))
+	if err == nil {
+		err = response.Error()
+	}
+	return time.Since(start), "", err
+}
+
+// Health checking of the connection and backoff of the reconnection when the health check fails
+type ReconnectPolicy struct {
+	HealthCheckInterval time.Duration // Interval of the health checks, 0 disables reconnection
+	MinBackoff          time.Duration // Delay after the first failed reconnection, doubled after each failure
+	MaxBackoff          time.Duration // Upper limit of the delay between reconnections
+}
+
+var DefaultReconnectPolicy = ReconnectPolicy{
+	HealthCheckInterval: 30 * time.Second,
+	MinBackoff:          time.Second,
+	MaxBackoff:          30 * time.Second,
+}
+
+var ErrClientClosed = errors.New("TimeSeriesDB client closed")
+
+// Client which stays usable across reconnections: it checks the health of the underlying client periodically
+// and replaces it with a new one from connect when the check fails
+type ReconnectingClient struct {
+	lock      sync.RWMutex
+	client    TimeSeriesDataGoClient
+	connect   func() (TimeSeriesDataGoClient, error)
+	policy    ReconnectPolicy
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	logger    Logger
+}
+
+// Connects using connect and starts the health checks as per policy. Without a positive MinBackoff and
+// MaxBackoff, those of DefaultReconnectPolicy are used
+func NewReconnectingClient(connect func() (TimeSeriesDataGoClient, error), policy ReconnectPolicy) (*ReconnectingClient, error) {
+	return newReconnectingClient(connect, policy, zerologLogger{})
+}
+
+func newReconnectingClient(connect func() (TimeSeriesDataGoClient, error), policy ReconnectPolicy, logger Logger) (*ReconnectingClient, error) {
+	client, err := connect()
+	if err != nil {
+		return nil, err
+	}
+	if policy.MinBackoff <= 0 || policy.MaxBackoff <= 0 {
+		policy.MinBackoff, policy.MaxBackoff = DefaultReconnectPolicy.MinBackoff, DefaultReconnectPolicy.MaxBackoff
+	}
+	rc := &ReconnectingClient{
+		client:  client,
+		connect: connect,
+		policy:  policy,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		logger:  logger,
+	}
+	if policy.HealthCheckInterval > 0 {
+		go rc.run()
+	} else {
+		close(rc.done)
+	}
+	return rc, nil
+}
+
+func (rc *ReconnectingClient) current() TimeSeriesDataGoClient {
+	rc.lock.RLock()
+	defer rc.lock.RUnlock()
+	return rc.client
+}
+
+func (rc *ReconnectingClient) Ping(timeout time.Duration) (time.Duration, string, error) {
+	return _ping(rc.current(), timeout)
+}
+
+func (rc *ReconnectingClient) Query(q timesrclient.Query) (*timesrclient.Response, error) {
+	return rc.current().Query(q)
+}
+
+func (rc *ReconnectingClient) Write(bp timesrclient.BatchPoints) error {
+	return rc.current().Write(bp)
+}
+
+// Stops the health checks and closes the underlying client
+func (rc *ReconnectingClient) Close() (err error) {
+	err = ErrClientClosed
+	rc.closeOnce.Do(func() {
+		close(rc.stop)
+		<-rc.done
+		err = rc.current().Close()
+	})
+	return err
+}
+
+// Replaces the underlying client with a new one from connect now, eg. after a change of credentials
+func (rc *ReconnectingClient) Reconnect() error {
+	client, err := rc.connect()
+	if err != nil {
+		return err
+	}
+	rc.lock.Lock()
+	old := rc.client
+	rc.client = client
+	rc.lock.Unlock()
+	return old.Close()
+}
+
+func (rc *ReconnectingClient) run() {
+	defer close(rc.done)
+	ticker := time.NewTicker(rc.policy.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-rc.stop:
+			return
+		case <-ticker.C:
+		}
+		if _, _, err := _ping(rc.current(), healthCheckTimeout); err != nil {
+			rc.logger.Warnf("TimeSeriesDB health check failed, reconnecting: %v\n", err)
+			rc.reconnect()
+		}
+	}
+}
+
+// Replaces the client until the new one passes the health check, backing off between attempts
+func (rc *ReconnectingClient) reconnect() {
+	backoff := rc.policy.MinBackoff
+	for attempt := 1; ; attempt++ {
+		client, err := rc.connect()
+		if err == nil {
+			if _, _, err = _ping(client, healthCheckTimeout); err == nil {
+				rc.lock.Lock()
+				old := rc.client
+				rc.client = client
+				rc.lock.Unlock()
+				_ = old.Close()
+				rc.logger.Infof("TimeSeriesDB reconnected after %v attempts\n", attempt)
+				return
+			}
+			_ = client.Close()
+		}
+		rc.logger.Warnf("TimeSeriesDB reconnection attempt %v failed: %v, retrying in %v\n", attempt, err, backoff)
+		select {
+		case <-rc.stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > rc.policy.MaxBackoff {
+			backoff = rc.policy.MaxBackoff
+		}
+	}
+}
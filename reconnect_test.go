@@ -0,0 +1,155 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"errors"
+	"stslgo"
+	"sync"
+	"testing"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Client failing its health check once marked down
+type flakyClient struct {
+	lock   sync.Mutex
+	id     int
+	down   bool
+	closed bool
+}
+
+func (c *flakyClient) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *flakyClient) Ping(timeout time.Duration) (time.Duration, string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.down {
+		return 0, "", errors.New("connection refused")
+	}
+	return 0, "1.8.0", nil
+}
+
+func (c *flakyClient) Query(q timesrclient.Query) (*timesrclient.Response, error) {
+	return &timesrclient.Response{Err: string(rune('0' + c.id))}, nil
+}
+
+func (c *flakyClient) Write(bp timesrclient.BatchPoints) error {
+	return nil
+}
+
+// Test function for the reconnection after a failed health check
+func TestTimeSeriesDbReconnect(t *testing.T) {
+	var lock sync.Mutex
+	var clients []*flakyClient
+	connectFails := 1
+	connect := func() (stslgo.TimeSeriesDataGoClient, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if len(clients) == 1 && connectFails > 0 {
+			connectFails--
+			return nil, errors.New("dial failed")
+		}
+		c := &flakyClient{id: len(clients)}
+		clients = append(clients, c)
+		return c, nil
+	}
+
+	rc, err := stslgo.NewReconnectingClient(connect, stslgo.ReconnectPolicy{
+		HealthCheckInterval: 10 * time.Millisecond,
+		MinBackoff:          5 * time.Millisecond,
+		MaxBackoff:          20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Unable to connect with error %v", err)
+	}
+	if resp, _ := rc.Query(timesrclient.Query{}); resp.Err != "0" {
+		t.Fatalf("Expected first client used, got %v", resp.Err)
+	}
+
+	clients[0].lock.Lock()
+	clients[0].down = true
+	clients[0].lock.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if resp, _ := rc.Query(timesrclient.Query{}); resp.Err == "1" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Client not replaced after failed health check")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err = rc.Close(); err != nil {
+		t.Errorf("Close failed with error %v", err)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	for i, c := range clients {
+		c.lock.Lock()
+		if !c.closed {
+			t.Errorf("Client %v not closed", i)
+		}
+		c.lock.Unlock()
+	}
+	if rc.Close() != stslgo.ErrClientClosed {
+		t.Errorf("Expected ErrClientClosed on second Close")
+	}
+}
+
+// Test function for the default backoff of a policy without one
+func TestTimeSeriesDbReconnectDefaultBackoff(t *testing.T) {
+	var lock sync.Mutex
+	first := &flakyClient{}
+	attempts := 0
+	connect := func() (stslgo.TimeSeriesDataGoClient, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		attempts++
+		if attempts == 1 {
+			return first, nil
+		}
+		return nil, errors.New("dial failed")
+	}
+
+	rc, err := stslgo.NewReconnectingClient(connect, stslgo.ReconnectPolicy{HealthCheckInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Unable to connect with error %v", err)
+	}
+	first.lock.Lock()
+	first.down = true
+	first.lock.Unlock()
+
+	time.Sleep(200 * time.Millisecond)
+	lock.Lock()
+	if attempts > 3 {
+		t.Errorf("Expected the reconnections to back off, got %v attempts", attempts)
+	}
+	lock.Unlock()
+	_ = rc.Close()
+}
@@ -0,0 +1,84 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Writes recorded points again with their timestamps shifted to now, eg. to test a control xApp against a recorded
+// KPI stream. The oldest point is written right away and the others as the time between them elapses, divided by
+// speed (eg. 10 for 10 times faster), their timestamps being the times they are written, those of the same time
+// together. With speed 0 (or less) all the points are written at once, in a single write shifted to now with their
+// original spacing. The points are written as WritePointsMixed(). Returns the number of points written once all
+// are, or on error or ctx done
+func (timeserData *TimeSeriesClientData) Replay(ctx context.Context, points []Point, speed float64) (written int, err error) {
+	sorted := append([]Point(nil), points...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+	if len(sorted) == 0 {
+		return 0, nil
+	}
+
+	origin, start := sorted[0].Time, time.Now()
+	if speed <= 0 {
+		// At once, in a single write
+		if err = ctx.Err(); err != nil {
+			return 0, err
+		}
+		for i := range sorted {
+			sorted[i].Time = start.Add(sorted[i].Time.Sub(origin))
+		}
+		if err = timeserData.WritePointsMixed(sorted); err != nil {
+			timeserData.logger().Errorf("Failed to replay points with error %v\n", err)
+			return 0, err
+		}
+		timeserData.logger().Debugf("TimeSeriesDB Replay: DB=%v points=%v, speed=%v\n", timeserData.timeSeriesDbName, len(sorted), speed)
+		return len(sorted), nil
+	}
+
+	due := func(point Point) time.Time {
+		return start.Add(time.Duration(float64(point.Time.Sub(origin)) / speed))
+	}
+	for i := 0; i < len(sorted); {
+		t := due(sorted[i])
+		batch := []Point{}
+		for ; i < len(sorted) && due(sorted[i]).Equal(t); i++ {
+			point := sorted[i]
+			point.Time = t
+			batch = append(batch, point)
+		}
+		timer := time.NewTimer(time.Until(t))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return written, ctx.Err()
+		}
+		if err = timeserData.WritePointsMixed(batch); err != nil {
+			timeserData.logger().Errorf("Failed to replay points at %v with error %v\n", t, err)
+			return written, err
+		}
+		written += len(batch)
+	}
+	timeserData.logger().Debugf("TimeSeriesDB Replay: DB=%v points=%v, speed=%v\n", timeserData.timeSeriesDbName, written, speed)
+	return written, nil
+}
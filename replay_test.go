@@ -0,0 +1,80 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"stslgo"
+)
+
+// Test function for replaying recorded points shifted to now
+func TestTimeSeriesDbReplay(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	recorded := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	points := []stslgo.Point{
+		{Measurement: "CellKpi", Fields: map[string]interface{}{"prb": 3}, Time: recorded.Add(2 * time.Second)},
+		{Measurement: "CellKpi", Fields: map[string]interface{}{"prb": 1}, Time: recorded},
+		{Measurement: "UeKpi", Fields: map[string]interface{}{"thp": 1}, Time: recorded},
+		{Measurement: "CellKpi", Fields: map[string]interface{}{"prb": 2}, Time: recorded.Add(time.Second)},
+	}
+
+	// All at once, keeping the spacing
+	before := time.Now()
+	if written, err := timeserData.Replay(context.Background(), points, 0); err != nil || written != 4 {
+		t.Errorf("Expected 4 points replayed, got %v with error %v", written, err)
+	}
+	if writeCalls != 1 || len(writtenPoints) != 4 {
+		t.Fatalf("Expected 4 points in a single write, got %v in %v", len(writtenPoints), writeCalls)
+	}
+	first := writtenPoints[0].Time()
+	if first.Before(before) || !writtenPoints[1].Time().Equal(first) || writtenPoints[3].Time().Sub(first) != 2*time.Second {
+		t.Errorf("Unexpected times %v", writtenPoints)
+	}
+	if fields, _ := writtenPoints[3].Fields(); fields["prb"] != int64(3) {
+		t.Errorf("Unexpected order %v", writtenPoints)
+	}
+
+	// 100 times faster, 20ms in total
+	writtenPoints = nil
+	before = time.Now()
+	if written, err := timeserData.Replay(context.Background(), points, 100); err != nil || written != 4 {
+		t.Errorf("Expected 4 points replayed, got %v with error %v", written, err)
+	}
+	if elapsed := time.Since(before); elapsed < 20*time.Millisecond {
+		t.Errorf("Replay took %v, expected at least 20ms", elapsed)
+	}
+	if len(writtenPoints) != 4 || writtenPoints[3].Time().Sub(writtenPoints[0].Time()) != 20*time.Millisecond {
+		t.Errorf("Unexpected times %v", writtenPoints)
+	}
+
+	// Stopped by the context
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if written, err := timeserData.Replay(ctx, points, 1); err != context.DeadlineExceeded || written != 2 {
+		t.Errorf("Expected 2 points replayed before the deadline, got %v with error %v", written, err)
+	}
+}
@@ -0,0 +1,116 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+type readFromPrimaryKey struct{}
+
+// Sends the SELECT and SHOW queries to another TimeSeriesDB instance than the writes, eg. a replica, so that
+// heavy analytic queries do not slow down the ingestion of real-time KPIs. The port is the one of the primary
+// when empty. Taken into account by the next CreateTimeSeriesConnection(), with the same credentials and TLS
+func (timeserData *TimeSeriesClientData) SetReadEndpoint(host, port string) {
+	timeserData.readHost = host
+	timeserData.readPort = port
+}
+
+// Returns a context whose queries (eg. QueryContext()) go to the primary even with a read endpoint, for reads
+// which have to see the latest writes
+func ReadFromPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readFromPrimaryKey{}, true)
+}
+
+// Connects to the read endpoint, with the scheme of the primary address
+func (timeserData *TimeSeriesClientData) connectReadEndpoint(primaryAddr string) error {
+	primary, err := url.Parse(primaryAddr)
+	if err != nil {
+		return err
+	}
+	port := timeserData.readPort
+	if port == "" {
+		port = primary.Port()
+	}
+	addr := fmt.Sprintf("%v://%v:%v", primary.Scheme, timeserData.readHost, port)
+	client, err := newReconnectingClient(timeserData.connector(addr), timeserData.reconnectPolicy, timeserData.logger())
+	if err != nil {
+		return err
+	}
+	timeserData.readClient = client
+	timeserData.logger().Infof("TimeSeriesDB read Client created successfully: %v\n", addr)
+	return nil
+}
+
+// Returns the client running a query: the read endpoint for reads unless ctx asks for the primary
+func (timeserData *TimeSeriesClientData) queryClient(ctx context.Context, q timesrclient.Query) TimeSeriesDataGoClient {
+	if timeserData.readClient == nil || ctx.Value(readFromPrimaryKey{}) != nil {
+		return timeserData.Iclient
+	}
+	if _readOnly(q.Command) {
+		return timeserData.readClient
+	}
+	return timeserData.Iclient
+}
+
+// Re-creates the connections, eg. after a change of credentials
+func (timeserData *TimeSeriesClientData) reconnect() error {
+	for _, client := range []TimeSeriesDataGoClient{timeserData.Iclient, timeserData.readClient} {
+		if rc, ok := client.(*ReconnectingClient); ok {
+			if err := rc.Reconnect(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Whether all the statements of a query are SELECT without INTO or SHOW. A ; within a literal only makes the
+// query go to the primary
+func _readOnly(command string) bool {
+	for _, statement := range strings.Split(command, ";") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		operation := _statementOperation(statement)
+		if strings.HasPrefix(operation, "SHOW ") {
+			continue
+		}
+		if operation != "SELECT" || _containsWord(strings.ToUpper(statement), "INTO") {
+			return false
+		}
+	}
+	return true
+}
+
+// Whether the words of text include word
+func _containsWord(text, word string) bool {
+	for _, w := range strings.Fields(text) {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
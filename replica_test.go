@@ -0,0 +1,116 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"context"
+	"strings"
+	"stslgo"
+	"testing"
+	"time"
+)
+
+// Test function for sending the queries to a read endpoint
+func TestTimeSeriesDbReadEndpoint(t *testing.T) {
+	seen := make(chan string, 10)
+	primary, host, port := optionsServer(t, seen)
+	defer primary.Close()
+	replica, readHost, readPort := optionsServer(t, seen)
+	defer replica.Close()
+
+	noReconnect := stslgo.ReconnectPolicy{}
+	timeserData, err := stslgo.NewTimeSeriesClientWithOptions(stslgo.Options{
+		Host: host, Port: port, ReadHost: readHost, ReadPort: readPort, DbName: "testdb", Reconnect: &noReconnect,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = timeserData.CreateTimeSeriesConnection(); err != nil {
+		t.Fatalf("Unable to connect with error %v", err)
+	}
+	defer timeserData.Close()
+
+	primaryAddr, readAddr := host+":"+port, readHost+":"+readPort
+	for _, request := range []struct {
+		run      func() error
+		expected string
+	}{
+		{func() error { _, err := timeserData.Query("SELECT * FROM CellKpi"); return err }, readAddr},
+		{func() error { _, err := timeserData.ListMeasurements(); return err }, readAddr},
+		{func() error { _, err := timeserData.Query("SELECT * FROM CellKpi; SELECT * FROM UeKpi"); return err }, readAddr},
+		{func() error {
+			_, err := timeserData.QueryContext(stslgo.ReadFromPrimary(context.Background()), "SELECT * FROM CellKpi")
+			return err
+		}, primaryAddr},
+		{func() error {
+			_, errs := timeserData.QueryBatch(stslgo.ReadFromPrimary(context.Background()), []string{"SELECT * FROM CellKpi"})
+			return errs[0]
+		}, primaryAddr},
+		{func() error { _, err := timeserData.Query("SELECT mean(prb) INTO CellKpi_1h FROM CellKpi"); return err }, primaryAddr},
+		{func() error {
+			_, err := timeserData.Query("SELECT * FROM CellKpi; DROP MEASUREMENT CellKpi")
+			return err
+		}, primaryAddr},
+		{timeserData.CreateTimeSeriesDB, primaryAddr},
+		{func() error { return timeserData.WritePoint("CellKpi", nil, map[string]interface{}{"prb": 1}) }, primaryAddr},
+	} {
+		if err = request.run(); err != nil {
+			t.Fatalf("Request failed with error %v", err)
+		}
+		if got := <-seen; !strings.HasPrefix(got, request.expected+" ") {
+			t.Errorf("Expected request to %v, got %v", request.expected, got)
+		}
+	}
+}
+
+// Test function for failing fast on the read endpoint only while it is down
+func TestTimeSeriesDbReadEndpointCircuitBreaker(t *testing.T) {
+	seen := make(chan string, 10)
+	primary, host, port := optionsServer(t, seen)
+	defer primary.Close()
+	replica, readHost, readPort := optionsServer(t, seen)
+
+	noReconnect := stslgo.ReconnectPolicy{}
+	timeserData, err := stslgo.NewTimeSeriesClientWithOptions(stslgo.Options{
+		Host: host, Port: port, ReadHost: readHost, ReadPort: readPort, DbName: "testdb", Reconnect: &noReconnect,
+		CircuitBreaker: stslgo.CircuitBreakerPolicy{FailureThreshold: 1, OpenDuration: time.Hour},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = timeserData.CreateTimeSeriesConnection(); err != nil {
+		t.Fatalf("Unable to connect with error %v", err)
+	}
+	defer timeserData.Close()
+
+	replica.Close()
+	if _, err = timeserData.Query("SELECT * FROM CellKpi"); err == nil || err == stslgo.ErrCircuitOpen {
+		t.Errorf("Expected the query to fail on the read endpoint, got %v", err)
+	}
+	if _, err = timeserData.Query("SELECT * FROM CellKpi"); err != stslgo.ErrCircuitOpen {
+		t.Errorf("Expected the query to fail fast, got %v", err)
+	}
+	if err = timeserData.WritePoint("CellKpi", nil, map[string]interface{}{"prb": 1}); err != nil || timeserData.CircuitState() != stslgo.CircuitClosed {
+		t.Errorf("Expected the primary unaffected, got %v in state %v", err, timeserData.CircuitState())
+	}
+	if _, err = timeserData.QueryContext(stslgo.ReadFromPrimary(context.Background()), "SELECT * FROM CellKpi"); err != nil {
+		t.Errorf("Expected the query to the primary to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"fmt"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Keeps the points of a measurement for duration (eg. 7d, 1h30m, 6mo) instead of the default retention of the DB.
+// The measurement is written to the retention policy rp_<duration>, created if missing, so queries have to
+// select it as "rp_<duration>"."measurement", see RetentionPolicyOf()
+func (timeserData *TimeSeriesClientData) MapMeasurementToRetention(measurement, duration string) (err error) {
+	d, err := _parseRetention(duration)
+	if err == nil && d <= 0 {
+		err = fmt.Errorf("Invalid retention duration %q: a measurement cannot be kept forever", duration)
+	}
+	if err != nil {
+		return err
+	}
+	retentionPolicyName := "rp_" + _durationLiteral(d)
+
+	queryStr := fmt.Sprintf("CREATE RETENTION POLICY %v ON %v DURATION %v REPLICATION 1", _quoteIdent(retentionPolicyName), _quoteIdent(timeserData.timeSeriesDbName), _durationLiteral(d))
+	if _, err = timeserData.query(timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, "")); err != nil {
+		timeserData.logger().Errorf("Failed to create retention policy %v for measurement %v with error %v\n", retentionPolicyName, measurement, err)
+		return err
+	}
+
+	timeserData.retentionLock.Lock()
+	defer timeserData.retentionLock.Unlock()
+	if timeserData.retentions == nil {
+		timeserData.retentions = make(map[string]string)
+	}
+	timeserData.retentions[timeserData.MeasurementName(measurement)] = retentionPolicyName
+	timeserData.logger().Infof("Measurement %v mapped to retention policy %v\n", measurement, retentionPolicyName)
+	return nil
+}
+
+// Returns the retention policy a measurement is written to, empty for the default one of the DB
+func (timeserData *TimeSeriesClientData) RetentionPolicyOf(measurement string) string {
+	timeserData.retentionLock.RLock()
+	defer timeserData.retentionLock.RUnlock()
+	return timeserData.retentions[timeserData.MeasurementName(measurement)]
+}
+
+// Writes a batch, routing the points of the measurements mapped by MapMeasurementToRetention to their policy
+func (timeserData *TimeSeriesClientData) write(bp timesrclient.BatchPoints) error {
+	return timeserData.writeContext(context.Background(), bp)
+}
+
+// Writes a batch within the span of ctx, in the namespace of the client and without the duplicate points when
+// SetDedupWindow() is set. The cached values of its fields are dropped. The batches failing to write are reported
+// to OnWriteError() unless ctx collects them for a retry
+func (timeserData *TimeSeriesClientData) writeContext(ctx context.Context, bp timesrclient.BatchPoints) error {
+	if _, retried := ctx.Value(failedBatchesKey{}).(*failedBatches); !retried {
+		var failed *failedBatches
+		ctx, failed = _collectFailed(ctx)
+		defer timeserData.reportFailed(failed)
+	}
+	bp, err := timeserData.namespaced(bp)
+	if err != nil {
+		timeserData.logger().Errorf("Failed to add the namespace and tags to the points: %v\n", err)
+		return err
+	}
+	defer timeserData.invalidatePoints(bp.Points())
+	if dedup := timeserData.dedup; dedup != nil {
+		return dedup.write(bp, func(bp timesrclient.BatchPoints) error {
+			return timeserData.writeMapped(ctx, bp)
+		})
+	}
+	return timeserData.writeMapped(ctx, bp)
+}
+
+// Writes a batch, split per retention policy of its measurements, and delivers it to the subscriptions
+func (timeserData *TimeSeriesClientData) writeMapped(ctx context.Context, bp timesrclient.BatchPoints) (err error) {
+	release, err := timeserData.acquireWrite(ctx, len(bp.Points()))
+	if err != nil {
+		return err
+	}
+	defer release()
+	defer func() {
+		// Even on error, some points may have been written
+		timeserData.clearQueryCache()
+		if err == nil {
+			timeserData.publish(bp.Points())
+		}
+	}()
+
+	timeserData.retentionLock.RLock()
+	mapped := len(timeserData.retentions) > 0
+	timeserData.retentionLock.RUnlock()
+	if !mapped || bp.RetentionPolicy() != "" || bp.Database() != timeserData.timeSeriesDbName {
+		return timeserData.writeBatch(ctx, bp)
+	}
+
+	// One batch per retention policy, in the order of their first point
+	order := []string{}
+	batches := make(map[string]timesrclient.BatchPoints)
+	for _, pt := range bp.Points() {
+		retentionPolicyName := timeserData.RetentionPolicyOf(pt.Name())
+		batch, ok := batches[retentionPolicyName]
+		if !ok {
+			batch, _ = timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+				Database:         bp.Database(),
+				Precision:        bp.Precision(),
+				RetentionPolicy:  retentionPolicyName,
+				WriteConsistency: bp.WriteConsistency(),
+			})
+			batches[retentionPolicyName] = batch
+			order = append(order, retentionPolicyName)
+		}
+		batch.AddPoint(pt)
+	}
+	for _, retentionPolicyName := range order {
+		if werr := timeserData.writeBatch(ctx, batches[retentionPolicyName]); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return err
+}
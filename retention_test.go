@@ -0,0 +1,75 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"fmt"
+	"stslgo"
+	"testing"
+)
+
+// Test function for routing the writes of measurements mapped to a retention
+func TestTimeSeriesDbMeasurementRetention(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	if err = timeserData.MapMeasurementToRetention("ue.metrics", "7d"); err != nil {
+		t.Fatalf("Unable to map retention with error %v", err)
+	}
+	if err = timeserData.MapMeasurementToRetention("cell.metrics", "36h"); err != nil {
+		t.Fatalf("Unable to map retention with error %v", err)
+	}
+	expected := []string{
+		`CREATE RETENTION POLICY "rp_1w" ON "testdb" DURATION 1w REPLICATION 1`,
+		`CREATE RETENTION POLICY "rp_36h" ON "testdb" DURATION 36h REPLICATION 1`,
+	}
+	if fmt.Sprint(issuedQueries) != fmt.Sprint(expected) {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+	if timeserData.RetentionPolicyOf("ue.metrics") != "rp_1w" || timeserData.RetentionPolicyOf("other") != "" {
+		t.Errorf("Unexpected retention policies")
+	}
+	if err = timeserData.MapMeasurementToRetention("ue.metrics", "a week"); err == nil {
+		t.Errorf("Expected error for invalid duration")
+	}
+
+	fields := map[string]interface{}{"prb": 3}
+	timeserData.WritePoint("ue.metrics", nil, fields)
+	timeserData.WritePoint("other", nil, fields)
+	if fmt.Sprint(writtenRetentionPolicies) != "[rp_1w ]" {
+		t.Errorf("Unexpected retention policies written %q", writtenRetentionPolicies)
+	}
+
+	// Batches mixing measurements are split per retention policy
+	writtenRetentionPolicies = nil
+	writtenPoints = nil
+	bw := timeserData.NewBatchWriter(stslgo.BatchWriterConfig{})
+	bw.WritePoint("cell.metrics", nil, fields)
+	bw.WritePoint("other", nil, fields)
+	bw.WritePoint("cell.metrics", nil, fields)
+	if err = bw.Close(); err != nil {
+		t.Fatalf("Unable to flush with error %v", err)
+	}
+	if fmt.Sprint(writtenRetentionPolicies) != "[rp_36h ]" || len(writtenPoints) != 3 {
+		t.Errorf("Unexpected retention policies written %q, points %v", writtenRetentionPolicies, len(writtenPoints))
+	}
+}
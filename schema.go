@@ -0,0 +1,265 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Type of a field, as reported by SHOW FIELD KEYS
+type FieldType int
+
+const (
+	FieldFloat FieldType = iota + 1
+	FieldInteger
+	FieldString
+	FieldBoolean
+)
+
+var fieldTypeNames = map[FieldType]string{
+	FieldFloat:   "float",
+	FieldInteger: "integer",
+	FieldString:  "string",
+	FieldBoolean: "boolean",
+}
+
+func (fieldType FieldType) String() string {
+	if name, ok := fieldTypeNames[fieldType]; ok {
+		return name
+	}
+	return fmt.Sprintf("FieldType(%d)", int(fieldType))
+}
+
+var ErrSchemaConflict = errors.New("Data conflicts with the measurement schema")
+
+// Expected tag keys and field types of a measurement
+type MeasurementSchema struct {
+	TagKeys []string             // Allowed tag keys, any when empty
+	Fields  map[string]FieldType // Declared fields and their types
+	Strict  bool                 // Reject the fields not declared in Fields
+}
+
+// Schemas of the measurements written by an xApp, can be shared by several clients
+type SchemaRegistry struct {
+	lock    sync.RWMutex
+	schemas map[string]MeasurementSchema
+}
+
+// Creates an empty schema registry
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{schemas: make(map[string]MeasurementSchema)}
+}
+
+// Declares the schema of a measurement, replacing any previous one
+func (registry *SchemaRegistry) Register(measurement string, schema MeasurementSchema) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	registry.schemas[measurement] = schema
+}
+
+// Returns the schema declared for a measurement
+func (registry *SchemaRegistry) Lookup(measurement string) (schema MeasurementSchema, ok bool) {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+	schema, ok = registry.schemas[measurement]
+	return schema, ok
+}
+
+// Validates the points of WritePoint, the BatchWriter, WriteStruct and the JSON insert operations against
+// the registry. Declared fields are coerced to their type where no precision is lost (eg. integer up to 2^53 to
+// float, integral float to integer), other conflicts fail the write with ErrSchemaConflict. nil disables validation
+func (timeserData *TimeSeriesClientData) SetSchemaRegistry(registry *SchemaRegistry) {
+	timeserData.schemaRegistry = registry
+}
+
+// Checks the tags and fields of a point against the schema of the measurement, fields is not modified
+func (timeserData *TimeSeriesClientData) schemaFields(measurement string, tags map[string]string, fields map[string]interface{}) (map[string]interface{}, error) {
+	if timeserData.schemaRegistry == nil {
+		return fields, nil
+	}
+	schema, ok := timeserData.schemaRegistry.Lookup(measurement)
+	if !ok {
+		return fields, nil
+	}
+
+	if len(schema.TagKeys) > 0 {
+		for key := range tags {
+			if !_contains(schema.TagKeys, key) {
+				return nil, fmt.Errorf("%v: undeclared tag %v.%v", ErrSchemaConflict, measurement, key)
+			}
+		}
+	}
+	var coerced map[string]interface{}
+	for key, value := range fields {
+		if _contains(schema.TagKeys, key) {
+			return nil, fmt.Errorf("%v: %v.%v is declared as a tag", ErrSchemaConflict, measurement, key)
+		}
+		fieldType, ok := schema.Fields[key]
+		if !ok {
+			if schema.Strict {
+				return nil, fmt.Errorf("%v: undeclared field %v.%v", ErrSchemaConflict, measurement, key)
+			}
+			continue
+		}
+		converted, ok := _coerceField(value, fieldType)
+		if !ok {
+			timeserData.logger().Errorf("Field %v of measurement %v is %T, declared %v\n", key, measurement, value, fieldType)
+			return nil, fmt.Errorf("%v: %v.%v is %T, declared %v", ErrSchemaConflict, measurement, key, value, fieldType)
+		}
+		if converted == value {
+			continue
+		}
+		if coerced == nil {
+			coerced = make(map[string]interface{}, len(fields))
+			for k, v := range fields {
+				coerced[k] = v
+			}
+		}
+		coerced[key] = converted
+	}
+	if coerced == nil {
+		return fields, nil
+	}
+	return coerced, nil
+}
+
+// Reports the tag keys and field types of a measurement as found in the DB
+func (timeserData *TimeSeriesClientData) DescribeMeasurement(measurement string) (schema MeasurementSchema, err error) {
+	queryStr := fmt.Sprintf("SHOW TAG KEYS FROM %v; SHOW FIELD KEYS FROM %v", timeserData.measurementIdent(measurement), timeserData.measurementIdent(measurement))
+	q := timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, "")
+	response, err := timeserData.query(q)
+	if err != nil {
+		timeserData.logger().Errorf("Failed to describe measurement %v with error %v\n", measurement, err)
+		return schema, err
+	}
+	if len(response.Results) != 2 || len(response.Results[1].Series) == 0 {
+		return schema, fmt.Errorf("Measurement %v not found", measurement)
+	}
+
+	for _, series := range response.Results[0].Series {
+		for _, value := range series.Values {
+			if len(value) > 0 {
+				schema.TagKeys = append(schema.TagKeys, fmt.Sprint(value[0]))
+			}
+		}
+	}
+	schema.Fields = make(map[string]FieldType)
+	for _, series := range response.Results[1].Series {
+		for _, value := range series.Values {
+			if len(value) < 2 {
+				continue
+			}
+			for fieldType, name := range fieldTypeNames {
+				if name == fmt.Sprint(value[1]) {
+					schema.Fields[fmt.Sprint(value[0])] = fieldType
+				}
+			}
+		}
+	}
+	timeserData.logger().Debugf("TimeSeriesDB DescribeMeasurement: DB=%v Measurement=%v schema=%v\n", timeserData.timeSeriesDbName, measurement, schema)
+	return schema, nil
+}
+
+// Largest integer magnitude a float64 holds exactly
+const maxExactFloatInt = 1 << 53
+
+// Converts a field value to the declared type when no precision is lost
+func _coerceField(value interface{}, fieldType FieldType) (interface{}, bool) {
+	if number, ok := value.(json.Number); ok {
+		if i, err := number.Int64(); err == nil && !strings.ContainsAny(number.String(), ".eE") {
+			value = i
+		} else if f, err := number.Float64(); err == nil {
+			value = f
+		} else {
+			return nil, false
+		}
+	}
+
+	switch fieldType {
+	case FieldFloat:
+		switch v := value.(type) {
+		case float64:
+			return v, true
+		case float32:
+			return float64(v), true
+		}
+		// Integers beyond 2^53 would be rounded, they conflict rather than silently change
+		if i, ok := _integerValue(value); ok && i >= -maxExactFloatInt && i <= maxExactFloatInt {
+			return float64(i), true
+		}
+	case FieldInteger:
+		if i, ok := _integerValue(value); ok {
+			return i, true
+		}
+		var f float64
+		switch v := value.(type) {
+		case float64:
+			f = v
+		case float32:
+			f = float64(v)
+		default:
+			return nil, false
+		}
+		if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			return int64(f), true
+		}
+	case FieldString:
+		if s, ok := value.(string); ok {
+			return s, true
+		}
+	case FieldBoolean:
+		if b, ok := value.(bool); ok {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// Returns the value of any integer type as int64
+func _integerValue(value interface{}) (int64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() <= math.MaxInt64 {
+			return int64(v.Uint()), true
+		}
+	}
+	return 0, false
+}
+
+// Tells if list holds value
+func _contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"fmt"
+	"strings"
+	"stslgo"
+	"testing"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for validating and coercing written points against the schema registry
+func TestTimeSeriesDbSchemaValidation(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	registry := stslgo.NewSchemaRegistry()
+	registry.Register("CellKpi", stslgo.MeasurementSchema{
+		TagKeys: []string{"cellId"},
+		Fields:  map[string]stslgo.FieldType{"prb": stslgo.FieldInteger, "thp": stslgo.FieldFloat, "state": stslgo.FieldString},
+	})
+	timeserData.SetSchemaRegistry(registry)
+
+	// Coerced without loss of precision
+	fields := map[string]interface{}{"prb": 3.0, "thp": 12, "other": true}
+	if err = timeserData.WritePoint("CellKpi", map[string]string{"cellId": "c1"}, fields); err != nil {
+		t.Fatalf("Unable to write with error %v", err)
+	}
+	written, _ := writtenPoints[0].Fields()
+	if written["prb"] != int64(3) || written["thp"] != 12.0 || written["other"] != true {
+		t.Errorf("Unexpected fields written %v", written)
+	}
+	if fields["prb"] != 3.0 {
+		t.Errorf("Fields of the caller modified %v", fields)
+	}
+	if err = timeserData.InsertJson("CellKpi", []string{}, []byte(`{"prb": 4, "state": "up"}`)); err != nil {
+		t.Fatalf("Unable to insert JSON with error %v", err)
+	}
+	written, _ = writtenPoints[1].Fields()
+	if written["prb"] != int64(4) {
+		t.Errorf("Unexpected fields written %v", written)
+	}
+
+	// Conflicts
+	for _, tc := range []struct {
+		tags   map[string]string
+		fields map[string]interface{}
+	}{
+		{nil, map[string]interface{}{"prb": 3.5}},
+		{nil, map[string]interface{}{"state": 1}},
+		{nil, map[string]interface{}{"thp": int64(1<<53 + 1)}},
+		{nil, map[string]interface{}{"cellId": "c1"}},
+		{map[string]string{"node": "gnb1"}, map[string]interface{}{"prb": 3}},
+	} {
+		err = timeserData.WritePoint("CellKpi", tc.tags, tc.fields)
+		if err == nil || !strings.HasPrefix(err.Error(), stslgo.ErrSchemaConflict.Error()) {
+			t.Errorf("Expected schema conflict for %v %v, got %v", tc.tags, tc.fields, err)
+		}
+	}
+	if err = timeserData.InsertJson("CellKpi", []string{}, []byte(`{"state": true}`)); err == nil {
+		t.Errorf("Expected schema conflict for JSON insert")
+	}
+	if len(writtenPoints) != 2 {
+		t.Errorf("Conflicting points written %v", writtenPoints)
+	}
+
+	// Measurements without a schema are not validated
+	if err = timeserData.WritePoint("OtherKpi", nil, map[string]interface{}{"prb": "n/a"}); err != nil {
+		t.Errorf("Unable to write with error %v", err)
+	}
+}
+
+// Test function for reporting the schema of a measurement found in the DB
+func TestTimeSeriesDbDescribeMeasurement(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{
+			{Series: []models.Row{{Name: "CellKpi", Columns: []string{"tagKey"}, Values: [][]interface{}{{"cellId"}}}}},
+			{Series: []models.Row{{Name: "CellKpi", Columns: []string{"fieldKey", "fieldType"}, Values: [][]interface{}{{"prb", "integer"}, {"thp", "float"}}}}},
+		}}, nil
+	}
+	schema, err := timeserData.DescribeMeasurement("CellKpi")
+	if err != nil {
+		t.Fatalf("Unable to describe measurement with error %v", err)
+	}
+	if issuedQueries[0] != `SHOW TAG KEYS FROM "CellKpi"; SHOW FIELD KEYS FROM "CellKpi"` {
+		t.Errorf("Unexpected query %v", issuedQueries[0])
+	}
+	if len(schema.TagKeys) != 1 || schema.TagKeys[0] != "cellId" || schema.Fields["prb"] != stslgo.FieldInteger || schema.Fields["thp"] != stslgo.FieldFloat {
+		t.Errorf("Unexpected schema %v", schema)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{}, {}}}, nil
+	}
+	if _, err = timeserData.DescribeMeasurement("Missing"); err == nil {
+		t.Errorf("Expected error for missing measurement")
+	}
+}
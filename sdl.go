@@ -0,0 +1,243 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Default measurement of an SDLStorage
+const DefaultSDLMeasurement = "sdl"
+
+// Key-value storage with namespaces mirroring the operations of the RIC shared data layer (SDL). Each change is
+// a point tagged with the namespace (ns) and key, with the value as string field, so the history of the state is
+// kept. Removed keys are marked by a point with deleted=true. The conditional operations are atomic among the
+// operations of the same SDLStorage only, as TimeSeriesDB has no transactions
+type SDLStorage struct {
+	timeserData *TimeSeriesClientData
+	measurement string
+	lock        sync.Mutex
+}
+
+// Creates an SDL compatible storage in the measurement, DefaultSDLMeasurement when empty
+func (timeserData *TimeSeriesClientData) NewSDLStorage(measurement string) *SDLStorage {
+	if measurement == "" {
+		measurement = DefaultSDLMeasurement
+	}
+	return &SDLStorage{timeserData: timeserData, measurement: measurement}
+}
+
+// Sets keys of the namespace, given as key, value pairs or as a map[string]interface{}. Values are stored as
+// strings, []byte as is and other types formatted with fmt
+func (sdl *SDLStorage) Set(ns string, pairs ...interface{}) error {
+	values, err := _sdlPairs(pairs)
+	if err != nil {
+		return err
+	}
+	sdl.lock.Lock()
+	defer sdl.lock.Unlock()
+	return sdl.write(ns, values, false)
+}
+
+// Gets the current values of keys of the namespace, missing and removed keys are not in the result
+func (sdl *SDLStorage) Get(ns string, keys []string) (map[string]interface{}, error) {
+	if len(keys) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	return sdl.current(ns, keys)
+}
+
+// Sets a key to newData if its current value is oldData, returns whether it was set
+func (sdl *SDLStorage) SetIf(ns, key string, oldData, newData interface{}) (bool, error) {
+	sdl.lock.Lock()
+	defer sdl.lock.Unlock()
+	values, err := sdl.current(ns, []string{key})
+	if err != nil {
+		return false, err
+	}
+	if current, ok := values[key]; !ok || current != _sdlValue(oldData) {
+		return false, nil
+	}
+	return true, sdl.write(ns, map[string]string{key: _sdlValue(newData)}, false)
+}
+
+// Sets a key if it has no current value, returns whether it was set
+func (sdl *SDLStorage) SetIfNotExists(ns, key string, data interface{}) (bool, error) {
+	sdl.lock.Lock()
+	defer sdl.lock.Unlock()
+	values, err := sdl.current(ns, []string{key})
+	if err != nil {
+		return false, err
+	}
+	if _, ok := values[key]; ok {
+		return false, nil
+	}
+	return true, sdl.write(ns, map[string]string{key: _sdlValue(data)}, false)
+}
+
+// Removes keys of the namespace
+func (sdl *SDLStorage) Remove(ns string, keys []string) error {
+	removed := make(map[string]string, len(keys))
+	for _, key := range keys {
+		removed[key] = ""
+	}
+	sdl.lock.Lock()
+	defer sdl.lock.Unlock()
+	return sdl.write(ns, removed, true)
+}
+
+// Removes a key if its current value is data, returns whether it was removed
+func (sdl *SDLStorage) RemoveIf(ns, key string, data interface{}) (bool, error) {
+	sdl.lock.Lock()
+	defer sdl.lock.Unlock()
+	values, err := sdl.current(ns, []string{key})
+	if err != nil {
+		return false, err
+	}
+	if current, ok := values[key]; !ok || current != _sdlValue(data) {
+		return false, nil
+	}
+	return true, sdl.write(ns, map[string]string{key: ""}, true)
+}
+
+// Returns the keys of the namespace having a current value, sorted
+func (sdl *SDLStorage) GetAll(ns string) ([]string, error) {
+	values, err := sdl.current(ns, nil)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Removes all the keys of the namespace
+func (sdl *SDLStorage) RemoveAll(ns string) error {
+	sdl.lock.Lock()
+	defer sdl.lock.Unlock()
+	values, err := sdl.current(ns, nil)
+	if err != nil || len(values) == 0 {
+		return err
+	}
+	removed := make(map[string]string, len(values))
+	for key := range values {
+		removed[key] = ""
+	}
+	return sdl.write(ns, removed, true)
+}
+
+// Writes a point per key in a single batch
+func (sdl *SDLStorage) write(ns string, values map[string]string, deleted bool) error {
+	if ns == "" {
+		return errors.New("SDL namespace must not be empty")
+	}
+	points := make([]Point, 0, len(values))
+	for key, value := range values {
+		if key == "" {
+			return errors.New("SDL key must not be empty")
+		}
+		points = append(points, Point{
+			Tags:   map[string]string{"ns": ns, "key": key},
+			Fields: map[string]interface{}{"value": value, "deleted": deleted},
+		})
+	}
+	return sdl.timeserData.WritePoints(sdl.measurement, points)
+}
+
+// Gets the current values of keys of the namespace, of all its keys when keys is nil
+func (sdl *SDLStorage) current(ns string, keys []string) (map[string]interface{}, error) {
+	queryStr := fmt.Sprintf(`SELECT "value", "deleted" FROM %v WHERE "ns" = %v`, _quoteIdent(sdl.measurement), _quoteLiteral(ns))
+	if len(keys) > 0 {
+		conditions := make([]string, len(keys))
+		for i, key := range keys {
+			conditions[i] = `"key" = ` + _quoteLiteral(key)
+		}
+		queryStr += " AND (" + strings.Join(conditions, " OR ") + ")"
+	}
+	queryStr += ` GROUP BY "key" ORDER BY time DESC LIMIT 1`
+	q := timesrclient.NewQuery(queryStr, sdl.timeserData.timeSeriesDbName, "")
+	// The latest values, not cached or replicated ones
+	response, err := sdl.timeserData.queryContext(ReadFromPrimary(context.Background()), q)
+	if err != nil {
+		sdl.timeserData.logger().Errorf("Failed to get SDL namespace %v with error %v\n", ns, err)
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	for _, result := range response.Results {
+		for _, series := range result.Series {
+			// Columns are time, value and deleted
+			for _, row := range series.Values {
+				if len(row) < 3 || row[2] == true || row[1] == nil {
+					continue
+				}
+				values[series.Tags["key"]] = fmt.Sprint(row[1])
+			}
+		}
+	}
+	sdl.timeserData.logger().Debugf("TimeSeriesDB SDL Get: DB=%v ns=%v keys=%v values=%v\n", sdl.timeserData.timeSeriesDbName, ns, keys, len(values))
+	return values, nil
+}
+
+// Converts the pairs of Set() to string values
+func _sdlPairs(pairs []interface{}) (map[string]string, error) {
+	values := make(map[string]string)
+	if len(pairs) == 1 {
+		if m, ok := pairs[0].(map[string]interface{}); ok {
+			for key, value := range m {
+				values[key] = _sdlValue(value)
+			}
+			return values, nil
+		}
+	}
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("SDL Set needs key, value pairs")
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("SDL key %v is not a string", pairs[i])
+		}
+		values[key] = _sdlValue(pairs[i+1])
+	}
+	return values, nil
+}
+
+// Converts a value to the stored string
+func _sdlValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,128 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Mock response with the latest row per key
+func sdlResp(rows map[string][]interface{}) *timesrclient.Response {
+	result := timesrclient.Result{}
+	for key, row := range rows {
+		result.Series = append(result.Series, models.Row{Name: "sdl", Tags: map[string]string{"key": key},
+			Columns: []string{"time", "value", "deleted"}, Values: [][]interface{}{row}})
+	}
+	return &timesrclient.Response{Results: []timesrclient.Result{result}}
+}
+
+// Test function for the SDL compatible key-value storage
+func TestTimeSeriesDbSDLStorage(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	sdl := timeserData.NewSDLStorage("")
+
+	err = sdl.Set("xapp", "a", "1", "b", []byte("2"), "c", 3)
+	if err != nil {
+		t.Fatalf("Unable to set keys with error %v", err)
+	}
+	if writeCalls != 1 || len(writtenPoints) != 3 {
+		t.Fatalf("Expected 3 points in a single write, got %v in %v", len(writtenPoints), writeCalls)
+	}
+	for _, pt := range writtenPoints {
+		fields, _ := pt.Fields()
+		if pt.Name() != "sdl" || pt.Tags()["ns"] != "xapp" || fields["deleted"] != false {
+			t.Errorf("Unexpected point %v", pt)
+		}
+		if pt.Tags()["key"] == "c" && fields["value"] != "3" {
+			t.Errorf("Expected value formatted as string, got %v", fields["value"])
+		}
+	}
+	if err = sdl.Set("xapp", "a"); err == nil {
+		t.Errorf("Expected error for a key without value")
+	}
+	if err = sdl.Set("", "a", "1"); err == nil {
+		t.Errorf("Expected error for an empty namespace")
+	}
+
+	// Removed keys are not returned
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		issuedQueries = append(issuedQueries, q.Command)
+		return sdlResp(map[string][]interface{}{
+			"a": {"2021-08-20T05:47:46Z", "1", false},
+			"b": {"2021-08-20T05:47:46Z", "", true},
+		}), nil
+	}
+	values, err := sdl.Get("xapp", []string{"a", "b", "x"})
+	if err != nil {
+		t.Fatalf("Unable to get keys with error %v", err)
+	}
+	if len(values) != 1 || values["a"] != "1" {
+		t.Errorf("Unexpected values %v", values)
+	}
+	last := issuedQueries[len(issuedQueries)-1]
+	if !strings.Contains(last, `"ns" = 'xapp' AND ("key" = 'a' OR "key" = 'b' OR "key" = 'x')`) || !strings.Contains(last, "LIMIT 1") {
+		t.Errorf("Unexpected query %v", last)
+	}
+
+	keys, err := sdl.GetAll("xapp")
+	if err != nil || len(keys) != 1 || keys[0] != "a" {
+		t.Errorf("Unexpected keys %v with error %v", keys, err)
+	}
+
+	// Conditional operations
+	writtenPoints = nil
+	if ok, err := sdl.SetIf("xapp", "a", "0", "2"); ok || err != nil || len(writtenPoints) != 0 {
+		t.Errorf("Expected SetIf to fail on a different value, got %v %v", ok, err)
+	}
+	if ok, err := sdl.SetIf("xapp", "a", "1", "2"); !ok || err != nil || len(writtenPoints) != 1 {
+		t.Errorf("Expected SetIf to set the value, got %v %v", ok, err)
+	}
+	if ok, err := sdl.SetIfNotExists("xapp", "a", "3"); ok || err != nil {
+		t.Errorf("Expected SetIfNotExists to fail on an existing key, got %v %v", ok, err)
+	}
+	if ok, err := sdl.SetIfNotExists("xapp", "b", "3"); !ok || err != nil {
+		t.Errorf("Expected SetIfNotExists to set a removed key, got %v %v", ok, err)
+	}
+	writtenPoints = nil
+	if ok, err := sdl.RemoveIf("xapp", "a", "1"); !ok || err != nil || len(writtenPoints) != 1 {
+		t.Fatalf("Expected RemoveIf to remove the key, got %v %v", ok, err)
+	}
+	fields, _ := writtenPoints[0].Fields()
+	if fields["deleted"] != true {
+		t.Errorf("Expected a removal point, got %v", fields)
+	}
+
+	writtenPoints = nil
+	if err = sdl.Remove("xapp", []string{"a", "b"}); err != nil || len(writtenPoints) != 2 {
+		t.Errorf("Expected 2 removal points, got %v with error %v", len(writtenPoints), err)
+	}
+	writtenPoints = nil
+	if err = sdl.RemoveAll("xapp"); err != nil || len(writtenPoints) != 1 || writtenPoints[0].Tags()["key"] != "a" {
+		t.Errorf("Expected removal of the current keys, got %v with error %v", writtenPoints, err)
+	}
+}
@@ -0,0 +1,98 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"errors"
+	"fmt"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Gets the newest n values of a field of the series matching tags, in chronological order
+func (timeserData *TimeSeriesClientData) GetLastN(measurement, field string, tags map[string]string, n int) ([]TimedValue, error) {
+	if n <= 0 {
+		return nil, errors.New("GetLastN needs n > 0")
+	}
+	values, err := timeserData.selectValues(measurement, field, fmt.Sprintf("SELECT %v FROM %v%v ORDER BY time DESC LIMIT %v",
+		_quoteIdent(field), timeserData.measurementIdent(measurement), _tagWhere(tags), n))
+	if err != nil {
+		return nil, err
+	}
+	// Query returns newest first
+	for l, r := 0, len(values)-1; l < r; l, r = l+1, r-1 {
+		values[l], values[r] = values[r], values[l]
+	}
+	return values, nil
+}
+
+// Gets the oldest value of a field of the series matching tags, ErrKeyNotFound when the field has no value
+func (timeserData *TimeSeriesClientData) GetFirst(measurement, field string, tags map[string]string) (TimedValue, error) {
+	return timeserData.selectValue(measurement, field, "FIRST", tags)
+}
+
+// Gets the newest value of a field of the series matching tags, ErrKeyNotFound when the field has no value
+func (timeserData *TimeSeriesClientData) GetLast(measurement, field string, tags map[string]string) (TimedValue, error) {
+	return timeserData.selectValue(measurement, field, "LAST", tags)
+}
+
+// Runs a selector returning a single value with its time
+func (timeserData *TimeSeriesClientData) selectValue(measurement, field, selector string, tags map[string]string) (TimedValue, error) {
+	values, err := timeserData.selectValues(measurement, field, fmt.Sprintf("SELECT %v(%v) FROM %v%v",
+		selector, _quoteIdent(field), timeserData.measurementIdent(measurement), _tagWhere(tags)))
+	if err != nil {
+		return TimedValue{}, err
+	}
+	if len(values) == 0 || values[0].Value == nil {
+		return TimedValue{}, ErrKeyNotFound
+	}
+	return values[0], nil
+}
+
+// Runs a query of a field and returns the values in the order of the response
+func (timeserData *TimeSeriesClientData) selectValues(measurement, field, queryStr string) (result []TimedValue, err error) {
+	q := timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, "")
+	response, err := timeserData.query(q)
+	if err != nil {
+		timeserData.logger().Errorf("Failed to get %v from measurement %v with error %v\n", field, measurement, err)
+		return nil, err
+	}
+
+	result = []TimedValue{}
+	for _, v := range response.Results {
+		for _, row := range v.Series {
+			values, err := _timedValues(row.Values)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, values...)
+		}
+	}
+	timeserData.logger().Debugf("TimeSeriesDB Select: DB=%v, QueryString=%v, values=%v\n", timeserData.timeSeriesDbName, queryStr, len(result))
+	return result, nil
+}
+
+// Builds the WHERE clause matching all the tags, empty for no tags
+func _tagWhere(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return " WHERE " + _tagCondition(tags)
+}
@@ -0,0 +1,75 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+
+	"stslgo"
+)
+
+// Test function for getting the last n, first and last values of the series matching tags
+func TestTimeSeriesDbSelectors(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+			{Name: "cellMetrics", Columns: []string{"time", "prb"}, Values: [][]interface{}{
+				{"2024-01-01T00:00:20Z", json.Number("50")},
+				{"2024-01-01T00:00:10Z", json.Number("40")},
+			}},
+		}}}}, nil
+	}
+
+	values, err := timeserData.GetLastN("cellMetrics", "prb", map[string]string{"cell": "c1", "du": "d'1"}, 2)
+	if err != nil || len(values) != 2 || values[0].Value != json.Number("40") || values[1].Value != json.Number("50") {
+		t.Errorf("Unexpected values %v, error %v", values, err)
+	}
+	last, err := timeserData.GetLast("cellMetrics", "prb", map[string]string{"cell": "c1"})
+	if err != nil || last.Value != json.Number("50") || last.Time.Second() != 20 {
+		t.Errorf("Unexpected last value %v, error %v", last, err)
+	}
+	_, _ = timeserData.GetFirst("cellMetrics", "prb", nil)
+	expected := []string{
+		`SELECT "prb" FROM "cellMetrics" WHERE "cell" = 'c1' AND "du" = 'd\'1' ORDER BY time DESC LIMIT 2`,
+		`SELECT LAST("prb") FROM "cellMetrics" WHERE "cell" = 'c1'`,
+		`SELECT FIRST("prb") FROM "cellMetrics"`,
+	}
+	if fmt.Sprint(issuedQueries) != fmt.Sprint(expected) {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{}}}, nil
+	}
+	if _, err = timeserData.GetFirst("cellMetrics", "prb", nil); err != stslgo.ErrKeyNotFound {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+	if _, err = timeserData.GetLastN("cellMetrics", "prb", nil, 0); err == nil {
+		t.Errorf("Expected an error for n = 0")
+	}
+}
@@ -0,0 +1,250 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package server
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"stslgo"
+	"time"
+)
+
+// Protobuf encoding of the messages of stslgo.proto
+
+var errTruncated = errors.New("Truncated protobuf message")
+
+// Protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// Calls fn with the number of each field of a protobuf message and its value, the varint or fixed value in value,
+// the bytes of a length delimited value in data
+func _fields(message []byte, fn func(field int, value uint64, data []byte) error) error {
+	for len(message) > 0 {
+		key, n := binary.Uvarint(message)
+		if n <= 0 {
+			return errTruncated
+		}
+		message = message[n:]
+		var value uint64
+		var data []byte
+		switch key & 7 {
+		case wireVarint:
+			if value, n = binary.Uvarint(message); n <= 0 {
+				return errTruncated
+			}
+			message = message[n:]
+		case wireFixed64:
+			if len(message) < 8 {
+				return errTruncated
+			}
+			value, message = binary.LittleEndian.Uint64(message), message[8:]
+		case wireBytes:
+			length, n := binary.Uvarint(message)
+			if n <= 0 || length > uint64(len(message)-n) {
+				return errTruncated
+			}
+			data, message = message[n:n+int(length)], message[n+int(length):]
+		case wireFixed32:
+			if len(message) < 4 {
+				return errTruncated
+			}
+			value, message = uint64(binary.LittleEndian.Uint32(message)), message[4:]
+		default:
+			return fmt.Errorf("Unsupported protobuf wire type %v", key&7)
+		}
+		if err := fn(int(key>>3), value, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decodes a map entry, the key in field 1 and the value in field 2
+func _mapEntry(entry []byte) (key string, value []byte, err error) {
+	err = _fields(entry, func(field int, _ uint64, data []byte) error {
+		switch field {
+		case 1:
+			key = string(data)
+		case 2:
+			value = data
+		}
+		return nil
+	})
+	return key, value, err
+}
+
+// Decodes a Value message to a field value, nil when no value is set
+func _decodeValue(message []byte) (value interface{}, err error) {
+	err = _fields(message, func(field int, v uint64, data []byte) error {
+		switch field {
+		case 1:
+			value = math.Float64frombits(v)
+		case 2:
+			value = int64(v)
+		case 3:
+			value = string(data)
+		case 4:
+			value = v != 0
+		}
+		return nil
+	})
+	return value, err
+}
+
+// Decodes a Point message
+func _decodePoint(message []byte) (point stslgo.Point, err error) {
+	point.Tags = map[string]string{}
+	point.Fields = map[string]interface{}{}
+	err = _fields(message, func(field int, v uint64, data []byte) error {
+		switch field {
+		case 1:
+			point.Measurement = string(data)
+		case 2:
+			key, value, err := _mapEntry(data)
+			if err != nil {
+				return err
+			}
+			point.Tags[key] = string(value)
+		case 3:
+			key, value, err := _mapEntry(data)
+			if err != nil {
+				return err
+			}
+			fieldValue, err := _decodeValue(value)
+			if err != nil {
+				return err
+			}
+			if fieldValue != nil {
+				point.Fields[key] = fieldValue
+			}
+		case 4:
+			if v != 0 {
+				point.Time = time.Unix(0, int64(v))
+			}
+		}
+		return nil
+	})
+	return point, err
+}
+
+// Decodes a WriteRequest message
+func _decodeWriteRequest(message []byte) (points []stslgo.Point, err error) {
+	err = _fields(message, func(field int, _ uint64, data []byte) error {
+		if field != 1 {
+			return nil
+		}
+		point, err := _decodePoint(data)
+		if err != nil {
+			return err
+		}
+		if point.Measurement == "" {
+			return errors.New("Point without measurement")
+		}
+		points = append(points, point)
+		return nil
+	})
+	return points, err
+}
+
+func _appendKey(buf []byte, field int, wire int) []byte {
+	return _appendVarint(buf, uint64(field)<<3|uint64(wire))
+}
+
+func _appendVarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	return append(buf, tmp[:binary.PutUvarint(tmp[:], v)]...)
+}
+
+func _appendBytes(buf []byte, field int, data []byte) []byte {
+	buf = _appendKey(buf, field, wireBytes)
+	buf = _appendVarint(buf, uint64(len(data)))
+	return append(buf, data...)
+}
+
+// Encodes a column value as a Value message, false for the values with no Value representation (eg. null)
+func _encodeValue(value interface{}) ([]byte, bool) {
+	var buf []byte
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return _encodeValue(i)
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return _encodeValue(v.String())
+		}
+		return _encodeValue(f)
+	case float64:
+		buf = _appendKey(buf, 1, wireFixed64)
+		var tmp [8]byte
+		binary.LittleEndian.PutUint64(tmp[:], math.Float64bits(v))
+		buf = append(buf, tmp[:]...)
+	case int64:
+		buf = _appendKey(buf, 2, wireVarint)
+		buf = _appendVarint(buf, uint64(v))
+	case int:
+		return _encodeValue(int64(v))
+	case string:
+		buf = _appendBytes(buf, 3, []byte(v))
+	case bool:
+		buf = _appendKey(buf, 4, wireVarint)
+		if v {
+			buf = _appendVarint(buf, 1)
+		} else {
+			buf = _appendVarint(buf, 0)
+		}
+	default:
+		return nil, false
+	}
+	return buf, true
+}
+
+// Encodes the rows of a query as a QueryResponse message, the columns in name order
+func _encodeQueryResponse(rows []stslgo.JsonRow) []byte {
+	var buf []byte
+	for _, row := range rows {
+		names := make([]string, 0, len(row))
+		for name := range row {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var rowBuf []byte
+		for _, name := range names {
+			value, ok := _encodeValue(row[name])
+			if !ok {
+				continue
+			}
+			entry := _appendBytes(nil, 1, []byte(name))
+			entry = _appendBytes(entry, 2, value)
+			rowBuf = _appendBytes(rowBuf, 1, entry)
+		}
+		buf = _appendBytes(buf, 1, rowBuf)
+	}
+	return buf
+}
@@ -0,0 +1,261 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+// Package server exposes the write, query and DB administration operations of a client over gRPC, for the
+// xApps not written in Go. The service is described by stslgo.proto
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"stslgo"
+)
+
+// Maximum size of the body of a request
+const maxBodyBytes = 32 << 20
+
+// gRPC status codes
+const (
+	StatusOK                = 0
+	StatusInvalidArgument   = 3
+	StatusNotFound          = 5
+	StatusResourceExhausted = 8
+	StatusUnimplemented     = 12
+	StatusInternal          = 13
+	StatusUnavailable       = 14
+	StatusUnauthenticated   = 16
+)
+
+// Error replied with a gRPC status
+type statusError struct {
+	code    int
+	message string
+}
+
+func (e *statusError) Error() string {
+	return e.message
+}
+
+// gRPC server of a client, serving the unary calls of the stslgo.TimeSeries service. gRPC runs over HTTP/2,
+// which net/http serves over TLS: pass the Server to http.ListenAndServeTLS() or http.Server.ServeTLS().
+// Messages must not be compressed
+type Server struct {
+	timeserData *stslgo.TimeSeriesClientData
+	token       string
+	methods     map[string]func(message []byte) ([]byte, error)
+}
+
+// Creates the gRPC server of the client. Calls must carry the metadata "authorization: Bearer <token>"
+// (or Token), an empty token disables the authentication
+func New(timeserData *stslgo.TimeSeriesClientData, token string) *Server {
+	s := &Server{timeserData: timeserData, token: token}
+	s.methods = map[string]func(message []byte) ([]byte, error){
+		"/stslgo.TimeSeries/Write":                 s.write,
+		"/stslgo.TimeSeries/WriteLineProtocol":     s.writeLineProtocol,
+		"/stslgo.TimeSeries/Query":                 s.query,
+		"/stslgo.TimeSeries/CreateDB":              s.createDB,
+		"/stslgo.TimeSeries/DeleteDB":              s.deleteDB,
+		"/stslgo.TimeSeries/CreateRetentionPolicy": s.createRetentionPolicy,
+		"/stslgo.TimeSeries/DeleteRetentionPolicy": s.deleteRetentionPolicy,
+	}
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/grpc" && !strings.HasPrefix(contentType, "application/grpc+proto") {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	// The status is sent in the trailers, after the reply message if any
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Grpc-Accept-Encoding", "identity")
+	w.Header().Add("Trailer", "Grpc-Status")
+	w.Header().Add("Trailer", "Grpc-Message")
+	reply, err := s.call(w, r)
+	w.WriteHeader(http.StatusOK)
+	if err == nil {
+		frame := make([]byte, 5, 5+len(reply))
+		binary.BigEndian.PutUint32(frame[1:], uint32(len(reply)))
+		w.Write(append(frame, reply...))
+	}
+	code, message := _status(err)
+	w.Header().Set("Grpc-Status", fmt.Sprint(code))
+	w.Header().Set("Grpc-Message", _percentEncode(message))
+}
+
+// Authenticates a call, reads its request message and runs the method, returning the reply message
+func (s *Server) call(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	if !_authorized(r, s.token) {
+		return nil, &statusError{StatusUnauthenticated, "Invalid or missing token"}
+	}
+	method, ok := s.methods[r.URL.Path]
+	if !ok {
+		return nil, &statusError{StatusUnimplemented, "Unknown method " + r.URL.Path}
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		return nil, &statusError{StatusResourceExhausted, err.Error()}
+	}
+	if len(body) < 5 || int64(binary.BigEndian.Uint32(body[1:5])) != int64(len(body)-5) {
+		return nil, &statusError{StatusInvalidArgument, "Body must be a single length-prefixed message"}
+	}
+	if body[0] != 0 {
+		return nil, &statusError{StatusUnimplemented, "Compressed messages not supported"}
+	}
+	return method(body[5:])
+}
+
+func (s *Server) write(message []byte) ([]byte, error) {
+	points, err := _decodeWriteRequest(message)
+	if err != nil {
+		return nil, &statusError{StatusInvalidArgument, err.Error()}
+	}
+	return nil, s.timeserData.WritePointsMixed(points)
+}
+
+func (s *Server) writeLineProtocol(message []byte) ([]byte, error) {
+	var lines string
+	err := _fields(message, func(field int, _ uint64, data []byte) error {
+		if field == 1 {
+			lines = string(data)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, &statusError{StatusInvalidArgument, err.Error()}
+	}
+	return nil, s.timeserData.WriteLineProtocol(lines)
+}
+
+func (s *Server) query(message []byte) ([]byte, error) {
+	var query string
+	err := _fields(message, func(field int, _ uint64, data []byte) error {
+		if field == 1 {
+			query = string(data)
+		}
+		return nil
+	})
+	if err != nil || query == "" {
+		return nil, &statusError{StatusInvalidArgument, "Request must have a query"}
+	}
+	rows, err := s.timeserData.QueryRows(query)
+	if err != nil {
+		return nil, err
+	}
+	return _encodeQueryResponse(rows), nil
+}
+
+func (s *Server) createDB(message []byte) ([]byte, error) {
+	return nil, s.timeserData.CreateTimeSeriesDB()
+}
+
+func (s *Server) deleteDB(message []byte) ([]byte, error) {
+	return nil, s.timeserData.DeleteTimeSeriesDB()
+}
+
+func (s *Server) createRetentionPolicy(message []byte) ([]byte, error) {
+	var name, duration string
+	var setDefault bool
+	err := _fields(message, func(field int, value uint64, data []byte) error {
+		switch field {
+		case 1:
+			name = string(data)
+		case 2:
+			duration = string(data)
+		case 3:
+			setDefault = value != 0
+		}
+		return nil
+	})
+	if err != nil || name == "" || duration == "" {
+		return nil, &statusError{StatusInvalidArgument, "Request must have a name and duration"}
+	}
+	return nil, s.timeserData.CreateRetentionPolicy(name, duration, setDefault)
+}
+
+func (s *Server) deleteRetentionPolicy(message []byte) ([]byte, error) {
+	var name string
+	err := _fields(message, func(field int, _ uint64, data []byte) error {
+		if field == 1 {
+			name = string(data)
+		}
+		return nil
+	})
+	if err != nil || name == "" {
+		return nil, &statusError{StatusInvalidArgument, "Request must have a name"}
+	}
+	return nil, s.timeserData.DeleteRetentionPolicy(name)
+}
+
+// Returns whether the call carries "authorization: Bearer <token>" (or Token), always true when token is empty
+func _authorized(r *http.Request, token string) bool {
+	if token == "" {
+		return true
+	}
+	auth := r.Header.Get("Authorization")
+	received := strings.TrimPrefix(strings.TrimPrefix(auth, "Token "), "Bearer ")
+	return received != auth && subtle.ConstantTimeCompare([]byte(received), []byte(token)) == 1
+}
+
+// Status of the reply to a call: unavailable when not connected, not found for a missing DB, internal when
+// TimeSeriesDB failed and invalid argument for the invalid requests
+func _status(err error) (code int, message string) {
+	if err == nil {
+		return StatusOK, ""
+	}
+	if e, ok := err.(*statusError); ok {
+		return e.code, e.message
+	}
+	switch {
+	case stslgo.IsKind(err, stslgo.ErrNotConnected) || stslgo.IsKind(err, stslgo.ErrCircuitOpen) || stslgo.IsKind(err, stslgo.ErrClientClosed):
+		return StatusUnavailable, err.Error()
+	case err == stslgo.ErrWriteLimited:
+		return StatusResourceExhausted, err.Error()
+	case stslgo.IsKind(err, stslgo.ErrTimeSeriesDBNotFound):
+		return StatusNotFound, err.Error()
+	case stslgo.ErrorCause(err) != nil:
+		return StatusInternal, err.Error()
+	}
+	return StatusInvalidArgument, err.Error()
+}
+
+// Percent-encodes a grpc-message, all bytes but the printable ASCII other than '%'
+func _percentEncode(message string) string {
+	var b strings.Builder
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
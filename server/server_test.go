@@ -0,0 +1,243 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package server_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"stslgo"
+	"stslgo/server"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Mock client recording the written points and queries
+type MockClient struct {
+	points  []*timesrclient.Point
+	queries []string
+	resp    *timesrclient.Response
+}
+
+func (c *MockClient) Close() error {
+	return nil
+}
+
+func (c *MockClient) Ping(timeout time.Duration) (time.Duration, string, error) {
+	return 0, "1.8.0", nil
+}
+
+func (c *MockClient) Query(q timesrclient.Query) (*timesrclient.Response, error) {
+	c.queries = append(c.queries, q.Command)
+	if c.resp != nil {
+		return c.resp, nil
+	}
+	return &timesrclient.Response{}, nil
+}
+
+func (c *MockClient) Write(bp timesrclient.BatchPoints) error {
+	c.points = append(c.points, bp.Points()...)
+	return nil
+}
+
+// Protobuf encoding of the fields of the test messages
+func protoKey(field, wire int) []byte {
+	return protoUvarint(uint64(field)<<3 | uint64(wire))
+}
+
+func protoUvarint(v uint64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	return buf[:binary.PutUvarint(buf, v)]
+}
+
+func protoVarint(field int, v uint64) []byte {
+	return append(protoKey(field, 0), protoUvarint(v)...)
+}
+
+func protoDouble(field int, v float64) []byte {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, math.Float64bits(v))
+	return append(protoKey(field, 1), buf...)
+}
+
+func protoBytes(field int, data ...[]byte) []byte {
+	message := bytes.Join(data, nil)
+	return append(append(protoKey(field, 2), protoUvarint(uint64(len(message)))...), message...)
+}
+
+func protoEntry(field int, key string, value []byte) []byte {
+	return protoBytes(field, protoBytes(1, []byte(key)), value)
+}
+
+// Calls a method of the server with a request message, returning the recorded reply
+func call(s *server.Server, method, token string, message []byte) *http.Response {
+	frame := make([]byte, 5)
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	r := httptest.NewRequest(http.MethodPost, "/stslgo.TimeSeries/"+method, bytes.NewReader(append(frame, message...)))
+	r.Header.Set("Content-Type", "application/grpc")
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w.Result()
+}
+
+// Returns the message of a reply, failing the test unless its grpc-status is code
+func replyMessage(t *testing.T, resp *http.Response, code int) []byte {
+	t.Helper()
+	var body bytes.Buffer
+	body.ReadFrom(resp.Body)
+	if status := resp.Trailer.Get("Grpc-Status"); status != strconv.Itoa(code) {
+		t.Fatalf("Expected grpc-status %v, got %v (%v)", code, status, resp.Trailer.Get("Grpc-Message"))
+	}
+	if code != server.StatusOK {
+		return nil
+	}
+	if body.Len() < 5 || int(binary.BigEndian.Uint32(body.Bytes()[1:5])) != body.Len()-5 {
+		t.Fatalf("Expected a single length-prefixed message, got %v bytes", body.Len())
+	}
+	return body.Bytes()[5:]
+}
+
+// Test function for writing points and line protocol through the gRPC server
+func TestServerWrite(t *testing.T) {
+	timeserData := stslgo.NewTimeSeriesClientData("testdb", "testuser", "testpasswd")
+	mock := &MockClient{}
+	timeserData.Iclient = mock
+	s := server.New(timeserData, "secret")
+
+	point := protoBytes(1,
+		protoBytes(1, []byte("CellKpi")),
+		protoEntry(2, "cellId", protoBytes(2, []byte("c1"))),
+		protoEntry(3, "prb", protoBytes(2, protoDouble(1, 0.5))),
+		protoEntry(3, "ues", protoBytes(2, protoVarint(2, 3))),
+		protoEntry(3, "state", protoBytes(2, protoBytes(3, []byte("up")))),
+		protoEntry(3, "active", protoBytes(2, protoVarint(4, 1))),
+		protoVarint(4, uint64(time.Unix(10, 0).UnixNano())))
+	replyMessage(t, call(s, "Write", "secret", point), server.StatusOK)
+	if len(mock.points) != 1 {
+		t.Fatalf("Expected 1 point written, got %v", len(mock.points))
+	}
+	if line := mock.points[0].String(); line != `CellKpi,cellId=c1 active=true,prb=0.5,state="up",ues=3i 10000000000` {
+		t.Errorf("Unexpected point %v", line)
+	}
+
+	replyMessage(t, call(s, "WriteLineProtocol", "secret", protoBytes(1, []byte("CellKpi prb=1 20000000000"))), server.StatusOK)
+	if len(mock.points) != 2 || mock.points[1].String() != "CellKpi prb=1 20000000000" {
+		t.Errorf("Expected the line written, got %v", mock.points)
+	}
+
+	// A point without measurement is an invalid argument
+	replyMessage(t, call(s, "Write", "secret", protoBytes(1, protoEntry(3, "prb", protoBytes(2, protoDouble(1, 1))))), server.StatusInvalidArgument)
+	if len(mock.points) != 2 {
+		t.Errorf("Expected no point written for the invalid request, got %v", len(mock.points))
+	}
+}
+
+// Test function for querying through the gRPC server
+func TestServerQuery(t *testing.T) {
+	timeserData := stslgo.NewTimeSeriesClientData("testdb", "testuser", "testpasswd")
+	mock := &MockClient{}
+	timeserData.Iclient = mock
+	s := server.New(timeserData, "")
+
+	result := timesrclient.Result{Series: []models.Row{{
+		Name:    "CellKpi",
+		Columns: []string{"time", "prb", "state"},
+		Values:  [][]interface{}{{"1970-01-01T00:00:10Z", json.Number("5"), nil}},
+	}}}
+	mock.resp = &timesrclient.Response{Results: []timesrclient.Result{result}}
+	message := replyMessage(t, call(s, "Query", "", protoBytes(1, []byte("SELECT * FROM CellKpi"))), server.StatusOK)
+	expected := protoBytes(1,
+		protoEntry(1, "prb", protoBytes(2, protoVarint(2, 5))),
+		protoEntry(1, "time", protoBytes(2, protoBytes(3, []byte("1970-01-01T00:00:10Z")))))
+	if !bytes.Equal(message, expected) {
+		t.Errorf("Unexpected query response %x, expected %x", message, expected)
+	}
+	if len(mock.queries) != 1 || mock.queries[0] != "SELECT * FROM CellKpi" {
+		t.Errorf("Unexpected queries %v", mock.queries)
+	}
+
+	mock.resp = &timesrclient.Response{Err: "database not found: testdb"}
+	replyMessage(t, call(s, "Query", "", protoBytes(1, []byte("SELECT * FROM CellKpi"))), server.StatusNotFound)
+	replyMessage(t, call(s, "Query", "", nil), server.StatusInvalidArgument)
+}
+
+// Test function for the DB and retention policy administration through the gRPC server
+func TestServerAdministration(t *testing.T) {
+	timeserData := stslgo.NewTimeSeriesClientData("testdb", "testuser", "testpasswd")
+	mock := &MockClient{}
+	timeserData.Iclient = mock
+	s := server.New(timeserData, "")
+
+	replyMessage(t, call(s, "CreateDB", "", nil), server.StatusOK)
+	replyMessage(t, call(s, "CreateRetentionPolicy", "", bytes.Join([][]byte{
+		protoBytes(1, []byte("week")), protoBytes(2, []byte("7d")), protoVarint(3, 1)}, nil)), server.StatusOK)
+	replyMessage(t, call(s, "DeleteRetentionPolicy", "", protoBytes(1, []byte("week"))), server.StatusOK)
+	replyMessage(t, call(s, "DeleteDB", "", nil), server.StatusOK)
+	replyMessage(t, call(s, "CreateRetentionPolicy", "", protoBytes(1, []byte("week"))), server.StatusInvalidArgument)
+	if len(mock.queries) != 4 {
+		t.Errorf("Expected 4 statements issued, got %v", mock.queries)
+	}
+}
+
+// Test function for the calls rejected by the gRPC server
+func TestServerRejected(t *testing.T) {
+	timeserData := stslgo.NewTimeSeriesClientData("testdb", "testuser", "testpasswd")
+	timeserData.Iclient = &MockClient{}
+	s := server.New(timeserData, "secret")
+
+	replyMessage(t, call(s, "CreateDB", "", nil), server.StatusUnauthenticated)
+	replyMessage(t, call(s, "CreateDB", "wrong", nil), server.StatusUnauthenticated)
+	replyMessage(t, call(s, "Unknown", "secret", nil), server.StatusUnimplemented)
+
+	// Truncated frame
+	r := httptest.NewRequest(http.MethodPost, "/stslgo.TimeSeries/CreateDB", bytes.NewReader([]byte{0, 0, 0, 0, 9, 1}))
+	r.Header.Set("Content-Type", "application/grpc")
+	r.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	replyMessage(t, w.Result(), server.StatusInvalidArgument)
+
+	// Not a gRPC request
+	r = httptest.NewRequest(http.MethodPost, "/stslgo.TimeSeries/CreateDB", nil)
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected 415 for a non gRPC request, got %v", w.Code)
+	}
+
+	// Not connected
+	s = server.New(stslgo.NewTimeSeriesClientData("testdb", "testuser", "testpasswd"), "")
+	resp := call(s, "WriteLineProtocol", "", protoBytes(1, []byte("CellKpi prb=1")))
+	replyMessage(t, resp, server.StatusUnavailable)
+	if message := resp.Trailer.Get("Grpc-Message"); message == "" {
+		t.Errorf("Expected a grpc-message for the error")
+	}
+}
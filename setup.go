@@ -0,0 +1,49 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"errors"
+	"fmt"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Bootstraps a fresh TimeSeriesDB, so that a RIC deployment does not need manual influx CLI steps: creates the
+// admin user, which TimeSeriesDB allows without credentials as long as it has no user, switches the client to
+// its credentials and creates the DB of the client with the retention policy, if its name is not empty.
+// Running it again is harmless as long as the password is the same
+func (timeserData *TimeSeriesClientData) Setup(userName, password, retentionPolicyName, duration string) (err error) {
+	if userName == "" || password == "" {
+		return errors.New("Setup needs a user name and a password")
+	}
+	queryStr := fmt.Sprintf("CREATE USER %v WITH PASSWORD %v WITH ALL PRIVILEGES", _quoteIdent(userName), _quoteLiteral(password))
+	if _, err = timeserData.query(timesrclient.NewQuery(queryStr, "", "")); err != nil {
+		timeserData.logger().Errorf("Failed to create TimeSeriesDB admin user %v with error %v\n", userName, err)
+		return err
+	}
+	timeserData.logger().Infof("Sucessfully created TimeSeriesDB admin user %v\n", userName)
+
+	timeserData.setToken(userName + ":" + password)
+	if err = timeserData.reconnect(); err != nil {
+		return err
+	}
+	return timeserData.CreateTimeSeriesDBNamed(timeserData.timeSeriesDbName, retentionPolicyName, duration)
+}
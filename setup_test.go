@@ -0,0 +1,53 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Test function for bootstrapping a fresh TimeSeriesDB
+func TestTimeSeriesDbSetup(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	if err = timeserData.Setup("admin", "it's secret", "rp_7d", "7d"); err != nil {
+		t.Fatalf("Unable to set up with error %v", err)
+	}
+	expected := []string{
+		`CREATE USER "admin" WITH PASSWORD 'it\'s secret' WITH ALL PRIVILEGES`,
+		`CREATE DATABASE "testdb" WITH DURATION 7d REPLICATION 1 SHARD DURATION 7d NAME "rp_7d"`,
+	}
+	if len(issuedQueries) != len(expected) {
+		t.Fatalf("Unexpected queries %v", issuedQueries)
+	}
+	for i, q := range expected {
+		if issuedQueries[i] != q {
+			t.Errorf("Expected %v, got %v", q, issuedQueries[i])
+		}
+	}
+
+	if err = timeserData.Setup("admin", "", "", ""); err == nil {
+		t.Errorf("Expected error without password")
+	}
+}
@@ -0,0 +1,109 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"sync"
+)
+
+// Background components of a client (BatchWriters, Aggregators, Watchers, Alerts) closed by CloseContext()
+type tracked struct {
+	lock  sync.Mutex
+	items map[interface{}]trackedItem
+}
+
+type trackedItem struct {
+	writes bool // Whether closing writes points, which is done after stopping the others
+	close  func() error
+}
+
+// Stops the background processing of the client within the deadline of ctx, then closes the connection to
+// TimeSeriesDB: stops the Watchers and Alerts, writes the points pending in the BatchWriters and Aggregators
+// not closed yet and drains the write errors. When ctx ends first, the connection is closed anyway, failing the
+// writes in progress, and the error of ctx is returned
+func (timeserData *TimeSeriesClientData) CloseContext(ctx context.Context) (err error) {
+	timeserData.stopTokenWatcher()
+	timeserData.StopXappConfigWatch()
+
+	flushed := make(chan error, 1)
+	go func() {
+		err := timeserData.closeTracked()
+		timeserData.writeErrors.stop()
+		flushed <- err
+	}()
+	select {
+	case err = <-flushed:
+	case <-ctx.Done():
+		err = ctx.Err()
+		timeserData.logger().Errorf("TimeSeriesDB client closed before the pending writes completed: %v\n", err)
+	}
+
+	if timeserData.Iclient != nil {
+		if cerr := timeserData.Iclient.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	if timeserData.readClient != nil {
+		if cerr := timeserData.readClient.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Registers a background component to be closed by CloseContext()
+func (timeserData *TimeSeriesClientData) track(item interface{}, writes bool, close func() error) {
+	timeserData.background.lock.Lock()
+	defer timeserData.background.lock.Unlock()
+	if timeserData.background.items == nil {
+		timeserData.background.items = make(map[interface{}]trackedItem)
+	}
+	timeserData.background.items[item] = trackedItem{writes: writes, close: close}
+}
+
+// Unregisters a background component closed by its owner
+func (timeserData *TimeSeriesClientData) untrack(item interface{}) {
+	timeserData.background.lock.Lock()
+	defer timeserData.background.lock.Unlock()
+	delete(timeserData.background.items, item)
+}
+
+// Closes the background components, those writing points last. Returns the first error of their closing
+func (timeserData *TimeSeriesClientData) closeTracked() (err error) {
+	timeserData.background.lock.Lock()
+	var stopped, flushed []func() error
+	for _, item := range timeserData.background.items {
+		if item.writes {
+			flushed = append(flushed, item.close)
+		} else {
+			stopped = append(stopped, item.close)
+		}
+	}
+	timeserData.background.lock.Unlock()
+
+	for _, close := range append(stopped, flushed...) {
+		// Closed by their owner meanwhile
+		if cerr := close(); cerr != nil && cerr != ErrBatchWriterClosed && cerr != ErrAggregatorClosed && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
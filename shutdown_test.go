@@ -0,0 +1,86 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+
+	"stslgo"
+)
+
+// Mock client whose writes block until released, then fail
+type blockingWriteClient struct {
+	MockClient
+	release chan struct{}
+}
+
+func (c *blockingWriteClient) Write(bp timesrclient.BatchPoints) error {
+	<-c.release
+	return errors.New("connection closed")
+}
+
+// Test function for closing a client with its background components
+func TestTimeSeriesDbCloseContext(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	bw := timeserData.NewBatchWriter(stslgo.BatchWriterConfig{BatchSize: 100, FlushInterval: time.Hour})
+	bw.WritePoint("CellKpi", nil, map[string]interface{}{"prb": 1})
+	closed := timeserData.NewBatchWriter(stslgo.BatchWriterConfig{BatchSize: 100})
+	closed.Close()
+	agg, _ := timeserData.NewAggregator(stslgo.AggregatorConfig{Window: time.Hour, Functions: []stslgo.AggregateFunction{stslgo.AggregateMax}})
+	agg.Add("UeKpi", nil, map[string]interface{}{"thp": 5}, time.Now())
+	watcher, _ := timeserData.Watch("CellKpi", "prb", time.Hour)
+	alerts := timeserData.NewAlerts(func(stslgo.Alert) {})
+	alerts.Start(time.Hour)
+
+	if err = timeserData.CloseContext(context.Background()); err != nil {
+		t.Fatalf("Close failed with error %v", err)
+	}
+	if len(writtenPoints) != 2 {
+		t.Errorf("Expected the pending points written, got %v", writtenPoints)
+	}
+	if _, ok := <-watcher.C; ok {
+		t.Errorf("Expected the watcher stopped")
+	}
+	if err = bw.WritePoint("CellKpi", nil, map[string]interface{}{"prb": 2}); err != stslgo.ErrBatchWriterClosed {
+		t.Errorf("Expected ErrBatchWriterClosed, got %v", err)
+	}
+
+	// Deadline
+	timeserData, _ = setup()
+	client := &blockingWriteClient{release: make(chan struct{})}
+	timeserData.Iclient = client
+	defer close(client.release)
+	bw = timeserData.NewBatchWriter(stslgo.BatchWriterConfig{BatchSize: 100})
+	bw.WritePoint("CellKpi", nil, map[string]interface{}{"prb": 1})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err = timeserData.CloseContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,341 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Default bound of the spill file
+const DefaultSpillMaxBytes = 64 << 20
+
+// Maximum number of points per write when replaying the spill file
+const spillReplayBatchSize = 5000
+
+// Interval of the background replays of the spill file
+var spillReplayInterval = 5 * time.Second
+
+// File keeping the points which could not be written while TimeSeriesDB was unreachable.
+// Each line holds the database, retention policy and line protocol of a point, separated by tabs
+type spillFile struct {
+	lock       sync.Mutex // Guards the file and size, never held across a write to TimeSeriesDB
+	replayLock sync.Mutex // Serializes the replays
+	path       string
+	maxBytes   int64
+	size       int64
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// Keeps the points of the writes failing because TimeSeriesDB is unreachable in a file, up to maxBytes
+// (DefaultSpillMaxBytes if 0), instead of failing the write. While the file holds points, the next writes are
+// appended to it as well to keep the order. The points are replayed, oldest first, in the background every few
+// seconds and survive a restart of the process. Writes rejected by TimeSeriesDB (eg. field type conflicts) and
+// points beyond maxBytes still fail
+func (timeserData *TimeSeriesClientData) SetSpillFile(path string, maxBytes int64) error {
+	if maxBytes <= 0 {
+		maxBytes = DefaultSpillMaxBytes
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() > 0 {
+		timeserData.logger().Infof("TimeSeriesDB spill file %v holds %v bytes to replay\n", path, info.Size())
+	}
+	if old := timeserData.spill; old != nil {
+		timeserData.untrack(old)
+		old.close()
+	}
+	spill := &spillFile{path: path, maxBytes: maxBytes, size: info.Size(), stop: make(chan struct{}), done: make(chan struct{})}
+	timeserData.spill = spill
+	timeserData.track(spill, false, spill.close)
+	go timeserData.runSpillReplay(spill)
+	return nil
+}
+
+// Returns the size of the points waiting in the spill file
+func (timeserData *TimeSeriesClientData) SpillSize() int64 {
+	if timeserData.spill == nil {
+		return 0
+	}
+	timeserData.spill.lock.Lock()
+	defer timeserData.spill.lock.Unlock()
+	return timeserData.spill.size
+}
+
+// Writes the points of the spill file to TimeSeriesDB now, keeping those not written
+func (timeserData *TimeSeriesClientData) ReplaySpill() error {
+	if timeserData.spill == nil {
+		return nil
+	}
+	return timeserData.replaySpill(timeserData.spill)
+}
+
+// Writes a batch to TimeSeriesDB, through the spill file when set
+func (timeserData *TimeSeriesClientData) writeBatch(ctx context.Context, bp timesrclient.BatchPoints) (err error) {
+	if timeserData.Iclient == nil {
+		return ErrNotConnected
+	}
+	defer func() {
+		if err != nil {
+			err = &kindError{kind: ErrWriteFailed, cause: err}
+			_keepFailed(ctx, bp, err)
+		}
+	}()
+	if timeserData.metrics != nil {
+		defer func() { timeserData.metrics.Written(len(bp.Points()), err) }()
+	}
+	if timeserData.tracer != nil {
+		var span Span
+		_, span = timeserData.startSpan(ctx, "stslgo.Write")
+		span.SetAttribute("db.name", bp.Database())
+		span.SetAttribute("stslgo.retention_policy", bp.RetentionPolicy())
+		span.SetAttribute("stslgo.points", len(bp.Points()))
+		defer func() { span.End(err) }()
+	}
+	spill := timeserData.spill
+	if spill == nil {
+		return timeserData.clientWrite(ctx, bp)
+	}
+
+	// Behind the older points of the file, unless it is full
+	if spill.appendPending(bp, timeserData.logger()) {
+		return nil
+	}
+	err = timeserData.clientWrite(ctx, bp)
+	if _, unreachable := err.(net.Error); unreachable || err == ErrCircuitOpen {
+		if spill.append(bp, err, timeserData.logger()) {
+			return nil
+		}
+	}
+	return err
+}
+
+// Replays the spill file periodically until closed
+func (timeserData *TimeSeriesClientData) runSpillReplay(spill *spillFile) {
+	defer close(spill.done)
+	ticker := time.NewTicker(spillReplayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-spill.stop:
+			return
+		case <-ticker.C:
+		}
+		spill.lock.Lock()
+		pending := spill.size > 0
+		spill.lock.Unlock()
+		if pending {
+			_ = timeserData.replaySpill(spill)
+		}
+	}
+}
+
+// Stops the background replays
+func (spill *spillFile) close() error {
+	select {
+	case <-spill.stop:
+	default:
+		close(spill.stop)
+	}
+	<-spill.done
+	return nil
+}
+
+// Writes the points of the spill file. The file is locked only to read it and to remove the points written,
+// the points appended meanwhile are kept
+func (timeserData *TimeSeriesClientData) replaySpill(spill *spillFile) error {
+	spill.replayLock.Lock()
+	defer spill.replayLock.Unlock()
+
+	spill.lock.Lock()
+	content, err := ioutil.ReadFile(spill.path)
+	spill.lock.Unlock()
+	if err != nil || len(content) == 0 {
+		return err
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	written := 0
+	var replayErr error
+	for written < len(lines) {
+		bp, n, err := _spillBatch(lines[written:])
+		if err != nil {
+			// Corrupt line, eg. torn by a crash during append
+			timeserData.logger().Errorf("Dropping TimeSeriesDB spill line %q: %v\n", lines[written], err)
+			timeserData.reportDropped(1, "spill_corrupt")
+			written++
+			continue
+		}
+		if bp != nil {
+			err = timeserData.circuitWrite(bp)
+			if _, unreachable := err.(net.Error); unreachable || err == ErrCircuitOpen {
+				timeserData.logger().Warnf("TimeSeriesDB spill replay failed, %v points left: %v\n", len(lines)-written, err)
+				replayErr = err
+				break
+			}
+			if err != nil {
+				// Rejected by TimeSeriesDB, would fail again
+				timeserData.logger().Errorf("Dropping %v TimeSeriesDB spilled points: %v\n", len(bp.Points()), err)
+				timeserData.reportDropped(len(bp.Points()), "spill_rejected")
+			}
+		}
+		written += n
+	}
+
+	spill.lock.Lock()
+	defer spill.lock.Unlock()
+	current, err := ioutil.ReadFile(spill.path)
+	if err != nil {
+		return err
+	}
+	left := strings.Join(lines[written:], "")
+	if len(current) > len(content) {
+		left += string(current[len(content):])
+	}
+	if left == "" {
+		timeserData.logger().Infof("TimeSeriesDB spill file %v replayed\n", spill.path)
+	}
+	return spill.rewrite(left, replayErr)
+}
+
+// Appends the points of a write while the file holds older points, returning whether they were appended
+func (spill *spillFile) appendPending(bp timesrclient.BatchPoints, logger Logger) bool {
+	spill.lock.Lock()
+	defer spill.lock.Unlock()
+	if spill.size == 0 || !spill.appendLocked(bp, logger) {
+		return false
+	}
+	logger.Debugf("TimeSeriesDB spill file %v not replayed yet, %v points spilled\n", spill.path, len(bp.Points()))
+	return true
+}
+
+// Appends the points of a failed write, returning whether they are safe in the file
+func (spill *spillFile) append(bp timesrclient.BatchPoints, writeErr error, logger Logger) bool {
+	spill.lock.Lock()
+	defer spill.lock.Unlock()
+	if !spill.appendLocked(bp, logger) {
+		return false
+	}
+	logger.Warnf("TimeSeriesDB unreachable, %v points spilled to %v: %v\n", len(bp.Points()), spill.path, writeErr)
+	return true
+}
+
+// Appends the points of a batch, called with the lock held
+func (spill *spillFile) appendLocked(bp timesrclient.BatchPoints, logger Logger) bool {
+	var record strings.Builder
+	for _, pt := range bp.Points() {
+		fmt.Fprintf(&record, "%v\t%v\t%v\n", bp.Database(), bp.RetentionPolicy(), _spillLine(pt, bp.Precision()))
+	}
+	if spill.size+int64(record.Len()) > spill.maxBytes {
+		logger.Errorf("TimeSeriesDB spill file %v full, %v points not spilled\n", spill.path, len(bp.Points()))
+		return false
+	}
+	file, err := os.OpenFile(spill.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	n, err := file.WriteString(record.String())
+	spill.size += int64(n)
+	return err == nil
+}
+
+// Replaces the content of the file, returning replayErr. Called with the lock held
+func (spill *spillFile) rewrite(content string, replayErr error) error {
+	tmp := spill.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(content), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, spill.path); err != nil {
+		return err
+	}
+	spill.size = int64(len(content))
+	return replayErr
+}
+
+// Builds a batch of the leading lines sharing database and retention policy, returns the number of lines used
+func _spillBatch(lines []string) (timesrclient.BatchPoints, int, error) {
+	var bp timesrclient.BatchPoints
+	n := 0
+	for _, line := range lines {
+		if n == spillReplayBatchSize {
+			break
+		}
+		if strings.TrimSpace(line) == "" {
+			n++
+			continue
+		}
+		parts := strings.SplitN(strings.TrimSuffix(line, "\n"), "\t", 3)
+		if len(parts) != 3 {
+			if bp == nil {
+				return nil, 0, fmt.Errorf("malformed line")
+			}
+			break
+		}
+		if bp != nil && (parts[0] != bp.Database() || parts[1] != bp.RetentionPolicy()) {
+			break
+		}
+		points, err := models.ParsePointsString(parts[2])
+		if err != nil || len(points) != 1 {
+			if bp == nil {
+				return nil, 0, fmt.Errorf("malformed point: %v", err)
+			}
+			break
+		}
+		if bp == nil {
+			bp, _ = timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+				Database:        parts[0],
+				RetentionPolicy: parts[1],
+				Precision:       "ns",
+			})
+		}
+		bp.AddPoint(timesrclient.NewPointFrom(points[0]))
+		n++
+	}
+	return bp, n, nil
+}
+
+// Line protocol of a point with the timestamp truncated to the write precision, kept in nanoseconds
+func _spillLine(pt *timesrclient.Point, precision string) string {
+	line := pt.String()
+	multiplier := models.GetPrecisionMultiplier(precision)
+	if multiplier == 1 || pt.Time().IsZero() {
+		return line
+	}
+	ns := strconv.FormatInt(pt.UnixNano(), 10)
+	return strings.TrimSuffix(line, ns) + strconv.FormatInt(pt.UnixNano()/multiplier*multiplier, 10)
+}
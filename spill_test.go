@@ -0,0 +1,190 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"stslgo"
+	"testing"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for keeping the points in the spill file while TimeSeriesDB is unreachable
+func TestTimeSeriesDbSpillFile(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	dir, err := ioutil.TempDir("", "stslgo-spill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "spill")
+	if err = timeserData.SetSpillFile(path, 0); err != nil {
+		t.Fatalf("Unable to set spill file with error %v", err)
+	}
+
+	// Unreachable, the points are kept
+	writeErr = &url.Error{Op: "Post", URL: "http://localhost:8086/write", Err: errors.New("connection refused")}
+	err = timeserData.WritePoint("CellKpi", map[string]string{"cellId": "c1"}, map[string]interface{}{"prb": 1})
+	if err == nil {
+		err = timeserData.WritePoint("CellKpi", map[string]string{"cellId": "c1"}, map[string]interface{}{"prb": 2})
+	}
+	if err != nil || timeserData.SpillSize() == 0 {
+		t.Errorf("Expected points spilled, got %v, size %v", err, timeserData.SpillSize())
+	}
+
+	// Kept across a restart
+	restarted, _ := setup()
+	if err = restarted.SetSpillFile(path, 0); err != nil {
+		t.Fatalf("Unable to set spill file with error %v", err)
+	}
+	if restarted.SpillSize() == 0 {
+		t.Errorf("Spilled points lost on restart")
+	}
+
+	// Written behind the older points, replayed oldest first
+	writeErr = nil
+	if err = restarted.WritePoint("CellKpi", map[string]string{"cellId": "c1"}, map[string]interface{}{"prb": 3}); err != nil || len(writtenPoints) != 0 {
+		t.Errorf("Expected the point spilled behind the older ones, got %v written with error %v", len(writtenPoints), err)
+	}
+	if err = restarted.ReplaySpill(); err != nil || len(writtenPoints) != 3 || restarted.SpillSize() != 0 {
+		t.Fatalf("Expected 3 points written and empty spill file, got %v and %v", len(writtenPoints), restarted.SpillSize())
+	}
+	for i, pt := range writtenPoints {
+		fields, _ := pt.Fields()
+		if fields["prb"] != int64(i+1) || pt.Tags()["cellId"] != "c1" {
+			t.Errorf("Unexpected point %v at %v", pt, i)
+		}
+	}
+
+	// Rejected writes and writes beyond the bound still fail
+	writeErr = errors.New("field type conflict")
+	err = restarted.WritePoint("CellKpi", nil, map[string]interface{}{"prb": "n/a"})
+	if err == nil || restarted.SpillSize() != 0 {
+		t.Errorf("Expected rejected write to fail, got %v, size %v", err, restarted.SpillSize())
+	}
+	if err = restarted.SetSpillFile(path, 10); err != nil {
+		t.Fatalf("Unable to set spill file with error %v", err)
+	}
+	writeErr = &url.Error{Op: "Post", URL: "http://localhost:8086/write", Err: errors.New("connection refused")}
+	err = restarted.WritePoint("CellKpi", map[string]string{"cellId": "c1"}, map[string]interface{}{"prb": 4})
+	if err == nil || restarted.SpillSize() != 0 {
+		t.Errorf("Expected write beyond bound to fail, got %v, size %v", err, restarted.SpillSize())
+	}
+}
+
+// Test function for replaying the spill file through the circuit breaker
+func TestTimeSeriesDbSpillCircuitBreaker(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	dir, err := ioutil.TempDir("", "stslgo-spill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = timeserData.SetSpillFile(filepath.Join(dir, "spill"), 0); err != nil {
+		t.Fatalf("Unable to set spill file with error %v", err)
+	}
+	client := &failingWriteClient{failures: 10}
+	timeserData.Iclient = client
+	timeserData.SetCircuitBreaker(stslgo.CircuitBreakerPolicy{FailureThreshold: 1, OpenDuration: time.Minute})
+
+	// The first write opens the circuit, the others are spilled without reaching TimeSeriesDB
+	for i := 0; i < 3; i++ {
+		timeserData.WritePoint("CellKpi", nil, map[string]interface{}{"prb": i})
+	}
+	if client.failures != 9 || timeserData.CircuitState() != stslgo.CircuitOpen {
+		t.Errorf("Expected a single write attempt, %v failures left, circuit %v", client.failures, timeserData.CircuitState())
+	}
+	if err = timeserData.ReplaySpill(); !stslgo.IsKind(err, stslgo.ErrCircuitOpen) || client.failures != 9 {
+		t.Errorf("Expected the replay to fail fast, got %v with %v failures left", err, client.failures)
+	}
+}
+
+// Client holding its writes until released
+type heldWriteClient struct {
+	MockClient
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *heldWriteClient) Write(bp timesrclient.BatchPoints) error {
+	c.started <- struct{}{}
+	<-c.release
+	return c.MockClient.Write(bp)
+}
+
+// Test function for writing while the spill file is replayed
+func TestTimeSeriesDbSpillReplayConcurrentWrite(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	dir, err := ioutil.TempDir("", "stslgo-spill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = timeserData.SetSpillFile(filepath.Join(dir, "spill"), 0); err != nil {
+		t.Fatalf("Unable to set spill file with error %v", err)
+	}
+	writeErr = &url.Error{Op: "Post", URL: "http://localhost:8086/write", Err: errors.New("connection refused")}
+	_ = timeserData.WritePoint("CellKpi", nil, map[string]interface{}{"prb": 1})
+	writeErr = nil
+
+	client := &heldWriteClient{started: make(chan struct{}, 1), release: make(chan struct{})}
+	timeserData.Iclient = client
+	replayed := make(chan error, 1)
+	go func() { replayed <- timeserData.ReplaySpill() }()
+	<-client.started
+
+	// The write does not wait for the replay in progress, its point is kept behind the replayed ones
+	written := make(chan error, 1)
+	go func() { written <- timeserData.WritePoint("CellKpi", nil, map[string]interface{}{"prb": 2}) }()
+	select {
+	case err = <-written:
+		if err != nil {
+			t.Errorf("Unable to write during the replay with error %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Write blocked by the replay")
+	}
+	close(client.release)
+	if err = <-replayed; err != nil || len(writtenPoints) != 1 || timeserData.SpillSize() == 0 {
+		t.Fatalf("Expected the replayed point written and the new one kept, got %v with error %v", len(writtenPoints), err)
+	}
+	if err = timeserData.ReplaySpill(); err != nil || len(writtenPoints) != 2 || timeserData.SpillSize() != 0 {
+		t.Fatalf("Expected both points written, got %v with error %v", len(writtenPoints), err)
+	}
+	if fields, _ := writtenPoints[1].Fields(); fields["prb"] != int64(2) {
+		t.Errorf("Unexpected order %v", writtenPoints)
+	}
+}
@@ -20,23 +20,30 @@
 package stslgo
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 	_ "github.com/influxdata/influxdb1-client"
 	timesrclient "github.com/influxdata/influxdb1-client/v2"
+	"github.com/rs/zerolog"
 )
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 //                      Datastructures for storing all the timeseries db specific information
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
 type TimeSeriesDataGoClient interface {
 	Close() error
 	Query(timesrclient.Query) (*timesrclient.Response, error)
@@ -44,74 +51,368 @@ type TimeSeriesDataGoClient interface {
 }
 
 type TimeSeriesClientData struct {
-	Iclient            TimeSeriesDataGoClient // Connection to TimeSeriesDB
-	timeSeriesDbName   string                 // TimeSeries DB to be used for this XAPP
-	timeSeriesUserName string                 // Username for accessing the TimeSeries DB
-	timeSeriesPassword string                 // Password for accessing the TimeSeries DB
+	Iclient            TimeSeriesDataGoClient    // Connection to TimeSeriesDB
+	RetentionPolicy    string                    // Default retention policy of the DB, set by AttachTimeSeriesDB()
+	RetentionDuration  time.Duration             // Duration of the default retention policy, 0 for infinite
+	timeSeriesDbName   string                    // TimeSeries DB to be used for this XAPP
+	timeSeriesUserName string                    // Username for accessing the TimeSeries DB
+	timeSeriesPassword string                    // Password for accessing the TimeSeries DB
+	singleObjectMode   SingleObjectMode          // Handling of a single JSON object passed to InsertJsonArray
+	jsonNumberMode     JsonNumberMode            // Decoding of the JSON numbers, see SetJsonNumberMode()
+	nonFinitePolicy    NonFinitePolicy           // Handling of NaN and Inf float fields
+	nonFiniteSentinel  float64                   // Substitute for NaN and Inf with NonFiniteSubstitute
+	eventStateLock     sync.Mutex                // Protects eventState
+	eventState         map[string]bool           // Last recorded state of each event, see RecordEvent()
+	histogramLock      sync.Mutex                // Protects histograms
+	histograms         map[string]*histogram     // Bucket counters of each histogram series, see RecordHistogram()
+	writeErrors        writeErrorDrainer         // Handling of the errors of Set and WritePoint writes
+	reconnectPolicy    ReconnectPolicy           // Health checking and reconnection of the connection
+	host               string                    // TimeSeriesDB host, taken from the environment when empty
+	port               string                    // TimeSeriesDB HTTP port, taken from the environment when empty
+	readHost           string                    // TimeSeriesDB host serving the queries, see SetReadEndpoint()
+	readPort           string                    // HTTP port of readHost, the port of host when empty
+	readClient         TimeSeriesDataGoClient    // Connection to readHost
+	timeout            time.Duration             // Timeout of the requests to TimeSeriesDB, 0 for none
+	queryPoolSize      int                       // Number of clients serving the queries, see SetQueryPoolSize()
+	batchSize          int                       // Default BatchSize of the BatchWriters, see NewBatchWriter()
+	precision          string                    // Precision of the written timestamps, ns when empty, see SetWritePrecision()
+	writeGzip          bool                      // Compression of the writes, see SetWriteGzip()
+	valueCache         *lruCache                 // Latest values of the keys read, see SetValueCache()
+	queryCache         *lruCache                 // Responses of the read queries, see SetQueryCache()
+	subscriptions      subscriptions             // Receivers of the written points, see Subscribe()
+	writeMaxRetries    int                       // Retries of the writes failing on network errors, see SetWriteRetry()
+	writeRetryInterval time.Duration             // Wait before each retry of a write
+	schemaRegistry     *SchemaRegistry           // Schemas the written points are validated against, see SetSchemaRegistry()
+	retentionLock      sync.RWMutex              // Protects retentions
+	retentions         map[string]string         // Retention policy of the measurements, see MapMeasurementToRetention()
+	spill              *spillFile                // File keeping the points while TimeSeriesDB is unreachable, see SetSpillFile()
+	deadLetter         *deadLetterFile           // File keeping the batches failed for good, see SetDeadLetterFile()
+	writeErrorHook     func(error, []string)     // Receives the batches failed for good, see OnWriteError()
+	dedup              *dedupFilter              // Points recently written, see SetDedupWindow()
+	limiter            *writeLimiter             // Rate and concurrency limits of the writes, see SetWriteLimits()
+	breaker            *circuitBreaker           // Failing fast while TimeSeriesDB is down, see SetCircuitBreaker()
+	readBreaker        *circuitBreaker           // Failing fast while the read endpoint is down
+	metrics            MetricsHook               // Receives the outcome of the operations, see SetMetricsHook()
+	tracer             Tracer                    // Creates the spans of the operations, see SetTracer()
+	log                Logger                    // Receives the log messages, zerolog when nil, see SetLogger()
+	tlsOptions         *TLSOptions               // TLS settings, taken from the environment when nil
+	credLock           sync.RWMutex              // Protects the credentials, tokenWatcher and tokenRefreshHook
+	tokenWatcher       *tokenWatcher             // Token file the credentials are taken from, see SetTokenFile()
+	tokenRefreshHook   func(error)               // Called after a changed token file is reloaded
+	xappConfig         *xappConfigWatcher        // xApp configuration file of the client, see NewTimeSeriesClientFromXappConfig()
+	background         tracked                   // BatchWriters, Aggregators, Watchers and Alerts closed by Close()
+	namespaceLock      sync.RWMutex              // Protects namespace, xappName, xappVersion and globalTags
+	namespace          string                    // Prefix of the measurements of the client, see SetNamespace()
+	xappName           string                    // Value of the xapp tag of the written points
+	xappVersion        string                    // Value of the xapp_version tag of the written points
+	globalTags         map[string]string         // Tags added to the written points, see SetGlobalTags()
+	jsonConfigLock     sync.RWMutex              // Protects tagKeys, timeKeys, flattenOptions and fieldMappings
+	tagKeys            map[string][]string       // Flattened JSON keys stored as tags, per measurement
+	timeKeys           map[string]jsonTimeKey    // Flattened JSON key holding the point timestamp, per measurement
+	flattenOptions     map[string]FlattenOptions // Flattening of the inserted JSON, per measurement
+	fieldMappings      map[string]FieldMapping   // Normalization of the inserted JSON keys, per measurement
 }
 
 type JsonRow map[string]interface{}
 
+// Handling of a single JSON object (not wrapped in an array) passed to InsertJsonArray
+type SingleObjectMode int
+
+const (
+	SingleObjectWrap   SingleObjectMode = iota // Insert the object as a one element array (default)
+	SingleObjectReject                         // Fail with ErrNotJsonArray
+)
+
+// Decoding of the numbers of the inserted JSON
+type JsonNumberMode int
+
+const (
+	JsonNumberFloat    JsonNumberMode = iota // Store all numbers as float64 fields (default)
+	JsonNumberPreserve                       // Store integer literals as int64 fields, other numbers as float64
+)
+
+var ErrTimeSeriesDBNotFound = errors.New("TimeSeriesDB not found")
+
+var ErrNotConnected = errors.New("Not connected to TimeSeriesDB")
+
+// Kinds of the errors of queries and writes, the error of the client or TimeSeriesDB is kept as cause
+var ErrQueryFailed = errors.New("TimeSeriesDB query failed")
+var ErrWriteFailed = errors.New("TimeSeriesDB write failed")
+
+// Error of a kind (eg. ErrQueryFailed) with its cause, see IsKind() and ErrorCause()
+type kindError struct {
+	kind  error
+	cause error
+}
+
+func (e *kindError) Error() string {
+	return e.kind.Error() + ": " + e.cause.Error()
+}
+
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *kindError) Unwrap() error {
+	return e.cause
+}
+
+// Returns whether err is the error kind (eg. ErrWriteFailed), an error of that kind or caused by it
+func IsKind(err error, kind error) bool {
+	if e, ok := err.(*kindError); ok {
+		return e.kind == kind || IsKind(e.cause, kind)
+	}
+	return err == kind
+}
+
+// Returns the error of the client or TimeSeriesDB causing err, nil when err is not of a kind
+func ErrorCause(err error) error {
+	if e, ok := err.(*kindError); ok {
+		return e.cause
+	}
+	return nil
+}
+
+var ErrNotJsonArray = errors.New("JSON payload is an object, not an array")
+
+var ErrKeyNotFound = errors.New("Key not found")
+
+// Handling of the JSON arrays when flattening
+type ArrayMode int
+
+const (
+	ArrayIndex   ArrayMode = iota // Flatten the elements under their index, eg. cells.0.rsrp (default)
+	ArrayJoin                     // Store arrays of scalars as one comma separated string, other arrays as JSON strings
+	ArrayJSON                     // Store arrays as JSON strings
+	ArrayExplode                  // Insert each element of the arrays of objects as a point of its own, tagged with its ElementKey. Arrays of scalars are joined
+)
+
+// Flattening of the JSON inserted in a measurement, see SetFlattenOptions()
+type FlattenOptions struct {
+	Separator string    // Separator of the keys of the nesting levels, "." when empty
+	ArrayMode ArrayMode // Handling of the arrays
+	MaxDepth  int       // Number of nesting levels flattened, deeper objects and arrays are stored as JSON strings. 0 for no limit
+
+	// With ArrayExplode, key of the array elements (eg. CID of a neighbor cell) stored as tag of their point, named
+	// by the flattened key (eg. Neighbors.CID). Elements without it are tagged with their index, under <array>.index
+	// when ElementKey is empty
+	ElementKey string
+}
+
+// Flattened JSON key holding the point timestamp and its layout
+type jsonTimeKey struct {
+	key    string
+	layout string
+}
+
+// Handling of NaN and +/-Inf float fields, which TimeSeriesDB rejects along with the whole point
+type NonFinitePolicy int
+
+const (
+	NonFiniteDrop       NonFinitePolicy = iota // Drop the field and write the rest of the point (default)
+	NonFiniteSubstitute                        // Replace the value with the sentinel set with SetNonFinitePolicy()
+	NonFiniteError                             // Fail the write with ErrNonFiniteField
+)
+
+var ErrNonFiniteField = errors.New("Field value is NaN or Inf")
+
+// Value of a field along with the time of the point it belongs to
+type TimedValue struct {
+	Time  time.Time
+	Value interface{}
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 //                                     Constructor for TimeSeriesClientData
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
 func NewTimeSeriesClientData(dbName, userName, passWord string) *TimeSeriesClientData {
 	zerolog.SetGlobalLevel(zerolog.InfoLevel) //default logging, can be changed using SetLoggingLevel()
 	return &TimeSeriesClientData{
 		timeSeriesDbName:   dbName,
 		timeSeriesUserName: userName,
 		timeSeriesPassword: passWord,
+		reconnectPolicy:    DefaultReconnectPolicy,
 	}
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 //                                     Methods for TimeSeriesClientData
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
 func (timeserData *TimeSeriesClientData) CreateTimeSeriesConnection() (err error) {
+	config, err := timeserData.httpConfig()
+	if err != nil {
+		timeserData.logger().Errorf("Error creating TimeSeriesDB Client: %v\n", err.Error())
+		return err
+	}
+	timeserData.logger().Infof("Establishing connection with TimeSeriesDB %v\n", config.Addr)
+	// The connection stays open until Close(), and is re-created when the health check fails
+	// or the token changes, with the credentials current at that time
+	client, err := newReconnectingClient(timeserData.connector(""), (*timeserData).reconnectPolicy, timeserData.logger())
+	if err == nil && timeserData.readHost != "" {
+		err = timeserData.connectReadEndpoint(config.Addr)
+		if err != nil {
+			client.Close()
+		}
+	}
+	if err != nil {
+		timeserData.logger().Errorf("Error creating TimeSeriesDB Client: %v\n", err.Error())
+	} else {
+		(*timeserData).Iclient = client
+		timeserData.logger().Infof("TimeSeriesDB Client created successfully: %v\n", config.Addr)
+		timeserData.startTokenWatcher()
+	}
+	return err
+}
+
+// Returns the function creating the clients of the connection, to addr instead of the configured host when not empty
+func (timeserData *TimeSeriesClientData) connector(addr string) func() (TimeSeriesDataGoClient, error) {
+	return func() (TimeSeriesDataGoClient, error) {
+		config, err := timeserData.httpConfig()
+		if err != nil {
+			return nil, err
+		}
+		if addr != "" {
+			config.Addr = addr
+		}
+		if timeserData.queryPoolSize > 1 {
+			return NewPooledClient(timeserData.queryPoolSize, func() (TimeSeriesDataGoClient, error) {
+				return timesrclient.NewHTTPClient(config)
+			})
+		}
+		return timesrclient.NewHTTPClient(config)
+	}
+}
+
+// Builds the configuration of the connection to TimeSeriesDB
+func (timeserData *TimeSeriesClientData) httpConfig() (config timesrclient.HTTPConfig, err error) {
 	// TimeSeriesDB specific intialization
-	hostname := os.Getenv("TIMESERIESDB_SERVICE_HOST")
+	hostname := timeserData.host
+	if hostname == "" {
+		hostname = os.Getenv("TIMESERIESDB_SERVICE_HOST")
+	}
 	if hostname == "" {
 		hostname = "localhost"
 	}
-	port := os.Getenv("TIMESERIESDB_SERVICE_PORT_HTTP")
+	port := timeserData.port
+	if port == "" {
+		port = os.Getenv("TIMESERIESDB_SERVICE_PORT_HTTP")
+	}
 	if port == "" {
 		port = "8086"
 	}
-	log.Info().Msgf("Establishing connection with TimeSeriesDB hostname: %v, port: %v\n", hostname, port)
-	(*timeserData).Iclient, err = timesrclient.NewHTTPClient(timesrclient.HTTPConfig{
-		Addr:     fmt.Sprintf("http://%v:%v", hostname, port),
-		Username: (*timeserData).timeSeriesUserName,
-		Password: (*timeserData).timeSeriesPassword,
-	})
+	tlsOptions := TLSOptionsFromEnv()
+	if timeserData.tlsOptions != nil {
+		tlsOptions = *timeserData.tlsOptions
+	}
+
+	scheme := "http"
+	if tlsOptions.IsEnabled() {
+		scheme = "https"
+		if config.TLSConfig, err = tlsOptions.TLSConfig(); err != nil {
+			return config, err
+		}
+		config.InsecureSkipVerify = tlsOptions.InsecureSkipVerify
+	}
+	config.Addr = fmt.Sprintf("%v://%v:%v", scheme, hostname, port)
+	config.Username, config.Password = timeserData.credentials()
+	config.Timeout = timeserData.timeout
+	if timeserData.writeGzip {
+		config.WriteEncoding = timesrclient.GzipEncoding
+	}
+	return config, nil
+}
+
+// Sets the health checking and reconnection of the connections created afterwards by CreateTimeSeriesConnection()
+func (timeserData *TimeSeriesClientData) SetReconnectPolicy(policy ReconnectPolicy) {
+	timeserData.reconnectPolicy = policy
+}
+
+// Stops the background processing of the client and closes the connection to TimeSeriesDB, see CloseContext()
+func (timeserData *TimeSeriesClientData) Close() (err error) {
+	return timeserData.CloseContext(context.Background())
+}
+
+// Attaches to an existing database without creating it, populating RetentionPolicy and RetentionDuration.
+// Returns ErrTimeSeriesDBNotFound if the database does not exist
+func (timeserData *TimeSeriesClientData) AttachTimeSeriesDB() (err error) {
+	q := timesrclient.NewQuery(fmt.Sprintf("SHOW RETENTION POLICIES ON %v", _quoteIdent(timeserData.timeSeriesDbName)), "", "")
+	response, err := timeserData.query(q)
 	if err != nil {
-		log.Error().Msgf("Error creating TimeSeriesDB Client: %v\n", err.Error())
-	} else {
-		log.Info().Msgf("TimeSeriesDB Client created successfully: %v\n", (*timeserData).Iclient)
-		defer timeserData.Iclient.Close()
+		if strings.Contains(err.Error(), "database not found") {
+			err = ErrTimeSeriesDBNotFound
+		}
+		timeserData.logger().Errorf("Failed to attach DB %v with error %v\n", timeserData.timeSeriesDbName, err)
+		return err
 	}
-	return err
+
+	// Columns are name, duration, shardGroupDuration, replicaN, default
+	for _, result := range response.Results {
+		for _, row := range result.Series {
+			for _, value := range row.Values {
+				if len(value) < 5 || value[4] != true {
+					continue
+				}
+				name, _ := value[0].(string)
+				duration, _ := value[1].(string)
+				timeserData.RetentionPolicy = name
+				timeserData.RetentionDuration, err = time.ParseDuration(duration)
+				if err != nil {
+					return err
+				}
+				timeserData.logger().Infof("Sucessfully attached DB %v with retention policy %v (%v)\n", timeserData.timeSeriesDbName, name, duration)
+				return nil
+			}
+		}
+	}
+	return ErrTimeSeriesDBNotFound
 }
 
 // Creates a new database
 func (timeserData *TimeSeriesClientData) CreateTimeSeriesDB() (err error) {
 	q := timesrclient.NewQuery(fmt.Sprintf("CREATE DATABASE %v", (*timeserData).timeSeriesDbName), "", "")
 
-	if response, err := (*timeserData).Iclient.Query(q); err == nil && response.Error() == nil {
-		log.Info().Msgf("Sucessfully created DB %v\n", (*timeserData).timeSeriesDbName)
+	// Response errors are returned as err by query()
+	if _, err = (*timeserData).query(q); err == nil {
+		timeserData.logger().Infof("Sucessfully created DB %v\n", (*timeserData).timeSeriesDbName)
 	} else {
-		log.Error().Msgf("Failed to create DB %v with error %v\n", (*timeserData).timeSeriesDbName, err)
+		timeserData.logger().Errorf("Failed to create DB %v with error %v\n", (*timeserData).timeSeriesDbName, err)
 	}
 	return err
 }
 
 // Creates a new database
 func (timeserData *TimeSeriesClientData) CreateTimeSeriesDBWithRetentionPolicy(retentionPolicyName, duration string) (err error) {
+	if duration, err = _retentionLiteral(duration); err != nil {
+		return err
+	}
 	q := timesrclient.NewQuery(fmt.Sprintf("CREATE DATABASE %v WITH DURATION %v REPLICATION 1 SHARD DURATION %v NAME %v", (*timeserData).timeSeriesDbName, duration, duration, retentionPolicyName), "", "")
 
-	if response, err := (*timeserData).Iclient.Query(q); err == nil && response.Error() == nil {
-		log.Info().Msgf("Sucessfully created DB %v with retention policy %v\n", (*timeserData).timeSeriesDbName, retentionPolicyName)
+	// Response errors are returned as err by query()
+	if _, err = (*timeserData).query(q); err == nil {
+		timeserData.logger().Infof("Sucessfully created DB %v with retention policy %v\n", (*timeserData).timeSeriesDbName, retentionPolicyName)
 	} else {
-		log.Error().Msgf("Failed to create DB %v with retention policy %v with error %v\n", (*timeserData).timeSeriesDbName, retentionPolicyName, err)
+		timeserData.logger().Errorf("Failed to create DB %v with retention policy %v with error %v\n", (*timeserData).timeSeriesDbName, retentionPolicyName, err)
+	}
+	return err
+}
+
+// Creates a database other than the one of the client, eg. for aggregated data kept longer than the raw data,
+// to be used with WritePointTo() and QueryFrom(). The retention policy is created only when its name is not empty
+func (timeserData *TimeSeriesClientData) CreateTimeSeriesDBNamed(dbName, retentionPolicyName, duration string) (err error) {
+	queryStr := fmt.Sprintf("CREATE DATABASE %v", _quoteIdent(dbName))
+	if retentionPolicyName != "" {
+		if duration, err = _retentionLiteral(duration); err != nil {
+			return err
+		}
+		queryStr += fmt.Sprintf(" WITH DURATION %v REPLICATION 1 SHARD DURATION %v NAME %v", duration, duration, _quoteIdent(retentionPolicyName))
+	}
+	_, err = timeserData.query(timesrclient.NewQuery(queryStr, "", ""))
+	if err == nil {
+		timeserData.logger().Infof("Sucessfully created DB %v\n", dbName)
+	} else {
+		timeserData.logger().Errorf("Failed to create DB %v with error %v\n", dbName, err)
 	}
 	return err
 }
@@ -120,33 +421,134 @@ func (timeserData *TimeSeriesClientData) CreateTimeSeriesDBWithRetentionPolicy(r
 func (timeserData *TimeSeriesClientData) DeleteTimeSeriesDB() (err error) {
 	q := timesrclient.NewQuery(fmt.Sprintf("DROP DATABASE %v", (*timeserData).timeSeriesDbName), "", "")
 
-	if response, err := (*timeserData).Iclient.Query(q); err == nil && response.Error() == nil {
-		log.Info().Msgf("Sucessfully deleted DB %v\n", (*timeserData).timeSeriesDbName)
+	// Response errors are returned as err by query()
+	if _, err = (*timeserData).query(q); err == nil {
+		timeserData.logger().Infof("Sucessfully deleted DB %v\n", (*timeserData).timeSeriesDbName)
+	} else {
+		timeserData.logger().Errorf("Failed to delete DB %v with error %v\n", (*timeserData).timeSeriesDbName, err)
+	}
+	timeserData.invalidateCache("")
+	return err
+}
+
+// Deletes a database other than the one of the client, eg. of a tenant being removed
+func (timeserData *TimeSeriesClientData) DeleteTimeSeriesDBNamed(dbName string) (err error) {
+	if _, err = timeserData.query(timesrclient.NewQuery(fmt.Sprintf("DROP DATABASE %v", _quoteIdent(dbName)), "", "")); err == nil {
+		timeserData.logger().Infof("Sucessfully deleted DB %v\n", dbName)
 	} else {
-		log.Error().Msgf("Failed to delete DB %v with error %v\n", (*timeserData).timeSeriesDbName, err)
+		timeserData.logger().Errorf("Failed to delete DB %v with error %v\n", dbName, err)
 	}
 	return err
 }
 
 // Deletes a table
 func (timeserData *TimeSeriesClientData) DropMeasurement(measurement string) (err error) {
-	q := timesrclient.NewQuery(fmt.Sprintf("DELETE FROM %v", measurement), (*timeserData).timeSeriesDbName, "")
+	q := timesrclient.NewQuery(fmt.Sprintf("DELETE FROM %v", timeserData.measurementIdent(measurement)), (*timeserData).timeSeriesDbName, "")
 
-	if response, err := (*timeserData).Iclient.Query(q); err == nil && response.Error() == nil {
-		log.Info().Msgf("Sucessfully deleted measurement %v\n", measurement)
+	_, err = (*timeserData).query(q)
+	if err == nil {
+		timeserData.logger().Infof("Sucessfully deleted measurement %v\n", measurement)
 	} else {
-		log.Error().Msgf("Failed to delete measurement %v with error %v\n", measurement, err)
+		timeserData.logger().Errorf("Failed to delete measurement %v with error %v\n", measurement, err)
 	}
+	timeserData.invalidateCache(measurement)
 	return err
 }
 
+// Deletes the points of a measurement matching all the tags within [start, stop). Zero start or stop leaves
+// the range open on that side, an empty measurement stands for all measurements
+func (timeserData *TimeSeriesClientData) DeleteData(measurement string, tags map[string]string, start, stop time.Time) (err error) {
+	queryStr := "DELETE"
+	if measurement != "" {
+		queryStr += " FROM " + timeserData.measurementIdent(measurement)
+	}
+	conditions := []string{}
+	if tagCondition := _tagCondition(tags); tagCondition != "" {
+		conditions = append(conditions, tagCondition)
+	}
+	if !start.IsZero() {
+		conditions = append(conditions, "time >= "+_quoteLiteral(start.UTC().Format(time.RFC3339Nano)))
+	}
+	if !stop.IsZero() {
+		conditions = append(conditions, "time < "+_quoteLiteral(stop.UTC().Format(time.RFC3339Nano)))
+	}
+	if len(conditions) > 0 {
+		queryStr += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	q := timesrclient.NewQuery(queryStr, (*timeserData).timeSeriesDbName, "")
+
+	_, err = (*timeserData).query(q)
+	if err == nil {
+		timeserData.logger().Infof("Sucessfully deleted points of measurement %v with tags %v between %v and %v\n", measurement, tags, start, stop)
+	} else {
+		timeserData.logger().Errorf("Failed to delete points of measurement %v with error %v\n", measurement, err)
+	}
+	timeserData.invalidateCache(measurement)
+	return err
+}
+
+// Deletes the points matching the predicate within [start, stop) across all measurements.
+// Predicate is an InfluxQL tag condition (eg. "cellId" = '1'), empty predicate matches all points
+func (timeserData *TimeSeriesClientData) DeleteByTimeRange(predicate string, start, stop time.Time) (err error) {
+	q := timesrclient.NewQuery(fmt.Sprintf("DELETE WHERE %v", _whereClause(predicate, start, stop)), (*timeserData).timeSeriesDbName, "")
+
+	_, err = (*timeserData).query(q)
+	if err == nil {
+		timeserData.logger().Infof("Sucessfully deleted points matching '%v' between %v and %v\n", predicate, start, stop)
+	} else {
+		timeserData.logger().Errorf("Failed to delete points matching '%v' with error %v\n", predicate, err)
+	}
+	timeserData.invalidateCache("")
+	return err
+}
+
+// Dry-run of DeleteByTimeRange, counts the points that would be deleted without deleting them, in all the
+// retention policies of the DB. The points of a series are counted by its field present in the most points,
+// exact when a field is present in every point (eg. a KPI) and a lower bound otherwise
+func (timeserData *TimeSeriesClientData) CountToDelete(predicate string, start, stop time.Time) (count int64, err error) {
+	info, err := timeserData.GetTimeSeriesDBInfo()
+	if err != nil {
+		return 0, err
+	}
+	statements := make([]string, len(info.RetentionPolicies))
+	for i, policy := range info.RetentionPolicies {
+		statements[i] = fmt.Sprintf("SELECT COUNT(*) FROM %v.%v./.*/ WHERE %v", _quoteIdent(info.Name), _quoteIdent(policy.Name), _whereClause(predicate, start, stop))
+	}
+	if len(statements) == 0 {
+		return 0, nil
+	}
+	q := timesrclient.NewQuery(strings.Join(statements, "; "), (*timeserData).timeSeriesDbName, "")
+
+	response, err := (*timeserData).query(q)
+	if err != nil {
+		timeserData.logger().Errorf("Failed to count points matching '%v' with error %v\n", predicate, err)
+		return 0, err
+	}
+	for _, result := range response.Results {
+		for _, row := range result.Series {
+			// COUNT(*) reports one column per field, the widest one is the number of points in the series
+			var seriesCount int64
+			for _, value := range row.Values {
+				for _, column := range value[1:] {
+					if n, ok := _toInt64(column); ok && n > seriesCount {
+						seriesCount = n
+					}
+				}
+			}
+			count += seriesCount
+		}
+	}
+	timeserData.logger().Debugf("TimeSeriesDB CountToDelete: DB=%v predicate=%v count=%v\n", timeserData.timeSeriesDbName, predicate, count)
+	return count, nil
+}
+
 // Set operation to mimic traditional key-value pair setting.
 // PS - This creates new row than updating existing one to demonstrate time series capability
 func (timeserData *TimeSeriesClientData) Set(measurement, key string, value []byte) (err error) {
 	// Create a new point batch
 	bp, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
 		Database:  (*timeserData).timeSeriesDbName,
-		Precision: "ns",
+		Precision: timeserData.writePrecision(),
 	})
 
 	// Create a point and add to batch
@@ -156,68 +558,478 @@ func (timeserData *TimeSeriesClientData) Set(measurement, key string, value []by
 	}
 	pt, err := timesrclient.NewPoint(measurement, tags, fields, time.Now())
 	if err != nil {
-		fmt.Println("Error: ", err.Error())
+		timeserData.logger().Errorf("Error: %v\n", err.Error())
 		return err
 	}
 	bp.AddPoint(pt)
 	// Write the batch
-	timeserData.Iclient.Write(bp)
-	log.Debug().Msgf("TimeSeriesDB Set: DB=%v Measurement=%v key=%v, value=%v err=%v\n", timeserData.timeSeriesDbName, measurement, key, value, err)
+	err = timeserData.write(bp)
+	timeserData.logger().Debugf("TimeSeriesDB Set: DB=%v Measurement=%v key=%v, value=%v err=%v\n", timeserData.timeSeriesDbName, measurement, key, value, err)
 	return err
 }
 
-// Get operation to mimic traditional key-value pair get operation
+// Get operation to mimic traditional key-value pair get operation, ErrKeyNotFound when the key has no value
 func (timeserData *TimeSeriesClientData) Get(measurement, key string) (result interface{}, err error) {
-	queryStr := fmt.Sprintf("SELECT %v FROM %v ORDER BY time DESC LIMIT 1", key, measurement)
+	cache := timeserData.valueCache
+	if cache != nil {
+		if value, ok := cache.get(_cacheKey(timeserData.MeasurementName(measurement), key)); ok {
+			return value, nil
+		}
+	}
+	queryStr := fmt.Sprintf("SELECT %v FROM %v ORDER BY time DESC LIMIT 1", _quoteIdent(key), timeserData.measurementIdent(measurement))
 	q := timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, "")
-	if response, err := timeserData.Iclient.Query(q); err == nil && response.Error() == nil {
-		for _, v := range response.Results {
-			for _, row := range v.Series {
-				for _, value := range row.Values {
-					fmt.Printf("Row: %v, Value: %v\n", row, value)
-					result = value[1] // value[0] is time
+	response, err := timeserData.query(q)
+	if err != nil {
+		timeserData.logger().Errorf("Failed to get %v from measurement %v with error %v\n", key, measurement, err)
+		return nil, err
+	}
+
+	err = ErrKeyNotFound
+	for _, v := range response.Results {
+		for _, row := range v.Series {
+			for _, value := range row.Values {
+				timeserData.logger().Debugf("Row: %v, Value: %v\n", row, value)
+				// value[0] is time
+				if len(value) != 2 {
+					return nil, fmt.Errorf("Unexpected row %v for key %v", value, key)
+				}
+				if value[1] != nil {
+					result, err = value[1], nil
 				}
 			}
 		}
 	}
-	log.Debug().Msgf("TimeSeriesDB Get: DB=%v Measurement=%v key=%v, value=%v err=%v\n", timeserData.timeSeriesDbName, measurement, key, result, err)
+	timeserData.logger().Debugf("TimeSeriesDB Get: DB=%v Measurement=%v key=%v, value=%v err=%v\n", timeserData.timeSeriesDbName, measurement, key, result, err)
+	if err == nil && cache != nil {
+		cache.put(_cacheKey(timeserData.MeasurementName(measurement), key), result)
+	}
 	return result, err
 }
 
-// Generic query operation
-func (timeserData *TimeSeriesClientData) Query(queryStr string) (resp *timesrclient.Response, err error) {
-	q := timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, "")
-	response, err := timeserData.Iclient.Query(q)
-	log.Debug().Msgf("TimeSeriesDB Query: DB=%v, QueryString=%v, Result=%v, err=%v\n", timeserData.timeSeriesDbName, queryStr, response, err)
-	return response, err
+// Gets the latest value of a key as float64, numeric strings are parsed
+func (timeserData *TimeSeriesClientData) GetFloat(measurement, key string) (float64, error) {
+	value, err := timeserData.Get(measurement, key)
+	if err != nil {
+		return 0, err
+	}
+	f, err := _toFloat64(value)
+	if err != nil {
+		return 0, fmt.Errorf("Value %v of key %v is not a float", value, key)
+	}
+	return f, nil
+}
+
+// Gets the latest value of a key as int64, integer strings are parsed
+func (timeserData *TimeSeriesClientData) GetInt(measurement, key string) (int64, error) {
+	value, err := timeserData.Get(measurement, key)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := _toInteger(value)
+	if !ok {
+		return 0, fmt.Errorf("Value %v of key %v is not an integer", value, key)
+	}
+	return n, nil
+}
+
+// Gets the latest value of a key as string
+func (timeserData *TimeSeriesClientData) GetString(measurement, key string) (string, error) {
+	value, err := timeserData.Get(measurement, key)
+	if err != nil {
+		return "", err
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("Value %v of key %v is not a string", value, key)
+	}
+	return str, nil
+}
+
+// Gets the latest value of a key as bool, strings as accepted by strconv.ParseBool are parsed
+func (timeserData *TimeSeriesClientData) GetBool(measurement, key string) (bool, error) {
+	value, err := timeserData.Get(measurement, key)
+	if err != nil {
+		return false, err
+	}
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("Value %v of key %v is not a bool", value, key)
+}
+
+// Sets several keys in a single point, as Set() does for one key
+func (timeserData *TimeSeriesClientData) SetMulti(measurement string, values map[string]interface{}) (err error) {
+	if len(values) == 0 {
+		return errors.New("SetMulti needs at least one key")
+	}
+	return timeserData.WritePoint(measurement, map[string]string{}, values)
+}
+
+// Gets the latest value of each of the keys with a single request, keys without value are not in the result
+func (timeserData *TimeSeriesClientData) GetMulti(measurement string, keys []string) (result map[string]interface{}, err error) {
+	result = make(map[string]interface{})
+	missing := keys
+	if cache := timeserData.valueCache; cache != nil {
+		missing = nil
+		for _, key := range keys {
+			if value, ok := cache.get(_cacheKey(timeserData.MeasurementName(measurement), key)); ok {
+				result[key] = value
+			} else {
+				missing = append(missing, key)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+	selections := make([]string, len(missing))
+	for i, key := range missing {
+		selections[i] = fmt.Sprintf("LAST(%v) AS %v", _quoteIdent(key), _quoteIdent(key))
+	}
+	values, err := timeserData.getLatest(measurement, strings.Join(selections, ", "), "")
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		result[key] = value
+	}
+	return result, nil
+}
+
+// Gets the latest value of every key of the measurement with a single request
+func (timeserData *TimeSeriesClientData) GetAll(measurement string) (result map[string]interface{}, err error) {
+	return timeserData.getLatest(measurement, "LAST(*)", "last_")
+}
+
+// Gets the latest values selected from the measurement by key, the column names without prefix
+func (timeserData *TimeSeriesClientData) getLatest(measurement, selection, prefix string) (result map[string]interface{}, err error) {
+	queryStr := fmt.Sprintf("SELECT %v FROM %v", selection, timeserData.measurementIdent(measurement))
+	q := timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, "")
+	response, err := timeserData.query(q)
+	if err != nil {
+		timeserData.logger().Errorf("Failed to get latest values from measurement %v with error %v\n", measurement, err)
+		return nil, err
+	}
+
+	result = make(map[string]interface{})
+	for _, v := range response.Results {
+		for _, row := range v.Series {
+			for _, value := range row.Values {
+				// Column 0 is time
+				for i := 1; i < len(row.Columns) && i < len(value); i++ {
+					if value[i] != nil {
+						result[strings.TrimPrefix(row.Columns[i], prefix)] = value[i]
+					}
+				}
+			}
+		}
+	}
+	timeserData.logger().Debugf("TimeSeriesDB getLatest: DB=%v Measurement=%v selection=%v, result=%v\n", timeserData.timeSeriesDbName, measurement, selection, result)
+	if cache := timeserData.valueCache; cache != nil {
+		for key, value := range result {
+			cache.put(_cacheKey(timeserData.MeasurementName(measurement), key), value)
+		}
+	}
+	return result, nil
+}
+
+// Gets the values of a key in [start, stop) in chronological order, a zero stop means no upper bound
+func (timeserData *TimeSeriesClientData) GetRange(measurement, key string, start, stop time.Time) (result []TimedValue, err error) {
+	queryStr := fmt.Sprintf("SELECT %v FROM %v WHERE time >= %v", _quoteIdent(key), timeserData.measurementIdent(measurement), _quoteLiteral(start.UTC().Format(time.RFC3339Nano)))
+	if !stop.IsZero() {
+		queryStr += " AND time < " + _quoteLiteral(stop.UTC().Format(time.RFC3339Nano))
+	}
+	q := timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, "")
+	response, err := timeserData.query(q)
+	if err != nil {
+		timeserData.logger().Errorf("Failed to get %v from measurement %v with error %v\n", key, measurement, err)
+		return nil, err
+	}
+
+	result = []TimedValue{}
+	for _, v := range response.Results {
+		for _, row := range v.Series {
+			values, err := _timedValues(row.Values)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, values...)
+		}
+	}
+	timeserData.logger().Debugf("TimeSeriesDB GetRange: DB=%v Measurement=%v key=%v, start=%v, stop=%v, values=%v\n", timeserData.timeSeriesDbName, measurement, key, start, stop, len(result))
+	return result, nil
+}
+
+// Gets the newest n values of a key in chronological order
+func (timeserData *TimeSeriesClientData) GetHistory(measurement, key string, n int) (result []TimedValue, err error) {
+	values, err := timeserData.GetLastNFields(measurement, []string{key}, n)
+	if err != nil {
+		return nil, err
+	}
+	return values[key], nil
+}
+
+// Gets the newest n values of each of the fields in chronological order, using a single request to the TimeSeriesDB
+func (timeserData *TimeSeriesClientData) GetLastNFields(measurement string, fields []string, n int) (result map[string][]TimedValue, err error) {
+	if len(fields) == 0 || n <= 0 {
+		return nil, errors.New("GetLastNFields needs at least one field and n > 0")
+	}
+	// One statement per field so that points missing a field do not reduce the count of the others
+	statements := make([]string, 0, len(fields))
+	for _, field := range fields {
+		statements = append(statements, fmt.Sprintf("SELECT %v FROM %v ORDER BY time DESC LIMIT %v", _quoteIdent(field), timeserData.measurementIdent(measurement), n))
+	}
+	q := timesrclient.NewQuery(strings.Join(statements, "; "), timeserData.timeSeriesDbName, "")
+	response, err := timeserData.query(q)
+	if err != nil {
+		timeserData.logger().Errorf("Failed to get last %v values of %v from measurement %v with error %v\n", n, fields, measurement, err)
+		return nil, err
+	}
+
+	result = make(map[string][]TimedValue, len(fields))
+	for i, field := range fields {
+		values := []TimedValue{}
+		if i < len(response.Results) {
+			for _, row := range response.Results[i].Series {
+				rowValues, err := _timedValues(row.Values)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, rowValues...)
+			}
+		}
+		// Query returns newest first
+		for l, r := 0, len(values)-1; l < r; l, r = l+1, r-1 {
+			values[l], values[r] = values[r], values[l]
+		}
+		result[field] = values
+	}
+	timeserData.logger().Debugf("TimeSeriesDB GetLastNFields: DB=%v Measurement=%v fields=%v, n=%v, result=%v\n", timeserData.timeSeriesDbName, measurement, fields, n, result)
+	return result, nil
+}
+
+// Generic query operation
+func (timeserData *TimeSeriesClientData) Query(queryStr string) (resp *timesrclient.Response, err error) {
+	return timeserData.QueryFrom(timeserData.timeSeriesDbName, queryStr)
+}
+
+// Generic query operation on another database than the one of the client
+func (timeserData *TimeSeriesClientData) QueryFrom(dbName, queryStr string) (resp *timesrclient.Response, err error) {
+	q := timesrclient.NewQuery(queryStr, dbName, "")
+	response, err := timeserData.query(q)
+	timeserData.logger().Debugf("TimeSeriesDB Query: DB=%v, QueryString=%v, Result=%v, err=%v\n", dbName, queryStr, response, err)
+	return response, err
+}
+
+// Sets how NaN and Inf float fields are handled by WritePoint and the JSON insert operations
+func (timeserData *TimeSeriesClientData) SetNonFinitePolicy(policy NonFinitePolicy, sentinel float64) {
+	timeserData.nonFinitePolicy = policy
+	timeserData.nonFiniteSentinel = sentinel
+}
+
+// Query operation with bound parameters, referenced as $name in the query (eg. WHERE "cellId" = $cell).
+// The parameters are sent separately from the query, so their values cannot alter the statement.
+// Identifiers cannot be bound, use QuoteIdentifier to embed them
+func (timeserData *TimeSeriesClientData) QueryWithParams(queryStr string, params map[string]interface{}) (resp *timesrclient.Response, err error) {
+	q := timesrclient.NewQueryWithParameters(queryStr, timeserData.timeSeriesDbName, "", params)
+	response, err := timeserData.query(q)
+	timeserData.logger().Debugf("TimeSeriesDB QueryWithParams: DB=%v, QueryString=%v, Params=%v, Result=%v, err=%v\n", timeserData.timeSeriesDbName, queryStr, params, response, err)
+	return response, err
+}
+
+// Quotes and escapes a measurement, field or tag name for embedding in a query
+func QuoteIdentifier(name string) string {
+	return _quoteIdent(name)
+}
+
+// Quotes and escapes a string value for embedding in a query
+func QuoteLiteral(value string) string {
+	return _quoteLiteral(value)
+}
+
+// Generic query operation returning the result rows, each row holds the columns and tags of its series
+func (timeserData *TimeSeriesClientData) QueryRows(queryStr string) (rows []JsonRow, err error) {
+	response, err := timeserData.Query(queryStr)
+	if err != nil {
+		return nil, err
+	}
+	return _jsonRows(response), nil
+}
+
+// Generic write point operation
+func (timeserData *TimeSeriesClientData) WritePoint(measurement string, tags map[string]string, fields map[string]interface{}) (err error) {
+	return timeserData.WritePointTo(timeserData.timeSeriesDbName, measurement, tags, fields)
+}
+
+// Generic write point operation to another database than the one of the client
+func (timeserData *TimeSeriesClientData) WritePointTo(dbName, measurement string, tags map[string]string, fields map[string]interface{}) (err error) {
+	return timeserData.writePoint(context.Background(), dbName, measurement, tags, fields, time.Now())
+}
+
+// Generic write point operation with the timestamp of the point, eg. for backfilling historical values
+func (timeserData *TimeSeriesClientData) WritePointAt(measurement string, tags map[string]string, fields map[string]interface{}, t time.Time) (err error) {
+	return timeserData.writePoint(context.Background(), timeserData.timeSeriesDbName, measurement, tags, fields, t)
+}
+
+// Writes a point within the span of ctx
+func (timeserData *TimeSeriesClientData) writePoint(ctx context.Context, dbName, measurement string, tags map[string]string, fields map[string]interface{}, t time.Time) (err error) {
+	ctx, span := timeserData.startSpan(ctx, "stslgo.WritePoint")
+	span.SetAttribute("db.name", dbName)
+	span.SetAttribute("stslgo.measurement", measurement)
+	defer func() { span.End(err) }()
+
+	// Create a new point batch
+	bp, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+		Database:  dbName,
+		Precision: timeserData.writePrecision(),
+	})
+
+	fields, err = timeserData.finiteFields(measurement, fields)
+	if err == nil {
+		fields, err = timeserData.schemaFields(measurement, tags, fields)
+	}
+	if err != nil {
+		return err
+	}
+	// Create a point and add to batch
+	pt, err := timesrclient.NewPoint(measurement, tags, fields, t)
+	if err != nil {
+		timeserData.logger().Errorf("Error: %v\n", err.Error())
+		return err
+	}
+	bp.AddPoint(pt)
+	// Write the batch
+	err = timeserData.writeContext(ctx, bp)
+	timeserData.logger().Debugf("\nTimeSeriesDB WritePoint: DB=%v Measurement=%v tags=%v, fields=%v, err=%v", dbName, measurement, tags, fields, err)
+	return err
+}
+
+// Records the state of a boolean event (eg. alarm on/off) as a point in the measurement.
+// Only transitions are stored, a state equal to the last recorded one for the same event name and tags is not
+// written again. Unlike WritePoint, the error of the write is returned and the state is kept only once written
+func (timeserData *TimeSeriesClientData) RecordEvent(measurement, name string, active bool, tags map[string]string) (err error) {
+	eventTags := map[string]string{"name": name}
+	for k, v := range tags {
+		eventTags[k] = v
+	}
+	key := _seriesKey(measurement, eventTags) + "/" + name
+
+	timeserData.eventStateLock.Lock()
+	last, ok := timeserData.eventState[key]
+	timeserData.eventStateLock.Unlock()
+	if ok && last == active {
+		timeserData.logger().Debugf("TimeSeriesDB RecordEvent: Measurement=%v name=%v unchanged, active=%v\n", measurement, name, active)
+		return nil
+	}
+
+	fields, err := timeserData.schemaFields(measurement, eventTags, map[string]interface{}{"active": active})
+	if err != nil {
+		return err
+	}
+	pt, err := timesrclient.NewPoint(measurement, eventTags, fields, time.Now())
+	if err != nil {
+		timeserData.logger().Errorf("Error: %v\n", err.Error())
+		return err
+	}
+	bp, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+		Database:  timeserData.timeSeriesDbName,
+		Precision: timeserData.writePrecision(),
+	})
+	bp.AddPoint(pt)
+	if err = timeserData.write(bp); err != nil {
+		timeserData.logger().Errorf("Failed to record event %v of measurement %v with error %v\n", name, measurement, err)
+		return err
+	}
+
+	timeserData.eventStateLock.Lock()
+	defer timeserData.eventStateLock.Unlock()
+	if timeserData.eventState == nil {
+		timeserData.eventState = make(map[string]bool)
+	}
+	timeserData.eventState[key] = active
+	return nil
+}
+
+// Declares the flattened JSON keys (eg. CID, Cell-RF.cellId) stored as tags instead of fields when JSON
+// is inserted in the measurement. Passing no keys stores everything as fields again
+func (timeserData *TimeSeriesClientData) SetTagKeys(measurement string, tagKeys []string) {
+	timeserData.jsonConfigLock.Lock()
+	defer timeserData.jsonConfigLock.Unlock()
+	if timeserData.tagKeys == nil {
+		timeserData.tagKeys = make(map[string][]string)
+	}
+	if len(tagKeys) == 0 {
+		delete(timeserData.tagKeys, measurement)
+		return
+	}
+	timeserData.tagKeys[measurement] = append([]string{}, tagKeys...)
+}
+
+// Moves the tag keys of the measurement out of the flattened JSON into the returned tags
+func (timeserData *TimeSeriesClientData) extractTags(measurement string, flatjson map[string]interface{}) map[string]string {
+	tags := make(map[string]string)
+	timeserData.jsonConfigLock.RLock()
+	defer timeserData.jsonConfigLock.RUnlock()
+	for _, key := range timeserData.tagKeys[measurement] {
+		if value, ok := flatjson[key]; ok {
+			if value != nil {
+				tags[key] = _tagValue(value)
+			}
+			delete(flatjson, key)
+		}
+	}
+	return tags
+}
+
+// Declares the flattened JSON key holding the timestamp of the points when JSON is inserted in the measurement.
+// layout is a time.Parse layout (eg. time.RFC3339) for string values, or the epoch unit s, ms, us or ns for
+// numeric values. The key is not stored as field. Passing an empty key stamps the points with the insertion time again
+func (timeserData *TimeSeriesClientData) SetTimeKey(measurement, key, layout string) {
+	timeserData.jsonConfigLock.Lock()
+	defer timeserData.jsonConfigLock.Unlock()
+	if timeserData.timeKeys == nil {
+		timeserData.timeKeys = make(map[string]jsonTimeKey)
+	}
+	if key == "" {
+		delete(timeserData.timeKeys, measurement)
+		return
+	}
+	timeserData.timeKeys[measurement] = jsonTimeKey{key: key, layout: layout}
 }
 
-// Generic write point operation
-func (timeserData *TimeSeriesClientData) WritePoint(measurement string, tags map[string]string, fields map[string]interface{}) (err error) {
-	// Create a new point batch
-	bp, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
-		Database:  (*timeserData).timeSeriesDbName,
-		Precision: "ns",
-	})
+// Moves the time key of the measurement out of the flattened JSON and returns the timestamp it holds.
+// Returns the current time when the measurement has no time key or the JSON does not hold it
+func (timeserData *TimeSeriesClientData) extractTime(measurement string, flatjson map[string]interface{}) (time.Time, error) {
+	timeserData.jsonConfigLock.RLock()
+	timeKey, ok := timeserData.timeKeys[measurement]
+	timeserData.jsonConfigLock.RUnlock()
+	if !ok {
+		return time.Now(), nil
+	}
+	value, ok := flatjson[timeKey.key]
+	if !ok || value == nil {
+		timeserData.logger().Warnf("Time key %v missing in JSON for measurement %v, using current time\n", timeKey.key, measurement)
+		return time.Now(), nil
+	}
+	delete(flatjson, timeKey.key)
 
-	// Create a point and add to batch
-	pt, err := timesrclient.NewPoint(measurement, tags, fields, time.Now())
+	timestamp, err := _parseTime(value, timeKey.layout)
 	if err != nil {
-		fmt.Println("Error: ", err.Error())
-		return err
+		timeserData.logger().Errorf("Not able to parse time key %v=%v for measurement %v: %v\n", timeKey.key, value, measurement, err)
 	}
-	bp.AddPoint(pt)
-	// Write the batch
-	timeserData.Iclient.Write(bp)
-	log.Debug().Msgf("\nTimeSeriesDB WritePoint: DB=%v Measurement=%v tags=%v, fields=%v, err=%v", timeserData.timeSeriesDbName, measurement, tags, fields, err)
-	return err
+	return timestamp, err
 }
 
 // Function to flatten nested json
 func (timeserData *TimeSeriesClientData) Flatten(nested map[string]interface{}, prefix string, IgnoreKeyList []string) (map[string]interface{}, error) {
 	flatmap := make(map[string]interface{})
 
-	err := _flatten(true, flatmap, nested, prefix, IgnoreKeyList)
+	err := _flatten(true, flatmap, nested, prefix, IgnoreKeyList, FlattenOptions{}, 0, timeserData.logger())
 	if err != nil {
 		return nil, err
 	}
@@ -225,47 +1037,65 @@ func (timeserData *TimeSeriesClientData) Flatten(nested map[string]interface{},
 	return flatmap, nil
 }
 
-// Insert 1 or more Json Rows as a single batch
+// Sets how the JSON inserted in the measurement is flattened to fields
+func (timeserData *TimeSeriesClientData) SetFlattenOptions(measurement string, opts FlattenOptions) {
+	timeserData.jsonConfigLock.Lock()
+	defer timeserData.jsonConfigLock.Unlock()
+	if timeserData.flattenOptions == nil {
+		timeserData.flattenOptions = make(map[string]FlattenOptions)
+	}
+	timeserData.flattenOptions[measurement] = opts
+}
+
+// Returns the FlattenOptions of the measurement
+func (timeserData *TimeSeriesClientData) flattenOpts(measurement string) FlattenOptions {
+	timeserData.jsonConfigLock.RLock()
+	defer timeserData.jsonConfigLock.RUnlock()
+	return timeserData.flattenOptions[measurement]
+}
+
+// Flattens JSON inserted in the measurement as per its FlattenOptions
+func (timeserData *TimeSeriesClientData) flatten(measurement string, nested map[string]interface{}, ignoreList []string) (map[string]interface{}, error) {
+	flatmap := make(map[string]interface{})
+	if err := _flatten(true, flatmap, nested, "", ignoreList, timeserData.flattenOpts(measurement), 0, timeserData.logger()); err != nil {
+		return nil, err
+	}
+	return flatmap, nil
+}
+
+// Insert 1 or more Json Rows as a single batch, each row as a point of its own. The timestamp of a row is
+// taken from the time key of the measurement, see SetTimeKey()
 func (timeserData *TimeSeriesClientData) InsertUnmarshalledJsonRows(measurement string, rows []JsonRow, ignoreKeyList []string) (err error) {
-	tags := make(map[string]string)
-	field := make(map[string]interface{})
+	return timeserData.insertJsonRows(context.Background(), measurement, rows, ignoreKeyList)
+}
 
+// Inserts JSON rows as a single batch written within ctx
+func (timeserData *TimeSeriesClientData) insertJsonRows(ctx context.Context, measurement string, rows []JsonRow, ignoreKeyList []string) (err error) {
 	bp, err := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
 		Database:  (*timeserData).timeSeriesDbName,
-		Precision: "ns",
+		Precision: timeserData.writePrecision(),
 	})
 
-	for _, data := range rows {
-		flatjson, err := timeserData.Flatten(data, "", ignoreKeyList)
+	for i, data := range rows {
+		flatjson, err := timeserData.flatten(measurement, data, ignoreKeyList)
 		if err != nil {
-			log.Warn().Msgf("\n Not able to flatten json %s for:%v", err.Error(), data)
+			timeserData.logger().Errorf("\n Not able to flatten json row %v %s for:%v", i, err.Error(), data)
+			return err
 		}
 
-		log.Info().Msgf("\n Data after flattening: %v", flatjson)
+		timeserData.logger().Infof("\n Data after flattening: %v", flatjson)
 
-		for key, value := range flatjson {
-			if value != nil {
-				if reflect.ValueOf(value).Type().Kind() == reflect.Float64 {
-					field[key] = value
-				} else if reflect.ValueOf(value).Type().Kind() == reflect.String {
-					field[key] = value
-				} else if reflect.ValueOf(value).Type().Kind() == reflect.Bool {
-					field[key] = value
-				} else if reflect.ValueOf(value).Type().Kind() == reflect.Int {
-					field[key] = value
-				}
-			}
-		}
-		// Create a point and add to batch
-		pt, err := timesrclient.NewPoint(measurement, tags, field, time.Now())
+		points, err := timeserData.jsonPoints(measurement, flatjson)
 		if err != nil {
-			log.Error().Msgf("Error: %s", err.Error())
 			return err
 		}
-		bp.AddPoint(pt)
+		bp.AddPoints(points)
+	}
+	if len(bp.Points()) == 0 {
+		return nil
 	}
 	// Write the batch
-	err = timeserData.Iclient.Write(bp)
+	err = timeserData.writeContext(ctx, bp)
 	return err
 }
 
@@ -276,7 +1106,7 @@ func (timeserData *TimeSeriesClientData) UnmarshallJsonRows(jsonBuffer []byte) (
 	jsonrow := []JsonRow{}
 
 	// Unmarshal the json into it. this will use the struct tag
-	err := json.Unmarshal(jsonBuffer, &jsonrow)
+	err := timeserData.unmarshalJson(jsonBuffer, &jsonrow)
 	if err != nil {
 		return nil, err
 	}
@@ -284,66 +1114,313 @@ func (timeserData *TimeSeriesClientData) UnmarshallJsonRows(jsonBuffer []byte) (
 	return jsonrow, nil
 }
 
+// Sets how the numbers of the inserted JSON are decoded. With JsonNumberPreserve counters keep their integer
+// precision, a field must then be written consistently with or without a fraction to keep its type
+func (timeserData *TimeSeriesClientData) SetJsonNumberMode(mode JsonNumberMode) {
+	timeserData.jsonNumberMode = mode
+}
+
+// Decodes JSON as per the JsonNumberMode
+func (timeserData *TimeSeriesClientData) unmarshalJson(jsonBuffer []byte, v interface{}) error {
+	if timeserData.jsonNumberMode != JsonNumberPreserve {
+		return json.Unmarshal(jsonBuffer, v)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonBuffer))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	var extra json.RawMessage
+	if err := decoder.Decode(&extra); err != io.EOF {
+		return errors.New("invalid data after top-level JSON value")
+	}
+	return nil
+}
+
+// Sets the precision (ns, us, ms or s) the timestamps of the written points are truncated to. A coarser precision
+// reduces the size of the writes
+func (timeserData *TimeSeriesClientData) SetWritePrecision(precision string) error {
+	switch precision {
+	case "ns", "us", "ms", "s":
+	default:
+		return fmt.Errorf("Unsupported write precision %v", precision)
+	}
+	timeserData.precision = precision
+	return nil
+}
+
+// Returns the write precision, ns by default
+func (timeserData *TimeSeriesClientData) writePrecision() string {
+	if timeserData.precision == "" {
+		return "ns"
+	}
+	return timeserData.precision
+}
+
+// Sets how InsertJsonArray handles a payload holding a single JSON object instead of an array
+func (timeserData *TimeSeriesClientData) SetSingleObjectMode(mode SingleObjectMode) {
+	timeserData.singleObjectMode = mode
+}
+
 // Inserts JSON rows as separate time points in the mentioned measurement
+// A single JSON object is inserted as one row or rejected as per SetSingleObjectMode()
+// A valid but empty array writes nothing and returns nil
 func (timeserData *TimeSeriesClientData) InsertJsonArray(measurement string, ignoreList []string, jsonBuffer []byte) (err error) {
+	return timeserData.insertJsonArray(context.Background(), measurement, ignoreList, jsonBuffer)
+}
+
+// Inserts JSON rows as by InsertJsonArray(), written within ctx
+func (timeserData *TimeSeriesClientData) insertJsonArray(ctx context.Context, measurement string, ignoreList []string, jsonBuffer []byte) (err error) {
+	if trimmed := bytes.TrimSpace(jsonBuffer); len(trimmed) > 0 && trimmed[0] == '{' {
+		if timeserData.singleObjectMode == SingleObjectReject {
+			timeserData.logger().Errorf("Failed to insert into measurement %v: %v\n", measurement, ErrNotJsonArray)
+			return ErrNotJsonArray
+		}
+		jsonBuffer = append(append([]byte{'['}, trimmed...), ']')
+	}
+	rows, err := timeserData.UnmarshallJsonRows(jsonBuffer)
+	if err != nil {
+		err = fmt.Errorf("Failed to parse JSON array for measurement %v: %v", measurement, err)
+		timeserData.logger().Errorf("%v\n", err)
+		return err
+	}
+	if len(rows) == 0 {
+		timeserData.logger().Debugf("TimeSeriesDB InsertJsonArray: Measurement=%v empty array, nothing written\n", measurement)
+		return nil
+	}
+	// We can call InsertUnmarshalledJsonRow but it will do write for each row
+	// Instead, use batching if rows more than 1
+	return timeserData.insertJsonRows(ctx, measurement, rows, ignoreList)
+}
+
+// Inserts JSON rows as separate time points, each row into the measurement named by its measurementKey field.
+// The measurementKey field is not stored. Returns the number of rows written per measurement
+func (timeserData *TimeSeriesClientData) InsertJsonArrayRouted(measurementKey string, ignoreList []string, jsonBuffer []byte) (counts map[string]int, err error) {
+	return timeserData.insertJsonArrayRouted(context.Background(), measurementKey, ignoreList, jsonBuffer)
+}
+
+// Inserts JSON rows as by InsertJsonArrayRouted(), written within ctx
+func (timeserData *TimeSeriesClientData) insertJsonArrayRouted(ctx context.Context, measurementKey string, ignoreList []string, jsonBuffer []byte) (counts map[string]int, err error) {
 	rows, err := timeserData.UnmarshallJsonRows(jsonBuffer)
-	if err == nil && len(rows) > 0 {
-		// We can call InsertUnmarshalledJsonRow but it will do write for each row
-		// Instead, use batching if rows more than 1
-		err = timeserData.InsertUnmarshalledJsonRows(measurement, rows, ignoreList)
+	if err != nil {
+		err = fmt.Errorf("Failed to parse JSON array routed by %v: %v", measurementKey, err)
+		timeserData.logger().Errorf("%v\n", err)
+		return nil, err
 	}
-	return err
+
+	bp, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+		Database:  (*timeserData).timeSeriesDbName,
+		Precision: timeserData.writePrecision(),
+	})
+
+	counts = make(map[string]int)
+	for i, data := range rows {
+		measurement, ok := data[measurementKey].(string)
+		if !ok || measurement == "" {
+			err = fmt.Errorf("Row %v has no string %v field to route it to a measurement", i, measurementKey)
+			timeserData.logger().Errorf("%v\n", err)
+			return nil, err
+		}
+		flatjson, err := timeserData.flatten(measurement, data, ignoreList)
+		if err != nil {
+			timeserData.logger().Errorf("\n Not able to flatten json %s for:%v", err.Error(), data)
+			return nil, err
+		}
+		delete(flatjson, measurementKey)
+		points, err := timeserData.jsonPoints(measurement, flatjson)
+		if err != nil {
+			return nil, err
+		}
+		bp.AddPoints(points)
+		counts[measurement]++
+	}
+	if len(bp.Points()) == 0 {
+		return counts, nil
+	}
+	err = timeserData.writeContext(ctx, bp)
+	if err != nil {
+		return nil, err
+	}
+	timeserData.logger().Debugf("TimeSeriesDB InsertJsonArrayRouted: DB=%v key=%v counts=%v\n", timeserData.timeSeriesDbName, measurementKey, counts)
+	return counts, nil
 }
 
 // Inserts json data as single row in the mentioned meausrement
 // PS - Use only for single row data
 func (timeserData *TimeSeriesClientData) InsertJson(measurement string, ignoreList []string, jsonBuffer []byte) (err error) {
-	tags := make(map[string]string)
-	field := make(map[string]interface{})
 	data := make(map[string]interface{})
 
-	err = json.Unmarshal(jsonBuffer, &data)
+	err = timeserData.unmarshalJson(jsonBuffer, &data)
 	if err != nil {
-		log.Error().Msgf("\n Not able to Parse data %s", err.Error())
+		timeserData.logger().Errorf("\n Not able to Parse data %s", err.Error())
 		return err
 	}
 
 	bp, err := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
 		Database:  (*timeserData).timeSeriesDbName,
-		Precision: "ns",
+		Precision: timeserData.writePrecision(),
 	})
 
-	flatjson, err := timeserData.Flatten(data, "", ignoreList)
+	flatjson, err := timeserData.flatten(measurement, data, ignoreList)
+	if err != nil {
+		timeserData.logger().Errorf("\n Not able to flatten json %s for:%v", err.Error(), data)
+		return err
+	}
+
+	timeserData.logger().Infof("\n Data after flattening: %v", flatjson)
+
+	points, err := timeserData.jsonPoints(measurement, flatjson)
 	if err != nil {
-		log.Error().Msgf("\n Not able to flatten json %s for:%v", err.Error(), data)
 		return err
 	}
+	bp.AddPoints(points)
+	// Write the batch
+	err = timeserData.write(bp)
+	return err
+}
 
-	log.Info().Msgf("\n Data after flattening: %v", flatjson)
+// Builds the points of a flattened JSON row: the row itself and, with ArrayExplode, a point per element of its
+// arrays of objects. The tag and time keys are moved out of flatjson
+func (timeserData *TimeSeriesClientData) jsonPoints(measurement string, flatjson map[string]interface{}) ([]*timesrclient.Point, error) {
+	mapping, err := timeserData.mapFields(measurement, flatjson)
+	if err != nil {
+		return nil, err
+	}
+	tags := timeserData.extractTags(measurement, flatjson)
+	for tag, value := range mapping.StaticTags {
+		tags[tag] = value
+	}
+	timestamp, err := timeserData.extractTime(measurement, flatjson)
+	if err != nil {
+		return nil, err
+	}
+	elements, err := timeserData.explodedPoints(measurement, flatjson, tags, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	fields := timeserData.jsonFields(measurement, flatjson)
+	if len(fields) == 0 && len(elements) > 0 {
+		return elements, nil
+	}
+	pt, err := timeserData.jsonPoint(measurement, tags, fields, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*timesrclient.Point{pt}, elements...), nil
+}
 
+// Moves the exploded arrays out of flatjson and creates a point per element, with the tags of the row and the
+// ElementKey of the element
+func (timeserData *TimeSeriesClientData) explodedPoints(measurement string, flatjson map[string]interface{}, tags map[string]string, timestamp time.Time) ([]*timesrclient.Point, error) {
+	keys := []string{}
 	for key, value := range flatjson {
-		if value != nil {
-			if reflect.ValueOf(value).Type().Kind() == reflect.Float64 {
-				field[key] = value
-			} else if reflect.ValueOf(value).Type().Kind() == reflect.String {
-				field[key] = value
-			} else if reflect.ValueOf(value).Type().Kind() == reflect.Bool {
-				field[key] = value
-			} else if reflect.ValueOf(value).Type().Kind() == reflect.Int {
-				field[key] = value
+		if _, ok := value.([]map[string]interface{}); ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	sort.Strings(keys)
+	opts := timeserData.flattenOpts(measurement)
+	sep := opts.Separator
+	if sep == "" {
+		sep = "."
+	}
+	points := []*timesrclient.Point{}
+	for _, key := range keys {
+		elements := flatjson[key].([]map[string]interface{})
+		delete(flatjson, key)
+		for i, element := range elements {
+			elementTags := make(map[string]string, len(tags)+1)
+			for k, v := range tags {
+				elementTags[k] = v
+			}
+			elementKey := key + sep + opts.ElementKey
+			if value := element[elementKey]; opts.ElementKey != "" && value != nil {
+				elementTags[elementKey] = _tagValue(value)
+				delete(element, elementKey)
+			} else if opts.ElementKey != "" {
+				elementTags[elementKey] = strconv.Itoa(i)
+			} else {
+				elementTags[key+sep+"index"] = strconv.Itoa(i)
+			}
+
+			if _, err := timeserData.mapFields(measurement, element); err != nil {
+				return nil, err
+			}
+			// Elements may hold exploded arrays in turn
+			nested, err := timeserData.explodedPoints(measurement, element, elementTags, timestamp)
+			if err != nil {
+				return nil, err
 			}
+			if fields := timeserData.jsonFields(measurement, element); len(fields) > 0 {
+				pt, err := timeserData.jsonPoint(measurement, elementTags, fields, timestamp)
+				if err != nil {
+					return nil, err
+				}
+				points = append(points, pt)
+			}
+			points = append(points, nested...)
 		}
 	}
-	// Create a point and add to batch
-	pt, err := timesrclient.NewPoint(measurement, tags, field, time.Now())
+	return points, nil
+}
+
+// Creates a point of JSON fields
+func (timeserData *TimeSeriesClientData) jsonPoint(measurement string, tags map[string]string, fields map[string]interface{}, timestamp time.Time) (*timesrclient.Point, error) {
+	fields, err := timeserData.finiteFields(measurement, fields)
+	if err == nil {
+		fields, err = timeserData.schemaFields(measurement, tags, fields)
+	}
 	if err != nil {
-		log.Error().Msgf("Error: %s", err.Error())
-		return err
+		return nil, err
 	}
-	bp.AddPoint(pt)
-	// Write the batch
-	err = timeserData.Iclient.Write(bp)
-	return err
+	pt, err := timesrclient.NewPoint(measurement, tags, fields, timestamp)
+	if err != nil {
+		timeserData.logger().Errorf("Error: %s", err.Error())
+		return nil, err
+	}
+	return pt, nil
+}
+
+// Applies the NonFinitePolicy to the NaN and Inf float fields, fields is not modified
+func (timeserData *TimeSeriesClientData) finiteFields(measurement string, fields map[string]interface{}) (map[string]interface{}, error) {
+	var finite map[string]interface{}
+	for key, value := range fields {
+		var f float64
+		switch v := value.(type) {
+		case float64:
+			f = v
+		case float32:
+			f = float64(v)
+		default:
+			continue
+		}
+		if !math.IsNaN(f) && !math.IsInf(f, 0) {
+			continue
+		}
+		if timeserData.nonFinitePolicy == NonFiniteError {
+			timeserData.logger().Errorf("Field %v of measurement %v is %v\n", key, measurement, f)
+			return nil, fmt.Errorf("%v: %v.%v", ErrNonFiniteField, measurement, key)
+		}
+		if finite == nil {
+			finite = make(map[string]interface{}, len(fields))
+			for k, v := range fields {
+				finite[k] = v
+			}
+		}
+		if timeserData.nonFinitePolicy == NonFiniteSubstitute {
+			finite[key] = timeserData.nonFiniteSentinel
+		} else {
+			timeserData.logger().Warnf("Dropping field %v of measurement %v with value %v\n", key, measurement, f)
+			delete(finite, key)
+		}
+	}
+	if finite == nil {
+		return fields, nil
+	}
+	return finite, nil
 }
 
 // Creates a new retention policy
@@ -352,11 +1429,15 @@ func (timeserData *TimeSeriesClientData) CreateRetentionPolicy(retentionPolicyNa
 	if true == setDefault {
 		isDefault = "DEFAULT"
 	}
+	if duration, err = _retentionLiteral(duration); err != nil {
+		return err
+	}
 	q := timesrclient.NewQuery(fmt.Sprintf("CREATE RETENTION POLICY %v ON %v DURATION %v REPLICATION 1 SHARD DURATION %v %v", retentionPolicyName, (*timeserData).timeSeriesDbName, duration, duration, isDefault), (*timeserData).timeSeriesDbName, "")
-	if response, err := (*timeserData).Iclient.Query(q); err == nil && response.Error() == nil {
-		log.Info().Msgf("Sucessfully created retention policy %v\n", retentionPolicyName)
+	// Response errors are returned as err by query()
+	if _, err = (*timeserData).query(q); err == nil {
+		timeserData.logger().Infof("Sucessfully created retention policy %v\n", retentionPolicyName)
 	} else {
-		log.Error().Msgf("Failed to create retention policy %v with error %v\n", retentionPolicyName, err)
+		timeserData.logger().Errorf("Failed to create retention policy %v with error %v\n", retentionPolicyName, err)
 	}
 	return err
 }
@@ -367,11 +1448,15 @@ func (timeserData *TimeSeriesClientData) UpdateRetentionPolicy(retentionPolicyNa
 	if true == setDefault {
 		isDefault = "DEFAULT"
 	}
+	if duration, err = _retentionLiteral(duration); err != nil {
+		return err
+	}
 	q := timesrclient.NewQuery(fmt.Sprintf("ALTER RETENTION POLICY %v ON %v DURATION %v SHARD DURATION %v %v", retentionPolicyName, (*timeserData).timeSeriesDbName, duration, duration, isDefault), (*timeserData).timeSeriesDbName, "")
-	if response, err := (*timeserData).Iclient.Query(q); err == nil && response.Error() == nil {
-		log.Info().Msgf("Sucessfully updatated retention policy %v\n", retentionPolicyName)
+	// Response errors are returned as err by query()
+	if _, err = (*timeserData).query(q); err == nil {
+		timeserData.logger().Infof("Sucessfully updatated retention policy %v\n", retentionPolicyName)
 	} else {
-		log.Error().Msgf("Failed to updatate retention policy %v with error %v\n", retentionPolicyName, err)
+		timeserData.logger().Errorf("Failed to updatate retention policy %v with error %v\n", retentionPolicyName, err)
 	}
 	return err
 }
@@ -380,10 +1465,11 @@ func (timeserData *TimeSeriesClientData) UpdateRetentionPolicy(retentionPolicyNa
 func (timeserData *TimeSeriesClientData) DeleteRetentionPolicy(retentionPolicyName string) (err error) {
 	q := timesrclient.NewQuery(fmt.Sprintf("DROP RETENTION POLICY %v ON %v", retentionPolicyName, (*timeserData).timeSeriesDbName), (*timeserData).timeSeriesDbName, "")
 
-	if response, err := (*timeserData).Iclient.Query(q); err == nil && response.Error() == nil {
-		log.Info().Msgf("Sucessfully deleted retention policy %v\n", retentionPolicyName)
+	// Response errors are returned as err by query()
+	if _, err = (*timeserData).query(q); err == nil {
+		timeserData.logger().Infof("Sucessfully deleted retention policy %v\n", retentionPolicyName)
 	} else {
-		log.Error().Msgf("Failed to delete retention policy %v with error %v\n", retentionPolicyName, err)
+		timeserData.logger().Errorf("Failed to delete retention policy %v with error %v\n", retentionPolicyName, err)
 	}
 	return err
 }
@@ -391,19 +1477,30 @@ func (timeserData *TimeSeriesClientData) DeleteRetentionPolicy(retentionPolicyNa
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 //                                       Generic functions - Non methods
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-func _flatten(top bool, flatMap map[string]interface{}, nested interface{}, prefix string, ignorelist []string) error {
+
+func _flatten(top bool, flatMap map[string]interface{}, nested interface{}, prefix string, ignorelist []string, opts FlattenOptions, depth int, logger Logger) error {
 	var flag int
+	sep := opts.Separator
+	if sep == "" {
+		sep = "."
+	}
+
+	// Stores a value as JSON string
+	marshal := func(newKey string, v interface{}) error {
+		b, err := json.Marshal(&v)
+		if err != nil {
+			logger.Errorf("\n Not able to Marshal data for key:%s=%v", newKey, v)
+			return err
+		}
+		flatMap[newKey] = string(b)
+		return nil
+	}
 
 	assign := func(newKey string, v interface{}, ignoretag bool) error {
 		if ignoretag {
 			switch v.(type) {
 			case map[string]interface{}, []interface{}:
-				v, err := json.Marshal(&v)
-				if err != nil {
-					log.Error().Msgf("\n Not able to Marshal data for key:%s=%v", newKey, v)
-					return err
-				}
-				flatMap[newKey] = string(v)
+				return marshal(newKey, v)
 			default:
 				flatMap[newKey] = v
 			}
@@ -411,8 +1508,30 @@ func _flatten(top bool, flatMap map[string]interface{}, nested interface{}, pref
 		} else {
 			switch v.(type) {
 			case map[string]interface{}, []interface{}:
-				if err := _flatten(false, flatMap, v, newKey, ignorelist); err != nil {
-					log.Error().Msgf("\n Not able to flatten data for key:%s=%v", newKey, v)
+				if opts.MaxDepth > 0 && depth+1 >= opts.MaxDepth {
+					return marshal(newKey, v)
+				}
+				if array, ok := v.([]interface{}); ok && opts.ArrayMode != ArrayIndex {
+					if elements, ok := _objectArray(array); ok && opts.ArrayMode == ArrayExplode {
+						// Flattened elements, turned into points by explodedPoints()
+						flatElements := make([]map[string]interface{}, len(elements))
+						for i, element := range elements {
+							flatElements[i] = make(map[string]interface{})
+							if err := _flatten(false, flatElements[i], element, newKey, ignorelist, opts, depth+1, logger); err != nil {
+								return err
+							}
+						}
+						flatMap[newKey] = flatElements
+						return nil
+					}
+					if joined, ok := _joinArray(array); ok && (opts.ArrayMode == ArrayJoin || opts.ArrayMode == ArrayExplode) {
+						flatMap[newKey] = joined
+						return nil
+					}
+					return marshal(newKey, v)
+				}
+				if err := _flatten(false, flatMap, v, newKey, ignorelist, opts, depth+1, logger); err != nil {
+					logger.Errorf("\n Not able to flatten data for key:%s=%v", newKey, v)
 					return err
 				}
 			default:
@@ -442,13 +1561,13 @@ func _flatten(top bool, flatMap map[string]interface{}, nested interface{}, pref
 					return err
 				}
 			} else if flag == 0 {
-				newKey := _createkey(top, prefix, k)
+				newKey := _createkey(top, prefix, k, sep)
 				err := assign(newKey, v, true)
 				if err != nil {
 					return err
 				}
 			} else {
-				newKey := _createkey(top, prefix, k)
+				newKey := _createkey(top, prefix, k, sep)
 				err := assign(newKey, v, false)
 				if err != nil {
 					return err
@@ -462,14 +1581,14 @@ func _flatten(top bool, flatMap map[string]interface{}, nested interface{}, pref
 				for tag, value := range v.(map[string]interface{}) {
 					ok := _matchkey(ignorelist, tag)
 					if ok {
-						subkey := strconv.Itoa(i) + "." + tag
-						newKey := _createkey(top, prefix, subkey)
+						subkey := strconv.Itoa(i) + sep + tag
+						newKey := _createkey(top, prefix, subkey, sep)
 						err := assign(newKey, value, true)
 						if err != nil {
 							return err
 						}
 					} else {
-						newKey := _createkey(top, prefix, strconv.Itoa(i))
+						newKey := _createkey(top, prefix, strconv.Itoa(i), sep)
 						err := assign(newKey, v, false)
 						if err != nil {
 							return err
@@ -477,7 +1596,7 @@ func _flatten(top bool, flatMap map[string]interface{}, nested interface{}, pref
 					}
 				}
 			default:
-				newKey := _createkey(top, prefix, strconv.Itoa(i))
+				newKey := _createkey(top, prefix, strconv.Itoa(i), sep)
 				err := assign(newKey, v, false)
 				if err != nil {
 					return err
@@ -492,13 +1611,299 @@ func _flatten(top bool, flatMap map[string]interface{}, nested interface{}, pref
 	return nil
 }
 
-func _createkey(top bool, prefix, subkey string) string {
+// Returns the objects of an array, false when it holds other values
+func _objectArray(array []interface{}) ([]map[string]interface{}, bool) {
+	objects := make([]map[string]interface{}, len(array))
+	for i, v := range array {
+		object, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		objects[i] = object
+	}
+	return objects, true
+}
+
+// Joins an array of scalars with commas, returns false when it holds objects or arrays
+func _joinArray(array []interface{}) (string, bool) {
+	values := make([]string, len(array))
+	for i, v := range array {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return "", false
+		case nil:
+		default:
+			values[i] = fmt.Sprint(v)
+		}
+	}
+	return strings.Join(values, ","), true
+}
+
+// Keeps the flattened JSON values which can be stored as fields, logging the keys of the others
+func (timeserData *TimeSeriesClientData) jsonFields(measurement string, flatjson map[string]interface{}) map[string]interface{} {
+	fields, skipped := _jsonFields(flatjson)
+	if len(skipped) > 0 {
+		sort.Strings(skipped)
+		timeserData.logger().Warnf("Keys %v of measurement %v not stored, their values cannot be fields\n", skipped, measurement)
+	}
+	return fields
+}
+
+// Converts the flattened JSON values to field values: integers to int64, floats to float64, json.Number to
+// int64 or float64 and time.Time to RFC3339 strings. Returns the keys of the values which cannot be stored
+func _jsonFields(flatjson map[string]interface{}) (fields map[string]interface{}, skipped []string) {
+	fields = make(map[string]interface{})
+	for key, value := range flatjson {
+		if value == nil {
+			continue
+		}
+		if field, ok := _jsonField(value); ok {
+			fields[key] = field
+		} else {
+			skipped = append(skipped, key)
+		}
+	}
+	return fields, skipped
+}
+
+// Converts a JSON or Go value to a field value
+func _jsonField(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case float64, string, bool:
+		return v, true
+	case float32:
+		return float64(v), true
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, true
+		}
+		f, err := v.Float64()
+		return f, err == nil
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano), true
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() > math.MaxInt64 {
+			return nil, false
+		}
+		return int64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.String:
+		return rv.String(), true
+	case reflect.Bool:
+		return rv.Bool(), true
+	}
+	return nil, false
+}
+
+// Converts all the series of a query response to rows of column and tag values
+func _jsonRows(response *timesrclient.Response) []JsonRow {
+	rows := []JsonRow{}
+	for _, result := range response.Results {
+		for _, series := range result.Series {
+			for _, value := range series.Values {
+				row := make(JsonRow, len(series.Columns)+len(series.Tags))
+				for k, v := range series.Tags {
+					row[k] = v
+				}
+				for i, column := range series.Columns {
+					if i < len(value) {
+						row[column] = value[i]
+					}
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows
+}
+
+// Parses a JSON timestamp as per layout: epoch unit s, ms, us or ns, or a time.Parse layout
+func _parseTime(value interface{}, layout string) (time.Time, error) {
+	var unit float64
+	switch layout {
+	case "s":
+		unit = float64(time.Second)
+	case "ms":
+		unit = float64(time.Millisecond)
+	case "us":
+		unit = float64(time.Microsecond)
+	case "ns":
+		unit = float64(time.Nanosecond)
+	default:
+		str, ok := value.(string)
+		if !ok {
+			return time.Time{}, fmt.Errorf("Time value %v is not a string for layout %v", value, layout)
+		}
+		return time.Parse(layout, str)
+	}
+
+	var epoch float64
+	switch v := value.(type) {
+	case float64:
+		epoch = v
+	case json.Number:
+		// Integers are converted exactly, nanosecond epochs do not fit in a float64
+		if n, err := v.Int64(); err == nil {
+			return time.Unix(0, n*int64(unit)), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, err
+		}
+		epoch = f
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		epoch = f
+	default:
+		if n, ok := _toInt64(v); ok {
+			return time.Unix(0, n*int64(unit)), nil
+		}
+		return time.Time{}, fmt.Errorf("Time value %v is not a number for epoch unit %v", value, layout)
+	}
+	return time.Unix(0, int64(epoch*unit)), nil
+}
+
+// Formats a JSON value as tag value, numbers without exponent
+func _tagValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Builds the WHERE condition for the predicate restricted to [start, stop)
+func _whereClause(predicate string, start, stop time.Time) string {
+	timeRange := fmt.Sprintf("time >= '%v' AND time < '%v'", start.UTC().Format(time.RFC3339Nano), stop.UTC().Format(time.RFC3339Nano))
+	if predicate == "" {
+		return timeRange
+	}
+	return fmt.Sprintf("(%v) AND %v", predicate, timeRange)
+}
+
+// Quotes an identifier (measurement, field or tag key) for use in InfluxQL
+func _quoteIdent(name string) string {
+	return `"` + strings.Replace(strings.Replace(name, `\`, `\\`, -1), `"`, `\"`, -1) + `"`
+}
+
+// Converts the time column returned by the TimeSeriesDB client, RFC3339 string or epoch in nanoseconds
+func _toTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case string:
+		return time.Parse(time.RFC3339Nano, v)
+	default:
+		if n, ok := _toInt64(v); ok {
+			return time.Unix(0, n).UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Not a valid time value: %v", value)
+}
+
+// Converts rows of time and value columns to TimedValues
+func _timedValues(rows [][]interface{}) ([]TimedValue, error) {
+	values := make([]TimedValue, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		t, err := _toTime(row[0])
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, TimedValue{Time: t, Value: row[1]})
+	}
+	return values, nil
+}
+
+// Builds an InfluxQL condition matching all the tags, empty for no tags
+func _tagCondition(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	conditions := make([]string, 0, len(keys))
+	for _, k := range keys {
+		conditions = append(conditions, fmt.Sprintf("%v = %v", _quoteIdent(k), _quoteLiteral(tags[k])))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// Quotes a string literal (eg. a tag value) for use in InfluxQL
+func _quoteLiteral(value string) string {
+	return "'" + strings.Replace(strings.Replace(value, `\`, `\\`, -1), "'", `\'`, -1) + "'"
+}
+
+// Identifies the series of a measurement and its tags
+func _seriesKey(measurement string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	key := measurement
+	for _, k := range keys {
+		key += "," + k + "=" + tags[k]
+	}
+	return key
+}
+
+// Converts a numeric value returned by the TimeSeriesDB client to int64
+func _toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, true
+		}
+		if f, err := v.Float64(); err == nil {
+			return int64(f), true
+		}
+	case float64:
+		return int64(v), true
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// Converts an integral number or integer string to int64, unlike _toInt64 fractions are not truncated
+func _toInteger(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	case float64:
+		if v != math.Trunc(v) || v >= math.MaxInt64 || v < math.MinInt64 {
+			return 0, false
+		}
+	}
+	return _toInt64(value)
+}
+
+func _createkey(top bool, prefix, subkey, sep string) string {
 	key := prefix
 
 	if top {
 		key += subkey
 	} else {
-		key += "." + subkey
+		key += sep + subkey
 	}
 
 	return key
@@ -16,13 +16,24 @@
 
 //  This source code is part of the near-RT RIC (RAN Intelligent Controller)
 //  platform project (RICP).
+
 package stslgo_test
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"stslgo"
+	"sync"
 	"testing"
+	"time"
 
 	_ "github.com/influxdata/influxdb1-client"
 	"github.com/influxdata/influxdb1-client/models"
@@ -34,23 +45,68 @@ import (
 //                   Mock client structure implements the timesrclient.Iclient interface
 //                   and mocks responses instead of using the TimeSeriesDB provided GO library.
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
 type MockClient struct{}
 
 func (c *MockClient) Close() error {
 	return nil
 }
 
+// Error returned by the mock on ping, reset by setup()
+var pingErr error
+
+func (c *MockClient) Ping(timeout time.Duration) (time.Duration, string, error) {
+	return 0, "1.8.0", pingErr
+}
+
 // Dynamic function for queryResponse so that based on the test case different outputs can be simulated
 var queryResp func(q timesrclient.Query) (*timesrclient.Response, error)
 
+// Queries issued and points written through the mock, reset by setup()
+var issuedQueries []string
+var writtenPoints []*timesrclient.Point
+var writtenDatabases []string
+var writtenRetentionPolicies []string
+var writtenPrecision string
+var writeCalls int
+
+// Error returned by the mock on write, reset by setup()
+var writeErr error
+
+// Serializes the mock for tests issuing concurrent requests
+var mockLock sync.Mutex
+
 func (c *MockClient) Query(q timesrclient.Query) (*timesrclient.Response, error) {
-	return queryResp(q)
+	mockLock.Lock()
+	issuedQueries = append(issuedQueries, q.Command)
+	respond := queryResp
+	mockLock.Unlock()
+	return respond(q)
 }
 
 func (c *MockClient) Write(bp timesrclient.BatchPoints) error {
+	mockLock.Lock()
+	defer mockLock.Unlock()
+	writeCalls++
+	if writeErr != nil {
+		return writeErr
+	}
+	writtenPoints = append(writtenPoints, bp.Points()...)
+	writtenDatabases = append(writtenDatabases, bp.Database())
+	writtenRetentionPolicies = append(writtenRetentionPolicies, bp.RetentionPolicy())
+	writtenPrecision = bp.Precision()
 	return nil
 }
 
+// Builds a single series response as returned by the TimeSeriesDB for a query
+func seriesResp(name string, columns []string, values ...[]interface{}) *timesrclient.Response {
+	result := timesrclient.Result{}
+	result.Series = append(result.Series, models.Row{Name: name, Columns: columns, Values: values})
+	resp := timesrclient.Response{}
+	resp.Results = append(resp.Results, result)
+	return &resp
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 //                                    Test & utility functions for the stslgo GO module
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -72,6 +128,14 @@ func setupIclient(timeserData *stslgo.TimeSeriesClientData) {
 
 // Setup the test environment for each test case
 func setup() (timeserData *stslgo.TimeSeriesClientData, err error) {
+	issuedQueries = nil
+	writtenPoints = nil
+	writtenDatabases = nil
+	writtenRetentionPolicies = nil
+	writtenPrecision = ""
+	writeCalls = 0
+	writeErr = nil
+	pingErr = nil
 	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
 		result := timesrclient.Result{}
 		resp := timesrclient.Response{}
@@ -231,3 +295,1007 @@ func TestTimeSeriesDbFlatten(t *testing.T) {
 		fmt.Printf("\n Failed to flatten and insert the json array with error %s", err.Error())
 	}
 }
+
+// Test function for the dry-run count reporting the same points a delete would remove
+func TestTimeSeriesDbCountToDelete(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_ = timeserData.WritePoint("CountTable", map[string]string{"cellId": "1"}, map[string]interface{}{"prb": i, "thp": i * 10})
+	}
+	if len(writtenPoints) != 3 {
+		t.Fatalf("Expected 3 points written, got %v", len(writtenPoints))
+	}
+
+	// Every point carries both fields so each count column reports 3, in the default retention policy
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		if strings.HasPrefix(q.Command, "SHOW RETENTION POLICIES") {
+			return seriesResp("", []string{"name", "duration", "shardGroupDuration", "replicaN", "default"},
+				[]interface{}{"autogen", "0s", "168h0m0s", json.Number("1"), true},
+				[]interface{}{"rp_1d", "24h0m0s", "1h0m0s", json.Number("1"), false}), nil
+		}
+		if strings.HasPrefix(q.Command, "DELETE") {
+			return &timesrclient.Response{}, nil
+		}
+		resp := seriesResp("CountTable", []string{"time", "count_prb", "count_thp"},
+			[]interface{}{"1970-01-01T00:00:00Z", json.Number("3"), json.Number("3")})
+		resp.Results = append(resp.Results, timesrclient.Result{})
+		return resp, nil
+	}
+
+	start := time.Now().Add(-time.Hour)
+	stop := time.Now().Add(time.Minute)
+	predicate := `"cellId" = '1'`
+	count, err := timeserData.CountToDelete(predicate, start, stop)
+	if err != nil {
+		t.Fatalf("Unable to count points with error %v", err)
+	}
+	if count != int64(len(writtenPoints)) {
+		t.Errorf("Expected count %v, got %v", len(writtenPoints), count)
+	}
+
+	err = timeserData.DeleteByTimeRange(predicate, start, stop)
+	if err != nil {
+		t.Fatalf("Unable to delete points with error %v", err)
+	}
+
+	// The delete must target exactly the points that were counted, in every retention policy
+	countQuery, deleteQuery := issuedQueries[len(issuedQueries)-2], issuedQueries[len(issuedQueries)-1]
+	deleteWhere := deleteQuery[strings.Index(deleteQuery, " WHERE "):]
+	statements := strings.Split(countQuery, "; ")
+	if len(statements) != 2 || !strings.HasPrefix(statements[0], `SELECT COUNT(*) FROM "testdb"."autogen"./.*/`) ||
+		!strings.HasPrefix(statements[1], `SELECT COUNT(*) FROM "testdb"."rp_1d"./.*/`) {
+		t.Errorf("Expected a count per retention policy, got %q", countQuery)
+	}
+	for _, statement := range statements {
+		if !strings.HasPrefix(deleteQuery, "DELETE") || statement[strings.Index(statement, " WHERE "):] != deleteWhere {
+			t.Errorf("Count query %q and delete query %q do not match the same points", statement, deleteQuery)
+		}
+	}
+}
+
+// Test function for recording only the transitions of a boolean event
+func TestTimeSeriesDbRecordEvent(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	tags := map[string]string{"cellId": "1"}
+	for i := 0; i < 2; i++ {
+		if err = timeserData.RecordEvent("AlarmTable", "linkDown", true, tags); err != nil {
+			t.Fatalf("Unable to record event with error %v", err)
+		}
+	}
+	if len(writtenPoints) != 1 {
+		t.Fatalf("Expected 1 point for a repeated state, got %v", len(writtenPoints))
+	}
+
+	if err = timeserData.RecordEvent("AlarmTable", "linkDown", false, tags); err != nil {
+		t.Fatalf("Unable to record event with error %v", err)
+	}
+	if len(writtenPoints) != 2 {
+		t.Fatalf("Expected 2 points after a state change, got %v", len(writtenPoints))
+	}
+	fields, _ := writtenPoints[1].Fields()
+	if fields["active"] != false || writtenPoints[1].Tags()["name"] != "linkDown" {
+		t.Errorf("Unexpected event point %v", writtenPoints[1])
+	}
+
+	// The state is kept per series, and only once written
+	writeErr = errors.New("timeout")
+	if err = timeserData.RecordEvent("AlarmTable", "linkDown", true, map[string]string{"cellId": "2"}); err == nil {
+		t.Errorf("Expected the write error returned")
+	}
+	writeErr = nil
+	if err = timeserData.RecordEvent("AlarmTable", "linkDown", true, map[string]string{"cellId": "2"}); err != nil || len(writtenPoints) != 3 {
+		t.Errorf("Expected the event of another cell written after a failure, got %v points and %v", len(writtenPoints), err)
+	}
+}
+
+// Test function for reading the newest n values of several fields
+func TestTimeSeriesDbGetLastNFields(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	base := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 6; i++ {
+		_ = timeserData.WritePoint("LastNTable", nil, map[string]interface{}{"prb": i, "thp": i * 10})
+	}
+
+	// Newest first, one result per statement as the TimeSeriesDB would respond
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		resp := timesrclient.Response{}
+		for _, field := range []string{"prb", "thp"} {
+			var values [][]interface{}
+			for i := 5; i >= 3; i-- {
+				values = append(values, []interface{}{base.Add(time.Duration(i) * time.Second).Format(time.RFC3339Nano), json.Number(fmt.Sprint(i))})
+			}
+			result := timesrclient.Result{}
+			result.Series = append(result.Series, models.Row{Name: "LastNTable", Columns: []string{"time", field}, Values: values})
+			resp.Results = append(resp.Results, result)
+		}
+		return &resp, nil
+	}
+
+	result, err := timeserData.GetLastNFields("LastNTable", []string{"prb", "thp"}, 3)
+	if err != nil {
+		t.Fatalf("Unable to get last values with error %v", err)
+	}
+	if len(issuedQueries) != 1 {
+		t.Errorf("Expected a single query, got %v", issuedQueries)
+	}
+	for _, field := range []string{"prb", "thp"} {
+		values := result[field]
+		if len(values) != 3 {
+			t.Fatalf("Expected 3 values for %v, got %v", field, values)
+		}
+		if !values[0].Time.Before(values[2].Time) || values[0].Value != json.Number("3") {
+			t.Errorf("Values of %v not in chronological order: %v", field, values)
+		}
+	}
+}
+
+// Test function for a single JSON object passed where an array is expected
+func TestTimeSeriesDbJsonArraySingleObject(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	cell := []byte(` {"CID": "310-680-200-555001", "Cell-RF": {"rsp": -90, "rsrq": -13}}`)
+
+	// Default is to wrap the object as a one element array
+	err = timeserData.InsertJsonArray("SingleObjectTable", []string{}, cell)
+	if err != nil {
+		t.Fatalf("Failed to insert single object with error %v", err)
+	}
+	if len(writtenPoints) != 1 {
+		t.Fatalf("Expected the object to be inserted as 1 point, got %v", len(writtenPoints))
+	}
+	fields, _ := writtenPoints[0].Fields()
+	if fields["CID"] != "310-680-200-555001" {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+
+	timeserData.SetSingleObjectMode(stslgo.SingleObjectReject)
+	err = timeserData.InsertJsonArray("SingleObjectTable", []string{}, cell)
+	if err != stslgo.ErrNotJsonArray || len(writtenPoints) != 1 {
+		t.Errorf("Expected ErrNotJsonArray and no write, got %v with %v points", err, len(writtenPoints))
+	}
+}
+
+// Test function for malformed and empty JSON arrays
+func TestTimeSeriesDbJsonArrayInvalid(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	err = timeserData.InsertJsonArray("InvalidJsonTable", []string{}, []byte(`[{"CID": "310-680-200-555001",]`))
+	if err == nil || !strings.Contains(err.Error(), "Failed to parse JSON array for measurement InvalidJsonTable") {
+		t.Errorf("Expected parse error with context, got %v", err)
+	}
+
+	err = timeserData.InsertJsonArray("InvalidJsonTable", []string{}, []byte(` [] `))
+	if err != nil {
+		t.Errorf("Expected nil error for empty array, got %v", err)
+	}
+	if len(writtenPoints) != 0 {
+		t.Errorf("Expected nothing written, got %v points", len(writtenPoints))
+	}
+}
+
+// Test function for routing JSON rows to measurements by a field value
+func TestTimeSeriesDbJsonArrayRouted(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	rows := []byte(`[{"type": "cpu", "usage": 45.5}, {"type": "mem", "used": 1024}, {"type": "cpu", "usage": 50}]`)
+	counts, err := timeserData.InsertJsonArrayRouted("type", []string{}, rows)
+	if err != nil {
+		t.Fatalf("Failed to insert routed rows with error %v", err)
+	}
+	if counts["cpu"] != 2 || counts["mem"] != 1 {
+		t.Errorf("Unexpected counts %v", counts)
+	}
+	if len(writtenPoints) != 3 {
+		t.Fatalf("Expected 3 points, got %v", len(writtenPoints))
+	}
+	for _, pt := range writtenPoints {
+		fields, _ := pt.Fields()
+		if _, ok := fields["type"]; ok {
+			t.Errorf("Routing key was stored as a field in %v", pt)
+		}
+		if _, ok := fields["usage"]; ok != (pt.Name() == "cpu") {
+			t.Errorf("Row routed to wrong measurement: %v", pt)
+		}
+	}
+
+	_, err = timeserData.InsertJsonArrayRouted("type", []string{}, []byte(`[{"usage": 1}]`))
+	if err == nil {
+		t.Errorf("Expected error for a row without routing key")
+	}
+}
+
+// Test function for the handling of NaN and Inf float fields
+func TestTimeSeriesDbNonFiniteFields(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	fields := map[string]interface{}{"prbUsage": math.NaN(), "thp": 12.5, "ues": 4}
+	timeserData.SetNonFinitePolicy(stslgo.NonFiniteDrop, 0)
+	if err = timeserData.WritePoint("NonFiniteTable", nil, fields); err != nil {
+		t.Fatalf("Expected NaN field dropped, got error %v", err)
+	}
+	written, _ := writtenPoints[0].Fields()
+	if _, ok := written["prbUsage"]; ok || written["thp"] != 12.5 || len(written) != 2 {
+		t.Errorf("Expected only the NaN field dropped, got %v", written)
+	}
+	if !math.IsNaN(fields["prbUsage"].(float64)) {
+		t.Errorf("Caller fields modified")
+	}
+
+	timeserData.SetNonFinitePolicy(stslgo.NonFiniteSubstitute, -1)
+	_ = timeserData.WritePoint("NonFiniteTable", nil, map[string]interface{}{"thp": math.Inf(1)})
+	written, _ = writtenPoints[1].Fields()
+	if written["thp"] != float64(-1) {
+		t.Errorf("Expected sentinel substituted, got %v", written)
+	}
+
+	timeserData.SetNonFinitePolicy(stslgo.NonFiniteError, 0)
+	err = timeserData.WritePoint("NonFiniteTable", nil, fields)
+	if err == nil || len(writtenPoints) != 2 {
+		t.Errorf("Expected error and no write, got %v", err)
+	}
+}
+
+// Test function for attaching to an existing DB and reading its retention policy
+func TestTimeSeriesDbAttach(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		if strings.HasPrefix(q.Command, "SHOW RETENTION POLICIES") {
+			return seriesResp("", []string{"name", "duration", "shardGroupDuration", "replicaN", "default"},
+				[]interface{}{"autogen", "0s", "168h0m0s", json.Number("1"), false},
+				[]interface{}{"testdbrp", "2h0m0s", "1h0m0s", json.Number("1"), true}), nil
+		}
+		return seriesResp("AttachTable", []string{"time", "a"}, []interface{}{"2021-08-20T05:47:46.275224998Z", "2"}), nil
+	}
+
+	if err = timeserData.AttachTimeSeriesDB(); err != nil {
+		t.Fatalf("Unable to attach DB with error %v", err)
+	}
+	if timeserData.RetentionPolicy != "testdbrp" || timeserData.RetentionDuration != 2*time.Hour {
+		t.Errorf("Unexpected retention policy %v %v", timeserData.RetentionPolicy, timeserData.RetentionDuration)
+	}
+	for _, q := range issuedQueries {
+		if strings.HasPrefix(q, "CREATE") {
+			t.Errorf("Attach must not create, issued %v", q)
+		}
+	}
+
+	result, err := timeserData.Get("AttachTable", "a")
+	if err != nil || result != "2" {
+		t.Errorf("Unexpected Get result %v with error %v", result, err)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{Err: "database not found: testdb"}}}, nil
+	}
+	if err = timeserData.AttachTimeSeriesDB(); err != stslgo.ErrTimeSeriesDBNotFound {
+		t.Errorf("Expected ErrTimeSeriesDBNotFound, got %v", err)
+	}
+}
+
+// Test function for storing declared JSON keys as tags
+func TestTimeSeriesDbJsonTags(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	timeserData.SetTagKeys("TaggedTable", []string{"CID", "Cell-RF.cellId"})
+	neighborCells := []byte(`[{"CID": "310-680-200-555001", "Cell-RF": {"cellId": 555001, "rsrq": -13}}, {"CID": "310-680-200-555003", "Cell-RF": {"cellId": 555003, "rsrq": -17}}]`)
+	if err = timeserData.InsertJsonArray("TaggedTable", []string{}, neighborCells); err != nil {
+		t.Fatalf("Failed to insert json array with error %v", err)
+	}
+	if err = timeserData.InsertJson("TaggedTable", []string{}, []byte(`{"CID": "310-680-200-555002", "Cell-RF": {"cellId": 555002, "rsrq": -10}}`)); err != nil {
+		t.Fatalf("Failed to insert json with error %v", err)
+	}
+	if len(writtenPoints) != 3 {
+		t.Fatalf("Expected 3 points, got %v", len(writtenPoints))
+	}
+
+	expected := []string{"555001", "555003", "555002"}
+	for i, pt := range writtenPoints {
+		tags := pt.Tags()
+		fields, _ := pt.Fields()
+		if tags["Cell-RF.cellId"] != expected[i] || !strings.HasSuffix(tags["CID"], expected[i]) {
+			t.Errorf("Unexpected tags %v for point %v", tags, i)
+		}
+		if _, ok := fields["CID"]; ok || len(fields) != 1 {
+			t.Errorf("Tag keys stored as fields too: %v", fields)
+		}
+	}
+}
+
+// Test function for taking the point timestamp from a JSON key
+func TestTimeSeriesDbJsonTimeKey(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	timeserData.SetTimeKey("TimedTable", "collectionTime", "ms")
+	rows := []byte(`[{"collectionTime": 1629174962020, "rsrq": -13}, {"collectionTime": 1629174963020, "rsrq": -17}]`)
+	if err = timeserData.InsertJsonArray("TimedTable", []string{}, rows); err != nil {
+		t.Fatalf("Failed to insert json array with error %v", err)
+	}
+
+	timeserData.SetTimeKey("TimedJsonTable", "report.time", time.RFC3339)
+	if err = timeserData.InsertJson("TimedJsonTable", []string{}, []byte(`{"report": {"time": "2021-08-17T04:36:02Z", "rsrq": -10}}`)); err != nil {
+		t.Fatalf("Failed to insert json with error %v", err)
+	}
+
+	expected := []time.Time{
+		time.Unix(1629174962, 20000000),
+		time.Unix(1629174963, 20000000),
+		time.Date(2021, 8, 17, 4, 36, 2, 0, time.UTC),
+	}
+	if len(writtenPoints) != len(expected) {
+		t.Fatalf("Expected %v points, got %v", len(expected), len(writtenPoints))
+	}
+	for i, pt := range writtenPoints {
+		if !pt.Time().Equal(expected[i]) {
+			t.Errorf("Point %v expected at %v, got %v", i, expected[i], pt.Time())
+		}
+		fields, _ := pt.Fields()
+		if len(fields) != 1 {
+			t.Errorf("Time key stored as field: %v", fields)
+		}
+	}
+
+	err = timeserData.InsertJson("TimedJsonTable", []string{}, []byte(`{"report": {"time": "yesterday", "rsrq": -10}}`))
+	if err == nil {
+		t.Errorf("Expected error for unparsable time")
+	}
+}
+
+// Test function for queries with bound parameters
+func TestTimeSeriesDbQueryWithParams(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	var params map[string]interface{}
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		params = q.Parameters
+		return seriesResp("ParamTable", []string{"time", "prb"}, []interface{}{"2021-08-20T05:47:46Z", json.Number("3")}), nil
+	}
+
+	node := `gnb' OR '1'='1`
+	queryStr := fmt.Sprintf("SELECT prb FROM %v WHERE node = $node AND prb > $min", stslgo.QuoteIdentifier(`E2"Node`))
+	_, err = timeserData.QueryWithParams(queryStr, map[string]interface{}{"node": node, "min": 2})
+	if err != nil {
+		t.Fatalf("Unable to query with error %v", err)
+	}
+	if issuedQueries[0] != `SELECT prb FROM "E2\"Node" WHERE node = $node AND prb > $min` {
+		t.Errorf("Parameter values must not be embedded in the query: %v", issuedQueries[0])
+	}
+	if params["node"] != node || params["min"] != 2 {
+		t.Errorf("Parameters not passed with the query: %v", params)
+	}
+	if stslgo.QuoteLiteral(node) != `'gnb\' OR \'1\'=\'1'` {
+		t.Errorf("Unexpected quoted literal %v", stslgo.QuoteLiteral(node))
+	}
+}
+
+// Test function for writing to and querying other databases than the one of the client
+func TestTimeSeriesDbMultipleDatabases(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	var databases []string
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		databases = append(databases, q.Database)
+		return &timesrclient.Response{Results: []timesrclient.Result{{}}}, nil
+	}
+
+	if err = timeserData.CreateTimeSeriesDBNamed("aggdb", "longterm", "52w"); err != nil {
+		t.Fatalf("Unable to create DB with error %v", err)
+	}
+	if issuedQueries[0] != `CREATE DATABASE "aggdb" WITH DURATION 52w REPLICATION 1 SHARD DURATION 52w NAME "longterm"` {
+		t.Errorf("Unexpected query %v", issuedQueries[0])
+	}
+
+	fields := map[string]interface{}{"prb": 3}
+	if err = timeserData.WritePoint("CellKpi", nil, fields); err != nil {
+		t.Fatalf("Unable to write with error %v", err)
+	}
+	if err = timeserData.WritePointTo("aggdb", "CellKpi", nil, fields); err != nil {
+		t.Fatalf("Unable to write with error %v", err)
+	}
+	if len(writtenDatabases) != 2 || writtenDatabases[0] != "testdb" || writtenDatabases[1] != "aggdb" {
+		t.Errorf("Unexpected databases written %v", writtenDatabases)
+	}
+
+	databases = nil
+	timeserData.Query("SELECT * FROM CellKpi")
+	timeserData.QueryFrom("aggdb", "SELECT * FROM CellKpi")
+	if len(databases) != 2 || databases[0] != "testdb" || databases[1] != "aggdb" {
+		t.Errorf("Unexpected databases queried %v", databases)
+	}
+}
+
+// Test function for the values of a key within a time range and the newest values of a key
+func TestTimeSeriesDbGetRangeAndHistory(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		if strings.Contains(q.Command, "DESC") {
+			return seriesResp("KeyTable", []string{"time", "a"},
+				[]interface{}{"2021-08-20T05:47:48Z", json.Number("3")},
+				[]interface{}{"2021-08-20T05:47:47Z", json.Number("2")}), nil
+		}
+		return seriesResp("KeyTable", []string{"time", "a"},
+			[]interface{}{"2021-08-20T05:47:46Z", json.Number("1")},
+			[]interface{}{"2021-08-20T05:47:47Z", json.Number("2")}), nil
+	}
+
+	start := time.Date(2021, 8, 20, 5, 0, 0, 0, time.UTC)
+	values, err := timeserData.GetRange("KeyTable", "a", start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Unable to get range with error %v", err)
+	}
+	if issuedQueries[0] != `SELECT "a" FROM "KeyTable" WHERE time >= '2021-08-20T05:00:00Z' AND time < '2021-08-20T06:00:00Z'` {
+		t.Errorf("Unexpected query %v", issuedQueries[0])
+	}
+	if len(values) != 2 || values[0].Value != json.Number("1") || !values[1].Time.Equal(start.Add(47*time.Minute+47*time.Second)) {
+		t.Errorf("Unexpected values %v", values)
+	}
+	if _, err = timeserData.GetRange("KeyTable", "a", start, time.Time{}); err != nil || strings.Contains(issuedQueries[1], "time <") {
+		t.Errorf("Unexpected query %v with error %v", issuedQueries[1], err)
+	}
+
+	values, err = timeserData.GetHistory("KeyTable", "a", 2)
+	if err != nil {
+		t.Fatalf("Unable to get history with error %v", err)
+	}
+	if len(values) != 2 || values[0].Value != json.Number("2") || values[1].Value != json.Number("3") {
+		t.Errorf("Unexpected values %v", values)
+	}
+
+	// Errors are not ignored
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Err: "database not found: testdb"}, nil
+	}
+	if _, err = timeserData.Get("KeyTable", "a"); err == nil {
+		t.Errorf("Expected error from Get")
+	}
+	if _, err = timeserData.GetRange("KeyTable", "a", start, time.Time{}); err == nil {
+		t.Errorf("Expected error from GetRange")
+	}
+}
+
+// Test function for deleting the points of a measurement by tags and time range
+func TestTimeSeriesDbDeleteData(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	start := time.Date(2021, 8, 20, 5, 0, 0, 0, time.UTC)
+	err = timeserData.DeleteData("Cell KPI", map[string]string{"node": "gnb'1", "cellId": "c1"}, start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Unable to delete with error %v", err)
+	}
+	err = timeserData.DeleteData("Cell KPI", nil, time.Time{}, start)
+	if err != nil {
+		t.Fatalf("Unable to delete with error %v", err)
+	}
+	expected := []string{
+		`DELETE FROM "Cell KPI" WHERE "cellId" = 'c1' AND "node" = 'gnb\'1' AND time >= '2021-08-20T05:00:00Z' AND time < '2021-08-20T06:00:00Z'`,
+		`DELETE FROM "Cell KPI" WHERE time < '2021-08-20T05:00:00Z'`,
+	}
+	if fmt.Sprint(issuedQueries) != fmt.Sprint(expected) {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Err: "database not found: testdb"}, nil
+	}
+	if err = timeserData.DeleteData("Cell KPI", nil, start, time.Time{}); err == nil {
+		t.Errorf("Expected error from DeleteData")
+	}
+	if err = timeserData.DropMeasurement("Cell KPI"); err == nil {
+		t.Errorf("Expected error from DropMeasurement")
+	}
+}
+
+// Test function for the kinds of the errors returned by queries and writes
+func TestTimeSeriesDbErrorKinds(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Err: "error parsing query"}, nil
+	}
+	for name, call := range map[string]func() error{
+		"CreateTimeSeriesDB":    timeserData.CreateTimeSeriesDB,
+		"DeleteTimeSeriesDB":    timeserData.DeleteTimeSeriesDB,
+		"CreateRetentionPolicy": func() error { return timeserData.CreateRetentionPolicy("rp", "1d", false) },
+		"DeleteRetentionPolicy": func() error { return timeserData.DeleteRetentionPolicy("rp") },
+		"Query": func() error {
+			_, err := timeserData.Query("SELECT")
+			return err
+		},
+	} {
+		if err = call(); !stslgo.IsKind(err, stslgo.ErrQueryFailed) {
+			t.Errorf("Expected ErrQueryFailed from %v, got %v", name, err)
+		}
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Err: "database not found: testdb"}, nil
+	}
+	if _, err = timeserData.Get("KeyTable", "a"); !stslgo.IsKind(err, stslgo.ErrTimeSeriesDBNotFound) {
+		t.Errorf("Expected ErrTimeSeriesDBNotFound, got %v", err)
+	}
+
+	writeErr = errors.New("timeout")
+	if err = timeserData.InsertJson("JsonTable", []string{}, []byte(`{"a": 1}`)); !stslgo.IsKind(err, stslgo.ErrWriteFailed) || stslgo.ErrorCause(err) != writeErr {
+		t.Errorf("Expected ErrWriteFailed caused by the write error, got %v", err)
+	}
+
+	notConnected := stslgo.NewTimeSeriesClientData("testdb", "", "")
+	if _, err = notConnected.Query("SELECT * FROM JsonTable"); err != stslgo.ErrNotConnected {
+		t.Errorf("Expected ErrNotConnected, got %v", err)
+	}
+}
+
+// Test function for writing points with their timestamp and a coarser precision
+func TestTimeSeriesDbWritePointAtPrecision(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	at := time.Date(2021, 8, 20, 5, 47, 46, 123456789, time.UTC)
+	if err = timeserData.WritePointAt("CellKpi", map[string]string{"cellId": "c1"}, map[string]interface{}{"prb": 3}, at); err != nil {
+		t.Fatalf("Unable to write point with error %v", err)
+	}
+	if len(writtenPoints) != 1 || !writtenPoints[0].Time().Equal(at) || writtenPrecision != "ns" {
+		t.Errorf("Unexpected point %v with precision %v", writtenPoints, writtenPrecision)
+	}
+
+	if err = timeserData.SetWritePrecision("ps"); err == nil {
+		t.Errorf("Expected error for unsupported precision")
+	}
+	if err = timeserData.SetWritePrecision("s"); err != nil {
+		t.Fatalf("Unable to set precision with error %v", err)
+	}
+	timeserData.WritePointAt("CellKpi", nil, map[string]interface{}{"prb": 4}, at)
+	if writtenPrecision != "s" || !strings.HasSuffix(writtenPoints[1].PrecisionString(writtenPrecision), " 1629438466") {
+		t.Errorf("Expected second precision, got %v", writtenPrecision)
+	}
+
+	// Spilled points keep the precision
+	dir, err := ioutil.TempDir("", "stslgo-precision")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "spill")
+	timeserData.SetSpillFile(path, 0)
+	writeErr = &url.Error{Op: "Post", URL: "http://localhost:8086/write", Err: errors.New("connection refused")}
+	timeserData.WritePointAt("CellKpi", nil, map[string]interface{}{"prb": 5}, at)
+	content, _ := ioutil.ReadFile(path)
+	if string(content) != "testdb\t\tCellKpi prb=5i 1629438466000000000\n" {
+		t.Errorf("Unexpected spill file content %q", content)
+	}
+}
+
+// Test function for inserting JSON rows with disjoint keys and their own timestamps
+func TestTimeSeriesDbInsertJsonRowsDisjoint(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	timeserData.SetTimeKey("JsonTable", "ts", time.RFC3339)
+	rows := []stslgo.JsonRow{
+		{"a": 1.0, "s": "x", "ts": "2021-08-20T05:47:46Z"},
+		{"b": 2.0, "ts": "2021-08-20T05:47:47Z"},
+	}
+	if err = timeserData.InsertUnmarshalledJsonRows("JsonTable", rows, []string{}); err != nil {
+		t.Fatalf("Unable to insert rows with error %v", err)
+	}
+	if writeCalls != 1 || len(writtenPoints) != 2 {
+		t.Fatalf("Expected 2 points in a single write, got %v in %v", len(writtenPoints), writeCalls)
+	}
+	first, _ := writtenPoints[0].Fields()
+	second, _ := writtenPoints[1].Fields()
+	if len(first) != 2 || first["a"] != 1.0 || first["s"] != "x" || len(second) != 1 || second["b"] != 2.0 {
+		t.Errorf("Expected disjoint fields, got %v and %v", first, second)
+	}
+	if writtenPoints[0].Time().Unix() != 1629438466 || writtenPoints[1].Time().Unix() != 1629438467 {
+		t.Errorf("Unexpected timestamps %v and %v", writtenPoints[0].Time(), writtenPoints[1].Time())
+	}
+
+	if err = timeserData.InsertUnmarshalledJsonRows("JsonTable", []stslgo.JsonRow{}, []string{}); err != nil || writeCalls != 1 {
+		t.Errorf("Expected no write for no rows, got %v writes with error %v", writeCalls, err)
+	}
+}
+
+// Test function for the Go and JSON value kinds stored as fields
+func TestTimeSeriesDbInsertJsonFieldKinds(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	logger := &testLogger{}
+	timeserData.SetLogger(logger)
+
+	at := time.Date(2021, 8, 20, 5, 47, 46, 0, time.UTC)
+	rows := []stslgo.JsonRow{{
+		"i64": int64(1) << 60, "u": uint(3), "u64": uint64(math.MaxUint64), "f32": float32(0.5),
+		"n": json.Number("7"), "nf": json.Number("2.5"), "at": at, "ch": make(chan int),
+	}}
+	if err = timeserData.InsertUnmarshalledJsonRows("JsonTable", rows, []string{}); err != nil {
+		t.Fatalf("Unable to insert rows with error %v", err)
+	}
+	fields, _ := writtenPoints[0].Fields()
+	expected := map[string]interface{}{"i64": int64(1) << 60, "u": int64(3), "f32": 0.5, "n": int64(7), "nf": 2.5, "at": "2021-08-20T05:47:46Z"}
+	if len(fields) != len(expected) {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+	for key, value := range expected {
+		if fields[key] != value {
+			t.Errorf("Expected %v=%v, got %v", key, value, fields[key])
+		}
+	}
+
+	skipped := false
+	for _, message := range logger.messages {
+		if strings.HasPrefix(message, "warn Keys [ch u64] of measurement JsonTable not stored") {
+			skipped = true
+		}
+	}
+	if !skipped {
+		t.Errorf("Expected skipped keys to be logged, got %v", logger.messages)
+	}
+}
+
+// Test function for keeping JSON integers as integer fields
+func TestTimeSeriesDbInsertJsonNumberMode(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	payload := []byte(`[{"prbTotal": 9007199254740993, "load": 0.5, "Cell": {"pkts": 12}}]`)
+	if err = timeserData.InsertJsonArray("JsonTable", []string{}, payload); err != nil {
+		t.Fatalf("Unable to insert JSON with error %v", err)
+	}
+	fields, _ := writtenPoints[0].Fields()
+	if _, ok := fields["prbTotal"].(float64); !ok {
+		t.Errorf("Expected float fields by default, got %v", fields)
+	}
+
+	timeserData.SetJsonNumberMode(stslgo.JsonNumberPreserve)
+	writtenPoints = nil
+	if err = timeserData.InsertJsonArray("JsonTable", []string{}, payload); err != nil {
+		t.Fatalf("Unable to insert JSON with error %v", err)
+	}
+	fields, _ = writtenPoints[0].Fields()
+	if fields["prbTotal"] != int64(9007199254740993) || fields["load"] != 0.5 || fields["Cell.pkts"] != int64(12) {
+		t.Errorf("Expected integer fields, got %v", fields)
+	}
+	writtenPoints = nil
+	if err = timeserData.InsertJson("JsonTable", []string{}, []byte(`{"pkts": 3}`)); err != nil || len(writtenPoints) != 1 {
+		t.Fatalf("Unable to insert JSON with error %v", err)
+	}
+	fields, _ = writtenPoints[0].Fields()
+	if fields["pkts"] != int64(3) {
+		t.Errorf("Expected integer field, got %v", fields)
+	}
+	if err = timeserData.InsertJson("JsonTable", []string{}, []byte(`{"pkts": 3} x`)); err == nil {
+		t.Errorf("Expected error for data after the JSON object")
+	}
+}
+
+// Test function for the flattening options of a measurement
+func TestTimeSeriesDbFlattenOptions(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	payload := []byte(`{"cell": {"id": "c1", "nbr": [{"cid": "n1", "rsrp": -90}], "bands": [1, 3], "deep": {"a": {"b": 1}}}}`)
+	insert := func() map[string]interface{} {
+		writtenPoints = nil
+		if err := timeserData.InsertJson("JsonTable", []string{}, payload); err != nil || len(writtenPoints) != 1 {
+			t.Fatalf("Unable to insert JSON with error %v", err)
+		}
+		fields, _ := writtenPoints[0].Fields()
+		return fields
+	}
+
+	fields := insert()
+	if fields["cell.nbr.0.rsrp"] != -90.0 || fields["cell.bands.1"] != 3.0 || fields["cell.deep.a.b"] != 1.0 {
+		t.Errorf("Unexpected default flattening %v", fields)
+	}
+
+	timeserData.SetFlattenOptions("JsonTable", stslgo.FlattenOptions{Separator: "_", ArrayMode: stslgo.ArrayJoin, MaxDepth: 3})
+	fields = insert()
+	expected := map[string]interface{}{"cell_id": "c1", "cell_nbr": `[{"cid":"n1","rsrp":-90}]`, "cell_bands": "1,3", "cell_deep_a": `{"b":1}`}
+	if len(fields) != len(expected) {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+	for key, value := range expected {
+		if fields[key] != value {
+			t.Errorf("Expected %v=%v, got %v", key, value, fields[key])
+		}
+	}
+
+	timeserData.SetFlattenOptions("JsonTable", stslgo.FlattenOptions{ArrayMode: stslgo.ArrayJSON})
+	fields = insert()
+	if fields["cell.bands"] != "[1,3]" || fields["cell.deep.a.b"] != 1.0 {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+}
+
+// Test function for inserting the elements of JSON arrays as points of their own
+func TestTimeSeriesDbFlattenArrayExplode(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	timeserData.SetTagKeys("JsonTable", []string{"CID"})
+	timeserData.SetFlattenOptions("JsonTable", stslgo.FlattenOptions{ArrayMode: stslgo.ArrayExplode, ElementKey: "CID"})
+	payload := []byte(`{"CID": "c1", "PRB": 5, "Bands": [1, 3], "Neighbors": [{"CID": "n1", "RSRP": -90}, {"CID": "n2", "RSRP": -95}, {"RSRP": -99}]}`)
+	if err = timeserData.InsertJson("JsonTable", []string{}, payload); err != nil {
+		t.Fatalf("Unable to insert JSON with error %v", err)
+	}
+	if len(writtenPoints) != 4 {
+		t.Fatalf("Expected the row and 3 element points, got %v", writtenPoints)
+	}
+	fields, _ := writtenPoints[0].Fields()
+	if len(fields) != 2 || fields["PRB"] != 5.0 || fields["Bands"] != "1,3" || writtenPoints[0].Tags()["CID"] != "c1" {
+		t.Errorf("Unexpected row point %v", writtenPoints[0])
+	}
+	for i, cid := range []string{"n1", "n2", "2"} {
+		pt := writtenPoints[i+1]
+		fields, _ := pt.Fields()
+		if len(fields) != 1 || fields["Neighbors.RSRP"] == nil || pt.Tags()["CID"] != "c1" || pt.Tags()["Neighbors.CID"] != cid ||
+			!pt.Time().Equal(writtenPoints[0].Time()) {
+			t.Errorf("Unexpected element point %v", pt)
+		}
+	}
+
+	// Rows holding only arrays
+	writtenPoints = nil
+	timeserData.SetFlattenOptions("JsonTable", stslgo.FlattenOptions{ArrayMode: stslgo.ArrayExplode})
+	if err = timeserData.InsertJson("JsonTable", []string{}, []byte(`{"Ues": [{"rsrp": -80}, {"rsrp": -85}]}`)); err != nil {
+		t.Fatalf("Unable to insert JSON with error %v", err)
+	}
+	if len(writtenPoints) != 2 || writtenPoints[1].Tags()["Ues.index"] != "1" {
+		t.Errorf("Unexpected element points %v", writtenPoints)
+	}
+}
+
+// Test function for setting and getting several keys with a single request
+func TestTimeSeriesDbMultiKey(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	err = timeserData.SetMulti("KeyTable", map[string]interface{}{"a": "1", "b": 2.5})
+	if err != nil {
+		t.Fatalf("Unable to set keys with error %v", err)
+	}
+	if writeCalls != 1 || len(writtenPoints) != 1 {
+		t.Fatalf("Expected a single point, got %v in %v writes", len(writtenPoints), writeCalls)
+	}
+	if fields, _ := writtenPoints[0].Fields(); len(fields) != 2 || fields["b"] != 2.5 {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+	if err = timeserData.SetMulti("KeyTable", nil); err == nil {
+		t.Errorf("Expected error for no keys")
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		issuedQueries = append(issuedQueries, q.Command)
+		if strings.Contains(q.Command, "LAST(*)") {
+			return seriesResp("KeyTable", []string{"time", "last_a", "last_b"},
+				[]interface{}{"1970-01-01T00:00:00Z", "1", json.Number("2.5")}), nil
+		}
+		return seriesResp("KeyTable", []string{"time", "a", "c"},
+			[]interface{}{"1970-01-01T00:00:00Z", "1", nil}), nil
+	}
+	values, err := timeserData.GetMulti("KeyTable", []string{"a", "c"})
+	if err != nil {
+		t.Fatalf("Unable to get keys with error %v", err)
+	}
+	if issuedQueries[0] != `SELECT LAST("a") AS "a", LAST("c") AS "c" FROM "KeyTable"` {
+		t.Errorf("Unexpected query %v", issuedQueries[0])
+	}
+	if len(values) != 1 || values["a"] != "1" {
+		t.Errorf("Unexpected values %v", values)
+	}
+
+	values, err = timeserData.GetAll("KeyTable")
+	if err != nil {
+		t.Fatalf("Unable to get all keys with error %v", err)
+	}
+	if len(values) != 2 || values["a"] != "1" || values["b"] != json.Number("2.5") {
+		t.Errorf("Unexpected values %v", values)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Err: "database not found: testdb"}, nil
+	}
+	if _, err = timeserData.GetMulti("KeyTable", []string{"a"}); err == nil {
+		t.Errorf("Expected error from GetMulti")
+	}
+}
+
+// Test function for the typed getters of the key-value operations
+func TestTimeSeriesDbTypedGet(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	values := map[string]interface{}{
+		"f": json.Number("2.5"), "i": json.Number("7"), "s": "on", "b": true, "n": "12", "big": float64(1 << 63),
+	}
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		issuedQueries = append(issuedQueries, q.Command)
+		for key, value := range values {
+			if strings.HasPrefix(q.Command, fmt.Sprintf(`SELECT "%v" `, key)) {
+				return seriesResp("KeyTable", []string{"time", key}, []interface{}{"2021-08-20T05:47:46Z", value}), nil
+			}
+		}
+		return &timesrclient.Response{Results: []timesrclient.Result{{}}}, nil
+	}
+
+	if f, err := timeserData.GetFloat("KeyTable", "f"); err != nil || f != 2.5 {
+		t.Errorf("Unexpected float %v with error %v", f, err)
+	}
+	if n, err := timeserData.GetInt("KeyTable", "i"); err != nil || n != 7 {
+		t.Errorf("Unexpected int %v with error %v", n, err)
+	}
+	if n, err := timeserData.GetInt("KeyTable", "n"); err != nil || n != 12 {
+		t.Errorf("Unexpected int %v from string with error %v", n, err)
+	}
+	if _, err := timeserData.GetInt("KeyTable", "f"); err == nil {
+		t.Errorf("Expected error for a fraction")
+	}
+	if n, err := timeserData.GetInt("KeyTable", "big"); err == nil {
+		t.Errorf("Expected error for a float beyond int64, got %v", n)
+	}
+	if s, err := timeserData.GetString("KeyTable", "s"); err != nil || s != "on" {
+		t.Errorf("Unexpected string %v with error %v", s, err)
+	}
+	if _, err := timeserData.GetString("KeyTable", "b"); err == nil {
+		t.Errorf("Expected error for a bool as string")
+	}
+	if b, err := timeserData.GetBool("KeyTable", "b"); err != nil || !b {
+		t.Errorf("Unexpected bool %v with error %v", b, err)
+	}
+	if _, err := timeserData.GetBool("KeyTable", "s"); err == nil {
+		t.Errorf("Expected error for a string as bool")
+	}
+	if _, err := timeserData.GetFloat("KeyTable", "missing"); err != stslgo.ErrKeyNotFound {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+	if _, err := timeserData.Get("KeyTable", "missing"); err != stslgo.ErrKeyNotFound {
+		t.Errorf("Expected ErrKeyNotFound from Get, got %v", err)
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Err: "database not found: testdb"}, nil
+	}
+	if _, err := timeserData.GetBool("KeyTable", "b"); !stslgo.IsKind(err, stslgo.ErrTimeSeriesDBNotFound) {
+		t.Errorf("Expected ErrTimeSeriesDBNotFound, got %v", err)
+	}
+}
+
+// Test function to verify that a write leaves no goroutine behind
+func TestTimeSeriesDbWritePointNoGoroutine(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	if err = timeserData.WritePoint("GoroutineTable", nil, map[string]interface{}{"prb": 0}); err != nil {
+		t.Fatalf("Unable to write point with error %v", err)
+	}
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		if err = timeserData.WritePoint("GoroutineTable", nil, map[string]interface{}{"prb": i}); err != nil {
+			t.Fatalf("Unable to write point with error %v", err)
+		}
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("Expected no goroutine started by the writes, got %v before and %v after", before, after)
+	}
+}
+
+// Forgets the points written through the mock so that long benchmarks run in bounded memory
+func resetWrittenPoints() {
+	mockLock.Lock()
+	writtenPoints = nil
+	writtenDatabases = nil
+	writtenRetentionPolicies = nil
+	mockLock.Unlock()
+}
+
+// Benchmark of single point writes, each in its own request
+func BenchmarkTimeSeriesDbWritePoint(b *testing.B) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	tags := map[string]string{"cellId": "1"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err = timeserData.WritePoint("BenchTable", tags, map[string]interface{}{"prb": i}); err != nil {
+			b.Fatalf("Unable to write point with error %v", err)
+		}
+		if i%1024 == 0 {
+			resetWrittenPoints()
+		}
+	}
+}
+
+// Benchmark of the same writes going through a BatchWriter, one request per full batch
+func BenchmarkTimeSeriesDbBatchWriter(b *testing.B) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	bw := timeserData.NewBatchWriter(stslgo.BatchWriterConfig{BatchSize: 1000, FlushInterval: time.Hour})
+	defer bw.Close()
+	tags := map[string]string{"cellId": "1"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err = bw.WritePoint("BenchTable", tags, map[string]interface{}{"prb": i}); err != nil {
+			b.Fatalf("Unable to add point with error %v", err)
+		}
+		if i%1024 == 0 {
+			resetWrittenPoints()
+		}
+	}
+	if err = bw.Flush(); err != nil {
+		b.Fatalf("Unable to flush with error %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"sync"
+	"sync/atomic"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Number of points a Subscription holds for its reader, points beyond are dropped
+const SubscriptionBuffer = 1024
+
+// Delivers the points written by the client on C, until closed
+type Subscription struct {
+	C           <-chan Point
+	c           chan Point
+	measurement string
+	dropped     int64
+	registry    *subscriptions
+}
+
+// Local fan-out of the written points to the subscriptions
+type subscriptions struct {
+	lock sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// Subscribes to the points of a measurement (all measurements when empty) written by this client, delivered on
+// the channel of the returned Subscription once TimeSeriesDB accepted them, so that goroutines of an xApp can
+// react to new KPIs without querying. Points are not delivered when the reader falls SubscriptionBuffer points
+// behind, see Dropped(). The Subscription must be closed with Close()
+func (timeserData *TimeSeriesClientData) Subscribe(measurement string) *Subscription {
+	c := make(chan Point, SubscriptionBuffer)
+	sub := &Subscription{C: c, c: c, measurement: timeserData.MeasurementName(measurement), registry: &timeserData.subscriptions}
+	timeserData.subscriptions.lock.Lock()
+	defer timeserData.subscriptions.lock.Unlock()
+	if timeserData.subscriptions.subs == nil {
+		timeserData.subscriptions.subs = make(map[*Subscription]struct{})
+	}
+	timeserData.subscriptions.subs[sub] = struct{}{}
+	return sub
+}
+
+// Stops the delivery and closes C
+func (sub *Subscription) Close() {
+	sub.registry.lock.Lock()
+	defer sub.registry.lock.Unlock()
+	if _, ok := sub.registry.subs[sub]; ok {
+		delete(sub.registry.subs, sub)
+		close(sub.c)
+	}
+}
+
+// Returns the number of points not delivered because the reader was behind
+func (sub *Subscription) Dropped() int64 {
+	return atomic.LoadInt64(&sub.dropped)
+}
+
+// Delivers written points to the subscriptions
+func (timeserData *TimeSeriesClientData) publish(points []*timesrclient.Point) {
+	timeserData.subscriptions.lock.RLock()
+	defer timeserData.subscriptions.lock.RUnlock()
+	if len(timeserData.subscriptions.subs) == 0 {
+		return
+	}
+	for _, pt := range points {
+		for sub := range timeserData.subscriptions.subs {
+			if sub.measurement != "" && sub.measurement != pt.Name() {
+				continue
+			}
+			// Maps of its own for each subscriber
+			fields, _ := pt.Fields()
+			select {
+			case sub.c <- Point{Measurement: pt.Name(), Tags: pt.Tags(), Fields: fields, Time: pt.Time()}:
+			default:
+				atomic.AddInt64(&sub.dropped, 1)
+				timeserData.reportDropped(1, "subscriber_overflow")
+			}
+		}
+	}
+}
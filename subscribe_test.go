@@ -0,0 +1,75 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"errors"
+	"fmt"
+	"stslgo"
+	"testing"
+)
+
+// Test function for delivering the written points to local subscribers
+func TestTimeSeriesDbSubscribe(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	cells := timeserData.Subscribe("CellKpi")
+	all := timeserData.Subscribe("")
+
+	timeserData.WritePoints("CellKpi", []stslgo.Point{{Tags: map[string]string{"cellId": "c1"}, Fields: map[string]interface{}{"prb": 1}}})
+	timeserData.WritePoint("UeKpi", nil, map[string]interface{}{"rsrp": -90})
+	writeErr = errors.New("timeout")
+	timeserData.WritePoint("CellKpi", nil, map[string]interface{}{"prb": 2})
+	writeErr = nil
+
+	point := <-cells.C
+	if point.Measurement != "CellKpi" || point.Tags["cellId"] != "c1" || point.Fields["prb"] != int64(1) || point.Time.IsZero() {
+		t.Errorf("Unexpected point %v", point)
+	}
+	if len(cells.C) != 0 || len(all.C) != 2 {
+		t.Errorf("Expected only the written points of each subscription, got %v and %v", len(cells.C), len(all.C))
+	}
+	<-all.C
+	if point = <-all.C; point.Measurement != "UeKpi" {
+		t.Errorf("Unexpected point %v", point)
+	}
+
+	// Slow subscriber
+	for i := 0; i <= stslgo.SubscriptionBuffer; i++ {
+		timeserData.WritePoint("CellKpi", nil, map[string]interface{}{"prb": i})
+	}
+	if cells.Dropped() != 1 {
+		t.Errorf("Expected 1 point dropped, got %v", cells.Dropped())
+	}
+
+	cells.Close()
+	cells.Close()
+	timeserData.WritePoint("CellKpi", nil, map[string]interface{}{"prb": 3})
+	n := 0
+	for range cells.C {
+		n++
+	}
+	if n != stslgo.SubscriptionBuffer {
+		t.Errorf("Expected the buffered points then the channel closed, got %v", n)
+	}
+	all.Close()
+}
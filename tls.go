@@ -0,0 +1,96 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+)
+
+// TLS settings of the connection to TimeSeriesDB. TLS is used when Enabled or any other setting is present
+type TLSOptions struct {
+	Enabled            bool   // Use https even without any other setting (system CAs)
+	CAFile             string // PEM bundle of the CAs trusted to verify the TimeSeriesDB certificate
+	CertFile           string // PEM client certificate for mutual TLS
+	KeyFile            string // PEM key of the client certificate
+	InsecureSkipVerify bool   // Do not verify the TimeSeriesDB certificate, for tests only
+	ServerName         string // Name expected in the TimeSeriesDB certificate when it differs from the host name
+}
+
+// Reads the TLS settings from the environment:
+// TIMESERIESDB_TLS_ENABLED, TIMESERIESDB_TLS_CA_FILE, TIMESERIESDB_TLS_CERT_FILE, TIMESERIESDB_TLS_KEY_FILE,
+// TIMESERIESDB_TLS_INSECURE_SKIP_VERIFY and TIMESERIESDB_TLS_SERVER_NAME
+func TLSOptionsFromEnv() TLSOptions {
+	enabled, _ := strconv.ParseBool(os.Getenv("TIMESERIESDB_TLS_ENABLED"))
+	insecure, _ := strconv.ParseBool(os.Getenv("TIMESERIESDB_TLS_INSECURE_SKIP_VERIFY"))
+	return TLSOptions{
+		Enabled:            enabled,
+		CAFile:             os.Getenv("TIMESERIESDB_TLS_CA_FILE"),
+		CertFile:           os.Getenv("TIMESERIESDB_TLS_CERT_FILE"),
+		KeyFile:            os.Getenv("TIMESERIESDB_TLS_KEY_FILE"),
+		InsecureSkipVerify: insecure,
+		ServerName:         os.Getenv("TIMESERIESDB_TLS_SERVER_NAME"),
+	}
+}
+
+// Tells if the options ask for TLS
+func (opts TLSOptions) IsEnabled() bool {
+	return opts.Enabled || opts.CAFile != "" || opts.CertFile != "" || opts.KeyFile != "" || opts.InsecureSkipVerify || opts.ServerName != ""
+}
+
+// Builds the tls.Config for the options, loading the CA bundle and client certificate
+func (opts TLSOptions) TLSConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+		ServerName:         opts.ServerName,
+	}
+	if opts.CAFile != "" {
+		pem, err := ioutil.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read TimeSeriesDB CA bundle: %v", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No certificate found in TimeSeriesDB CA bundle %v", opts.CAFile)
+		}
+	}
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return nil, errors.New("TimeSeriesDB client certificate and key must be set together")
+	}
+	if opts.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load TimeSeriesDB client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// Sets the TLS settings of the connections created afterwards by CreateTimeSeriesConnection(),
+// they take precedence over the environment
+func (timeserData *TimeSeriesClientData) SetTLSOptions(opts TLSOptions) {
+	timeserData.tlsOptions = &opts
+}
@@ -0,0 +1,108 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"stslgo"
+	"testing"
+	"time"
+)
+
+// Writes a self-signed certificate and its key as PEM files in dir
+func writeTestCert(t *testing.T, dir, name string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	if err = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// Test function for building the TLS configuration from options and environment
+func TestTimeSeriesDbTLSOptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stslgo-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	caFile, _ := writeTestCert(t, dir, "ca")
+	certFile, keyFile := writeTestCert(t, dir, "client")
+
+	opts := stslgo.TLSOptions{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, ServerName: "influxdb.ricplt"}
+	config, err := opts.TLSConfig()
+	if err != nil {
+		t.Fatalf("Unable to build TLS config with error %v", err)
+	}
+	if config.RootCAs == nil || len(config.Certificates) != 1 || config.ServerName != "influxdb.ricplt" || config.InsecureSkipVerify {
+		t.Errorf("Unexpected TLS config %+v", config)
+	}
+
+	if _, err = (stslgo.TLSOptions{CertFile: certFile}).TLSConfig(); err == nil {
+		t.Errorf("Expected error for certificate without key")
+	}
+	if _, err = (stslgo.TLSOptions{CAFile: filepath.Join(dir, "missing.crt")}).TLSConfig(); err == nil {
+		t.Errorf("Expected error for missing CA bundle")
+	}
+
+	os.Setenv("TIMESERIESDB_TLS_CA_FILE", caFile)
+	os.Setenv("TIMESERIESDB_TLS_INSECURE_SKIP_VERIFY", "true")
+	defer os.Unsetenv("TIMESERIESDB_TLS_CA_FILE")
+	defer os.Unsetenv("TIMESERIESDB_TLS_INSECURE_SKIP_VERIFY")
+	envOpts := stslgo.TLSOptionsFromEnv()
+	if envOpts.CAFile != caFile || !envOpts.InsecureSkipVerify || !envOpts.IsEnabled() {
+		t.Errorf("Unexpected options from environment %+v", envOpts)
+	}
+	if (stslgo.TLSOptions{}).IsEnabled() {
+		t.Errorf("Empty options must not enable TLS")
+	}
+}
@@ -0,0 +1,212 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+)
+
+// Default interval of the checks for a changed token file
+const DefaultTokenRefreshInterval = 10 * time.Second
+
+var ErrEmptyToken = errors.New("TimeSeriesDB token file is empty")
+
+// Watches the token file and applies the credentials when its content changes
+type tokenWatcher struct {
+	path     string
+	interval time.Duration
+	content  []byte
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// Takes the credentials from a token file, eg. a mounted Kubernetes secret. The token is "username:password"
+// as for InfluxDB 1.8 token authentication, or only the password. The file is read now and re-read every
+// interval (DefaultTokenRefreshInterval if 0) once connected, reconnecting with the new credentials when it
+// changes. When already connected, the previous file stops being watched and the client reconnects with the new
+// credentials now. By default the path is taken from TIMESERIESDB_SERVICE_TOKEN_FILE
+func (timeserData *TimeSeriesClientData) SetTokenFile(path string, interval time.Duration) (err error) {
+	if timeserData.Iclient == nil {
+		return timeserData.setTokenFile(path, interval)
+	}
+	timeserData.stopTokenWatcher()
+	if err = timeserData.setTokenFile(path, interval); err == nil {
+		err = timeserData.reconnect()
+	}
+	// Watched as well when the reconnection failed, to apply a fixed token
+	timeserData.startTokenWatcher()
+	if err != nil {
+		timeserData.logger().Errorf("Failed to apply TimeSeriesDB token file %v: %v\n", path, err)
+	}
+	return err
+}
+
+// Reads the token file and watches it from the next connection
+func (timeserData *TimeSeriesClientData) setTokenFile(path string, interval time.Duration) (err error) {
+	if interval <= 0 {
+		interval = DefaultTokenRefreshInterval
+	}
+	watcher := &tokenWatcher{path: path, interval: interval}
+	if err = timeserData.loadToken(watcher); err != nil {
+		return err
+	}
+	timeserData.credLock.Lock()
+	timeserData.tokenWatcher = watcher
+	timeserData.credLock.Unlock()
+	return nil
+}
+
+// Sets a hook called after every reload of a changed token file, with the error if the reload failed
+func (timeserData *TimeSeriesClientData) SetTokenRefreshHook(hook func(err error)) {
+	timeserData.credLock.Lock()
+	defer timeserData.credLock.Unlock()
+	timeserData.tokenRefreshHook = hook
+}
+
+// Re-reads the token file now, reconnecting if the token changed
+func (timeserData *TimeSeriesClientData) RefreshToken() (err error) {
+	timeserData.credLock.RLock()
+	watcher, hook := timeserData.tokenWatcher, timeserData.tokenRefreshHook
+	timeserData.credLock.RUnlock()
+	if watcher == nil {
+		return errors.New("No TimeSeriesDB token file set")
+	}
+
+	content, err := ioutil.ReadFile(watcher.path)
+	if err == nil {
+		timeserData.credLock.RLock()
+		unchanged := bytes.Equal(content, watcher.content)
+		timeserData.credLock.RUnlock()
+		if unchanged {
+			return nil
+		}
+	}
+	if err == nil {
+		err = timeserData.loadToken(watcher)
+	}
+	if err == nil {
+		err = timeserData.reconnect()
+	}
+	if err != nil {
+		timeserData.logger().Errorf("Failed to refresh TimeSeriesDB token from %v: %v\n", watcher.path, err)
+	} else {
+		timeserData.logger().Infof("TimeSeriesDB token refreshed from %v\n", watcher.path)
+	}
+	if hook != nil {
+		hook(err)
+	}
+	return err
+}
+
+// Reads the token file and sets the credentials from it
+func (timeserData *TimeSeriesClientData) loadToken(watcher *tokenWatcher) error {
+	content, err := ioutil.ReadFile(watcher.path)
+	if err != nil {
+		return err
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return ErrEmptyToken
+	}
+
+	timeserData.setToken(token)
+	timeserData.credLock.Lock()
+	watcher.content = content
+	timeserData.credLock.Unlock()
+	return nil
+}
+
+// Sets the credentials from a "username:password" token, or only the password
+func (timeserData *TimeSeriesClientData) setToken(token string) {
+	timeserData.credLock.Lock()
+	defer timeserData.credLock.Unlock()
+	if i := strings.Index(token, ":"); i >= 0 {
+		timeserData.timeSeriesUserName = token[:i]
+		timeserData.timeSeriesPassword = token[i+1:]
+	} else {
+		timeserData.timeSeriesPassword = token
+	}
+}
+
+// Starts watching the token file, if any, for the connection just created
+func (timeserData *TimeSeriesClientData) startTokenWatcher() {
+	timeserData.credLock.Lock()
+	watcher := timeserData.tokenWatcher
+	if watcher == nil {
+		if path := os.Getenv("TIMESERIESDB_SERVICE_TOKEN_FILE"); path != "" {
+			timeserData.credLock.Unlock()
+			if err := timeserData.setTokenFile(path, 0); err != nil {
+				timeserData.logger().Errorf("Failed to read TimeSeriesDB token file %v: %v\n", path, err)
+				return
+			}
+			timeserData.credLock.Lock()
+			watcher = timeserData.tokenWatcher
+		}
+	}
+	if watcher == nil || watcher.stop != nil {
+		timeserData.credLock.Unlock()
+		return
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	watcher.stop, watcher.done = stop, done
+	timeserData.credLock.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(watcher.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				_ = timeserData.RefreshToken()
+			}
+		}
+	}()
+}
+
+// Stops watching the token file
+func (timeserData *TimeSeriesClientData) stopTokenWatcher() {
+	timeserData.credLock.Lock()
+	watcher := timeserData.tokenWatcher
+	if watcher == nil || watcher.stop == nil {
+		timeserData.credLock.Unlock()
+		return
+	}
+	stop, done := watcher.stop, watcher.done
+	watcher.stop = nil
+	timeserData.credLock.Unlock()
+
+	close(stop)
+	<-done
+}
+
+// Returns the current credentials
+func (timeserData *TimeSeriesClientData) credentials() (userName, password string) {
+	timeserData.credLock.RLock()
+	defer timeserData.credLock.RUnlock()
+	return timeserData.timeSeriesUserName, timeserData.timeSeriesPassword
+}
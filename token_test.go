@@ -0,0 +1,119 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"stslgo"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test function for taking credentials from a token file and reloading them when it changes
+func TestTimeSeriesDbTokenFile(t *testing.T) {
+	var lock sync.Mutex
+	var users, passwords []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		lock.Lock()
+		users = append(users, user)
+		passwords = append(passwords, password)
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"results":[{"statement_id":0}]}`))
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(serverURL.Host)
+	os.Setenv("TIMESERIESDB_SERVICE_HOST", host)
+	os.Setenv("TIMESERIESDB_SERVICE_PORT_HTTP", port)
+	defer os.Unsetenv("TIMESERIESDB_SERVICE_HOST")
+	defer os.Unsetenv("TIMESERIESDB_SERVICE_PORT_HTTP")
+
+	dir, err := ioutil.TempDir("", "stslgo-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	if err = ioutil.WriteFile(tokenFile, []byte("xapp:secret1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	timeserData := stslgo.NewTimeSeriesClientData("testdb", "", "")
+	timeserData.SetReconnectPolicy(stslgo.ReconnectPolicy{})
+	if err = timeserData.SetTokenFile(tokenFile, 10*time.Millisecond); err != nil {
+		t.Fatalf("Unable to read token file with error %v", err)
+	}
+	refreshed := make(chan error, 1)
+	timeserData.SetTokenRefreshHook(func(err error) {
+		refreshed <- err
+	})
+	if err = timeserData.CreateTimeSeriesConnection(); err != nil {
+		t.Fatalf("Unable to connect with error %v", err)
+	}
+	defer timeserData.Close()
+
+	if _, err = timeserData.Query("SHOW DATABASES"); err != nil {
+		t.Fatalf("Query failed with error %v", err)
+	}
+
+	// Rotated token, the password only
+	if err = ioutil.WriteFile(tokenFile, []byte("secret2"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-refreshed:
+		if err != nil {
+			t.Fatalf("Token refresh failed with error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Changed token file not reloaded")
+	}
+	if _, err = timeserData.Query("SHOW DATABASES"); err != nil {
+		t.Fatalf("Query failed with error %v", err)
+	}
+
+	// Another token file once connected applies right away
+	otherFile := filepath.Join(dir, "other")
+	if err = ioutil.WriteFile(otherFile, []byte("other:secret3"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = timeserData.SetTokenFile(otherFile, time.Hour); err != nil {
+		t.Fatalf("Unable to switch token file with error %v", err)
+	}
+	if _, err = timeserData.Query("SHOW DATABASES"); err != nil {
+		t.Fatalf("Query failed with error %v", err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(users) != 3 || users[0] != "xapp" || passwords[0] != "secret1" || users[1] != "xapp" || passwords[1] != "secret2" ||
+		users[2] != "other" || passwords[2] != "secret3" {
+		t.Errorf("Unexpected credentials used %v %v", users, passwords)
+	}
+}
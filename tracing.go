@@ -0,0 +1,85 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Span of an operation, as created by a Tracer
+type Span interface {
+	SetAttribute(key string, value interface{})
+	End(err error)
+}
+
+// Creates the spans of the operations of a client, eg. an adapter to an OpenTelemetry trace.Tracer.
+// The returned context carries the span, so that the spans of nested operations become its children
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key string, value interface{}) {}
+func (noopSpan) End(err error)                              {}
+
+// Sets the tracer creating spans for writes, queries and DB administration, nil for none
+func (timeserData *TimeSeriesClientData) SetTracer(tracer Tracer) {
+	timeserData.tracer = tracer
+}
+
+// Same as Query, with the span of the query created as a child of the span in ctx
+func (timeserData *TimeSeriesClientData) QueryContext(ctx context.Context, queryStr string) (resp *timesrclient.Response, err error) {
+	q := timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, "")
+	response, err := timeserData.queryContext(ctx, q)
+	timeserData.logger().Debugf("TimeSeriesDB Query: DB=%v, QueryString=%v, Result=%v, err=%v\n", timeserData.timeSeriesDbName, queryStr, response, err)
+	return response, err
+}
+
+// Same as WritePoint, with the span of the write created as a child of the span in ctx
+func (timeserData *TimeSeriesClientData) WritePointContext(ctx context.Context, measurement string, tags map[string]string, fields map[string]interface{}) (err error) {
+	return timeserData.writePoint(ctx, timeserData.timeSeriesDbName, measurement, tags, fields, time.Now())
+}
+
+// Starts a span with the tracer, if any
+func (timeserData *TimeSeriesClientData) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if timeserData.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	ctx, span := timeserData.tracer.Start(ctx, name)
+	span.SetAttribute("db.system", "influxdb")
+	return ctx, span
+}
+
+// Returns the operation of a statement for span attributes, eg. SELECT or CREATE DATABASE
+func _statementOperation(statement string) string {
+	words := strings.Fields(strings.ToUpper(statement))
+	switch {
+	case len(words) == 0:
+		return ""
+	case len(words) > 1 && (words[0] == "CREATE" || words[0] == "DROP" || words[0] == "ALTER" || words[0] == "SHOW"):
+		return words[0] + " " + words[1]
+	}
+	return words[0]
+}
@@ -0,0 +1,96 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"stslgo"
+	"sync"
+	"testing"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+type spanKey struct{}
+
+// Span recorded by the test tracer
+type testSpan struct {
+	name   string
+	parent string
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *testSpan) End(err error)                              { s.err, s.ended = err, true }
+
+type testTracer struct {
+	lock  sync.Mutex
+	spans []*testSpan
+}
+
+func (tr *testTracer) Start(ctx context.Context, name string) (context.Context, stslgo.Span) {
+	span := &testSpan{name: name, attrs: map[string]interface{}{}}
+	if parent, ok := ctx.Value(spanKey{}).(*testSpan); ok {
+		span.parent = parent.name
+	}
+	tr.lock.Lock()
+	tr.spans = append(tr.spans, span)
+	tr.lock.Unlock()
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Test function for the spans created around writes and queries
+func TestTimeSeriesDbTracing(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	tracer := &testTracer{}
+	timeserData.SetTracer(tracer)
+
+	ctx, _ := tracer.Start(context.Background(), "ingest")
+	timeserData.WritePointContext(ctx, "CellKpi", nil, map[string]interface{}{"prb": 3})
+	timeserData.QueryContext(ctx, "SELECT * FROM CellKpi")
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return nil, errors.New("timeout")
+	}
+	timeserData.CreateTimeSeriesDB()
+
+	if len(tracer.spans) != 5 {
+		t.Fatalf("Expected 5 spans, got %v", len(tracer.spans))
+	}
+	write, batch, query, admin := tracer.spans[1], tracer.spans[2], tracer.spans[3], tracer.spans[4]
+	if write.name != "stslgo.WritePoint" || write.parent != "ingest" || write.attrs["stslgo.measurement"] != "CellKpi" || !write.ended {
+		t.Errorf("Unexpected write span %+v", write)
+	}
+	if batch.name != "stslgo.Write" || batch.parent != "stslgo.WritePoint" || batch.attrs["stslgo.points"] != 1 || batch.attrs["db.name"] != "testdb" {
+		t.Errorf("Unexpected batch span %+v", batch)
+	}
+	if query.name != "stslgo.Query" || query.parent != "ingest" || query.attrs["db.operation"] != "SELECT" || query.attrs["db.statement.length"] != 21 {
+		t.Errorf("Unexpected query span %+v", query)
+	}
+	if admin.parent != "" || admin.attrs["db.operation"] != "CREATE DATABASE" || admin.err == nil {
+		t.Errorf("Unexpected admin span %+v", admin)
+	}
+}
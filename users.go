@@ -0,0 +1,105 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"fmt"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// User of TimeSeriesDB as reported by SHOW USERS
+type UserInfo struct {
+	Name  string
+	Admin bool
+}
+
+// Creates a user, admin with all the privileges on all the DBs or without privilege until granted some with
+// GrantToken(). Needs an admin client
+func (timeserData *TimeSeriesClientData) CreateUser(userName, password string, admin bool) (err error) {
+	queryStr := fmt.Sprintf("CREATE USER %v WITH PASSWORD %v", _quoteIdent(userName), _quoteLiteral(password))
+	if admin {
+		queryStr += " WITH ALL PRIVILEGES"
+	}
+	if _, err = timeserData.query(timesrclient.NewQuery(queryStr, "", "")); err != nil {
+		timeserData.logger().Errorf("Failed to create user %v with error %v\n", userName, err)
+		return err
+	}
+	timeserData.logger().Infof("Sucessfully created user %v\n", userName)
+	return nil
+}
+
+// Deletes a user
+func (timeserData *TimeSeriesClientData) DeleteUser(userName string) (err error) {
+	if _, err = timeserData.query(timesrclient.NewQuery(fmt.Sprintf("DROP USER %v", _quoteIdent(userName)), "", "")); err != nil {
+		timeserData.logger().Errorf("Failed to delete user %v with error %v\n", userName, err)
+		return err
+	}
+	timeserData.logger().Infof("Sucessfully deleted user %v\n", userName)
+	return nil
+}
+
+// Changes the password of a user
+func (timeserData *TimeSeriesClientData) SetUserPassword(userName, password string) (err error) {
+	queryStr := fmt.Sprintf("SET PASSWORD FOR %v = %v", _quoteIdent(userName), _quoteLiteral(password))
+	if _, err = timeserData.query(timesrclient.NewQuery(queryStr, "", "")); err != nil {
+		timeserData.logger().Errorf("Failed to set password of user %v with error %v\n", userName, err)
+		return err
+	}
+	timeserData.logger().Infof("Sucessfully set password of user %v\n", userName)
+	return nil
+}
+
+// Grants or revokes the admin privileges of a user. The privileges granted per DB are kept
+func (timeserData *TimeSeriesClientData) SetUserAdmin(userName string, admin bool) (err error) {
+	queryStr := fmt.Sprintf("REVOKE ALL PRIVILEGES FROM %v", _quoteIdent(userName))
+	if admin {
+		queryStr = fmt.Sprintf("GRANT ALL PRIVILEGES TO %v", _quoteIdent(userName))
+	}
+	if _, err = timeserData.query(timesrclient.NewQuery(queryStr, "", "")); err != nil {
+		timeserData.logger().Errorf("Failed to set admin %v for user %v with error %v\n", admin, userName, err)
+		return err
+	}
+	timeserData.logger().Infof("Sucessfully set admin %v for user %v\n", admin, userName)
+	return nil
+}
+
+// Lists the users of TimeSeriesDB, ListTokens() adds their privileges per DB
+func (timeserData *TimeSeriesClientData) ListUsers() (users []UserInfo, err error) {
+	response, err := timeserData.query(timesrclient.NewQuery("SHOW USERS", "", ""))
+	if err != nil {
+		timeserData.logger().Errorf("Failed to list users with error %v\n", err)
+		return nil, err
+	}
+	users = []UserInfo{}
+	for _, result := range response.Results {
+		for _, row := range result.Series {
+			// Columns are user and admin
+			for _, value := range row.Values {
+				if len(value) < 2 {
+					continue
+				}
+				admin, _ := value[1].(bool)
+				users = append(users, UserInfo{Name: fmt.Sprint(value[0]), Admin: admin})
+			}
+		}
+	}
+	return users, nil
+}
@@ -0,0 +1,72 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for the management of the users and the DBs of tenants
+func TestTimeSeriesDbUsers(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	for _, operation := range []func() error{
+		func() error { return timeserData.CreateUser("operator", "it's", true) },
+		func() error { return timeserData.CreateUser("kpimon", "secret", false) },
+		func() error { return timeserData.SetUserPassword("kpimon", "secret2") },
+		func() error { return timeserData.SetUserAdmin("kpimon", true) },
+		func() error { return timeserData.SetUserAdmin("kpimon", false) },
+		func() error { return timeserData.DeleteUser("kpimon") },
+		func() error { return timeserData.DeleteTimeSeriesDBNamed("tenant1") },
+	} {
+		if err = operation(); err != nil {
+			t.Errorf("Operation failed with error %v", err)
+		}
+	}
+	expected := []string{
+		`CREATE USER "operator" WITH PASSWORD 'it\'s' WITH ALL PRIVILEGES`,
+		`CREATE USER "kpimon" WITH PASSWORD 'secret'`,
+		`SET PASSWORD FOR "kpimon" = 'secret2'`,
+		`GRANT ALL PRIVILEGES TO "kpimon"`,
+		`REVOKE ALL PRIVILEGES FROM "kpimon"`,
+		`DROP USER "kpimon"`,
+		`DROP DATABASE "tenant1"`,
+	}
+	if strings.Join(issuedQueries, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected queries\n%v", strings.Join(issuedQueries, "\n"))
+	}
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+			{Columns: []string{"user", "admin"}, Values: [][]interface{}{{"operator", true}, {"kpimon", false}}},
+		}}}}, nil
+	}
+	users, err := timeserData.ListUsers()
+	if err != nil || fmt.Sprint(users) != "[{operator true} {kpimon false}]" {
+		t.Errorf("Unexpected users %v, error %v", users, err)
+	}
+}
@@ -0,0 +1,147 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Values kept for a TTL, least recently used dropped beyond size
+type lruCache struct {
+	lock    sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[string]*list.Element
+	order   *list.List // Most recently used first
+}
+
+type cachedValue struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+func newLRUCache(size int, ttl time.Duration) *lruCache {
+	return &lruCache{size: size, ttl: ttl, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+// Serves Get(), the typed getters and GetMulti() from a cache of up to size values, each kept for ttl, for
+// latest KPIs polled by control loops many times per second. The values of a measurement are invalidated by
+// the writes and deletes of the client, not by those of other processes, which are seen after at most ttl.
+// 0 size disables the cache. To be set before use
+func (timeserData *TimeSeriesClientData) SetValueCache(size int, ttl time.Duration) {
+	if size <= 0 || ttl <= 0 {
+		timeserData.valueCache = nil
+		return
+	}
+	timeserData.valueCache = newLRUCache(size, ttl)
+}
+
+// Returns the cached value of a key
+func (cache *lruCache) get(key string) (interface{}, bool) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	element, ok := cache.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cachedValue)
+	if time.Now().After(entry.expires) {
+		cache.remove(element)
+		return nil, false
+	}
+	cache.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Caches the value of a key
+func (cache *lruCache) put(key string, value interface{}) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	expires := time.Now().Add(cache.ttl)
+	if element, ok := cache.entries[key]; ok {
+		entry := element.Value.(*cachedValue)
+		entry.value, entry.expires = value, expires
+		cache.order.MoveToFront(element)
+		return
+	}
+	cache.entries[key] = cache.order.PushFront(&cachedValue{key: key, value: value, expires: expires})
+	for cache.order.Len() > cache.size {
+		cache.remove(cache.order.Back())
+	}
+}
+
+// Drops the values of the keys matching
+func (cache *lruCache) removeIf(match func(key string) bool) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	for key, element := range cache.entries {
+		if match(key) {
+			cache.remove(element)
+		}
+	}
+}
+
+// Drops an entry, called with the lock held
+func (cache *lruCache) remove(element *list.Element) {
+	cache.order.Remove(element)
+	delete(cache.entries, element.Value.(*cachedValue).key)
+}
+
+// Drops the cached values of the fields of the points
+func (timeserData *TimeSeriesClientData) invalidatePoints(points []*timesrclient.Point) {
+	cache := timeserData.valueCache
+	if cache == nil {
+		return
+	}
+	keys := make(map[string]bool)
+	for _, pt := range points {
+		fields, _ := pt.Fields()
+		for field := range fields {
+			keys[_cacheKey(pt.Name(), field)] = true
+		}
+	}
+	cache.removeIf(func(key string) bool { return keys[key] })
+}
+
+// Drops the cached values and query results of a measurement, all of them when empty
+func (timeserData *TimeSeriesClientData) invalidateCache(measurement string) {
+	if cache := timeserData.valueCache; cache != nil {
+		prefix := _cacheKey(timeserData.MeasurementName(measurement), "")
+		cache.removeIf(func(key string) bool { return measurement == "" || strings.HasPrefix(key, prefix) })
+	}
+	timeserData.clearQueryCache()
+}
+
+// Drops all the cached query results
+func (timeserData *TimeSeriesClientData) clearQueryCache() {
+	if cache := timeserData.queryCache; cache != nil {
+		cache.removeIf(func(key string) bool { return true })
+	}
+}
+
+func _cacheKey(measurement, key string) string {
+	return measurement + "\x00" + key
+}
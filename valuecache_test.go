@@ -0,0 +1,93 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for serving the latest values from the cache
+func TestTimeSeriesDbValueCache(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	timeserData.SetValueCache(2, 50*time.Millisecond)
+
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		if strings.HasPrefix(q.Command, "SELECT LAST") {
+			return seriesResp("KeyTable", []string{"time", "b", "c"}, []interface{}{"1970-01-01T00:00:00Z", "2", "3"}), nil
+		}
+		return seriesResp("KeyTable", []string{"time", "a"}, []interface{}{"2021-08-20T05:47:46Z", "1"}), nil
+	}
+	for i := 0; i < 3; i++ {
+		if value, err := timeserData.Get("KeyTable", "a"); err != nil || value != "1" {
+			t.Fatalf("Unexpected value %v with error %v", value, err)
+		}
+	}
+	if len(issuedQueries) != 1 {
+		t.Errorf("Expected a single query, got %v", issuedQueries)
+	}
+
+	// Only the keys not cached are queried
+	issuedQueries = nil
+	values, err := timeserData.GetMulti("KeyTable", []string{"a", "b", "c"})
+	if err != nil || len(values) != 3 || values["a"] != "1" || values["c"] != "3" {
+		t.Errorf("Unexpected values %v with error %v", values, err)
+	}
+	if len(issuedQueries) != 1 || issuedQueries[0] != `SELECT LAST("b") AS "b", LAST("c") AS "c" FROM "KeyTable"` {
+		t.Errorf("Unexpected queries %v", issuedQueries)
+	}
+
+	// a was evicted as least recently used
+	issuedQueries = nil
+	timeserData.Get("KeyTable", "c")
+	timeserData.Get("KeyTable", "a")
+	if len(issuedQueries) != 1 {
+		t.Errorf("Expected a query for the evicted key only, got %v", issuedQueries)
+	}
+
+	// Local writes invalidate
+	issuedQueries = nil
+	timeserData.WritePoint("KeyTable", nil, map[string]interface{}{"a": "5"})
+	timeserData.Get("KeyTable", "a")
+	timeserData.Get("KeyTable", "c")
+	if len(issuedQueries) != 1 {
+		t.Errorf("Expected a query for the written key only, got %v", issuedQueries)
+	}
+
+	// Deletes and expiry
+	issuedQueries = nil
+	timeserData.DropMeasurement("KeyTable")
+	timeserData.Get("KeyTable", "a")
+	if len(issuedQueries) != 2 {
+		t.Errorf("Expected a query after the delete, got %v", issuedQueries)
+	}
+	time.Sleep(60 * time.Millisecond)
+	timeserData.Get("KeyTable", "a")
+	if len(issuedQueries) != 3 {
+		t.Errorf("Expected a query after expiry, got %v", issuedQueries)
+	}
+}
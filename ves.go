@@ -0,0 +1,205 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+var ErrNotVesMeasurement = errors.New("Not a VES measurement event")
+
+// Header keys of a VES event stored as tags of its points
+var vesTagKeys = []string{"sourceName", "reportingEntityName", "eventName"}
+
+// Inserts ONAP/O-RAN VES 7.x measurement events, a single {"event": ...} or an {"eventList": [...]} batch, so that
+// O1 telemetry lands next to the E2 KPIs. The points are stamped with lastEpochMicrosec and tagged with the
+// sourceName, reportingEntityName and eventName of the header. Each additionalMeasurements group is a point of
+// the measurement named after the group, its hashMap values as fields, numbers parsed. Each element of an array
+// of measurementFields (eg. cpuUsageArray) is a point of the measurement named after the array without the Array
+// suffix (eg. cpuUsage), tagged with its identifier (eg. cpuIdentifier). The other numbers of measurementFields
+// and additionalFields are a point of the measurement measurementFields. Measurement names are prefixed with
+// prefix. Events of other domains are skipped, ErrNotVesMeasurement when none is a measurement event.
+// Returns the number of points written per measurement
+func (timeserData *TimeSeriesClientData) InsertVesEvent(prefix string, jsonBuffer []byte) (counts map[string]int, err error) {
+	var message struct {
+		Event     map[string]interface{}   `json:"event"`
+		EventList []map[string]interface{} `json:"eventList"`
+	}
+	if err = timeserData.unmarshalJson(jsonBuffer, &message); err != nil {
+		err = fmt.Errorf("Failed to parse VES event: %v", err)
+		timeserData.logger().Errorf("%v\n", err)
+		return nil, err
+	}
+	events := message.EventList
+	if message.Event != nil {
+		events = append(events, message.Event)
+	}
+
+	bp, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+		Database:  timeserData.timeSeriesDbName,
+		Precision: timeserData.writePrecision(),
+	})
+	counts = make(map[string]int)
+	measurementEvents := 0
+	for i, event := range events {
+		header, _ := event["commonEventHeader"].(map[string]interface{})
+		fields, _ := event["measurementFields"].(map[string]interface{})
+		if header["domain"] != "measurement" || fields == nil {
+			timeserData.logger().Warnf("Skipping VES event %v of domain %v\n", i, header["domain"])
+			continue
+		}
+		measurementEvents++
+		points, err := timeserData.vesPoints(prefix, header, fields)
+		if err != nil {
+			return nil, err
+		}
+		for _, pt := range points {
+			bp.AddPoint(pt)
+			counts[pt.Name()]++
+		}
+	}
+	if measurementEvents == 0 {
+		timeserData.logger().Errorf("Failed to insert VES events: %v\n", ErrNotVesMeasurement)
+		return nil, ErrNotVesMeasurement
+	}
+	if len(bp.Points()) > 0 {
+		if err = timeserData.write(bp); err != nil {
+			return nil, err
+		}
+	}
+	timeserData.logger().Debugf("TimeSeriesDB InsertVesEvent: DB=%v counts=%v\n", timeserData.timeSeriesDbName, counts)
+	return counts, nil
+}
+
+// Returns the points of the measurementFields of an event
+func (timeserData *TimeSeriesClientData) vesPoints(prefix string, header, measurementFields map[string]interface{}) ([]*timesrclient.Point, error) {
+	timestamp := time.Now()
+	if value, ok := header["lastEpochMicrosec"]; ok {
+		micros, err := _toFloat64(value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid VES lastEpochMicrosec %v: %v", value, err)
+		}
+		timestamp = time.Unix(0, int64(micros)*int64(time.Microsecond))
+	}
+	tags := make(map[string]string)
+	for _, key := range vesTagKeys {
+		if value, ok := header[key].(string); ok && value != "" {
+			tags[key] = value
+		}
+	}
+
+	points := []*timesrclient.Point{}
+	add := func(measurement string, tags map[string]string, fields map[string]interface{}) error {
+		fields = timeserData.jsonFields(measurement, fields)
+		if len(fields) == 0 {
+			return nil
+		}
+		pt, err := timeserData.jsonPoint(prefix+measurement, tags, fields, timestamp)
+		if err == nil {
+			points = append(points, pt)
+		}
+		return err
+	}
+
+	// Scalars of the measurementFields and additionalFields, in a point of their own
+	scalars := make(map[string]interface{})
+	keys := make([]string, 0, len(measurementFields))
+	for key := range measurementFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch value := measurementFields[key].(type) {
+		case string, bool:
+			// measurementFieldsVersion
+		case map[string]interface{}:
+			if key == "additionalFields" {
+				for name, value := range value {
+					scalars[name] = _vesValue(value)
+				}
+			}
+		case []interface{}:
+			if key == "additionalMeasurements" {
+				for _, group := range value {
+					group, _ := group.(map[string]interface{})
+					name, _ := group["name"].(string)
+					hashMap, _ := group["hashMap"].(map[string]interface{})
+					if name == "" || hashMap == nil {
+						continue
+					}
+					fields := make(map[string]interface{}, len(hashMap))
+					for k, v := range hashMap {
+						fields[k] = _vesValue(v)
+					}
+					if err := add(name, tags, fields); err != nil {
+						return nil, err
+					}
+				}
+				continue
+			}
+			for _, element := range value {
+				element, ok := element.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				elementTags := make(map[string]string, len(tags)+1)
+				for k, v := range tags {
+					elementTags[k] = v
+				}
+				fields := make(map[string]interface{}, len(element))
+				for k, v := range element {
+					if s, ok := v.(string); ok && strings.HasSuffix(k, "Identifier") {
+						elementTags[k] = s
+					} else if _, nested := v.(map[string]interface{}); !nested {
+						fields[k] = v
+					}
+				}
+				if err := add(strings.TrimSuffix(key, "Array"), elementTags, fields); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			scalars[key] = value
+		}
+	}
+	if err := add("measurementFields", tags, scalars); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// Converts a VES hashMap value, a string, to a number when it holds one
+func _vesValue(value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
@@ -0,0 +1,80 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"stslgo"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for inserting VES measurement events
+func TestTimeSeriesDbInsertVesEvent(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	event := `{"eventList": [
+		{"commonEventHeader": {"domain": "measurement", "eventName": "Measurement_O-DU", "sourceName": "odu-1",
+			"reportingEntityName": "odu-1", "startEpochMicrosec": 1629438400000000, "lastEpochMicrosec": 1629438466000000,
+			"version": "4.1", "vesEventListenerVersion": "7.2.1"},
+		 "measurementFields": {"measurementFieldsVersion": "4.0", "measurementInterval": 60,
+			"additionalFields": {"prbUsageDl": "42.5"},
+			"additionalMeasurements": [{"name": "cellKpi", "hashMap": {"ueCount": "12", "state": "active"}}],
+			"cpuUsageArray": [{"cpuIdentifier": "cpu0", "percentUsage": 10.5}, {"cpuIdentifier": "cpu1", "percentUsage": 20}]}},
+		{"commonEventHeader": {"domain": "fault", "sourceName": "odu-1"}, "faultFields": {}}
+	]}`
+	counts, err := timeserData.InsertVesEvent("ves_", []byte(event))
+	if err != nil {
+		t.Fatalf("Unable to insert VES event with error %v", err)
+	}
+	if len(counts) != 3 || counts["ves_cellKpi"] != 1 || counts["ves_cpuUsage"] != 2 || counts["ves_measurementFields"] != 1 {
+		t.Errorf("Unexpected counts %v", counts)
+	}
+
+	points := map[string]*timesrclient.Point{}
+	for _, pt := range writtenPoints {
+		points[pt.Name()+pt.Tags()["cpuIdentifier"]] = pt
+	}
+	cell := points["ves_cellKpi"]
+	if cell == nil || !cell.Time().Equal(time.Unix(1629438466, 0)) || cell.Tags()["sourceName"] != "odu-1" || cell.Tags()["eventName"] != "Measurement_O-DU" {
+		t.Fatalf("Unexpected cellKpi point %v", cell)
+	}
+	if fields, _ := cell.Fields(); fields["ueCount"] != 12.0 || fields["state"] != "active" {
+		t.Errorf("Unexpected cellKpi fields %v", fields)
+	}
+	if fields, _ := points["ves_cpuUsagecpu1"].Fields(); len(fields) != 1 || fields["percentUsage"] != 20.0 {
+		t.Errorf("Unexpected cpuUsage fields %v", fields)
+	}
+	if fields, _ := points["ves_measurementFields"].Fields(); len(fields) != 2 || fields["measurementInterval"] != 60.0 || fields["prbUsageDl"] != 42.5 {
+		t.Errorf("Unexpected measurementFields fields %v", fields)
+	}
+
+	if _, err = timeserData.InsertVesEvent("", []byte(`{"event": {"commonEventHeader": {"domain": "fault"}}}`)); err != stslgo.ErrNotVesMeasurement {
+		t.Errorf("Expected ErrNotVesMeasurement, got %v", err)
+	}
+	if _, err = timeserData.InsertVesEvent("", []byte(`{"event":`)); err == nil {
+		t.Errorf("Expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,95 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrNotReady = errors.New("TimeSeriesDB not ready")
+
+// Progress of WaitForTimeSeriesDB, one event per health check
+type WaitEvent struct {
+	Attempt   int           // Number of the health check, from 1
+	Elapsed   time.Duration // Time since the start of the wait
+	Err       error         // Failure of the health check, nil once TimeSeriesDB is ready
+	Version   string        // Version reported by TimeSeriesDB once ready
+	NextRetry time.Duration // Delay before the next health check, 0 once ready
+}
+
+// Waits until TimeSeriesDB answers its health check, for xApps starting along with it. The check is retried with
+// the backoff of the reconnect policy (DefaultReconnectPolicy when it has none) until timeout (no limit if 0) or
+// the end of ctx, which fail with ErrNotReady and the last failure as cause. progress, if not nil, receives an
+// event per check. Works before CreateTimeSeriesConnection() as well
+func (timeserData *TimeSeriesClientData) WaitForTimeSeriesDB(ctx context.Context, timeout time.Duration, progress func(WaitEvent)) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	client := timeserData.Iclient
+	if client == nil {
+		var err error
+		if client, err = timeserData.connector("")(); err != nil {
+			return err
+		}
+		defer client.Close()
+	}
+	policy := timeserData.reconnectPolicy
+	if policy.MinBackoff <= 0 || policy.MaxBackoff <= 0 {
+		policy = DefaultReconnectPolicy
+	}
+
+	start := time.Now()
+	backoff := policy.MinBackoff
+	for attempt := 1; ; attempt++ {
+		pingTimeout := preflightPingTimeout
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < pingTimeout {
+			pingTimeout = time.Until(deadline)
+		}
+		_, version, err := _ping(client, pingTimeout)
+		event := WaitEvent{Attempt: attempt, Elapsed: time.Since(start), Err: err, Version: version}
+		if err == nil {
+			timeserData.logger().Infof("TimeSeriesDB ready after %v attempts, version %v\n", attempt, version)
+			if progress != nil {
+				progress(event)
+			}
+			return nil
+		}
+
+		event.NextRetry = backoff
+		timeserData.logger().Warnf("TimeSeriesDB not ready, attempt %v failed: %v, retrying in %v\n", attempt, err, backoff)
+		if progress != nil {
+			progress(event)
+		}
+		select {
+		case <-ctx.Done():
+			timeserData.logger().Errorf("TimeSeriesDB not ready after %v attempts: %v\n", attempt, err)
+			return &kindError{kind: ErrNotReady, cause: err}
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,78 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stslgo"
+)
+
+// Test function for waiting until TimeSeriesDB is ready
+func TestTimeSeriesDbWaitForReady(t *testing.T) {
+	var pings, failures int32 = 0, 2
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&pings, 1) <= atomic.LoadInt32(&failures) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("X-Influxdb-Version", "1.8.10")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(serverURL.Host)
+	os.Setenv("TIMESERIESDB_SERVICE_HOST", host)
+	os.Setenv("TIMESERIESDB_SERVICE_PORT_HTTP", port)
+	defer os.Unsetenv("TIMESERIESDB_SERVICE_HOST")
+	defer os.Unsetenv("TIMESERIESDB_SERVICE_PORT_HTTP")
+
+	timeserData := stslgo.NewTimeSeriesClientData("testdb", "", "")
+	timeserData.SetReconnectPolicy(stslgo.ReconnectPolicy{MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+	var events []stslgo.WaitEvent
+	err := timeserData.WaitForTimeSeriesDB(context.Background(), 5*time.Second, func(event stslgo.WaitEvent) {
+		events = append(events, event)
+	})
+	if err != nil || len(events) != 3 {
+		t.Fatalf("Unexpected events %v, error %v", events, err)
+	}
+	if events[0].Err == nil || events[0].NextRetry != time.Millisecond || events[1].NextRetry != 2*time.Millisecond {
+		t.Errorf("Unexpected failed checks %+v %+v", events[0], events[1])
+	}
+	if last := events[2]; last.Attempt != 3 || last.Err != nil || last.Version != "1.8.10" || last.NextRetry != 0 {
+		t.Errorf("Unexpected ready event %+v", last)
+	}
+
+	// Deadline
+	atomic.StoreInt32(&failures, 1<<30)
+	start := time.Now()
+	err = timeserData.WaitForTimeSeriesDB(context.Background(), 50*time.Millisecond, nil)
+	if !stslgo.IsKind(err, stslgo.ErrNotReady) || time.Since(start) > 2*time.Second {
+		t.Errorf("Expected ErrNotReady at the deadline, got %v after %v", err, time.Since(start))
+	}
+}
@@ -0,0 +1,134 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Value of a watched field delivered by a Watcher, with the tags of its series
+type WatchedPoint struct {
+	Time  time.Time
+	Tags  map[string]string
+	Value interface{}
+}
+
+// Polls a field of a measurement and delivers the new points on C, until stopped
+type Watcher struct {
+	C    <-chan WatchedPoint
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Starts polling a field of a measurement every interval for points newer than the last one seen, delivering
+// them in chronological order on the channel of the returned Watcher. Only points written after the call are
+// delivered, points written later with an older timestamp than the last seen one are missed. Failed polls are
+// logged and retried on the next interval. The Watcher must be stopped with Stop()
+func (timeserData *TimeSeriesClientData) Watch(measurement, field string, interval time.Duration) (*Watcher, error) {
+	if interval <= 0 {
+		return nil, errors.New("Watch interval must be positive")
+	}
+	if timeserData.Iclient == nil {
+		return nil, ErrNotConnected
+	}
+
+	c := make(chan WatchedPoint)
+	watcher := &Watcher{C: c, stop: make(chan struct{}), done: make(chan struct{})}
+	timeserData.track(watcher, false, func() error {
+		watcher.Stop()
+		return nil
+	})
+	go timeserData.watch(watcher, c, measurement, field, interval, time.Now())
+	timeserData.logger().Infof("Watching %v of measurement %v every %v\n", field, measurement, interval)
+	return watcher, nil
+}
+
+// Stops polling and closes the channel of the Watcher
+func (watcher *Watcher) Stop() {
+	select {
+	case <-watcher.stop:
+	default:
+		close(watcher.stop)
+	}
+	<-watcher.done
+}
+
+func (timeserData *TimeSeriesClientData) watch(watcher *Watcher, c chan<- WatchedPoint, measurement, field string, interval time.Duration, since time.Time) {
+	defer close(watcher.done)
+	defer close(c)
+	defer timeserData.untrack(watcher)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-watcher.stop:
+			return
+		case <-ticker.C:
+		}
+
+		points, err := timeserData.pollWatched(measurement, field, since)
+		if err != nil {
+			timeserData.logger().Warnf("Failed to poll %v of measurement %v with error %v\n", field, measurement, err)
+			continue
+		}
+		for _, point := range points {
+			select {
+			case c <- point:
+				since = point.Time
+			case <-watcher.stop:
+				return
+			}
+		}
+	}
+}
+
+// Queries the points of a field newer than since, in chronological order
+func (timeserData *TimeSeriesClientData) pollWatched(measurement, field string, since time.Time) ([]WatchedPoint, error) {
+	queryStr := fmt.Sprintf("SELECT %v FROM %v WHERE time > %v GROUP BY *", _quoteIdent(field), timeserData.measurementIdent(measurement),
+		_quoteLiteral(since.UTC().Format(time.RFC3339Nano)))
+	q := timesrclient.NewQuery(queryStr, timeserData.timeSeriesDbName, "")
+	response, err := timeserData.query(q)
+	if err != nil {
+		return nil, err
+	}
+
+	points := []WatchedPoint{}
+	for _, result := range response.Results {
+		for _, series := range result.Series {
+			values, err := _timedValues(series.Values)
+			if err != nil {
+				return nil, err
+			}
+			for _, value := range values {
+				if value.Value != nil {
+					points = append(points, WatchedPoint{Time: value.Time, Tags: series.Tags, Value: value.Value})
+				}
+			}
+		}
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	return points, nil
+}
@@ -0,0 +1,94 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb1-client/models"
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Test function for watching a field for new points
+func TestTimeSeriesDbWatch(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	var lock sync.Mutex
+	var queries []string
+	queryResp = func(q timesrclient.Query) (*timesrclient.Response, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		queries = append(queries, q.Command)
+		switch len(queries) {
+		case 1:
+			return nil, errors.New("timeout")
+		case 2:
+			return &timesrclient.Response{Results: []timesrclient.Result{{Series: []models.Row{
+				{Name: "CellKpi", Tags: map[string]string{"cellId": "c1"}, Columns: []string{"time", "prb"},
+					Values: [][]interface{}{{"2099-08-20T05:47:47Z", json.Number("4")}}},
+				{Name: "CellKpi", Tags: map[string]string{"cellId": "c2"}, Columns: []string{"time", "prb"},
+					Values: [][]interface{}{{"2099-08-20T05:47:46Z", json.Number("3")}, {"2099-08-20T05:47:48Z", nil}}},
+			}}}}, nil
+		}
+		return &timesrclient.Response{Results: []timesrclient.Result{{}}}, nil
+	}
+
+	if _, err = timeserData.Watch("CellKpi", "prb", 0); err == nil {
+		t.Errorf("Expected error for zero interval")
+	}
+	watcher, err := timeserData.Watch("CellKpi", "prb", 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Unable to watch with error %v", err)
+	}
+
+	for i, expected := range []string{"c2", "c1"} {
+		select {
+		case point := <-watcher.C:
+			if point.Tags["cellId"] != expected || point.Value != json.Number(fmt.Sprint(3+i)) {
+				t.Errorf("Unexpected point %v", point)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("No point delivered")
+		}
+	}
+	time.Sleep(30 * time.Millisecond)
+	watcher.Stop()
+	if _, ok := <-watcher.C; ok {
+		t.Errorf("Expected channel to be closed after Stop")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if !strings.HasPrefix(queries[0], `SELECT "prb" FROM "CellKpi" WHERE time > '`) || !strings.HasSuffix(queries[0], "' GROUP BY *") {
+		t.Errorf("Unexpected query %v", queries[0])
+	}
+	if len(queries) < 3 || !strings.Contains(queries[2], "time > '2099-08-20T05:47:47Z'") {
+		t.Errorf("Expected polling to continue after the last delivered point, got %v", queries)
+	}
+}
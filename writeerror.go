@@ -0,0 +1,122 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Behavior for the errors of writes which have no caller to return them to, eg. the batches a BatchWriter drops
+type WriteErrorMode int
+
+const (
+	WriteErrorLog     WriteErrorMode = iota // Log the error (default)
+	WriteErrorHandler                       // Invoke the handler set with SetWriteErrorMode()
+	WriteErrorCounter                       // Only count the error, see WriteErrorCount()
+)
+
+// Size of the queue of write errors waiting to be drained, further errors are only counted
+const writeErrorQueueSize = 64
+
+// Drains the write errors in a single goroutine per client, started on first error and stopped by Close()
+type writeErrorDrainer struct {
+	lock    sync.Mutex
+	mode    WriteErrorMode
+	handler func(error)
+	errs    chan error
+	done    chan struct{}
+	stopped bool
+	logger  Logger
+	count   int64 // Number of write errors, accessed atomically
+}
+
+// Sets how the errors of writes without caller (eg. of the BatchWriter) are handled, handler is used only with
+// WriteErrorHandler
+func (timeserData *TimeSeriesClientData) SetWriteErrorMode(mode WriteErrorMode, handler func(error)) {
+	d := &timeserData.writeErrors
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.mode = mode
+	d.handler = handler
+}
+
+// Returns the number of write errors reported so far
+func (timeserData *TimeSeriesClientData) WriteErrorCount() int64 {
+	return atomic.LoadInt64(&timeserData.writeErrors.count)
+}
+
+// Queues a write error to the draining goroutine, starting it if needed
+func (timeserData *TimeSeriesClientData) reportWriteError(err error) {
+	d := &timeserData.writeErrors
+	atomic.AddInt64(&d.count, 1)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.stopped {
+		timeserData.logger().Errorf("TimeSeriesDB write failed after Close: %v\n", err)
+		return
+	}
+	if d.errs == nil {
+		d.logger = timeserData.logger()
+		d.errs = make(chan error, writeErrorQueueSize)
+		d.done = make(chan struct{})
+		go d.drain()
+	}
+	select {
+	case d.errs <- err:
+	default:
+		timeserData.logger().Warnf("TimeSeriesDB write error queue full, dropping: %v\n", err)
+	}
+}
+
+func (d *writeErrorDrainer) drain() {
+	defer close(d.done)
+	for err := range d.errs {
+		d.lock.Lock()
+		mode, handler := d.mode, d.handler
+		d.lock.Unlock()
+
+		switch {
+		case mode == WriteErrorHandler && handler != nil:
+			handler(err)
+		case mode == WriteErrorCounter:
+		default:
+			d.logger.Errorf("TimeSeriesDB write failed: %v\n", err)
+		}
+	}
+}
+
+// Stops the draining goroutine once the queued errors are handled
+func (d *writeErrorDrainer) stop() {
+	d.lock.Lock()
+	if d.stopped {
+		d.lock.Unlock()
+		return
+	}
+	d.stopped = true
+	errs, done := d.errs, d.done
+	d.lock.Unlock()
+
+	if errs != nil {
+		close(errs)
+		<-done
+	}
+}
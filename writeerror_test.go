@@ -0,0 +1,86 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"errors"
+	"fmt"
+	"stslgo"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test function for passing write errors to a handler and stopping on Close
+func TestTimeSeriesDbWriteErrorHandler(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	var lock sync.Mutex
+	var handled []error
+	timeserData.SetWriteErrorMode(stslgo.WriteErrorHandler, func(err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		handled = append(handled, err)
+	})
+
+	// The errors of WritePoint are returned to the caller, those without caller are passed to the handler
+	writeErr = errors.New("partial write: field type conflict")
+	if err = timeserData.WritePoint("WriteErrorTable", nil, map[string]interface{}{"prb": 0}); !stslgo.IsKind(err, stslgo.ErrWriteFailed) {
+		t.Errorf("Expected ErrWriteFailed returned, got %v", err)
+	}
+	if err = timeserData.Set("WriteErrorTable", "state", []byte("up")); !stslgo.IsKind(err, stslgo.ErrWriteFailed) {
+		t.Errorf("Expected ErrWriteFailed returned by Set, got %v", err)
+	}
+	bw := timeserData.NewBatchWriter(stslgo.BatchWriterConfig{BatchSize: 1})
+	for i := 0; i < 3; i++ {
+		_ = bw.WritePoint("WriteErrorTable", nil, map[string]interface{}{"prb": i})
+	}
+	if timeserData.WriteErrorCount() != 3 {
+		t.Errorf("Expected 3 write errors counted, got %v", timeserData.WriteErrorCount())
+	}
+
+	// Close returns once the queued errors are handled and the goroutine has exited
+	closed := make(chan struct{})
+	go func() {
+		_ = timeserData.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Close did not stop the write error goroutine")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(handled) != 3 || !stslgo.IsKind(handled[0], stslgo.ErrWriteFailed) || stslgo.ErrorCause(handled[0]) != writeErr {
+		t.Errorf("Expected handler invoked 3 times with the write error, got %v", handled)
+	}
+
+	// Errors after Close are no longer passed to the handler
+	bw = timeserData.NewBatchWriter(stslgo.BatchWriterConfig{BatchSize: 1})
+	_ = bw.WritePoint("WriteErrorTable", nil, map[string]interface{}{"prb": 4})
+	if len(handled) != 3 {
+		t.Errorf("Handler invoked after Close")
+	}
+}
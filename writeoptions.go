@@ -0,0 +1,78 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"context"
+	"net"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Wait before each retry of a failed write when SetWriteRetry() is given no positive interval
+var DefaultWriteRetryInterval = time.Second
+
+// Compresses the bodies of the writes with gzip, which reduces high volume KPM writes several times over at some
+// CPU cost. Taken into account by the next CreateTimeSeriesConnection()
+func (timeserData *TimeSeriesClientData) SetWriteGzip(enabled bool) {
+	timeserData.writeGzip = enabled
+}
+
+// Retries the writes failing on a network error (eg. timeout or connection refused) up to maxRetries times,
+// interval apart, before failing them or keeping them in the spill file. 0 retries disables it, a non-positive
+// interval is replaced by DefaultWriteRetryInterval
+func (timeserData *TimeSeriesClientData) SetWriteRetry(maxRetries int, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultWriteRetryInterval
+	}
+	timeserData.writeMaxRetries = maxRetries
+	timeserData.writeRetryInterval = interval
+}
+
+// Writes a batch with the client, retried as per SetWriteRetry()
+func (timeserData *TimeSeriesClientData) clientWrite(ctx context.Context, bp timesrclient.BatchPoints) error {
+	err := timeserData.circuitWrite(bp)
+	for retry := 1; retry <= timeserData.writeMaxRetries; retry++ {
+		if _, transient := err.(net.Error); !transient {
+			return err
+		}
+		timeserData.logger().Warnf("TimeSeriesDB write of %v points failed: %v, retry %v in %v\n", len(bp.Points()), err, retry, timeserData.writeRetryInterval)
+		timer := time.NewTimer(timeserData.writeRetryInterval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+		err = timeserData.circuitWrite(bp)
+	}
+	return err
+}
+
+// Writes a batch with the client unless the circuit breaker is open
+func (timeserData *TimeSeriesClientData) circuitWrite(bp timesrclient.BatchPoints) error {
+	if err := timeserData.circuitAllow(timeserData.breaker); err != nil {
+		return err
+	}
+	err := timeserData.Iclient.Write(bp)
+	timeserData.circuitRecord(timeserData.breaker, err)
+	return err
+}
@@ -0,0 +1,141 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"stslgo"
+	"testing"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Mock client failing the first writes with a network error
+type failingWriteClient struct {
+	MockClient
+	failures int
+}
+
+func (c *failingWriteClient) Write(bp timesrclient.BatchPoints) error {
+	if c.failures > 0 {
+		c.failures--
+		return &net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("connection refused")}
+	}
+	return c.MockClient.Write(bp)
+}
+
+// Test function for compressing the writes with gzip
+func TestTimeSeriesDbWriteGzip(t *testing.T) {
+	written := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/write" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		body := "not compressed"
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			reader, _ := gzip.NewReader(r.Body)
+			content, _ := ioutil.ReadAll(reader)
+			body = string(content)
+		}
+		written <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(serverURL.Host)
+
+	noReconnect := stslgo.ReconnectPolicy{}
+	timeserData, err := stslgo.NewTimeSeriesClientWithOptions(stslgo.Options{
+		Host: host, Port: port, DbName: "testdb", UseGzip: true, Reconnect: &noReconnect,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = timeserData.CreateTimeSeriesConnection(); err != nil {
+		t.Fatalf("Unable to connect with error %v", err)
+	}
+	defer timeserData.Close()
+
+	at := time.Unix(1629438466, 0)
+	if err = timeserData.WritePointAt("CellKpi", nil, map[string]interface{}{"prb": 1}, at); err != nil {
+		t.Fatalf("Unable to write point with error %v", err)
+	}
+	if body := <-written; body != "CellKpi prb=1i 1629438466000000000\n" {
+		t.Errorf("Unexpected write body %q", body)
+	}
+}
+
+// Test function for retrying the writes failing on network errors
+func TestTimeSeriesDbWriteRetry(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+	client := &failingWriteClient{failures: 2}
+	timeserData.Iclient = client
+	timeserData.SetWriteRetry(2, time.Millisecond)
+
+	if err = timeserData.WritePoints("CellKpi", []stslgo.Point{{Fields: map[string]interface{}{"prb": 1}}}); err != nil {
+		t.Fatalf("Expected the write to succeed on retry, got %v", err)
+	}
+	if len(writtenPoints) != 1 {
+		t.Errorf("Expected 1 point written, got %v", len(writtenPoints))
+	}
+
+	client.failures = 3
+	if err = timeserData.WritePoints("CellKpi", []stslgo.Point{{Fields: map[string]interface{}{"prb": 2}}}); err == nil {
+		t.Errorf("Expected the write to fail after the retries")
+	}
+	if client.failures != 0 {
+		t.Errorf("Expected 3 attempts, %v failures left", client.failures)
+	}
+
+	// Errors of TimeSeriesDB are not retried
+	client.failures = 0
+	writeErr = fmt.Errorf("field type conflict")
+	calls := writeCalls
+	timeserData.WritePoints("CellKpi", []stslgo.Point{{Fields: map[string]interface{}{"prb": 3}}})
+	if writeCalls != calls+1 {
+		t.Errorf("Expected a single attempt, got %v", writeCalls-calls)
+	}
+
+	// A non-positive interval does not retry in a busy loop
+	writeErr = nil
+	client.failures = 1
+	defaultInterval := stslgo.DefaultWriteRetryInterval
+	stslgo.DefaultWriteRetryInterval = 20 * time.Millisecond
+	defer func() { stslgo.DefaultWriteRetryInterval = defaultInterval }()
+	timeserData.SetWriteRetry(1, -time.Second)
+	start := time.Now()
+	if err = timeserData.WritePoints("CellKpi", []stslgo.Point{{Fields: map[string]interface{}{"prb": 4}}}); err != nil {
+		t.Fatalf("Expected the write to succeed on retry, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < stslgo.DefaultWriteRetryInterval {
+		t.Errorf("Expected the retry to wait %v, waited %v", stslgo.DefaultWriteRetryInterval, elapsed)
+	}
+}
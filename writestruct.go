@@ -0,0 +1,175 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	timesrclient "github.com/influxdata/influxdb1-client/v2"
+)
+
+// Struct tag key used by WriteStruct, eg. `ts:"cellId,tag"`, `ts:"prbUsage,field"`, `ts:"time"` or `ts:"-"`
+const structTagKey = "ts"
+
+var ErrNotStruct = errors.New("Value is not a struct, a pointer to struct or a slice of them")
+
+// Writes a struct, or a slice of structs as one batch, to the measurement. The struct fields are mapped with
+// the ts struct tag: `ts:"name,tag"` or `ts:"name,field"` (name defaults to the Go field name), `ts:"time"`
+// for the time.Time timestamp of the point (current time if zero or absent). Fields without ts tag are ignored
+func (timeserData *TimeSeriesClientData) WriteStruct(measurement string, v interface{}) (err error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	bp, _ := timesrclient.NewBatchPoints(timesrclient.BatchPointsConfig{
+		Database:  (*timeserData).timeSeriesDbName,
+		Precision: timeserData.writePrecision(),
+	})
+	addPoint := func(item reflect.Value) error {
+		tags, fields, timestamp, err := _structPoint(item)
+		if err != nil {
+			return err
+		}
+		fields, err = timeserData.finiteFields(measurement, fields)
+		if err == nil {
+			fields, err = timeserData.schemaFields(measurement, tags, fields)
+		}
+		if err != nil {
+			return err
+		}
+		pt, err := timesrclient.NewPoint(measurement, tags, fields, timestamp)
+		if err != nil {
+			return err
+		}
+		bp.AddPoint(pt)
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		err = addPoint(value)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len() && err == nil; i++ {
+			err = addPoint(value.Index(i))
+		}
+	default:
+		err = ErrNotStruct
+	}
+	if err != nil {
+		timeserData.logger().Errorf("Failed to write struct to measurement %v with error %v\n", measurement, err)
+		return err
+	}
+	if len(bp.Points()) == 0 {
+		return nil
+	}
+	err = timeserData.write(bp)
+	timeserData.logger().Debugf("TimeSeriesDB WriteStruct: DB=%v Measurement=%v points=%v, err=%v\n", timeserData.timeSeriesDbName, measurement, len(bp.Points()), err)
+	return err
+}
+
+// Maps the ts tagged fields of a struct to the tags, fields and timestamp of a point
+func _structPoint(value reflect.Value) (tags map[string]string, fields map[string]interface{}, timestamp time.Time, err error) {
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, nil, timestamp, ErrNotStruct
+	}
+
+	tags = make(map[string]string)
+	fields = make(map[string]interface{})
+	timestamp = time.Now()
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		sf := structType.Field(i)
+		spec, ok := sf.Tag.Lookup(structTagKey)
+		if !ok || spec == "-" || sf.PkgPath != "" {
+			continue
+		}
+		name, kind := sf.Name, "field"
+		if parts := strings.SplitN(spec, ",", 2); len(parts) == 2 {
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			kind = parts[1]
+		} else {
+			kind = parts[0]
+		}
+
+		fv := value.Field(i)
+		for fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				break
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Ptr {
+			continue // nil pointer, nothing to write
+		}
+
+		switch kind {
+		case "time":
+			t, ok := fv.Interface().(time.Time)
+			if !ok {
+				return nil, nil, timestamp, fmt.Errorf("Struct field %v tagged time is not a time.Time", sf.Name)
+			}
+			if !t.IsZero() {
+				timestamp = t
+			}
+		case "tag":
+			tags[name] = _tagValue(fv.Interface())
+		case "field":
+			fieldValue, err := _fieldValue(fv)
+			if err != nil {
+				return nil, nil, timestamp, fmt.Errorf("Struct field %v: %v", sf.Name, err)
+			}
+			fields[name] = fieldValue
+		default:
+			return nil, nil, timestamp, fmt.Errorf("Struct field %v has unknown ts tag kind %q", sf.Name, kind)
+		}
+	}
+	return tags, fields, timestamp, nil
+}
+
+// Converts a struct field to a value supported as TimeSeriesDB field
+func _fieldValue(fv reflect.Value) (interface{}, error) {
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if fv.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("Unsigned value %v overflows int64", fv.Uint())
+		}
+		return int64(fv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), nil
+	case reflect.String:
+		return fv.String(), nil
+	case reflect.Bool:
+		return fv.Bool(), nil
+	}
+	return nil, fmt.Errorf("Unsupported field type %v", fv.Type())
+}
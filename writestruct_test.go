@@ -0,0 +1,88 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"fmt"
+	"math"
+	"stslgo"
+	"testing"
+	"time"
+)
+
+type cellKpi struct {
+	CellId    string    `ts:"cellId,tag"`
+	Plmn      int       `ts:",tag"`
+	PrbUsage  float64   `ts:"prbUsage,field"`
+	ActiveUes uint32    `ts:"field"`
+	Degraded  *bool     `ts:"degraded,field"`
+	Collected time.Time `ts:"time"`
+	Comment   string
+	Ignored   int `ts:"-"`
+}
+
+// Test function for writing structs mapped with ts struct tags
+func TestTimeSeriesDbWriteStruct(t *testing.T) {
+	timeserData, err := setup()
+	if err != nil {
+		fmt.Println("Error in setup", err)
+	}
+
+	collected := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	kpi := cellKpi{CellId: "555001", Plmn: 310680, PrbUsage: 42.5, ActiveUes: 12, Collected: collected, Comment: "x", Ignored: 1}
+	if err = timeserData.WriteStruct("KpiTable", &kpi); err != nil {
+		t.Fatalf("Unable to write struct with error %v", err)
+	}
+	pt := writtenPoints[0]
+	fields, _ := pt.Fields()
+	if pt.Tags()["cellId"] != "555001" || pt.Tags()["Plmn"] != "310680" || len(pt.Tags()) != 2 {
+		t.Errorf("Unexpected tags %v", pt.Tags())
+	}
+	if fields["prbUsage"] != 42.5 || fields["ActiveUes"] != int64(12) || len(fields) != 2 {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+	if !pt.Time().Equal(collected) {
+		t.Errorf("Expected time %v, got %v", collected, pt.Time())
+	}
+
+	degraded := true
+	kpis := []cellKpi{{CellId: "1", PrbUsage: 1, Degraded: &degraded}, {CellId: "2", PrbUsage: 2}}
+	if err = timeserData.WriteStruct("KpiTable", kpis); err != nil {
+		t.Fatalf("Unable to write struct slice with error %v", err)
+	}
+	if len(writtenPoints) != 3 {
+		t.Fatalf("Expected 3 points, got %v", len(writtenPoints))
+	}
+	fields, _ = writtenPoints[1].Fields()
+	if fields["degraded"] != true {
+		t.Errorf("Expected pointer field written, got %v", fields)
+	}
+
+	overflow := struct {
+		Bytes uint64 `ts:"field"`
+	}{Bytes: math.MaxUint64}
+	if err = timeserData.WriteStruct("KpiTable", overflow); err == nil {
+		t.Errorf("Expected an error for a uint64 field overflowing int64")
+	}
+
+	if err = timeserData.WriteStruct("KpiTable", 5); err != stslgo.ErrNotStruct {
+		t.Errorf("Expected ErrNotStruct, got %v", err)
+	}
+}
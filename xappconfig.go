@@ -0,0 +1,308 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Path of the xApp configuration file when XAPP_DESCRIPTOR_PATH is not set, as mounted by the xApp onboarder
+const DefaultXappConfigPath = "/opt/ric/config/config-file.json"
+
+// Default interval of the checks for a changed xApp configuration file
+const DefaultXappConfigInterval = 10 * time.Second
+
+var ErrNoXappConfig = errors.New("No controls.timeSeriesDB section in the xApp configuration")
+
+// Configuration of a client in the "controls" of the xApp configuration file (config-file.json), under
+// "timeSeriesDB". Durations are Go durations (eg. "10s"), empty values fall back to the defaults of Options
+type XappConfig struct {
+	Host              string `json:"host"`
+	Port              string `json:"port"`
+	ReadHost          string `json:"readHost"`
+	ReadPort          string `json:"readPort"`
+	DbName            string `json:"dbName"`
+	UserName          string `json:"userName"`
+	Password          string `json:"password"`
+	TokenFile         string `json:"tokenFile"`
+	RetentionPolicy   string `json:"retentionPolicy"`   // Default retention policy of the DB, updated on reload
+	RetentionDuration string `json:"retentionDuration"` // InfluxQL duration of RetentionPolicy, eg. 7d
+	Timeout           string `json:"timeout"`
+	BatchSize         int    `json:"batchSize"`
+	Precision         string `json:"precision"`
+	UseGzip           bool   `json:"useGzip"`
+	MaxRetries        int    `json:"maxRetries"`
+	RetryInterval     string `json:"retryInterval"`
+	LogLevel          string `json:"logLevel"`
+	Namespace         string `json:"namespace"` // Prefix of the measurements, see SetNamespace()
+	XappName          string `json:"-"`         // Name of the xApp, from the top level of the file
+	XappVersion       string `json:"-"`         // Version of the xApp, from the top level of the file
+}
+
+// xApp configuration file of a client and its reloading
+type xappConfigWatcher struct {
+	lock    sync.Mutex // Protects config and content, serializing the reloads
+	path    string
+	config  XappConfig
+	content []byte
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// Returns the path of the xApp configuration file: config-file.json in XAPP_DESCRIPTOR_PATH, which may also name
+// the file itself, or DefaultXappConfigPath
+func XappConfigPath() string {
+	path := os.Getenv("XAPP_DESCRIPTOR_PATH")
+	if path == "" {
+		return DefaultXappConfigPath
+	}
+	if strings.HasSuffix(path, ".json") {
+		return path
+	}
+	return filepath.Join(path, "config-file.json")
+}
+
+// Reads the client configuration of an xApp configuration file, XappConfigPath() when path is empty
+func ReadXappConfig(path string) (XappConfig, error) {
+	if path == "" {
+		path = XappConfigPath()
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return XappConfig{}, err
+	}
+	return _parseXappConfig(content)
+}
+
+// Returns the Options of a client with the configuration
+func (config XappConfig) Options() (opts Options, err error) {
+	opts = Options{
+		Host:        config.Host,
+		Port:        config.Port,
+		ReadHost:    config.ReadHost,
+		ReadPort:    config.ReadPort,
+		DbName:      config.DbName,
+		UserName:    config.UserName,
+		Password:    config.Password,
+		TokenFile:   config.TokenFile,
+		BatchSize:   config.BatchSize,
+		Precision:   config.Precision,
+		UseGzip:     config.UseGzip,
+		MaxRetries:  config.MaxRetries,
+		LogLevel:    config.LogLevel,
+		Namespace:   config.Namespace,
+		XappName:    config.XappName,
+		XappVersion: config.XappVersion,
+	}
+	if config.Timeout != "" {
+		if opts.Timeout, err = time.ParseDuration(config.Timeout); err != nil {
+			return opts, fmt.Errorf("Invalid timeout in xApp configuration: %v", err)
+		}
+	}
+	if config.RetryInterval != "" {
+		if opts.RetryInterval, err = time.ParseDuration(config.RetryInterval); err != nil {
+			return opts, fmt.Errorf("Invalid retryInterval in xApp configuration: %v", err)
+		}
+	}
+	return opts, nil
+}
+
+// Creates a client configured by an xApp configuration file, XappConfigPath() when path is empty. As
+// NewTimeSeriesClientData(), it does not connect. The retention policy of the configuration, see XappConfig(), is
+// for the creation of the DB with CreateTimeSeriesDBNamed(). See StartXappConfigWatch() for reloading the file
+func NewTimeSeriesClientFromXappConfig(path string) (*TimeSeriesClientData, error) {
+	if path == "" {
+		path = XappConfigPath()
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config, err := _parseXappConfig(content)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := config.Options()
+	if err != nil {
+		return nil, err
+	}
+	timeserData, err := NewTimeSeriesClientWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	timeserData.xappConfig = &xappConfigWatcher{path: path, config: config, content: content}
+	timeserData.logger().Infof("TimeSeriesDB client configured from %v\n", path)
+	return timeserData, nil
+}
+
+// Returns the configuration last loaded from the xApp configuration file
+func (timeserData *TimeSeriesClientData) XappConfig() XappConfig {
+	watcher := timeserData.xappConfig
+	if watcher == nil {
+		return XappConfig{}
+	}
+	watcher.lock.Lock()
+	defer watcher.lock.Unlock()
+	return watcher.config
+}
+
+// Re-reads the xApp configuration file of a client created by NewTimeSeriesClientFromXappConfig() and applies the
+// changes of credentials (reconnecting), logging level and retention policy (when connected). The other settings
+// need a new client, their changes are logged and applied when the xApp restarts
+func (timeserData *TimeSeriesClientData) ReloadXappConfig() (err error) {
+	watcher := timeserData.xappConfig
+	if watcher == nil {
+		return errors.New("Client not created from an xApp configuration")
+	}
+	watcher.lock.Lock()
+	defer watcher.lock.Unlock()
+
+	content, err := ioutil.ReadFile(watcher.path)
+	if err != nil || bytes.Equal(content, watcher.content) {
+		return err
+	}
+	config, err := _parseXappConfig(content)
+	if err == nil {
+		_, err = config.Options()
+	}
+	if err != nil {
+		timeserData.logger().Errorf("Failed to reload TimeSeriesDB configuration from %v: %v\n", watcher.path, err)
+		return err
+	}
+	previous := watcher.config
+	watcher.config, watcher.content = config, content
+
+	if config.LogLevel != previous.LogLevel && config.LogLevel != "" {
+		SetLoggingLevel(config.LogLevel)
+	}
+	if config.UserName != previous.UserName || config.Password != previous.Password {
+		timeserData.credLock.Lock()
+		timeserData.timeSeriesUserName, timeserData.timeSeriesPassword = config.UserName, config.Password
+		timeserData.credLock.Unlock()
+		err = timeserData.reconnect()
+	}
+	if err == nil && config.RetentionPolicy != "" && timeserData.Iclient != nil &&
+		(config.RetentionPolicy != previous.RetentionPolicy || config.RetentionDuration != previous.RetentionDuration) {
+		// The policy may be new to the DB
+		if err = timeserData.UpdateRetentionPolicy(config.RetentionPolicy, config.RetentionDuration, true); err != nil {
+			err = timeserData.CreateRetentionPolicy(config.RetentionPolicy, config.RetentionDuration, true)
+		}
+	}
+
+	changed := previous
+	changed.UserName, changed.Password, changed.LogLevel = config.UserName, config.Password, config.LogLevel
+	changed.RetentionPolicy, changed.RetentionDuration = config.RetentionPolicy, config.RetentionDuration
+	if changed != config {
+		timeserData.logger().Warnf("TimeSeriesDB configuration changed in %v, endpoint, DB and write settings are applied on restart\n", watcher.path)
+	}
+	if err != nil {
+		timeserData.logger().Errorf("Failed to apply TimeSeriesDB configuration from %v: %v\n", watcher.path, err)
+	} else {
+		timeserData.logger().Infof("TimeSeriesDB configuration reloaded from %v\n", watcher.path)
+	}
+	return err
+}
+
+// Reloads the xApp configuration file every interval (DefaultXappConfigInterval if 0) when it changed, and when
+// the process receives one of signals (eg. syscall.SIGUSR1), until StopXappConfigWatch() or Close()
+func (timeserData *TimeSeriesClientData) StartXappConfigWatch(interval time.Duration, signals ...os.Signal) error {
+	watcher := timeserData.xappConfig
+	if watcher == nil {
+		return errors.New("Client not created from an xApp configuration")
+	}
+	if interval <= 0 {
+		interval = DefaultXappConfigInterval
+	}
+	watcher.lock.Lock()
+	if watcher.stop != nil {
+		watcher.lock.Unlock()
+		return nil
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	watcher.stop, watcher.done = stop, done
+	watcher.lock.Unlock()
+
+	received := make(chan os.Signal, 1)
+	if len(signals) > 0 {
+		signal.Notify(received, signals...)
+	}
+	go func() {
+		defer close(done)
+		defer signal.Stop(received)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			case <-received:
+			}
+			_ = timeserData.ReloadXappConfig()
+		}
+	}()
+	return nil
+}
+
+// Stops reloading the xApp configuration file
+func (timeserData *TimeSeriesClientData) StopXappConfigWatch() {
+	watcher := timeserData.xappConfig
+	if watcher == nil {
+		return
+	}
+	watcher.lock.Lock()
+	stop, done := watcher.stop, watcher.done
+	watcher.stop = nil
+	watcher.lock.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// Parses the client configuration out of the content of an xApp configuration file
+func _parseXappConfig(content []byte) (config XappConfig, err error) {
+	var file struct {
+		Name     string `json:"name"`
+		Version  string `json:"version"`
+		Controls struct {
+			TimeSeriesDB *XappConfig `json:"timeSeriesDB"`
+		} `json:"controls"`
+	}
+	if err = json.Unmarshal(content, &file); err != nil {
+		return config, fmt.Errorf("Invalid xApp configuration: %v", err)
+	}
+	if file.Controls.TimeSeriesDB == nil {
+		return config, ErrNoXappConfig
+	}
+	config = *file.Controls.TimeSeriesDB
+	config.XappName, config.XappVersion = file.Name, file.Version
+	return config, nil
+}
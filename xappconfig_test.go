@@ -0,0 +1,136 @@
+//
+// Copyright 2022 Parallel Wireless
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//  This source code is part of the near-RT RIC (RAN Intelligent Controller)
+//  platform project (RICP).
+
+package stslgo_test
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"stslgo"
+)
+
+// Test function for configuring a client from the xApp configuration file and reloading it
+func TestTimeSeriesDbXappConfig(t *testing.T) {
+	var lock sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		lock.Lock()
+		requests = append(requests, user+":"+password+" "+r.FormValue("q"))
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"results":[{"statement_id":0}]}`))
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(serverURL.Host)
+
+	dir, err := ioutil.TempDir("", "stslgo-xapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("XAPP_DESCRIPTOR_PATH", dir)
+	defer os.Unsetenv("XAPP_DESCRIPTOR_PATH")
+	configFile := filepath.Join(dir, "config-file.json")
+	writeConfig := func(password, duration string) {
+		content := `{"name": "kpimon", "version": "1.2.0", "controls": {"timeSeriesDB": {"host": "` + host + `", "port": "` + port + `", "dbName": "kpimon",
+			"userName": "xapp", "password": "` + password + `", "retentionPolicy": "raw", "retentionDuration": "` + duration + `",
+			"timeout": "5s", "batchSize": 500, "precision": "ms"}}}`
+		if err := ioutil.WriteFile(configFile, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err = stslgo.NewTimeSeriesClientFromXappConfig(""); err == nil {
+		t.Errorf("Expected error for a missing configuration file")
+	}
+	if err = ioutil.WriteFile(configFile, []byte(`{"controls": {}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = stslgo.ReadXappConfig(""); err != stslgo.ErrNoXappConfig {
+		t.Errorf("Expected ErrNoXappConfig, got %v", err)
+	}
+
+	writeConfig("secret1", "7d")
+	timeserData, err := stslgo.NewTimeSeriesClientFromXappConfig("")
+	if err != nil {
+		t.Fatalf("Unable to create client with error %v", err)
+	}
+	if config := timeserData.XappConfig(); config.DbName != "kpimon" || config.BatchSize != 500 || config.RetentionDuration != "7d" || config.XappVersion != "1.2.0" {
+		t.Errorf("Unexpected configuration %+v", config)
+	}
+	timeserData.SetReconnectPolicy(stslgo.ReconnectPolicy{})
+	if err = timeserData.CreateTimeSeriesConnection(); err != nil {
+		t.Fatalf("Unable to connect with error %v", err)
+	}
+	defer timeserData.Close()
+	if _, err = timeserData.Query("SHOW MEASUREMENTS"); err != nil {
+		t.Fatalf("Query failed with error %v", err)
+	}
+
+	// Unchanged file is not applied again
+	if err = timeserData.ReloadXappConfig(); err != nil {
+		t.Fatalf("Reload failed with error %v", err)
+	}
+	writeConfig("secret2", "30d")
+	if err = timeserData.ReloadXappConfig(); err != nil {
+		t.Fatalf("Reload failed with error %v", err)
+	}
+	if _, err = timeserData.Query("SHOW MEASUREMENTS"); err != nil {
+		t.Fatalf("Query failed with error %v", err)
+	}
+
+	// Reload on signal
+	if err = timeserData.StartXappConfigWatch(time.Hour, os.Interrupt); err != nil {
+		t.Fatalf("Unable to watch configuration with error %v", err)
+	}
+	writeConfig("secret2", "90d")
+	process, _ := os.FindProcess(os.Getpid())
+	if err = process.Signal(os.Interrupt); err != nil {
+		t.Skipf("Unable to signal the test process: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for timeserData.XappConfig().RetentionDuration != "90d" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	timeserData.StopXappConfigWatch()
+
+	lock.Lock()
+	defer lock.Unlock()
+	expected := []string{
+		"xapp:secret1 SHOW MEASUREMENTS",
+		"xapp:secret2 ALTER RETENTION POLICY raw ON kpimon DURATION 30d SHARD DURATION 30d DEFAULT",
+		"xapp:secret2 SHOW MEASUREMENTS",
+		"xapp:secret2 ALTER RETENTION POLICY raw ON kpimon DURATION 90d SHARD DURATION 90d DEFAULT",
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected requests\n%v", strings.Join(requests, "\n"))
+	}
+}